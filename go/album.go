@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// albumGain is the ReplayGain album gain of a batch treated as one album
+type albumGain struct {
+	Gain float64 // dB
+	Peak float64 // linear, the highest true peak of the album
+}
+
+// tagArgs are the album tags written next to each file's track tags
+func (a *albumGain) tagArgs() []string {
+	if a == nil {
+		return nil
+	}
+	return []string{
+		"-metadata", fmt.Sprintf("REPLAYGAIN_ALBUM_GAIN=%.2f dB", a.Gain),
+		"-metadata", fmt.Sprintf("REPLAYGAIN_ALBUM_PEAK=%.6f", a.Peak),
+	}
+}
+
+// measureAlbumGain measures the files of a batch together for their album
+// gain before any of them is tagged. Returns nil when album mode doesn't
+// apply or the measurement failed, the files then only get track tags.
+func (n *AudioNormalizer) measureAlbumGain(files []string, cfg ProcessConfig) *albumGain {
+	if !cfg.AlbumMode {
+		return nil
+	}
+	if !cfg.writeTags {
+		n.logStatus("⚠ Album mode only applies when writing ReplayGain tags")
+		return nil
+	}
+	if len(files) < 2 {
+		n.logStatus("⚠ Album mode needs at least two files, writing track tags only")
+		return nil
+	}
+
+	n.logStatus(fmt.Sprintf("→ Measuring %d files as one album", len(files)))
+	measured := 0
+	album, err := audio.MeasureAlbum(files, func(path string) ffmpeg.Watchdog {
+		wd := n.jobWatchdog(path)
+		wd.Context = cfg.ctx
+		return wd
+	}, func(path string) {
+		measured++
+		n.logToFile(n.logFile, fmt.Sprintf("Album measurement %d/%d: %s", measured, len(files), path))
+	})
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Album measurement failed, writing track tags only: %v", err))
+		return nil
+	}
+
+	target, targetTp := n.fileTargets("", cfg)
+	targetI, _ := strconv.ParseFloat(target, 64)
+	ceiling, _ := strconv.ParseFloat(targetTp, 64)
+	maxGain, capped, _ := parseMaxTrackGain(cfg.MaxTrackGain)
+
+	// The album is held to the same cap and clipping rules as its tracks
+	g := computeMusicLibGain(album.Integrated, album.Peak, targetI, ceiling, maxGain, capped && cfg.MusicLibrary, cfg.PreventClipping || cfg.TagClipCap)
+	if issue := g.issue(); issue != "" {
+		n.logStatus(fmt.Sprintf("⚠ Album %s (%.2f dB tagged, peak after gain %.1f dB)", issue, g.Gain, g.PeakAfter))
+	}
+
+	n.logStatus(fmt.Sprintf("✓ Album: %.1f LUFS, peak %.1f dBTP, album gain %.2f dB", album.Integrated, album.Peak, g.Gain))
+	n.logToFile(n.logFile, fmt.Sprintf("Album of %d files in %s: %.2f LUFS, %.2f dBTP, gain %.2f dB", len(files), filepath.Dir(files[0]), album.Integrated, album.Peak, g.Gain))
+	return &albumGain{Gain: g.Gain, Peak: audio.DbToLinear(album.Peak)}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// fullBandBranch names the whole signal's statistics in an analysis run
+const fullBandBranch = "full"
+
+// multibandBranches are the bands the Broadcast multiband compressor is set from
+func multibandBranches() []audio.AnalysisBranch {
+	var branches []audio.AnalysisBranch
+	for name, filter := range audio.FrequencyBandFilters() {
+		branches = append(branches, audio.AnalysisBranch{Name: name, Filter: filter})
+	}
+	return branches
+}
+
+// analysisPass is one analysis run over a state of a file: the whole
+// signal, and the compressor bands when the Broadcast preset needs them.
+// A state is a file and the stage filters still to be applied to it.
+type analysisPass struct {
+	path    string
+	chain   string
+	bands   bool
+	reports map[string]audio.AstatsReport
+}
+
+// full returns the whole signal's statistics
+func (p *analysisPass) full() audio.AstatsReport {
+	return p.reports[fullBandBranch]
+}
+
+// analysisCache keeps a file's last analysis run. The Dynamics Score,
+// dynamic normalization, the peak check and compression all read the same
+// statistics, and only need a new run when a stage has changed the audio.
+type analysisCache struct {
+	last *analysisPass
+}
+
+// release drops the cached run, the statistics of every branch, once a file
+// needs no more analysis
+func (c *analysisCache) release() {
+	c.last = nil
+}
+
+// analyze measures path through chain in a single decode, or returns the
+// cached run of the same audio
+func (n *AudioNormalizer) analyze(cache *analysisCache, path, chain string, bands bool) (*analysisPass, error) {
+	if last := cache.last; last != nil && last.path == path && last.chain == chain && (last.bands || !bands) {
+		return last, nil
+	}
+
+	branches := []audio.AnalysisBranch{{Name: fullBandBranch}}
+	if bands {
+		branches = append(branches, multibandBranches()...)
+	}
+
+	reports, err := audio.Analyze(path, chain, branches)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Analysis of %s failed: %v", path, err))
+		return nil, err
+	}
+	n.logToFile(n.logFile, fmt.Sprintf("Analyzed %s in one pass, %d measurements", path, len(branches)))
+
+	cache.last = &analysisPass{path: path, chain: chain, bands: bands, reports: reports}
+	return cache.last, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// analyzerTimeout is how long an analyzer may take on one file
+const analyzerTimeout = 10 * time.Minute
+
+var ErrAnalyzerLine = errors.New("analyzer lines are \"name = program\" or \"gate name = program\"")
+
+// AnalyzerPlugin is an external program run on each file of a preset before
+// processing, such as an in-house profanity detector. It gets the file path
+// as its only argument and prints a JSON object of metrics. A "pass" of
+// false, with an optional "reason", rejects the file when the analyzer gates.
+type AnalyzerPlugin struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Gate    bool   `json:"gate,omitempty"`
+}
+
+// analyzerResult is what an analyzer reported for one file
+type analyzerResult struct {
+	Metrics map[string]any `json:"metrics,omitempty"`
+	Pass    bool           `json:"pass"`
+	Reason  string         `json:"reason,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// withoutUntrusted strips what a preset from another machine mustn't bring
+// along: the programs its analyzers would run on every file, and an output
+// folder that is absolute or climbs out of the working folder. Returns what
+// was stripped, for the user to add back once they trust it.
+func (p Preset) withoutUntrusted() (Preset, []string) {
+	var stripped []string
+	for _, a := range p.Analyzers {
+		stripped = append(stripped, fmt.Sprintf("%s: analyzer %s (%s)", p.Name, a.Name, a.Command))
+	}
+	p.Analyzers = nil
+	if p.OutputDir != "" && !filepath.IsLocal(p.OutputDir) {
+		stripped = append(stripped, fmt.Sprintf("%s: output folder %s", p.Name, p.OutputDir))
+		p.OutputDir = ""
+	}
+	return p, stripped
+}
+
+// parseAnalyzerLines reads the analyzers typed into the presets tab, one per line
+func parseAnalyzerLines(text string) ([]AnalyzerPlugin, error) {
+	var analyzers []AnalyzerPlugin
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, command, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrAnalyzerLine, line)
+		}
+		a := AnalyzerPlugin{Name: strings.TrimSpace(name), Command: strings.TrimSpace(command)}
+		if rest, gated := strings.CutPrefix(a.Name, "gate "); gated {
+			a.Name, a.Gate = strings.TrimSpace(rest), true
+		}
+		if a.Name == "" || a.Command == "" {
+			return nil, fmt.Errorf("%w: %s", ErrAnalyzerLine, line)
+		}
+		analyzers = append(analyzers, a)
+	}
+	return analyzers, nil
+}
+
+// run runs the analyzer on one file
+func (a AnalyzerPlugin) run(inputPath string) analyzerResult {
+	ctx, cancel := context.WithTimeout(context.Background(), analyzerTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, a.Command, platform.LongPath(inputPath))
+	platform.HideWindow(cmd)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return analyzerResult{Error: err.Error()}
+	}
+
+	var metrics map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &metrics); err != nil {
+		return analyzerResult{Error: fmt.Sprintf("output is not a JSON object: %v", err)}
+	}
+
+	result := analyzerResult{Metrics: metrics, Pass: true}
+	if pass, ok := metrics["pass"].(bool); ok {
+		result.Pass = pass
+		delete(metrics, "pass")
+	}
+	if reason, ok := metrics["reason"].(string); ok {
+		result.Reason = reason
+		delete(metrics, "reason")
+	}
+	return result
+}
+
+// runAnalyzers runs the preset's analyzers on a file and reports whether it
+// may be processed. A gating analyzer that fails or can't run holds the file
+// back, others only warn.
+func (n *AudioNormalizer) runAnalyzers(inputPath string, cfg ProcessConfig) (map[string]analyzerResult, bool) {
+	name := filepath.Base(inputPath)
+	results := make(map[string]analyzerResult, len(cfg.Analyzers))
+	allowed := true
+
+	for _, a := range cfg.Analyzers {
+		result := a.run(inputPath)
+		results[a.Name] = result
+
+		switch {
+		case result.Error != "":
+			n.logToFile(n.logFile, fmt.Sprintf("Analyzer %s on %s failed: %s", a.Name, inputPath, result.Error))
+			if a.Gate {
+				n.logStatus(fmt.Sprintf("✗ Analyzer %s failed, file held back: %s", a.Name, name))
+				allowed = false
+			} else {
+				n.logStatus(fmt.Sprintf("⚠ Analyzer %s failed: %s", a.Name, name))
+			}
+		case !result.Pass:
+			reason := result.Reason
+			if reason == "" {
+				reason = "no reason given"
+			}
+			n.logToFile(n.logFile, fmt.Sprintf("Analyzer %s rejected %s: %s", a.Name, inputPath, reason))
+			if a.Gate {
+				n.logStatus(fmt.Sprintf("⚠ Rejected by %s (%s): %s", a.Name, reason, name))
+				allowed = false
+			} else {
+				n.logStatus(fmt.Sprintf("⚠ Flagged by %s (%s): %s", a.Name, reason, name))
+			}
+		default:
+			n.logToFile(n.logFile, fmt.Sprintf("Analyzer %s on %s: %v", a.Name, inputPath, result.Metrics))
+		}
+	}
+	return results, allowed
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// loudnorm only accepts integrated targets in this range
+const (
+	minLoudnormTarget = -70.0
+	maxLoudnormTarget = -5.0
+)
+
+// setAnchor stores the anchor region typed in for a queued file
+func (n *AudioNormalizer) setAnchor(path string, region audio.AnchorRegion) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.anchors == nil {
+		n.anchors = make(map[string]audio.AnchorRegion)
+	}
+	n.anchors[path] = region
+}
+
+// clearAnchor removes a file's anchor so the whole file is measured again
+func (n *AudioNormalizer) clearAnchor(path string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	delete(n.anchors, path)
+}
+
+// anchorText formats a stored anchor for the queue row
+func (n *AudioNormalizer) anchorText(path string) string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if region, ok := n.anchors[path]; ok {
+		return region.String()
+	}
+	return ""
+}
+
+// anchorFor returns the anchor of a file: the one typed into the queue, or
+// else a marker in the file when markers are enabled
+func (n *AudioNormalizer) anchorFor(inputPath string, cfg ProcessConfig) (audio.AnchorRegion, string, error) {
+	n.mutex.Lock()
+	region, ok := n.anchors[inputPath]
+	n.mutex.Unlock()
+	if ok {
+		return region, "queue", nil
+	}
+
+	if !cfg.AnchorMarkers {
+		return audio.AnchorRegion{}, "", audio.ErrNoAnchor
+	}
+	region, err := audio.ReadAnchorMarker(inputPath)
+	return region, "marker", err
+}
+
+// anchoredTarget moves the loudness target so that linear normalization of
+// the whole file lands the anchor, not the programme average, on target.
+// Returns the target unchanged when the file has no anchor.
+func (n *AudioNormalizer) anchoredTarget(inputPath, workingPath string, cfg ProcessConfig, target string, measured map[string]string) (string, error) {
+	region, source, err := n.anchorFor(inputPath, cfg)
+	if errors.Is(err, audio.ErrNoAnchor) {
+		return target, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("anchor %s: %w", source, err)
+	}
+
+	// Anchors are typed against the whole file, the working copy starts at the in point
+	if trim, ok := n.trimFor(inputPath); ok && workingPath != inputPath {
+		region.Start -= trim.In
+		region.End -= trim.In
+	}
+
+	anchorI, err := audio.MeasureAnchor(workingPath, region)
+	if err != nil {
+		return "", fmt.Errorf("measuring anchor %s: %w", region, err)
+	}
+	wholeI, err := strconv.ParseFloat(measured["input_i"], 64)
+	if err != nil {
+		return "", fmt.Errorf("no integrated loudness to anchor against")
+	}
+	targetI, err := strconv.ParseFloat(target, 64)
+	if err != nil {
+		return "", err
+	}
+
+	shifted := targetI + wholeI - anchorI
+	if shifted < minLoudnormTarget || shifted > maxLoudnormTarget {
+		return "", fmt.Errorf("anchor at %.1f LUFS is %.1f LU from the whole file, too far to normalize", anchorI, anchorI-wholeI)
+	}
+
+	n.logStatus(fmt.Sprintf("Anchor %s (%s) measures %.1f LUFS, whole file %.1f LUFS: %s", region, source, anchorI, wholeI, filepath.Base(inputPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("Anchor %s of %s from %s: %.2f LUFS, whole file %.2f LUFS, target moved to %.1f LUFS", region, inputPath, source, anchorI, wholeI, shifted))
+	return fmt.Sprintf("%.1f", shifted), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	auditLogName       = "processing_history.csv"
+	auditSidecarSuffix = ".audit.json"
+)
+
+var auditHeader = []string{"time", "os_user", "operator", "profile", "host", "input", "output", "result", "format", "target_lufs", "settings", "elapsed_s", "measured_by", "standard"}
+
+// auditRecord is who processed a file, when, and with which settings
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	OSUser   string    `json:"os_user"`
+	Operator string    `json:"operator,omitempty"`
+	Profile  string    `json:"profile"`
+	Host     string    `json:"host"`
+	Input    string    `json:"input"`
+	Output   string    `json:"output,omitempty"`
+	Result   string    `json:"result"`
+	Format   string    `json:"format"`
+	TargetI  string    `json:"target_lufs,omitempty"`
+	Settings string    `json:"settings"`
+	Elapsed  float64   `json:"elapsed_s"`
+	Version  string    `json:"tnt_version"`
+	// MeasuredBy is the loudness backend behind the tags and target, see loudness.go
+	MeasuredBy string `json:"measured_by,omitempty"`
+	// Standard is the BS.1770 revision it measured to
+	Standard string `json:"standard,omitempty"`
+	// Analyzers holds the preset's analyzer results by analyzer name
+	Analyzers map[string]analyzerResult `json:"analyzers,omitempty"`
+}
+
+// osUserName returns the logged in account, falling back to the environment
+func osUserName() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// row returns the record as a processing history CSV row
+func (r auditRecord) row() []string {
+	return []string{
+		r.Time.Format(time.RFC3339), r.OSUser, r.Operator, r.Profile, r.Host,
+		r.Input, r.Output, r.Result, r.Format, r.TargetI, r.Settings,
+		fmt.Sprintf("%.1f", r.Elapsed), r.MeasuredBy, r.Standard,
+	}
+}
+
+// recordAudit appends a processed file to the processing history and, for
+// successful outputs, writes a sidecar next to the output when enabled
+func (n *AudioNormalizer) recordAudit(inputPath, outputPath string, cfg ProcessConfig, ok bool, started time.Time) {
+	if cfg.simulation {
+		return
+	}
+	host, _ := os.Hostname()
+
+	n.mutex.Lock()
+	operator := n.operator
+	n.mutex.Unlock()
+	target, _ := n.normalizationTargets()
+	if cfg.TargetI != "" {
+		target = cfg.TargetI
+	}
+
+	record := auditRecord{
+		Time:       time.Now(),
+		OSUser:     osUserName(),
+		Operator:   operator,
+		Profile:    profileLabel(),
+		Host:       host,
+		Input:      inputPath,
+		Output:     outputPath,
+		Result:     "success",
+		Format:     cfg.Format,
+		Settings:   speedKey(cfg),
+		Elapsed:    time.Since(started).Seconds(),
+		Version:    currentVersion,
+		Analyzers:  cfg.analysis,
+		MeasuredBy: cfg.measuredBy,
+		Standard:   cfg.measuredTo,
+	}
+	if cfg.UseLoudnorm || cfg.writeTags {
+		record.TargetI = target
+	}
+	if !ok {
+		record.Result = "failed"
+	}
+
+	if err := appendCSVRow(filepath.Join(dataDir(), auditLogName), auditHeader, record.row()); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Processing history write failed: %v", err))
+	}
+
+	if !ok || !cfg.AuditSidecar || outputPath == "" {
+		return
+	}
+
+	data, _ := json.MarshalIndent(record, "", "  ")
+	if err := os.WriteFile(platform.LongPath(outputPath+auditSidecarSuffix), data, 0644); err != nil {
+		n.logStatus(fmt.Sprintf("⚠ Could not write audit sidecar: %s - %v", filepath.Base(outputPath), err))
+		n.logToFile(n.logFile, fmt.Sprintf("Audit sidecar for %s failed: %v", outputPath, err))
+	}
+}
+
+// askOperator asks who is at the desk, for the processing history
+func (n *AudioNormalizer) askOperator() {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Your name or initials")
+
+	dialog.ShowForm("Operator", "OK", "Skip", []*widget.FormItem{
+		widget.NewFormItem("Operator", entry),
+	}, func(confirmed bool) {
+		if confirmed {
+			n.mutex.Lock()
+			n.operator = strings.TrimSpace(entry.Text)
+			n.mutex.Unlock()
+			n.logToFile(n.logFile, fmt.Sprintf("Operator: %s", entry.Text))
+		}
+	}, n.window)
+}
+
+// openProcessingHistory opens the processing history in the program the
+// system opens CSV files with
+func (n *AudioNormalizer) openProcessingHistory() {
+	path := filepath.Join(dataDir(), auditLogName)
+	if _, err := os.Stat(path); err != nil {
+		dialog.ShowInformation("Processing history", "Nothing has been processed yet.", n.window)
+		return
+	}
+	if err := platform.OpenFile(path); err != nil {
+		dialog.ShowError(err, n.window)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	badgeWidth  = 260
+	badgeHeight = 84
+)
+
+// LoudnessBadge holds the values drawn onto a companion badge PNG
+type LoudnessBadge struct {
+	TargetLUFS   string
+	MeasuredLUFS string
+	TruePeak     string
+	MeasuredBy   string
+	Standard     string
+	Date         time.Time
+}
+
+// badgePathFor returns the badge path that sits next to the given output file
+func badgePathFor(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".loudness.png"
+}
+
+// writeLoudnessBadge renders the badge for an output file and returns the written path
+func writeLoudnessBadge(outputPath string, badge LoudnessBadge) (string, error) {
+	img := image.NewRGBA(image.Rect(0, 0, badgeWidth, badgeHeight))
+
+	// Navy background with the light theme accent as a left stripe
+	navy := color.RGBA{R: 0x14, G: 0x1e, B: 0x30, A: 0xff}
+	accent := color.RGBA{R: 0xde, G: 0x79, B: 0x7c, A: 0xff}
+	draw.Draw(img, img.Bounds(), &image.Uniform{navy}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, 6, badgeHeight), &image.Uniform{accent}, image.Point{}, draw.Src)
+
+	lines := []struct {
+		text string
+		col  color.Color
+	}{
+		{"TNT LOUDNESS", accent},
+		{fmt.Sprintf("Target:   %s LUFS", badge.TargetLUFS), color.White},
+		{fmt.Sprintf("Measured: %s LUFS (%s)", badge.MeasuredLUFS, badge.MeasuredBy), color.White},
+		{fmt.Sprintf("True peak: %s dBTP", badge.TruePeak), color.White},
+		{strings.TrimSpace(badge.Date.Format("2006-01-02") + "  " + badge.Standard), color.RGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff}},
+	}
+
+	face := basicfont.Face7x13
+	for i, line := range lines {
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(line.col),
+			Face: face,
+			Dot:  fixed.P(14, 16+i*15),
+		}
+		d.DrawString(line.text)
+	}
+
+	badgePath := badgePathFor(outputPath)
+	f, err := os.Create(platform.LongPath(badgePath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return "", err
+	}
+
+	return badgePath, nil
+}
+
+// generateLoudnessBadge measures a finished output and writes its badge
+func (n *AudioNormalizer) generateLoudnessBadge(outputPath string, target string, options audio.LoudnormOptions) {
+	measured := n.measureLoudness(outputPath, measureForReport, "", "", options)
+	if measured == nil || measured["input_i"] == "" {
+		n.logStatus(fmt.Sprintf("✗ Could not measure output for badge: %s", filepath.Base(outputPath)))
+		return
+	}
+
+	badgePath, err := writeLoudnessBadge(outputPath, LoudnessBadge{
+		TargetLUFS:   target,
+		MeasuredLUFS: measured["input_i"],
+		TruePeak:     measured["input_tp"],
+		MeasuredBy:   measured[measuredByKey],
+		Standard:     measured[standardKey],
+		Date:         time.Now(),
+	})
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to write loudness badge: %v", err))
+		n.logToFile(n.logFile, fmt.Sprintf("Badge write failed for %s: %v", outputPath, err))
+		return
+	}
+
+	n.logToFile(n.logFile, fmt.Sprintf("Loudness badge written: %s", badgePath))
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// batchHistorySaveEvery is how many new runs are kept in memory before the
+// history is written, so a crash mid-batch loses little and large batches
+// don't rewrite the file per file
+const batchHistorySaveEvery = 50
+
+// sourceFingerprint caches a file's content hash for as long as its size
+// and modification time stay the same
+type sourceFingerprint struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash"`
+}
+
+// batchRun is a successful run of one source with one set of settings
+type batchRun struct {
+	Source    string    `json:"source"`
+	Output    string    `json:"output"`
+	Processed time.Time `json:"processed"`
+}
+
+// batchHistory records successful runs by source content and settings, so
+// recurring library runs can skip what they already produced
+type batchHistory struct {
+	mutex   sync.Mutex
+	path    string
+	unsaved int
+	Sources map[string]sourceFingerprint `json:"sources"`
+	Runs    map[string]batchRun          `json:"runs"`
+}
+
+// loadBatchHistory reads the stored history, starting empty when there is none
+func loadBatchHistory() *batchHistory {
+	h := &batchHistory{path: filepath.Join(dataDir(), "batch_history.json")}
+
+	if data, err := os.ReadFile(h.path); err == nil {
+		json.Unmarshal(data, h)
+	}
+	if h.Sources == nil {
+		h.Sources = make(map[string]sourceFingerprint)
+	}
+	if h.Runs == nil {
+		h.Runs = make(map[string]batchRun)
+	}
+	return h
+}
+
+// save writes the history to disk, the caller holds the mutex
+func (h *batchHistory) save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	h.unsaved = 0
+	return os.WriteFile(h.path, data, 0644)
+}
+
+// flush writes runs recorded since the last save
+func (h *batchHistory) flush() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.unsaved == 0 {
+		return nil
+	}
+	return h.save()
+}
+
+// clear forgets every run, so the next batch processes everything again
+func (h *batchHistory) clear() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.Sources = make(map[string]sourceFingerprint)
+	h.Runs = make(map[string]batchRun)
+	return h.save()
+}
+
+// sourceHash returns the SHA-256 of a file's content, hashing it only when
+// it changed since it was last seen
+func (h *batchHistory) sourceHash(path string) (string, error) {
+	info, err := os.Stat(platform.LongPath(path))
+	if err != nil {
+		return "", err
+	}
+
+	h.mutex.Lock()
+	cached, ok := h.Sources[path]
+	h.mutex.Unlock()
+	if ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return cached.Hash, nil
+	}
+
+	f, err := os.Open(platform.LongPath(path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	hash := hex.EncodeToString(sum.Sum(nil))
+
+	h.mutex.Lock()
+	h.Sources[path] = sourceFingerprint{Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+	h.mutex.Unlock()
+	return hash, nil
+}
+
+// done returns the earlier run for key when its output is still there
+func (h *batchHistory) done(key string) (batchRun, bool) {
+	h.mutex.Lock()
+	run, ok := h.Runs[key]
+	h.mutex.Unlock()
+
+	if !ok {
+		return batchRun{}, false
+	}
+	if _, err := os.Stat(platform.LongPath(run.Output)); err != nil {
+		return batchRun{}, false
+	}
+	return run, true
+}
+
+// record stores a successful run
+func (h *batchHistory) record(key string, run batchRun) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.Runs[key] = run
+	h.unsaved++
+	if h.unsaved >= batchHistorySaveEvery {
+		return h.save()
+	}
+	return nil
+}
+
+// settingsFingerprint is everything besides the content that shapes one
+// file's output. The source path is part of it since output names derive from
+// it. The channel fix isn't, that follows from the content and ChannelFix.
+type settingsFingerprint struct {
+	Source        string
+	Config        ProcessConfig
+	WriteTags     bool
+	NoTranscode   bool
+	BypassProc    bool
+	DataCompLevel int8
+	TargetI       string
+	TargetTp      string
+	GainTrim      float64
+	Anchor        string
+	Mapping       *tagMapping
+}
+
+// settingsHash fingerprints the settings a file is processed with, including
+// its path, gain trim, anchor and mapped tags
+func (n *AudioNormalizer) settingsHash(inputPath string, cfg ProcessConfig) string {
+	target, targetTp := n.normalizationTargets()
+	if cfg.TargetI != "" {
+		target = cfg.TargetI
+	}
+	if cfg.TargetTp != "" {
+		targetTp = cfg.TargetTp
+	}
+
+	fp := settingsFingerprint{
+		Source:        inputPath,
+		Config:        cfg,
+		WriteTags:     cfg.writeTags,
+		NoTranscode:   cfg.noTranscode,
+		BypassProc:    cfg.bypassProc,
+		DataCompLevel: cfg.dataCompLevel,
+		TargetI:       target,
+		TargetTp:      targetTp,
+		GainTrim:      n.gainTrimFor(inputPath),
+		Anchor:        n.anchorText(inputPath),
+	}
+	if mapped, ok := n.tagMappingFor(inputPath); ok {
+		fp.Mapping = &mapped
+	}
+
+	data, _ := json.Marshal(fp)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// incrementalKey identifies a file's content processed with cfg
+func (n *AudioNormalizer) incrementalKey(inputPath string, cfg ProcessConfig) (string, error) {
+	hash, err := n.batchHistory.sourceHash(inputPath)
+	if err != nil {
+		return "", err
+	}
+	return hash + "/" + n.settingsHash(inputPath, cfg), nil
+}
+
+// alreadyProcessed reports whether an earlier run produced this file with the
+// same settings and its output still exists
+func (n *AudioNormalizer) alreadyProcessed(inputPath string, cfg ProcessConfig) bool {
+	key, err := n.incrementalKey(inputPath, cfg)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Could not hash %s: %v", inputPath, err))
+		return false
+	}
+
+	run, ok := n.batchHistory.done(key)
+	if ok {
+		n.logToFile(n.logFile, fmt.Sprintf("%s unchanged since %s, output %s", inputPath, run.Processed.Format(time.RFC3339), run.Output))
+	}
+	return ok
+}
+
+// recordProcessed stores a successful run for the next incremental batch
+func (n *AudioNormalizer) recordProcessed(key, inputPath, outputPath string) {
+	if key == "" || outputPath == "" {
+		return
+	}
+	err := n.batchHistory.record(key, batchRun{Source: inputPath, Output: outputPath, Processed: time.Now()})
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Batch history write failed: %v", err))
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// batchControl pauses and cancels a running batch. Pausing stops workers
+// from starting new files while running ones finish; cancelling also kills
+// the FFmpeg runs of files in flight through the context of their watchdog.
+type batchControl struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex  sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+func newBatchControl() *batchControl {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &batchControl{ctx: ctx, cancel: cancel, resume: make(chan struct{})}
+}
+
+// setPaused pauses or resumes dispatching new files
+func (c *batchControl) setPaused(paused bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if paused == c.paused {
+		return
+	}
+	c.paused = paused
+	if paused {
+		c.resume = make(chan struct{})
+	} else {
+		close(c.resume)
+	}
+}
+
+// isPaused reports whether dispatching is paused
+func (c *batchControl) isPaused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.paused
+}
+
+// wait blocks while the batch is paused and returns false once it is cancelled
+func (c *batchControl) wait() bool {
+	c.mutex.Lock()
+	resume := c.resume
+	paused := c.paused
+	c.mutex.Unlock()
+
+	if paused {
+		select {
+		case <-resume:
+		case <-c.ctx.Done():
+		}
+	}
+	return c.ctx.Err() == nil
+}
+
+// stop cancels the batch, waking paused workers so they can drain the queue
+func (c *batchControl) stop() {
+	c.cancel()
+}
+
+// cancelled reports whether the batch was cancelled
+func (c *batchControl) cancelled() bool {
+	return c.ctx.Err() != nil
+}
+
+// pauseBatch pauses or resumes the running batch from its Pause button
+func (n *AudioNormalizer) pauseBatch() {
+	n.mutex.Lock()
+	control := n.batchControl
+	n.mutex.Unlock()
+	if control == nil {
+		return
+	}
+
+	if control.isPaused() {
+		control.setPaused(false)
+		n.pauseBtn.SetText("Pause")
+		n.logStatus("→ Resumed")
+	} else {
+		control.setPaused(true)
+		n.pauseBtn.SetText("Resume")
+		n.logStatus("⊗ Paused, files already running will finish")
+	}
+}
+
+// cancelBatch stops the running batch, killing the FFmpeg runs in flight
+func (n *AudioNormalizer) cancelBatch() {
+	n.mutex.Lock()
+	control := n.batchControl
+	n.mutex.Unlock()
+	if control == nil || control.cancelled() {
+		return
+	}
+
+	control.stop()
+	n.cancelBtn.Disable()
+	n.pauseBtn.Disable()
+	n.logStatus("⊗ Cancelling, stopping the files in progress...")
+}
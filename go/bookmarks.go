@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// rememberedFolders returns every folder TNT reopens after a restart: the
+// output folder, recent and favorite folders, preset output folders and the
+// watch folders with their output folders
+func (n *AudioNormalizer) rememberedFolders() []string {
+	folders := []string{n.outputDir}
+	folders = append(folders, n.recentFolders...)
+	folders = append(folders, n.favoriteFolders...)
+	for _, p := range n.presets.list() {
+		folders = append(folders, p.OutputDir)
+	}
+	for _, f := range n.watchFolders.list() {
+		folders = append(folders, f.Dir, f.OutputDir)
+	}
+
+	slices.Sort(folders)
+	folders = slices.Compact(folders)
+	return slices.DeleteFunc(folders, func(f string) bool { return f == "" })
+}
+
+// updateFolderBookmarks bookmarks remembered folders that don't have a
+// bookmark yet and drops bookmarks of folders that are no longer remembered.
+// Returns nil where bookmarks aren't needed.
+func (n *AudioNormalizer) updateFolderBookmarks() map[string][]byte {
+	if !platform.BookmarksSupported {
+		return nil
+	}
+
+	bookmarks := make(map[string][]byte)
+	for _, dir := range n.rememberedFolders() {
+		if data, ok := n.folderBookmarks[dir]; ok {
+			bookmarks[dir] = data
+			continue
+		}
+		data, err := platform.CreateBookmark(dir)
+		if err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("Could not bookmark %s: %v", dir, err))
+			continue
+		}
+		bookmarks[dir] = data
+	}
+
+	n.folderBookmarks = bookmarks
+	return bookmarks
+}
+
+// saveFolderBookmarks stores the bookmarks without the rest of the current
+// settings, for folders remembered outside the preferences like watch folders
+func (n *AudioNormalizer) saveFolderBookmarks() {
+	if !platform.BookmarksSupported {
+		return
+	}
+
+	prefs, err := readPreferences()
+	if err != nil && !os.IsNotExist(err) {
+		n.logToFile(n.logFile, fmt.Sprintf("Folder bookmarks not saved: %v", err))
+		return
+	}
+	prefs.FolderBookmarks = n.updateFolderBookmarks()
+	if err := writePreferences(prefs); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Folder bookmarks not saved: %v", err))
+	}
+}
+
+// resolveFolderBookmarks regains access to the folders saved in prefs before
+// they are applied, watch folders included, so it runs after they are loaded.
+// Folders that were moved or renamed since are updated to their new path.
+func (n *AudioNormalizer) resolveFolderBookmarks(prefs *Preferences) {
+	if !platform.BookmarksSupported {
+		return
+	}
+
+	n.folderBookmarks = make(map[string][]byte)
+	for dir, data := range prefs.FolderBookmarks {
+		resolved, stale, err := platform.ResolveBookmark(data)
+		if err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("Could not regain access to %s: %v", dir, err))
+			continue
+		}
+
+		if stale || resolved != dir {
+			if fresh, err := platform.CreateBookmark(resolved); err == nil {
+				data = fresh
+			}
+		}
+		n.folderBookmarks[resolved] = data
+
+		if resolved == dir {
+			continue
+		}
+		n.logToFile(n.logFile, fmt.Sprintf("Remembered folder %s moved to %s", dir, resolved))
+		if prefs.LastOutputDir == dir {
+			prefs.LastOutputDir = resolved
+		}
+		for i := range prefs.RecentFolders {
+			if prefs.RecentFolders[i] == dir {
+				prefs.RecentFolders[i] = resolved
+			}
+		}
+		for i := range prefs.FavoriteFolders {
+			if prefs.FavoriteFolders[i] == dir {
+				prefs.FavoriteFolders[i] = resolved
+			}
+		}
+		if err := n.watchFolders.relocate(dir, resolved); err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("Watch folders not updated: %v", err))
+		}
+	}
+}
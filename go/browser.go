@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// maxRecentFolders is how many recently used folders the browser remembers
+const maxRecentFolders = 10
+
+// browserEntry is one row in the browser's folder listing
+type browserEntry struct {
+	name  string
+	path  string
+	isDir bool
+}
+
+// rememberFolder puts a folder at the top of the recent folders list
+func (n *AudioNormalizer) rememberFolder(dir string) {
+	n.recentFolders = slices.DeleteFunc(n.recentFolders, func(f string) bool { return f == dir })
+	n.recentFolders = append([]string{dir}, n.recentFolders...)
+	if len(n.recentFolders) > maxRecentFolders {
+		n.recentFolders = n.recentFolders[:maxRecentFolders]
+	}
+}
+
+// toggleFavorite adds a folder to the favorites, or removes it if it's already there
+func (n *AudioNormalizer) toggleFavorite(dir string) {
+	if slices.Contains(n.favoriteFolders, dir) {
+		n.favoriteFolders = slices.DeleteFunc(n.favoriteFolders, func(f string) bool { return f == dir })
+		return
+	}
+	n.favoriteFolders = append(n.favoriteFolders, dir)
+}
+
+// readBrowserFolder lists the subfolders and audio files of a folder, folders first
+func readBrowserFolder(dir string) ([]browserEntry, error) {
+	entries, err := os.ReadDir(platform.LongPath(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var listing []browserEntry
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			listing = append(listing, browserEntry{name: e.Name(), path: path, isDir: true})
+		} else if isAudioFile(path) && !isPartFile(path) {
+			listing = append(listing, browserEntry{name: e.Name(), path: path})
+		}
+	}
+
+	sort.SliceStable(listing, func(i, j int) bool {
+		return listing[i].isDir && !listing[j].isDir
+	})
+
+	return listing, nil
+}
+
+// showBrowser opens the file browser window with recent and favorite folders,
+// where files can be auditioned before they are added to the queue
+func (n *AudioNormalizer) showBrowser() {
+	if n.browserWindow != nil {
+		n.browserWindow.RequestFocus()
+		return
+	}
+
+	var currentDir string
+	var listing []browserEntry
+	var places []string
+
+	pathLabel := widget.NewLabel("No folder selected")
+	pathLabel.Truncation = fyne.TextTruncateEllipsis
+
+	var favoriteBtn *widget.Button
+	var placesList *widget.List
+	var filesList *widget.List
+
+	refreshPlaces := func() {
+		places = append(slices.Clone(n.favoriteFolders), n.recentFolders...)
+		places = slices.Compact(places)
+		placesList.Refresh()
+	}
+
+	openDir := func(dir string) {
+		entries, err := readBrowserFolder(dir)
+		if err != nil {
+			n.logStatus(fmt.Sprintf("✗ Failed to open folder: %v", err))
+			return
+		}
+
+		currentDir = dir
+		listing = entries
+		pathLabel.SetText(dir)
+		if slices.Contains(n.favoriteFolders, dir) {
+			favoriteBtn.SetIcon(theme.ContentRemoveIcon())
+		} else {
+			favoriteBtn.SetIcon(theme.ContentAddIcon())
+		}
+		filesList.UnselectAll()
+		filesList.Refresh()
+	}
+
+	placesList = widget.NewList(
+		func() int { return len(places) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template")
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			name := filepath.Base(places[i])
+			if slices.Contains(n.favoriteFolders, places[i]) {
+				name = "★ " + name
+			}
+			o.(*widget.Label).SetText(name)
+		},
+	)
+	placesList.OnSelected = func(i widget.ListItemID) {
+		openDir(places[i])
+		placesList.UnselectAll()
+	}
+
+	filesList = widget.NewList(
+		func() int { return len(listing) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.MediaPlayIcon(), nil),
+					widget.NewButtonWithIcon("", theme.ContentAddIcon(), nil),
+				),
+				widget.NewLabel("template"),
+			)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			entry := listing[i]
+			border := o.(*fyne.Container)
+			label := border.Objects[0].(*widget.Label)
+			buttons := border.Objects[1].(*fyne.Container)
+			playBtn := buttons.Objects[0].(*widget.Button)
+			addBtn := buttons.Objects[1].(*widget.Button)
+
+			if entry.isDir {
+				label.SetText(entry.name + string(filepath.Separator))
+				playBtn.Hide()
+				addBtn.Hide()
+				return
+			}
+
+			label.SetText(entry.name)
+			playBtn.Show()
+			addBtn.Show()
+			playBtn.OnTapped = func() {
+				go n.playExcerpt(entry.path, "Audition")
+			}
+			addBtn.OnTapped = func() {
+				go func() {
+					if err := audio.ValidateInput(entry.path); err != nil {
+						n.refuseFile(entry.path, err)
+						return
+					}
+					n.addFile(entry.path)
+				}()
+			}
+		},
+	)
+	filesList.OnSelected = func(i widget.ListItemID) {
+		if listing[i].isDir {
+			openDir(listing[i].path)
+		} else {
+			filesList.UnselectAll()
+		}
+	}
+
+	upBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		if currentDir != "" {
+			openDir(filepath.Dir(currentDir))
+		}
+	})
+
+	favoriteBtn = widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
+		if currentDir == "" {
+			return
+		}
+		n.toggleFavorite(currentDir)
+		openDir(currentDir)
+		refreshPlaces()
+	})
+
+	browseBtn := widget.NewButton("Choose folder", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			n.rememberFolder(uri.Path())
+			openDir(uri.Path())
+			refreshPlaces()
+		}, n.browserWindow)
+	})
+
+	addAllBtn := widget.NewButton("Add all files", func() {
+		var files []string
+		for _, entry := range listing {
+			if !entry.isDir {
+				files = append(files, entry.path)
+			}
+		}
+		go func() {
+			for _, file := range n.acceptFiles(files) {
+				n.addFile(file)
+			}
+		}()
+	})
+
+	stopBtn := widget.NewButtonWithIcon("", theme.MediaStopIcon(), platform.StopSound)
+
+	toolbar := container.NewBorder(nil, nil, container.NewHBox(upBtn, favoriteBtn), container.NewHBox(stopBtn, browseBtn), pathLabel)
+	split := container.NewHSplit(
+		container.NewBorder(widget.NewLabel("Favorites and recent"), nil, nil, nil, placesList),
+		container.NewBorder(nil, addAllBtn, nil, nil, filesList),
+	)
+	split.Offset = 0.3
+
+	browserWindow := fyne.CurrentApp().NewWindow("Browser")
+	browserWindow.SetContent(container.NewBorder(toolbar, nil, nil, nil, split))
+	browserWindow.Resize(fyne.NewSize(700, 450))
+
+	n.browserWindow = browserWindow
+	browserWindow.SetOnClosed(func() {
+		platform.StopSound()
+		n.browserWindow = nil
+	})
+
+	refreshPlaces()
+	if len(places) > 0 {
+		openDir(places[0])
+	}
+
+	browserWindow.Show()
+}
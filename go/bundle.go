@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// configBundleVersion is bumped when the bundle layout changes incompatibly
+const configBundleVersion = 1
+
+// Ways of importing a configuration bundle
+const (
+	BundleMerge   = "Merge"
+	BundleReplace = "Replace"
+)
+
+var ErrBundleVersion = errors.New("configuration bundle is from a newer TNT version")
+
+// configBundle is everything needed to set up another workstation identically:
+// preferences (including watch and metadata settings) and presets
+type configBundle struct {
+	Version     int         `json:"bundle_version"`
+	TNTVersion  string      `json:"tnt_version"`
+	Exported    time.Time   `json:"exported"`
+	Preferences Preferences `json:"preferences"`
+	Presets     []Preset    `json:"presets"`
+}
+
+// exportConfigBundle writes the current configuration to w
+func (n *AudioNormalizer) exportConfigBundle(w io.Writer) error {
+	bundle := configBundle{
+		Version:     configBundleVersion,
+		TNTVersion:  currentVersion,
+		Exported:    time.Now(),
+		Preferences: n.currentPreferences(),
+		Presets:     n.presets.list(),
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+// importConfigBundle applies a bundle read from r. Merge keeps this
+// machine's folders and presets that aren't in the bundle; Replace takes
+// everything from the bundle but the presets' analyzers and outside output
+// folders, which are returned.
+func (n *AudioNormalizer) importConfigBundle(r io.Reader, mode string) ([]string, error) {
+	var bundle configBundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("not a TNT configuration bundle: %w", err)
+	}
+	if bundle.Version > configBundleVersion {
+		return nil, ErrBundleVersion
+	}
+
+	prefs := bundle.Preferences
+	if mode == BundleMerge {
+		local := n.currentPreferences()
+		prefs.LastOutputDir = local.LastOutputDir
+		prefs.RecentFolders = local.RecentFolders
+		prefs.FavoriteFolders = local.FavoriteFolders
+	}
+
+	stripped, err := n.presets.importPresets(bundle.Presets, mode == BundleReplace)
+	if err != nil {
+		return nil, err
+	}
+
+	n.applyPreferences(prefs)
+	n.savePreferences()
+	n.refreshFastPresets()
+
+	n.logToFile(n.logFile, fmt.Sprintf("Imported configuration bundle from TNT %s (%s, %d presets)", bundle.TNTVersion, mode, len(bundle.Presets)))
+	if len(stripped) > 0 {
+		n.logToFile(n.logFile, fmt.Sprintf("Not imported from the bundle: %s", strings.Join(stripped, "; ")))
+	}
+	return stripped, nil
+}
+
+// showExportBundle asks where to save the configuration bundle
+func (n *AudioNormalizer) showExportBundle(parent fyne.Window) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if err := n.exportConfigBundle(writer); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		dialog.ShowInformation("Configuration exported", fmt.Sprintf("Saved to %s", writer.URI().Path()), parent)
+	}, parent)
+	save.SetFileName("tnt-configuration.json")
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	save.Show()
+}
+
+// showImportBundle asks for a bundle and whether it should be merged or replace the current setup
+func (n *AudioNormalizer) showImportBundle(parent fyne.Window) {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+
+		mode := widget.NewRadioGroup([]string{BundleMerge, BundleReplace}, nil)
+		mode.SetSelected(BundleMerge)
+		help := widget.NewLabel("Merge keeps this computer's folders and any presets not in the bundle. Replace takes everything from the bundle.")
+		help.Wrapping = fyne.TextWrapWord
+
+		dialog.ShowForm("Import configuration", "Import", "Cancel", []*widget.FormItem{
+			widget.NewFormItem("Mode", mode),
+			widget.NewFormItem("", help),
+		}, func(confirmed bool) {
+			defer reader.Close()
+			if !confirmed {
+				return
+			}
+			stripped, err := n.importConfigBundle(reader, mode.Selected)
+			if err != nil {
+				dialog.ShowError(err, parent)
+				return
+			}
+			dialog.ShowInformation("Configuration imported", "Preferences and presets were imported and saved."+strippedNotice(stripped), parent)
+		}, parent)
+	}, parent)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
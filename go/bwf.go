@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// bwfSpeechnorm is the speech normalization a speech hint selects when none is set
+const bwfSpeechnorm = "Medium"
+
+// readBWF reads the production metadata of a broadcast WAV and logs what was found
+func (n *AudioNormalizer) readBWF(inputPath string) audio.BWFInfo {
+	info, err := audio.ReadBWF(inputPath)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("BWF metadata for %s not read: %v", inputPath, err))
+	}
+	if !info.Empty() {
+		n.logToFile(n.logFile, fmt.Sprintf("BWF metadata for %s from %s: %s", inputPath, info.Source,
+			strings.ReplaceAll(bwfDetails(info), "\n", "; ")))
+	}
+	return info
+}
+
+// applyBWFHints switches a file marked as speech to the speech chain, and
+// names a file with scene and take after them, unless a preset names it
+func (n *AudioNormalizer) applyBWFHints(inputPath string, cfg ProcessConfig) ProcessConfig {
+	info := cfg.bwf
+
+	if info.Speech && !cfg.bypassProc {
+		cfg.IsSpeech = true
+		if cfg.Speechnorm == "" || cfg.Speechnorm == "Off" {
+			cfg.Speechnorm = bwfSpeechnorm
+		}
+		if cfg.EqTarget != "" && cfg.EqTarget != "Off" {
+			cfg.EqTarget = "Speech"
+		}
+		n.logStatus(fmt.Sprintf("Speech chain selected from BWF metadata: %s", filepath.Base(inputPath)))
+	}
+
+	return withBWFTemplate(cfg)
+}
+
+// withBWFTemplate names a file with scene or take after them, unless a
+// preset names it. Output planning calls it without the speech chain.
+func withBWFTemplate(cfg ProcessConfig) ProcessConfig {
+	if cfg.FilenameTemplate == "" && cfg.BWFTemplate != "" && (cfg.bwf.Scene != "" || cfg.bwf.Take != "") {
+		cfg.FilenameTemplate = cfg.BWFTemplate
+	}
+	return cfg
+}
+
+// bwfTemplateFields are the filename template placeholders filled from BWF metadata
+func bwfTemplateFields(info audio.BWFInfo) map[string]string {
+	return map[string]string{
+		"{scene}":   info.Scene,
+		"{take}":    info.Take,
+		"{tape}":    info.Tape,
+		"{project}": info.Project,
+	}
+}
+
+// bwfDetails describes the metadata one line per field, leaving out empty ones
+func bwfDetails(info audio.BWFInfo) string {
+	var lines []string
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, label+": "+value)
+		}
+	}
+
+	add("Description", info.Description)
+	add("Originator", info.Originator)
+	add("Recorded", info.OriginationDate)
+	add("Project", info.Project)
+	add("Scene", info.Scene)
+	add("Take", info.Take)
+	add("Tape", info.Tape)
+	add("Note", info.Note)
+
+	if info.Speech {
+		lines = append(lines, "Marked as speech")
+	}
+	if info.Resampled() {
+		lines = append(lines, fmt.Sprintf("Recorded at %d Hz, converted to %d Hz", info.OriginalSampleRate, info.SampleRate))
+	} else if info.SampleRate > 0 {
+		lines = append(lines, fmt.Sprintf("Sample rate: %d Hz", info.SampleRate))
+	}
+
+	if len(info.CodingHistory) > 0 {
+		lines = append(lines, "Coding history:")
+		for _, line := range info.CodingHistory {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showFileDetails shows the BWF and iXML metadata of a queued file
+func (n *AudioNormalizer) showFileDetails(path string) {
+	info, err := audio.ReadBWF(path)
+	if err != nil {
+		dialog.ShowError(err, n.window)
+		return
+	}
+
+	text := "No BWF or iXML metadata."
+	if !info.Empty() {
+		text = bwfDetails(info)
+	}
+
+	details := widget.NewLabel(text)
+	details.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(details)
+	scroll.SetMinSize(fyne.NewSize(420, 260))
+
+	dialog.ShowCustom(filepath.Base(path), "Close", scroll, n.window)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// readCDSource looks up the CD origin of a file and logs what was found
+func (n *AudioNormalizer) readCDSource(inputPath string) audio.CDInfo {
+	info, err := audio.ReadCDInfo(inputPath)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("CD info for %s not read: %v", inputPath, err))
+		return audio.CDInfo{}
+	}
+
+	if info.PreEmphasis {
+		n.logStatus(fmt.Sprintf("Pre-emphasis flag found in %s: %s", info.Source, filepath.Base(inputPath)))
+	}
+	if info.ISRC != "" || info.Catalog != "" {
+		n.logToFile(n.logFile, fmt.Sprintf("CD info for %s from %s: ISRC %q, catalog %q", inputPath, info.Source, info.ISRC, info.Catalog))
+	}
+	return info
+}
+
+// cdTagArgs writes the ISRC and catalog number of a rip. FFmpeg maps ISRC to
+// the matching frame or atom of each format.
+func cdTagArgs(info audio.CDInfo) []string {
+	var args []string
+	if info.ISRC != "" {
+		args = append(args, "-metadata", "ISRC="+info.ISRC)
+	}
+	if info.Catalog != "" {
+		args = append(args, "-metadata", "CATALOGNUMBER="+info.Catalog)
+	}
+	return args
+}
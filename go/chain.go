@@ -0,0 +1,160 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// pipelineStage is one block of the filter chain diagram
+type pipelineStage struct {
+	Name   string
+	Detail string
+	// Conditional stages only run when a file's analysis calls for them
+	Conditional bool
+}
+
+// pipelinePlan lists the stages processFile runs for cfg, in order. Keep it
+// in step with processFile when stages are added or moved.
+func (n *AudioNormalizer) pipelinePlan(cfg ProcessConfig) []pipelineStage {
+	plan := []pipelineStage{{Name: "Source", Detail: "input file"}}
+
+	if cfg.noTranscode {
+		plan = append(plan, pipelineStage{Name: "Copy", Detail: "no transcoding, audio untouched"})
+		if cfg.writeTags {
+			plan = append(plan, pipelineStage{Name: "Measure", Detail: "EBU R128"}, pipelineStage{Name: "Tags", Detail: "ReplayGain"})
+		}
+		return append(plan, pipelineStage{Name: "Output", Detail: "original format"})
+	}
+
+	if cfg.Restoration && !cfg.bypassProc {
+		plan = append(plan, pipelineStage{Name: "Restoration", Detail: "declick, declip, denoise", Conditional: true})
+	}
+
+	var prep []string
+	if !cfg.KeepEmphasis {
+		prep = append(prep, "de-emphasis")
+	}
+	n.mutex.Lock()
+	if len(n.trims) > 0 {
+		prep = append(prep, "trim")
+	}
+	if len(n.gainTrims) > 0 {
+		prep = append(prep, "gain trim")
+	}
+	n.mutex.Unlock()
+	if cfg.ChannelCheck {
+		prep = append(prep, "channel fix")
+	}
+	if !cfg.bypassProc && cfg.RumbleFilter {
+		prep = append(prep, "rumble filter")
+	}
+	if !cfg.bypassProc && cfg.ChannelAlign {
+		prep = append(prep, "azimuth and balance")
+	}
+	if len(prep) > 0 {
+		plan = append(plan, pipelineStage{Name: "Preparation", Detail: strings.Join(prep, ", "), Conditional: true})
+	}
+
+	if !cfg.bypassProc {
+		if cfg.EqTarget != "" && cfg.EqTarget != "Off" {
+			plan = append(plan, pipelineStage{Name: "EQ", Detail: cfg.EqTarget + " curve, de-esser"})
+		}
+		if cfg.DynNorm {
+			plan = append(plan, pipelineStage{Name: "Dynamic normalization", Detail: "dynaudnorm"})
+		}
+		if cfg.DynamicsPreset != "" && cfg.DynamicsPreset != "Off" {
+			detail := cfg.DynamicsPreset + " compression"
+			if cfg.DynamicsPreset == "Broadcast" {
+				detail = "Broadcast multiband"
+			}
+			plan = append(plan, pipelineStage{Name: "Dynamics", Detail: detail})
+		}
+		if audio.SpeechnormFilter(cfg.Speechnorm) != "" {
+			plan = append(plan, pipelineStage{Name: "Speech normalization", Detail: cfg.Speechnorm})
+		}
+	}
+
+	target, targetTp := n.normalizationTargets()
+	switch {
+	case cfg.UseLoudnorm:
+		plan = append(plan,
+			pipelineStage{Name: "Measure", Detail: "loudnorm first pass"},
+			pipelineStage{Name: "Loudness normalization", Detail: target + " LUFS, " + targetTp + " dBTP"},
+		)
+	case cfg.writeTags:
+		plan = append(plan,
+			pipelineStage{Name: "Measure", Detail: "EBU R128"},
+			pipelineStage{Name: "Tags", Detail: "ReplayGain, audio untouched"},
+		)
+	}
+
+	format := cfg.Format
+	if format == "" {
+		format = "original format"
+	}
+	plan = append(plan, pipelineStage{Name: "Encode", Detail: format})
+
+	if len(cfg.ExtraTargets) > 0 {
+		plan = append(plan, pipelineStage{Name: "Extra targets", Detail: strings.Join(cfg.ExtraTargets, ", ")})
+	}
+	if cfg.WebVersion && cfg.UseLoudnorm {
+		web := cfg.WebTarget
+		if web == "" {
+			web = webDefaultTarget
+		}
+		plan = append(plan, pipelineStage{Name: "Web version", Detail: web + " LUFS, " + cfg.WebFormat})
+	}
+	if cfg.PreviewCopy {
+		plan = append(plan, pipelineStage{Name: "Preview copy", Detail: strings.ToLower(cfg.PreviewMark) + ", MP3"})
+	}
+	return plan
+}
+
+// buildChainDiagram creates the empty diagram strip for the Processing tab
+func (n *AudioNormalizer) buildChainDiagram() fyne.CanvasObject {
+	n.chainDiagram = container.NewHBox()
+	return container.NewHScroll(n.chainDiagram)
+}
+
+// refreshChainDiagram redraws the diagram for the current settings. It only
+// runs while the Processing tab is showing, the settings are read from there.
+func (n *AudioNormalizer) refreshChainDiagram() {
+	if n.chainDiagram == nil || n.modeTabs == nil || n.modeTabs.Selected() != n.modeTabs.Items[2] {
+		return
+	}
+
+	var objects []fyne.CanvasObject
+	for i, stage := range n.pipelinePlan(n.getProcessConfig()) {
+		if i > 0 {
+			objects = append(objects, widget.NewLabel("→"))
+		}
+		objects = append(objects, chainBlock(stage))
+	}
+	n.chainDiagram.Objects = objects
+	n.chainDiagram.Refresh()
+}
+
+// chainBlock draws one stage as an outlined box, muted when it may not run
+func chainBlock(stage pipelineStage) fyne.CanvasObject {
+	border := canvas.NewRectangle(theme.Color(theme.ColorNameBackground))
+	border.StrokeWidth = 1
+	border.CornerRadius = theme.InputRadiusSize()
+	border.StrokeColor = theme.Color(theme.ColorNamePrimary)
+
+	name := widget.NewLabelWithStyle(stage.Name, fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	detail := widget.NewLabelWithStyle(stage.Detail, fyne.TextAlignCenter, fyne.TextStyle{Italic: stage.Conditional})
+	if stage.Conditional {
+		border.StrokeColor = theme.Color(theme.ColorNameDisabled)
+		detail.SetText(stage.Detail + " (if needed)")
+		detail.Importance = widget.LowImportance
+	}
+
+	return container.NewStack(border, container.NewVBox(name, detail))
+}
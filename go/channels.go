@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// channelPreflight detects dual-mono and one-sided recordings and returns the
+// pan filter that fixes them, or "" to leave the channels as they are
+func (n *AudioNormalizer) channelPreflight(inputPath string, cfg ProcessConfig) string {
+	name := filepath.Base(inputPath)
+
+	// Mono has no channels to compare, skip the decode
+	if fileIsMono(inputPath) {
+		return ""
+	}
+
+	issue, err := audio.DetectChannelIssue(inputPath)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Channel check failed for %s: %v", name, err))
+		return ""
+	}
+	if issue == audio.ChannelsOK {
+		return ""
+	}
+
+	n.logStatus(fmt.Sprintf("⚠ Channel layout is %s: %s", issue, name))
+
+	if cfg.ChannelFix == audio.ChannelFixAsk {
+		action := "copy the populated channel to both sides"
+		if issue == audio.ChannelsDualMono {
+			action = "fold it to true mono"
+		}
+		if !n.showConfirmDialog("Channel mapping", fmt.Sprintf("%s is %s. Do you want to %s before processing?", name, issue, action)) {
+			n.logStatus(fmt.Sprintf("→ Channel layout left unchanged: %s", name))
+			return ""
+		}
+	}
+
+	return audio.ChannelFixFilter(issue, cfg.ChannelFix)
+}
+
+// monoBitrateFloor is the lowest bitrate in kbps a mono file is encoded at
+const monoBitrateFloor = 32.0
+
+// fileIsMono reports whether a file has a single channel. Files whose
+// header can't be read are treated as stereo.
+func fileIsMono(inputPath string) bool {
+	channels, err := audio.ProbeChannels(inputPath)
+	return err == nil && channels == 1
+}
+
+// outputChannels returns how many channels a file's output has: its own, or
+// one when the channel fix folds it to mono
+func outputChannels(inputPath string, cfg ProcessConfig) int {
+	if strings.HasPrefix(cfg.channelFilter, "pan=mono") {
+		return 1
+	}
+	channels, err := audio.ProbeChannels(inputPath)
+	if err != nil {
+		return 2
+	}
+	return channels
+}
+
+// opusApplication picks Opus' speech mode for speech and for mono files,
+// which in a mixed batch are the interviews and phone-ins, and its music
+// mode for the rest
+func opusApplication(cfg ProcessConfig, channels int) string {
+	if cfg.IsSpeech || channels == 1 {
+		return "voip"
+	}
+	return "audio"
+}
+
+// monoKbps is the bitrate a mono file gets. Bitrates are set for stereo, and
+// one channel needs about half of it for the same quality.
+func monoKbps(kbps float64) float64 {
+	return math.Max(kbps/2, monoBitrateFloor)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// clipWarningTag marks files whose track gain takes the peak over the TP
+// target, so playout can tell which files rely on the player's limiter
+const clipWarningTag = "TNT_CLIP_WARNING"
+
+// tagClipGuard checks where the peak of a file tagged without transcoding
+// lands once a player applies its track gain. Positive gain can take it over
+// the TP target; the gain is then lowered to fit when enabled, otherwise the
+// file gets a warning tag. Returns the gain to tag and any extra tag args.
+func (n *AudioNormalizer) tagClipGuard(inputPath string, cfg ProcessConfig, measured map[string]string, target, targetTp string) (float64, []string) {
+	inputI, _ := strconv.ParseFloat(measured["input_i"], 64)
+	targetI, _ := strconv.ParseFloat(target, 64)
+	peak, err := strconv.ParseFloat(measured["input_tp"], 64)
+	if err != nil {
+		return targetI - inputI, nil
+	}
+	ceiling, _ := strconv.ParseFloat(targetTp, 64)
+
+	g := computeMusicLibGain(inputI, peak, targetI, ceiling, 0, false, cfg.TagClipCap)
+	cfg.report.peakAfterGain(g.PeakAfter, g.Clips && !g.Reduced)
+
+	switch {
+	case g.Reduced:
+		n.logStatus(fmt.Sprintf("⚠ Tagged gain lowered from %.2f to %.2f dB to prevent clipping: %s", g.Raw, g.Gain, filepath.Base(inputPath)))
+		return g.Gain, nil
+	case g.Clips:
+		n.logStatus(fmt.Sprintf("⚠ Peak reaches %.1f dBTP after the tagged gain, playback relies on a limiter: %s", g.PeakAfter, filepath.Base(inputPath)))
+		return g.Gain, []string{"-metadata", fmt.Sprintf("%s=peak %.1f dBTP after track gain", clipWarningTag, g.PeakAfter)}
+	}
+	return g.Gain, nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+var (
+	ErrClipListEmpty = errors.New("clip list has no rows with a file")
+	ErrClipRange     = errors.New("out point is not after the in point")
+	ErrClipName      = errors.New("output name is used by another clip")
+	ErrClipTranscode = errors.New("clips can't be cut without transcoding")
+)
+
+// clipListColumns are the CSV columns in the order used when the file has no header
+var clipListColumns = []string{"filename", "in", "out", "name"}
+
+// clipEntry is one range of a recording processed as an output of its own
+type clipEntry struct {
+	File  string
+	Range trimRange
+	// Name is the output file name without extension
+	Name string
+}
+
+// normalizeClipColumn maps header spellings like "Source file" or "Output name" to clipListColumns
+func normalizeClipColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	switch name {
+	case "file", "file_name", "source", "source_file":
+		return "filename"
+	case "in_point", "start", "source_in":
+		return "in"
+	case "out_point", "end", "source_out":
+		return "out"
+	case "output", "output_name", "clip", "clip_name":
+		return "name"
+	}
+	return name
+}
+
+// loadClipList reads a CSV or simple EDL of file, in, out and output name.
+// A header row, when present, may list the columns in any order. Files are
+// relative to the list's folder, times are seconds, mm:ss or hh:mm:ss.
+// Clips without a name are named after their file and row.
+func loadClipList(path string) ([]clipEntry, error) {
+	f, err := os.Open(platform.LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrClipListEmpty
+	}
+
+	// Spreadsheet exports often start with a byte order mark
+	rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+
+	firstLine := 1
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[normalizeClipColumn(name)] = i
+	}
+	if _, ok := columns["filename"]; ok {
+		rows = rows[1:]
+		firstLine = 2
+	} else {
+		columns = make(map[string]int)
+		for i, name := range clipListColumns {
+			columns[name] = i
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	dir := filepath.Dir(path)
+	names := make(map[string]bool)
+	var clips []clipEntry
+	for i, row := range rows {
+		line := firstLine + i
+		file := field(row, "filename")
+		if file == "" {
+			continue
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+
+		in, err := audio.ParseTimecode(field(row, "in"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		out, err := audio.ParseTimecode(field(row, "out"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if out <= in {
+			return nil, fmt.Errorf("line %d: %w", line, ErrClipRange)
+		}
+
+		name := strings.NewReplacer("/", "_", "\\", "_").Replace(field(row, "name"))
+		if name = strings.TrimSuffix(name, filepath.Ext(name)); name == "" || name == "." || name == ".." {
+			stem := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+			name = fmt.Sprintf("%s_clip%d", stem, len(clips)+1)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("line %d: %w: %s", line, ErrClipName, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		clips = append(clips, clipEntry{File: file, Range: trimRange{In: in, Out: out}, Name: name})
+	}
+
+	if len(clips) == 0 {
+		return nil, ErrClipListEmpty
+	}
+	return clips, nil
+}
+
+// keptRange returns the part of an input a job processes: the clip's range
+// for clip lists, otherwise what the trim editor kept
+func (n *AudioNormalizer) keptRange(inputPath string, cfg ProcessConfig) (trimRange, bool) {
+	if cfg.clip != nil {
+		return cfg.clip.Range, true
+	}
+	return n.trimFor(inputPath)
+}
+
+// processClipList cuts every listed range out of its recording and processes
+// it with the current settings, one output per clip named as listed
+func (n *AudioNormalizer) processClipList(path string) {
+	clips, err := loadClipList(path)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Clip list %s: %v", filepath.Base(path), err))
+		return
+	}
+
+	config := n.getProcessConfig()
+	if config.noTranscode {
+		n.logStatus(fmt.Sprintf("✗ Clip list %s: %v", filepath.Base(path), ErrClipTranscode))
+		return
+	}
+	// A preset with its own delivery folder doesn't need the global one
+	if config.OutputDir == "" {
+		if err := checkOutputWritable(n.outputDir); err != nil {
+			n.logStatus(fmt.Sprintf("✗ Clip list %s: %v", filepath.Base(path), err))
+			return
+		}
+	}
+
+	n.logStatus(fmt.Sprintf("Processing %d clips from %s...", len(clips), filepath.Base(path)))
+	failed := 0
+	for i, clip := range clips {
+		n.logStatus(fmt.Sprintf("→ Clip %d/%d %s: %s %s", i+1, len(clips), clip.Name, filepath.Base(clip.File), clip.Range))
+		clipConfig := config
+		clipConfig.clip = &clip
+		if !n.processFile(clip.File, clipConfig) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		n.logStatus(fmt.Sprintf("⚠ Clip list done, %d of %d clips failed", failed, len(clips)))
+		return
+	}
+	n.logStatus(fmt.Sprintf("✓ Clip list done, %d clips written", len(clips)))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// planOutputs works out every output path of a batch before it starts and
+// renames outputs that would overwrite another file's output. The first file
+// keeps its name, later ones get their source folder's name added, or a
+// number when that clashes too. Returns how many outputs were renamed.
+func (n *AudioNormalizer) planOutputs(files []string, cfg ProcessConfig) int {
+	n.clearPlannedOutputs()
+
+	codec := codecFor(cfg.Format)
+	taken := make(map[string]string)
+	type clash struct{ input, output string }
+	var clashes []clash
+
+	for _, file := range files {
+		fileCfg := cfg
+		// BWF metadata only matters for naming through a template
+		if cfg.FilenameTemplate != "" || cfg.BWFHints {
+			fileCfg.bwf, _ = audio.ReadBWF(file)
+			if cfg.BWFHints {
+				fileCfg = withBWFTemplate(fileCfg)
+			}
+		}
+
+		target, _ := n.fileTargets(file, fileCfg)
+		output, _, _ := n.outputPathFor(file, codec, target, fileCfg)
+		if _, exists := taken[pathKey(output)]; exists {
+			clashes = append(clashes, clash{file, output})
+			continue
+		}
+		taken[pathKey(output)] = file
+	}
+
+	planned := make(map[string]string)
+	for _, c := range clashes {
+		ext := filepath.Ext(c.output)
+		stem := strings.TrimSuffix(c.output, ext)
+
+		renamed := stem + "_" + filepath.Base(filepath.Dir(c.input)) + ext
+		for i := 2; taken[pathKey(renamed)] != ""; i++ {
+			renamed = fmt.Sprintf("%s_%d%s", stem, i, ext)
+		}
+		taken[pathKey(renamed)] = c.input
+		planned[c.input] = renamed
+
+		n.logStatus(fmt.Sprintf("⚠ Same output name as another file, writing %s instead: %s", filepath.Base(renamed), c.input))
+	}
+
+	n.mutex.Lock()
+	n.plannedOutputs = planned
+	n.mutex.Unlock()
+	return len(planned)
+}
+
+// plannedOutput returns the output planned for a file whose usual name clashed
+func (n *AudioNormalizer) plannedOutput(inputPath string) (string, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	output, ok := n.plannedOutputs[inputPath]
+	return output, ok
+}
+
+// clearPlannedOutputs forgets the batch's renames, so watch mode and later
+// batches name files the usual way
+func (n *AudioNormalizer) clearPlannedOutputs() {
+	n.mutex.Lock()
+	n.plannedOutputs = nil
+	n.mutex.Unlock()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+
+	"fyne.io/fyne/v2/theme"
+)
+
+const (
+	// complianceTolerance is how far in LU a compliant input may be off target
+	complianceTolerance = 1.0
+	// complianceNearTolerance is how far off an input may be and still be close
+	complianceNearTolerance = 3.0
+)
+
+// Compliance levels of a scanned input against the current loudness target
+const (
+	complianceWithin = iota
+	complianceNear
+	complianceOff
+)
+
+// complianceMeasurement is the loudness of a queued input from the pre-flight scan
+type complianceMeasurement struct {
+	Integrated float64
+	TruePeak   float64
+}
+
+// level classifies the input against a target: within the tolerance and
+// under the true peak limit, close to the target, or further off or over
+// the limit
+func (m complianceMeasurement) level(target, targetTp float64) int {
+	off := math.Abs(m.Integrated - target)
+	switch {
+	case m.TruePeak > targetTp || off > complianceNearTolerance:
+		return complianceOff
+	case off > complianceTolerance:
+		return complianceNear
+	}
+	return complianceWithin
+}
+
+// complianceColor is the color of a level in the file list
+func complianceColor(level int) color.Color {
+	switch level {
+	case complianceWithin:
+		return theme.Color(theme.ColorNameSuccess)
+	case complianceNear:
+		return theme.Color(theme.ColorNameWarning)
+	}
+	return theme.Color(theme.ColorNameError)
+}
+
+// complianceTargets returns the current targets as numbers
+func (n *AudioNormalizer) complianceTargets() (float64, float64) {
+	target, targetTp := n.normalizationTargets()
+	i, _ := strconv.ParseFloat(target, 64)
+	tp, _ := strconv.ParseFloat(targetTp, 64)
+	return i, tp
+}
+
+// complianceFor returns the scanned loudness of a queued file
+func (n *AudioNormalizer) complianceFor(path string) (complianceMeasurement, bool) {
+	m, ok := n.compliance.Load(path)
+	if !ok {
+		return complianceMeasurement{}, false
+	}
+	return m.(complianceMeasurement), true
+}
+
+// text is the loudness shown after the file name in the list
+func (m complianceMeasurement) text() string {
+	return fmt.Sprintf("%.1f LUFS, %.1f dBTP", m.Integrated, m.TruePeak)
+}
+
+// scanCompliance measures every queued file that hasn't been scanned yet,
+// a few at a time, and colors its row against the current target. The
+// levels follow the target when it's changed afterwards.
+func (n *AudioNormalizer) scanCompliance() {
+	n.mutex.Lock()
+	files := slices.Clone(n.files)
+	n.mutex.Unlock()
+	options := n.loudnormOptions()
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range max(1, runtime.NumCPU()/2) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				measured := n.measureLoudness(path, measureForReport, "", "", options)
+				integrated, errI := strconv.ParseFloat(measured["input_i"], 64)
+				truePeak, errTp := strconv.ParseFloat(measured["input_tp"], 64)
+				if errI != nil || errTp != nil {
+					n.logToFile(n.logFile, fmt.Sprintf("Loudness scan of %s failed", path))
+					continue
+				}
+				n.compliance.Store(path, complianceMeasurement{Integrated: integrated, TruePeak: truePeak})
+				n.fileListRefresh.Trigger()
+			}
+		}()
+	}
+
+	n.logStatus(fmt.Sprintf("→ Scanning loudness of %d files", len(files)))
+	for _, path := range files {
+		if _, ok := n.complianceFor(path); !ok {
+			jobs <- path
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	target, targetTp := n.complianceTargets()
+	var counts [3]int
+	for _, path := range files {
+		if m, ok := n.complianceFor(path); ok {
+			counts[m.level(target, targetTp)]++
+		}
+	}
+	n.logStatus(fmt.Sprintf("✓ Loudness scan against %.1f LUFS: %d within ±%.0f LU, %d within ±%.0f LU, %d further off or over the TP limit",
+		target, counts[complianceWithin], complianceTolerance, counts[complianceNear], complianceNearTolerance, counts[complianceOff]))
+	if counts[complianceWithin] > 0 {
+		n.logStatus("Files within target only need tags, they could be processed with Write RG tags and Do not transcode")
+	}
+}
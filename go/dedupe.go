@@ -0,0 +1,230 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	duplicatesReportName = "duplicate_audio.csv"
+	// dedupeProgressEvery is how many files pass between progress lines
+	dedupeProgressEvery = 100
+)
+
+var duplicatesReportHeader = []string{"group", "file", "size_bytes", "modified", "bit_error_rate", "suggestion"}
+
+// cachedFingerprint is a file's fingerprint, valid while its size and
+// modification time stay the same
+type cachedFingerprint struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Print   []byte    `json:"print"`
+}
+
+// fingerprintCache keeps fingerprints between scans, so rescanning a library
+// only decodes what was added or changed
+type fingerprintCache struct {
+	mutex  sync.Mutex
+	path   string
+	Prints map[string]cachedFingerprint `json:"prints"`
+}
+
+func loadFingerprintCache() *fingerprintCache {
+	c := &fingerprintCache{path: filepath.Join(dataDir(), "fingerprints.json")}
+	if data, err := os.ReadFile(c.path); err == nil {
+		json.Unmarshal(data, c)
+	}
+	if c.Prints == nil {
+		c.Prints = make(map[string]cachedFingerprint)
+	}
+	return c
+}
+
+// save writes the cache to disk
+func (c *fingerprintCache) save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// fingerprint returns a file's fingerprint, computing it only when the file changed
+func (c *fingerprintCache) fingerprint(path string, info fs.FileInfo) (audio.Fingerprint, error) {
+	c.mutex.Lock()
+	cached, ok := c.Prints[path]
+	c.mutex.Unlock()
+	if ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return audio.FingerprintFromBytes(cached.Print), nil
+	}
+
+	fp, err := audio.ComputeFingerprint(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.Prints[path] = cachedFingerprint{Size: info.Size(), ModTime: info.ModTime(), Print: fp.Bytes()}
+	c.mutex.Unlock()
+	return fp, nil
+}
+
+// libraryFile is an audio file found in the library scan
+type libraryFile struct {
+	Path string
+	Info fs.FileInfo
+}
+
+// findDuplicates fingerprints every audio file under dir and writes the groups
+// of near-identical audio to a report, suggesting which copy to keep
+func (n *AudioNormalizer) findDuplicates(dir string) {
+	n.logStatus(fmt.Sprintf("→ Looking for duplicate audio in %s", dir))
+
+	var files []libraryFile
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isAudioFile(path) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, libraryFile{path, info})
+		}
+		return nil
+	})
+	if len(files) < 2 {
+		n.logStatus(fmt.Sprintf("⊗ Not enough audio files to compare in %s", dir))
+		return
+	}
+
+	cache := loadFingerprintCache()
+	prints := make([]audio.Fingerprint, len(files))
+	var done atomic.Int64
+	var failed atomic.Int64
+
+	jobs := make(chan int, len(files))
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for range max(1, runtime.NumCPU()-1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fp, err := cache.fingerprint(files[i].Path, files[i].Info)
+				if err != nil {
+					if !errors.Is(err, audio.ErrTooShortToFingerprint) {
+						failed.Add(1)
+					}
+					n.logToFile(n.logFile, fmt.Sprintf("No fingerprint for %s: %v", files[i].Path, err))
+				}
+				prints[i] = fp
+				if count := done.Add(1); count%dedupeProgressEvery == 0 {
+					n.logStatus(fmt.Sprintf("→ Fingerprinted %d/%d files", count, len(files)))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := cache.save(); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Fingerprint cache not saved: %v", err))
+	}
+	if count := failed.Load(); count > 0 {
+		n.logStatus(fmt.Sprintf("⚠ %d files could not be decoded, see the log", count))
+	}
+
+	matches := audio.FindDuplicates(prints)
+	groups := audio.ClusterMatches(len(files), matches)
+	if len(groups) == 0 {
+		n.logStatus(fmt.Sprintf("✓ No duplicate audio among %d files", len(files)))
+		return
+	}
+
+	// The lowest bit error rate each file has against another in its group
+	bestBER := make(map[int]float64)
+	for _, m := range matches {
+		for _, i := range []int{m.A, m.B} {
+			if ber, ok := bestBER[i]; !ok || m.BER < ber {
+				bestBER[i] = m.BER
+			}
+		}
+	}
+
+	var rows [][]string
+	var reclaimable int64
+	for g, members := range groups {
+		// The largest copy is most likely the best quality one
+		keep := slices.MaxFunc(members, func(a, b int) int {
+			return cmp.Compare(files[a].Info.Size(), files[b].Info.Size())
+		})
+		for _, i := range members {
+			suggestion := "duplicate"
+			if i == keep {
+				suggestion = "keep"
+			} else {
+				reclaimable += files[i].Info.Size()
+			}
+			rows = append(rows, []string{
+				fmt.Sprint(g + 1),
+				files[i].Path,
+				fmt.Sprint(files[i].Info.Size()),
+				files[i].Info.ModTime().Format(time.RFC3339),
+				fmt.Sprintf("%.3f", bestBER[i]),
+				suggestion,
+			})
+		}
+	}
+
+	reportPath, err := writeDuplicatesReport(dir, rows)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to write duplicate report: %v", err))
+		return
+	}
+	n.logStatus(fmt.Sprintf("✓ %d groups of duplicate audio, %.2f GB reclaimable, listed in %s",
+		len(groups), float64(reclaimable)/(1<<30), reportPath))
+}
+
+// writeDuplicatesReport writes the report into the scanned folder, or next to
+// the preferences when the library is read-only
+func writeDuplicatesReport(dir string, rows [][]string) (string, error) {
+	var lastErr error
+	for _, folder := range []string{dir, dataDir()} {
+		path := filepath.Join(folder, duplicatesReportName)
+		f, err := os.Create(platform.LongPath(path))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		w := csv.NewWriter(f)
+		w.Write(duplicatesReportHeader)
+		w.WriteAll(rows)
+		if err := errors.Join(w.Error(), f.Close()); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	return "", lastErr
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/fremen-fi/tnt/go/internal/config"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	// minFreeTempSpace is the free temp space below which diagnostics warn.
+	// Staged processing writes 192 kHz 64-bit intermediates, several GB per hour of audio.
+	minFreeTempSpace = 5 << 30
+
+	diagnosticsTimeout = 5 * time.Second
+)
+
+// requiredFilters are the FFmpeg filters TNT's processing stages use
+var requiredFilters = []string{"loudnorm", "ebur128", "dynaudnorm", "speechnorm", "acompressor", "alimiter", "astats", "volume", "pan", "aresample", "equalizer", "aemphasis", "adeclick", "adeclip", "afftdn"}
+
+// diagnosticResult is one line of the diagnostics report
+type diagnosticResult struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+func (r diagnosticResult) String() string {
+	mark := "✓"
+	if !r.OK {
+		mark = "✗"
+	}
+	return fmt.Sprintf("%s %s: %s", mark, r.Name, r.Detail)
+}
+
+// runDiagnostics checks everything TNT depends on and returns the results in report order
+func (n *AudioNormalizer) runDiagnostics() []diagnosticResult {
+	return []diagnosticResult{
+		checkFFmpegIntegrity(),
+		checkFFmpegVersion(),
+		checkEncoders(),
+		checkFilters(),
+		checkTempDir(),
+		checkOutputDir(n.outputDir),
+		checkUpdateServer(),
+		checkWatcher(),
+	}
+}
+
+// checkFFmpegIntegrity compares the extracted FFmpeg with the copy embedded in
+// TNT, or a downloaded build with the hash the version feed gave for it
+func checkFFmpegIntegrity() diagnosticResult {
+	r := diagnosticResult{Name: "FFmpeg binary"}
+
+	if ffmpegBundleVersion != "" {
+		bundle, _, err := readFFmpegManifest()
+		if err != nil {
+			r.Detail = err.Error()
+			return r
+		}
+		sum, err := fileSHA256(ffmpeg.Path)
+		if err != nil {
+			r.Detail = err.Error()
+			return r
+		}
+		if !strings.EqualFold(sum, bundle.SHA256) {
+			r.Detail = fmt.Sprintf("%s differs from the downloaded FFmpeg %s, restart TNT", ffmpeg.Path, bundle.Version)
+			return r
+		}
+		r.OK = true
+		r.Detail = fmt.Sprintf("%s intact (downloaded FFmpeg %s)", ffmpeg.Path, bundle.Version)
+		return r
+	}
+
+	data, err := os.ReadFile(ffmpeg.Path)
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+	if sha256.Sum256(data) != sha256.Sum256(platform.FFmpegBinary) {
+		r.Detail = fmt.Sprintf("%s differs from the embedded copy, restart TNT", ffmpeg.Path)
+		return r
+	}
+
+	r.OK = true
+	r.Detail = fmt.Sprintf("%s intact (%d bytes)", ffmpeg.Path, len(data))
+	return r
+}
+
+// checkFFmpegVersion reports the first line of ffmpeg -version
+func checkFFmpegVersion() diagnosticResult {
+	r := diagnosticResult{Name: "FFmpeg version"}
+
+	out, err := ffmpeg.Output("-hide_banner", "-version")
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+
+	r.OK = true
+	r.Detail, _, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return r
+}
+
+// checkEncoders makes sure every output format has its encoder
+func checkEncoders() diagnosticResult {
+	r := diagnosticResult{Name: "Encoders"}
+
+	out, err := ffmpeg.Output("-hide_banner", "-encoders")
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+
+	var required []string
+	for _, format := range getPlatformFormats() {
+		codec := getPlatformCodecMap()[format]
+		if codec == "" {
+			codec = config.GetCodec(format)
+		}
+		if codec != "" && codec != "PCM" {
+			required = append(required, codec)
+		}
+	}
+	required = append(required, "pcm_s24le", "mp2")
+
+	missing := missingNames(out, required)
+	if len(missing) > 0 {
+		r.Detail = "missing " + strings.Join(missing, ", ")
+		return r
+	}
+
+	r.OK = true
+	r.Detail = strings.Join(required, ", ")
+	return r
+}
+
+// checkFilters makes sure the processing filters are compiled in
+func checkFilters() diagnosticResult {
+	r := diagnosticResult{Name: "Filters"}
+
+	out, err := ffmpeg.Output("-hide_banner", "-filters")
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+
+	if missing := missingNames(out, requiredFilters); len(missing) > 0 {
+		r.Detail = "missing " + strings.Join(missing, ", ")
+		return r
+	}
+
+	r.OK = true
+	r.Detail = fmt.Sprintf("all %d required filters available", len(requiredFilters))
+	return r
+}
+
+// missingNames returns the names that don't appear as a word in an FFmpeg listing
+func missingNames(listing []byte, names []string) []string {
+	words := make(map[string]bool)
+	for _, line := range strings.Split(string(listing), "\n") {
+		for _, field := range strings.Fields(line) {
+			words[field] = true
+		}
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !words[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// checkTempDir checks the temp folder the processing stages write to
+func checkTempDir() diagnosticResult {
+	dir := os.TempDir()
+	r := diagnosticResult{Name: "Temp folder"}
+
+	if err := checkOutputWritable(dir); err != nil {
+		r.Detail = fmt.Sprintf("%s not writable: %v", dir, err)
+		return r
+	}
+
+	free, err := platform.FreeSpace(dir)
+	if err != nil {
+		r.Detail = fmt.Sprintf("%s writable, free space unknown: %v", dir, err)
+		return r
+	}
+
+	r.Detail = fmt.Sprintf("%s writable, %.1f GB free", dir, float64(free)/(1<<30))
+	if free < minFreeTempSpace {
+		r.Detail += ", long files may not fit"
+		return r
+	}
+	r.OK = true
+	return r
+}
+
+// checkOutputDir checks the selected output folder
+func checkOutputDir(dir string) diagnosticResult {
+	r := diagnosticResult{Name: "Output folder"}
+
+	if err := checkOutputWritable(dir); err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+
+	r.OK = true
+	r.Detail = dir + " writable"
+	if free, err := platform.FreeSpace(dir); err == nil {
+		r.Detail += fmt.Sprintf(", %.1f GB free", float64(free)/(1<<30))
+	}
+	return r
+}
+
+// checkUpdateServer checks that the update server answers
+func checkUpdateServer() diagnosticResult {
+	r := diagnosticResult{Name: "Update server"}
+
+	client := http.Client{Timeout: diagnosticsTimeout}
+	resp, err := client.Get(versionCheckURL)
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		r.Detail = fmt.Sprintf("%s answered %s", versionCheckURL, resp.Status)
+		return r
+	}
+
+	r.OK = true
+	r.Detail = versionCheckURL + " reachable"
+	return r
+}
+
+// checkWatcher creates a file in a watched temp folder and waits for the event
+func checkWatcher() diagnosticResult {
+	r := diagnosticResult{Name: "Folder watcher"}
+
+	dir, err := os.MkdirTemp("", "tnt-watch-test-*")
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+	defer os.RemoveAll(dir)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+
+	testFile := filepath.Join(dir, "touch.wav")
+	if err := os.WriteFile(testFile, nil, 0644); err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+
+	timeout := time.After(diagnosticsTimeout)
+	for {
+		select {
+		case event := <-watcher.Events:
+			if event.Name == testFile && event.Op&fsnotify.Create == fsnotify.Create {
+				r.OK = true
+				r.Detail = "create event received"
+				return r
+			}
+		case err := <-watcher.Errors:
+			r.Detail = err.Error()
+			return r
+		case <-timeout:
+			r.Detail = fmt.Sprintf("no event within %s", diagnosticsTimeout)
+			return r
+		}
+	}
+}
+
+// diagnosticsReport formats the results of a kind of check for a support ticket
+func (n *AudioNormalizer) diagnosticsReport(kind string, results []diagnosticResult) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "TNT %s %s, %s\n", currentVersion, kind, time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "OS: %s/%s, profile: %s\n\n", runtime.GOOS, runtime.GOARCH, profileLabel())
+	for _, r := range results {
+		b.WriteString(r.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// showDiagnostics runs the checks in the background and shows a copyable report
+func (n *AudioNormalizer) showDiagnostics() {
+	n.showChecks("TNT diagnostics", "diagnostics", n.runDiagnostics)
+}
+
+// showChecks runs a kind of check in the background in its own window and
+// shows the copyable report once done
+func (n *AudioNormalizer) showChecks(title, kind string, run func() []diagnosticResult) {
+	w := fyne.CurrentApp().NewWindow(title)
+
+	report := widget.NewMultiLineEntry()
+	report.Wrapping = fyne.TextWrapWord
+	report.SetText("Running checks...")
+
+	copyBtn := widget.NewButton("Copy to clipboard", func() {
+		w.Clipboard().SetContent(report.Text)
+	})
+	copyBtn.Disable()
+
+	w.SetContent(container.NewBorder(nil, copyBtn, nil, nil, report))
+	w.Resize(fyne.NewSize(600, 400))
+	w.Show()
+
+	go func() {
+		text := n.diagnosticsReport(kind, run())
+		n.logToFile(n.logFile, title+":\n"+text)
+		fyne.Do(func() {
+			report.SetText(text)
+			copyBtn.Enable()
+		})
+	}()
+}
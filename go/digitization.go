@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// digitizationPreset is the built-in Fast preset for transfers of vinyl,
+// cassette and other legacy carriers
+const digitizationPreset = "Digitization (PCM 96kHz/24bit)"
+
+// Digitized carriers are normalized gently and kept well clear of the true
+// peak limit: consumer interfaces and worn records throw intersample
+// overs that a limiter at -1 dBTP would squash
+const (
+	digitizationTarget   = "-23"
+	digitizationTargetTp = "-3"
+)
+
+// digitizationConfig sets up an archive transfer: a high resolution PCM
+// master, restoration and rumble filtering, no tonal or dynamics processing
+// and conservative normalization. Channel alignment follows its checkbox.
+func digitizationConfig(cfg ProcessConfig) ProcessConfig {
+	cfg.Format = "PCM"
+	cfg.SampleRate = "96000"
+	cfg.BitDepth = "24"
+	cfg.Restoration = true
+	cfg.RumbleFilter = true
+	cfg.bypassProc = false
+	cfg.EqTarget = "Off"
+	cfg.DynamicsPreset = "Off"
+	cfg.DynNorm = false
+	cfg.Speechnorm = "Off"
+	cfg.UseLoudnorm = true
+	cfg.TargetI = digitizationTarget
+	cfg.TargetTp = digitizationTargetTp
+	return cfg
+}
+
+// digitizationFilters returns the rumble filter and the azimuth and
+// balance correction a file needs, in that order
+func (n *AudioNormalizer) digitizationFilters(inputPath string, cfg ProcessConfig) []string {
+	var filters []string
+	if cfg.RumbleFilter {
+		filters = append(filters, audio.RumbleFilter)
+	}
+	if !cfg.ChannelAlign {
+		return filters
+	}
+
+	alignment, err := audio.MeasureAlignment(inputPath)
+	if errors.Is(err, audio.ErrNotStereo) {
+		return filters
+	}
+	if err != nil {
+		n.logStatus(fmt.Sprintf("⚠ Channel alignment not measured for %s: %v", filepath.Base(inputPath), err))
+		return filters
+	}
+
+	n.logToFile(n.logFile, fmt.Sprintf("Channel alignment of %s: %s", inputPath, alignment))
+	if filter := alignment.Filter(); filter != "" {
+		n.logStatus(fmt.Sprintf("→ Correcting azimuth and balance (%s): %s", alignment, filepath.Base(inputPath)))
+		filters = append(filters, filter)
+	}
+	return filters
+}
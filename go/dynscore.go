@@ -2,72 +2,26 @@ package main
 
 import (
 	"fmt"
-	"strings"
-	"regexp"
-	"strconv"
-	"path/filepath"
 	"math"
 	
 	"github.com/fremen-fi/tnt/go/internal/audio"
-	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
 )
 
-func (n *AudioNormalizer) calculateDynamicsScore(inputPath string) *audio.DynamicsScoreAnalysis {
-	n.logStatus(fmt.Sprintf("→ Calculating Dynamics Score: %s", filepath.Base(inputPath)))
-	
-	cmd := ffmpeg.Command(
-		"-i", inputPath,
-		"-af", "astats",
-		"-f", "null",
-		"-",
-	)
-	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		n.logToFile(n.logFile, fmt.Sprintf("DS calculation failed: %v", err))
-		return nil
-	}
-	
-	return n.parseDynamicsScore(string(output))
-}
-
-func (n *AudioNormalizer) parseDynamicsScore(output string) *audio.DynamicsScoreAnalysis {
+// dynamicsScoreFromStats calculates the Dynamics Score from the whole
+// signal's statistics of an analysis run
+func (n *AudioNormalizer) dynamicsScoreFromStats(report audio.AstatsReport) *audio.DynamicsScoreAnalysis {
 	result := &audio.DynamicsScoreAnalysis{}
+	c := &result.Confidence
 	
-	// Parse Channel 1 section
-	lines := strings.Split(output, "\n")
-	inChannel1 := false
-	
-	for _, line := range lines {
-		if strings.Contains(line, "Channel: 1") {
-			inChannel1 = true
-			continue
-		}
-		if strings.Contains(line, "Channel: 2") {
-			break
-		}
-		
-		if inChannel1 {
-			if strings.Contains(line, "RMS peak dB:") {
-				re := regexp.MustCompile(`RMS peak dB:\s+([-\d.]+)`)
-				if match := re.FindStringSubmatch(line); len(match) > 1 {
-					result.RMSPeak, _ = strconv.ParseFloat(match[1], 64)
-				}
-			}
-			if strings.Contains(line, "RMS level dB:") && result.RMSLevel == 0 {
-				re := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-				if match := re.FindStringSubmatch(line); len(match) > 1 {
-					result.RMSLevel, _ = strconv.ParseFloat(match[1], 64)
-				}
-			}
-			if strings.Contains(line, "Crest factor:") {
-				re := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-				if match := re.FindStringSubmatch(line); len(match) > 1 {
-					result.CrestFactor, _ = strconv.ParseFloat(match[1], 64)
-				}
-			}
-		}
+	if len(report.Channels) == 0 {
+		c.Problems = append(c.Problems, "no channel statistics")
+		return result
 	}
+	channel1 := report.Channel(1)
+	
+	result.RMSPeak = c.Stat(channel1, "RMS peak dB", audio.LevelRange)
+	result.RMSLevel = c.Stat(channel1, "RMS level dB", audio.LevelRange)
+	result.CrestFactor = c.Stat(channel1, "Crest factor", audio.CrestRange)
 	
 	// Calculate DS = Crest × (RMS_peak - RMS_level)
 	result.DynamicsScore = math.Sqrt(result.CrestFactor) * (result.RMSPeak - result.RMSLevel)
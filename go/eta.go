@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+const (
+	// defaultRealtimeMultiple is the assumed processing time per second of
+	// audio until a preset has history of its own
+	defaultRealtimeMultiple = 0.1
+
+	// speedSmoothing weighs each finished job against the stored average
+	speedSmoothing = 0.3
+)
+
+// speedModel keeps a running average of processing seconds per audio second
+// for each preset, so ETAs improve with every finished job
+type speedModel struct {
+	mutex     sync.Mutex
+	path      string
+	Multiples map[string]float64 `json:"multiples"`
+}
+
+// loadSpeedModel reads the stored speed history, starting empty when there is none
+func loadSpeedModel() *speedModel {
+	m := &speedModel{
+		path:      filepath.Join(dataDir(), "speed_history.json"),
+		Multiples: make(map[string]float64),
+	}
+
+	if data, err := os.ReadFile(m.path); err == nil {
+		json.Unmarshal(data, m)
+	}
+	if m.Multiples == nil {
+		m.Multiples = make(map[string]float64)
+	}
+
+	return m
+}
+
+// speedKey identifies the settings that decide how heavy a job is
+func speedKey(cfg ProcessConfig) string {
+	return fmt.Sprintf("%s|eq=%s|dyn=%s|dynnorm=%v|loudnorm=%v|speech=%s|bypass=%v",
+		cfg.Format, cfg.EqTarget, cfg.DynamicsPreset, cfg.DynNorm, cfg.UseLoudnorm, cfg.Speechnorm, cfg.bypassProc)
+}
+
+// multiple returns the realtime multiple for a preset and whether it comes from history
+func (m *speedModel) multiple(key string) (float64, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if v, ok := m.Multiples[key]; ok {
+		return v, true
+	}
+	return defaultRealtimeMultiple, false
+}
+
+// record folds one finished job into the average and stores it
+func (m *speedModel) record(key string, audioSeconds float64, elapsed time.Duration) {
+	if audioSeconds <= 0 {
+		return
+	}
+	sample := elapsed.Seconds() / audioSeconds
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if v, ok := m.Multiples[key]; ok {
+		m.Multiples[key] = v + speedSmoothing*(sample-v)
+	} else {
+		m.Multiples[key] = sample
+	}
+
+	os.MkdirAll(filepath.Dir(m.path), 0755)
+	data, _ := json.MarshalIndent(m, "", "  ")
+	os.WriteFile(m.path, data, 0644)
+}
+
+// batchETA tracks the audio left in a running batch
+type batchETA struct {
+	mutex     sync.Mutex
+	key       string
+	workers   int
+	durations map[string]float64
+	finished  map[string]bool
+	remaining float64
+}
+
+func newBatchETA(cfg ProcessConfig, workers int) *batchETA {
+	return &batchETA{
+		key:       speedKey(cfg),
+		workers:   workers,
+		durations: make(map[string]float64),
+		finished:  make(map[string]bool),
+	}
+}
+
+// probe reads the durations of the batch while the workers run, calling
+// update every few files. Files that finish before being probed are skipped.
+func (e *batchETA) probe(files []string, update func()) {
+	for i, file := range files {
+		e.mutex.Lock()
+		done := e.finished[file]
+		e.mutex.Unlock()
+		if done {
+			continue
+		}
+
+		d, err := audio.ProbeDuration(file)
+		if err != nil {
+			continue
+		}
+
+		e.mutex.Lock()
+		if !e.finished[file] {
+			e.durations[file] = d
+			e.remaining += d
+		}
+		e.mutex.Unlock()
+
+		if i%20 == 0 || i == len(files)-1 {
+			update()
+		}
+	}
+}
+
+// duration returns the probed duration of a file, 0 when it isn't known
+func (e *batchETA) duration(file string) float64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.durations[file]
+}
+
+// finish removes a completed or skipped file from the remaining audio
+func (e *batchETA) finish(file string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.finished[file] = true
+	e.remaining -= e.durations[file]
+}
+
+// text formats the time left for the batch
+func (e *batchETA) text(model *speedModel) string {
+	e.mutex.Lock()
+	remaining := e.remaining
+	e.mutex.Unlock()
+
+	if remaining <= 0 {
+		return ""
+	}
+
+	multiple, known := model.multiple(e.key)
+	eta := time.Duration(remaining * multiple / float64(e.workers) * float64(time.Second)).Round(time.Second)
+
+	if !known {
+		return fmt.Sprintf("Estimated time remaining: ~%s (no history for these settings yet)", eta)
+	}
+	return fmt.Sprintf("Estimated time remaining: %s", eta)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	feedbackURL     = "https://software.collinsgroup.fi/tnt-feedback"
+	feedbackTimeout = 15 * time.Second
+	// feedbackLogBytes is how much of the end of the log goes with a report
+	feedbackLogBytes = 64 << 10
+)
+
+// Kinds of message the form sends
+var feedbackKinds = []string{"Problem report", "Feature request", "Other feedback"}
+
+var ErrFeedbackRejected = errors.New("the support server did not accept the message")
+
+// feedbackMessage is what the form posts to the support endpoint
+type feedbackMessage struct {
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	ReplyTo  string `json:"reply_to,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Version  string `json:"version"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Log      string `json:"log,omitempty"`
+}
+
+// recentLog returns the end of the log file, starting at a whole line
+func recentLog() (string, error) {
+	f, err := os.Open(filepath.Join(dataDir(), logName))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	start := max(info.Size()-feedbackLogBytes, 0)
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	if start > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return string(data), nil
+}
+
+// postFeedback sends a message to the support endpoint over HTTPS
+func postFeedback(msg feedbackMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: feedbackTimeout}
+	resp, err := client.Post(feedbackURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrFeedbackRejected, resp.Status)
+	}
+	return nil
+}
+
+// showFeedbackForm lets the user write a problem report or feature request
+// and send it from TNT. When the support server can't be reached, it offers
+// the email client instead.
+func (n *AudioNormalizer) showFeedbackForm() {
+	w := fyne.CurrentApp().NewWindow("TNT feedback")
+
+	kindSelect := widget.NewSelect(feedbackKinds, nil)
+	kindSelect.SetSelected(feedbackKinds[0])
+
+	message := widget.NewMultiLineEntry()
+	message.Wrapping = fyne.TextWrapWord
+	message.SetPlaceHolder("What happened, or what would you like TNT to do?")
+	message.SetMinRowsVisible(8)
+
+	replyTo := widget.NewEntry()
+	replyTo.SetPlaceHolder("Optional, for an answer")
+
+	includeLog := widget.NewCheck("Include the recent log", nil)
+	includeLog.SetChecked(true)
+
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+
+	var sendBtn *widget.Button
+	sendBtn = widget.NewButton("Send", func() {
+		if strings.TrimSpace(message.Text) == "" {
+			status.SetText("Write a message first.")
+			return
+		}
+
+		msg := feedbackMessage{
+			Kind:     kindSelect.Selected,
+			Message:  message.Text,
+			ReplyTo:  strings.TrimSpace(replyTo.Text),
+			Operator: n.operator,
+			Version:  currentVersion,
+			OS:       runtime.GOOS,
+			Arch:     runtime.GOARCH,
+		}
+		if includeLog.Checked {
+			if excerpt, err := recentLog(); err == nil {
+				msg.Log = excerpt
+			} else {
+				n.logToFile(n.logFile, fmt.Sprintf("Log excerpt for feedback not read: %v", err))
+			}
+		}
+
+		sendBtn.Disable()
+		status.SetText("Sending...")
+		go func() {
+			err := postFeedback(msg)
+			fyne.Do(func() {
+				if err == nil {
+					n.logToFile(n.logFile, fmt.Sprintf("Sent %s to support", strings.ToLower(msg.Kind)))
+					w.Close()
+					dialog.ShowInformation("Feedback", "Thank you, your message was sent.", n.window)
+					return
+				}
+
+				n.logToFile(n.logFile, fmt.Sprintf("Feedback not sent: %v", err))
+				sendBtn.Enable()
+				status.SetText(fmt.Sprintf("Not sent: %v", err))
+				dialog.ShowConfirm("Feedback not sent",
+					"The support server could not be reached. Send the report by email instead? Your message is copied to the clipboard.",
+					func(useMail bool) {
+						if useMail {
+							w.Clipboard().SetContent(msg.Message)
+							n.mailLogReport()
+						}
+					}, w)
+			})
+		}()
+	})
+	sendBtn.Importance = widget.HighImportance
+
+	form := widget.NewForm(
+		widget.NewFormItem("Kind", kindSelect),
+		widget.NewFormItem("Reply to", replyTo),
+	)
+
+	w.SetContent(container.NewBorder(
+		form,
+		container.NewVBox(includeLog, status, sendBtn),
+		nil, nil,
+		message,
+	))
+	w.Resize(fyne.NewSize(520, 420))
+	w.Show()
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/internal/license"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	ffmpegManifestName = "ffmpeg.json"
+	// ffmpegDownloadTimeout bounds a bundle download, builds are around 100 MB
+	ffmpegDownloadTimeout = 30 * time.Minute
+)
+
+// ffmpegPublicKey is the base64 Ed25519 key the FFmpeg bundles in the version
+// feed are signed with, set for release builds with
+// -ldflags "-X main.ffmpegPublicKey=...". Builds without one never use a
+// downloaded FFmpeg.
+var ffmpegPublicKey string
+
+var (
+	ErrBundleUnsigned  = errors.New("the FFmpeg bundle is not signed")
+	ErrBundleSignature = errors.New("the FFmpeg bundle signature doesn't match")
+	ErrBundleNoKey     = errors.New("this build has no key to verify FFmpeg bundles")
+)
+
+// FFmpegBundle is an FFmpeg build the version feed offers for one platform,
+// so codec and filter fixes reach every machine without a new TNT release.
+// The hash only catches a damaged download, the signature is what makes the
+// build trusted: whoever controls the feed can't sign without the vendor's key.
+type FFmpegBundle struct {
+	Version   string `json:"version"`
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// signedPayload is what a bundle's signature covers. The platform is part of
+// it, so a build signed for one platform can't be offered to another.
+func (b FFmpegBundle) signedPayload(platformKey string) []byte {
+	return []byte(fmt.Sprintf("tnt-ffmpeg\n%s\n%s\n%s", platformKey, b.Version, strings.ToLower(b.SHA256)))
+}
+
+// verify checks the bundle's Ed25519 signature against the key in this build
+func (b FFmpegBundle) verify(platformKey string) error {
+	if ffmpegPublicKey == "" {
+		return ErrBundleNoKey
+	}
+	pub, err := license.ParsePublicKey(ffmpegPublicKey)
+	if err != nil {
+		return err
+	}
+	if b.Signature == "" {
+		return ErrBundleUnsigned
+	}
+	sig, err := base64.StdEncoding.DecodeString(b.Signature)
+	if err != nil || !ed25519.Verify(pub, b.signedPayload(platformKey), sig) {
+		return ErrBundleSignature
+	}
+	return nil
+}
+
+// ffmpegBundleVersion is the version of the downloaded FFmpeg in use, "" for the embedded one
+var ffmpegBundleVersion string
+
+// ffmpegCacheDir holds downloaded FFmpeg builds. They are shared by every
+// profile, since they don't depend on settings.
+func ffmpegCacheDir() string {
+	return filepath.Join(configRoot(), "TNT", "ffmpeg")
+}
+
+// readFFmpegManifest returns the downloaded bundle and the path of its binary
+func readFFmpegManifest() (FFmpegBundle, string, error) {
+	var bundle FFmpegBundle
+	data, err := os.ReadFile(filepath.Join(ffmpegCacheDir(), ffmpegManifestName))
+	if err != nil {
+		return bundle, "", err
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return bundle, "", err
+	}
+	return bundle, filepath.Join(ffmpegCacheDir(), ffmpegBinaryName(bundle.SHA256)), nil
+}
+
+// ffmpegBinaryName names a downloaded build by its hash, so a new build
+// never overwrites one a running TNT still uses
+func ffmpegBinaryName(sum string) string {
+	name := "ffmpeg-" + sum[:min(len(sum), 12)]
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// fileSHA256 returns the hex SHA-256 of a file
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// useCachedFFmpeg switches to a downloaded FFmpeg when its signature and
+// hash check out and it runs on this machine, otherwise the embedded one
+// stays in use. It runs at startup, before any job, so a batch never mixes
+// two builds. The signature is checked again, as the manifest is only a file.
+func useCachedFFmpeg() {
+	bundle, path, err := readFFmpegManifest()
+	if err != nil {
+		return
+	}
+	if err := bundle.verify(getPlatformKey()); err != nil {
+		return
+	}
+	if sum, err := fileSHA256(path); err != nil || !strings.EqualFold(sum, bundle.SHA256) {
+		return
+	}
+
+	embedded := ffmpeg.Path
+	ffmpeg.Path = path
+	if _, err := ffmpeg.Output("-hide_banner", "-version"); err != nil {
+		ffmpeg.Path = embedded
+		return
+	}
+	ffmpegBundleVersion = bundle.Version
+	removeStaleFFmpeg(filepath.Base(path))
+}
+
+// removeStaleFFmpeg deletes builds replaced by a newer download. One still
+// running in another TNT can't be removed on Windows and is left for later.
+func removeStaleFFmpeg(keep string) {
+	entries, _ := os.ReadDir(ffmpegCacheDir())
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "ffmpeg-") && e.Name() != keep {
+			os.Remove(filepath.Join(ffmpegCacheDir(), e.Name()))
+		}
+	}
+}
+
+// updateFFmpeg downloads the FFmpeg build the version feed offers for this
+// platform when it differs from the one in use. A bundle without a valid
+// signature is never downloaded. The build is only kept when its hash
+// matches the signed one, and is used from the next start.
+func updateFFmpeg(versionInfo VersionInfo, logFile *os.File) {
+	bundle, ok := versionInfo.FFmpeg[getPlatformKey()]
+	if !ok || bundle.URL == "" || bundle.SHA256 == "" {
+		return
+	}
+	if err := bundle.verify(getPlatformKey()); err != nil {
+		logToFile(logFile, fmt.Sprintf("FFmpeg %s not updated: %v", bundle.Version, err))
+		return
+	}
+
+	if current, _, err := readFFmpegManifest(); err == nil && strings.EqualFold(current.SHA256, bundle.SHA256) {
+		return
+	}
+	embedded := sha256.Sum256(platform.FFmpegBinary)
+	if strings.EqualFold(hex.EncodeToString(embedded[:]), bundle.SHA256) {
+		return
+	}
+
+	logToFile(logFile, fmt.Sprintf("Downloading FFmpeg %s from %s", bundle.Version, bundle.URL))
+	if err := downloadFFmpegBundle(bundle); err != nil {
+		logToFile(logFile, fmt.Sprintf("FFmpeg %s not updated: %v", bundle.Version, err))
+		return
+	}
+	logToFile(logFile, fmt.Sprintf("FFmpeg %s downloaded, it is used from the next start", bundle.Version))
+}
+
+// downloadFFmpegBundle fetches a build into the cache, verifies it and
+// points the manifest at it
+func downloadFFmpegBundle(bundle FFmpegBundle) error {
+	dir := ffmpegCacheDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: ffmpegDownloadTimeout}
+	resp, err := client.Get(bundle.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server answered %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(dir, "download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	sum := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, sum), resp.Body)
+	if err = errors.Join(err, tmp.Close()); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(sum.Sum(nil)); !strings.EqualFold(got, bundle.SHA256) {
+		return fmt.Errorf("hash mismatch, expected %s, got %s", bundle.SHA256, got)
+	}
+
+	path := filepath.Join(dir, ffmpegBinaryName(bundle.SHA256))
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ffmpegManifestName), data, 0644)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// fileProgress follows the FFmpeg runs of one file being processed and shows
+// how far the current run is in the file's row. A file goes through several
+// runs, stages and the final encode, each starting over from the beginning.
+type fileProgress struct {
+	n      *AudioNormalizer
+	path   string
+	length time.Duration
+
+	mutex     sync.Mutex
+	pass      int
+	passStart time.Time
+	last      time.Duration
+}
+
+// trackProgress reports the progress of the watchdog's runs in the row of inputPath
+func (n *AudioNormalizer) trackProgress(inputPath string, wd *ffmpeg.Watchdog) *fileProgress {
+	p := &fileProgress{n: n, path: inputPath, length: wd.Length}
+	if p.length > 0 {
+		wd.Progress = p.update
+	}
+	return p
+}
+
+// update takes FFmpeg's position in the current run
+func (p *fileProgress) update(position time.Duration) {
+	p.mutex.Lock()
+	if p.pass == 0 || position < p.last {
+		p.pass++
+		p.passStart = time.Now()
+	}
+	p.last = position
+	fraction := min(float64(position)/float64(p.length), 1)
+	text := fmt.Sprintf("pass %d · %d%%", p.pass, int(fraction*100))
+	if elapsed := time.Since(p.passStart); fraction > 0.02 && elapsed > 2*time.Second {
+		left := time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+		text += " · " + left.Round(time.Second).String() + " left"
+	}
+	p.mutex.Unlock()
+
+	p.n.rowProgress.Store(p.path, text)
+	p.n.fileListRefresh.Trigger()
+}
+
+// done clears the row once the file is finished
+func (p *fileProgress) done() {
+	p.n.rowProgress.Delete(p.path)
+	p.n.fileListRefresh.Trigger()
+}
+
+// fileRowText is the file list label of a file, with its progress while processing
+func (n *AudioNormalizer) fileRowText(path string) string {
+	if text, ok := n.rowProgress.Load(path); ok {
+		return fmt.Sprintf("%s  (%s)", filepath.Base(path), text)
+	}
+	if m, ok := n.complianceFor(path); ok {
+		return fmt.Sprintf("%s  (%s)", filepath.Base(path), m.text())
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// floatOvers reports whether a float input peaks above full scale in a way
+// the output would clip. Loudness normalization limits the true peak, so only
+// unnormalized encodes to integer PCM or FLAC are at risk.
+func (n *AudioNormalizer) floatOvers(inputPath, codec string, cfg ProcessConfig) bool {
+	if cfg.UseLoudnorm || cfg.noTranscode {
+		return false
+	}
+	if codec != "flac" && !(codec == "PCM" && (cfg.BitDepth == "16" || cfg.BitDepth == "24")) {
+		return false
+	}
+	if !audio.IsFloatInput(inputPath) {
+		return false
+	}
+
+	peak, err := audio.SamplePeak(inputPath)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Float input peak not measured for %s: %v", inputPath, err))
+		return false
+	}
+	n.logToFile(n.logFile, fmt.Sprintf("Float input %s peaks at %+.2f dBFS", inputPath, peak))
+	return peak > 0
+}
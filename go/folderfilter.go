@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// appleDoubleDir is where macOS archivers keep resource forks
+const appleDoubleDir = "__MACOSX"
+
+// folderFilter holds the optional filters applied while a selected folder is
+// scanned. Zero values leave a filter off.
+type folderFilter struct {
+	MinSeconds float64
+	MaxSeconds float64
+	SkipHidden bool
+	// Since skips files last modified before it
+	Since time.Time
+}
+
+// parseFolderFilter reads the filter settings as typed in the preferences
+func parseFolderFilter(minText, maxText, sinceText string, skipHidden bool) (folderFilter, error) {
+	f := folderFilter{SkipHidden: skipHidden}
+
+	var err error
+	if strings.TrimSpace(minText) != "" {
+		if f.MinSeconds, err = audio.ParseTimecode(minText); err != nil {
+			return f, err
+		}
+	}
+	if strings.TrimSpace(maxText) != "" {
+		if f.MaxSeconds, err = audio.ParseTimecode(maxText); err != nil {
+			return f, err
+		}
+	}
+	if f.MaxSeconds > 0 && f.MaxSeconds < f.MinSeconds {
+		return f, fmt.Errorf("longest duration is shorter than the shortest")
+	}
+	if text := strings.TrimSpace(sinceText); text != "" {
+		if f.Since, err = time.ParseInLocation("2006-01-02", text, time.Local); err != nil {
+			return f, fmt.Errorf("date %q is not in the form YYYY-MM-DD", text)
+		}
+	}
+	return f, nil
+}
+
+// folderFilter returns the filters set in the preferences. Invalid settings
+// are reported and leave that scan unfiltered.
+func (n *AudioNormalizer) folderFilter() folderFilter {
+	f, err := parseFolderFilter(n.folderMinEntry.Text, n.folderMaxEntry.Text, n.folderSinceEntry.Text, n.folderSkipHiddenCheck.Checked)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("⚠ Folder filter ignored: %v", err))
+		return folderFilter{}
+	}
+	return f
+}
+
+// skipDir reports whether a whole folder is left out of the scan
+func (f folderFilter) skipDir(path string) bool {
+	if !f.SkipHidden {
+		return false
+	}
+	return filepath.Base(path) == appleDoubleDir || platform.IsHidden(path)
+}
+
+// skipFile returns why a file or archive is left out, or "" to keep it
+func (f folderFilter) skipFile(path string, d fs.DirEntry) string {
+	if f.SkipHidden && platform.IsHidden(path) {
+		return "hidden file"
+	}
+
+	if !f.Since.IsZero() {
+		if info, err := d.Info(); err == nil && info.ModTime().Before(f.Since) {
+			return "modified before " + f.Since.Format("2006-01-02")
+		}
+	}
+
+	return ""
+}
+
+// skipDuration returns why an audio file's length leaves it out, or "" to
+// keep it. Files without a readable duration are kept for validation to judge.
+func (f folderFilter) skipDuration(path string) string {
+	if f.MinSeconds == 0 && f.MaxSeconds == 0 {
+		return ""
+	}
+
+	duration, err := audio.ProbeDuration(path)
+	if err != nil {
+		return ""
+	}
+	if f.MinSeconds > 0 && duration < f.MinSeconds {
+		return fmt.Sprintf("shorter than %s", audio.FormatTimecode(f.MinSeconds))
+	}
+	if f.MaxSeconds > 0 && duration > f.MaxSeconds {
+		return fmt.Sprintf("longer than %s", audio.FormatTimecode(f.MaxSeconds))
+	}
+	return ""
+}
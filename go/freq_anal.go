@@ -2,11 +2,9 @@ package main
 
 import (
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 	"math"
-	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/internal/audio"
 )
 
 // FrequencyBand represents analyzed frequency response data for one band
@@ -19,8 +17,9 @@ type FrequencyBand struct {
 }
 
 // analyzeFrequencyResponseBands analyzes the frequency response across 10 bands
-// using lowpass, bandpass, and highpass filters with astats
-func (n *AudioNormalizer) analyzeFrequencyResponseBands(inputPath string) []FrequencyBand {
+// using lowpass, bandpass, and highpass filters with astats, after the
+// stage filters in chain
+func (n *AudioNormalizer) analyzeFrequencyResponseBands(inputPath, chain string) []FrequencyBand {
 	bands := []FrequencyBand{
 		{Frequency: "50Hz", FilterType: "lowpass"},
 		{Frequency: "100Hz", FilterType: "bandpass"},
@@ -37,6 +36,8 @@ func (n *AudioNormalizer) analyzeFrequencyResponseBands(inputPath string) []Freq
 	n.logStatus("Analyzing frequency response across 10 bands...")
 	n.logToFile(n.logFile, "Starting frequency response analysis")
 
+	// Every band is measured in one decode of the file, see audio.Analyze
+	branches := make([]audio.AnalysisBranch, len(bands))
 	for i := range bands {
 		band := &bands[i]
 		
@@ -44,45 +45,33 @@ func (n *AudioNormalizer) analyzeFrequencyResponseBands(inputPath string) []Freq
 		switch band.FilterType {
 		case "lowpass":
 			// Everything below 50Hz
-			filterChain = "highpass=f=25:p=1:r=f64:p=2,lowpass=f=50,astats"
+			filterChain = "highpass=f=25:p=1:r=f64:p=2,lowpass=f=50"
 			
 		case "highpass":
 			// Everything above 12.8kHz
-			filterChain = "highpass=f=12800,astats"
+			filterChain = "highpass=f=12800"
 			
 		case "bandpass":
 			// Extract center frequency and calculate bandwidth
 			centerFreq, bandwidth := n.getBandpassParams(band.Frequency)
-			filterChain = fmt.Sprintf("bandpass=f=%d:width_type=o:width=1,astats", centerFreq)
+			filterChain = fmt.Sprintf("bandpass=f=%d:width_type=o:width=1", centerFreq)
 			n.logToFile(n.logFile, fmt.Sprintf("Band %s: center=%dHz, bandwidth=%.1fHz (1 octave)", 
 				band.Frequency, centerFreq, bandwidth))
 		}
+		branches[i] = audio.AnalysisBranch{Name: band.Frequency, Filter: filterChain}
+	}
 
-		n.logStatus(fmt.Sprintf("  Measuring %s band...", band.Frequency))
-		
-		cmd := ffmpeg.Command(
-			
-			"-i", inputPath,
-			"-af", filterChain,
-			"-f", "null",
-			"-",
-		)
-		
-
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			n.logStatus(fmt.Sprintf("    Failed to analyze %s: %v", band.Frequency, err))
-			n.logToFile(n.logFile, fmt.Sprintf("Failed %s analysis: %v", band.Frequency, err))
-			continue
-		}
+	reports, err := audio.Analyze(inputPath, chain, branches)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("    Failed to analyze frequency response: %v", err))
+		n.logToFile(n.logFile, fmt.Sprintf("Frequency response analysis failed: %v", err))
+		return nil
+	}
 
-		// Log raw FFmpeg output for debugging
-		//n.logToFile(n.logFile, fmt.Sprintf("=== RAW OUTPUT for %s ===", band.Frequency))
-		//n.logToFile(n.logFile, string(output))
-		//n.logToFile(n.logFile, fmt.Sprintf("=== END RAW OUTPUT for %s ===", band.Frequency))
+	for i := range bands {
+		band := &bands[i]
 
-		// Parse astats output for this band
-		stats := n.parseFrequencyBandStats(string(output))
+		stats := n.bandStatsFrom(reports[band.Frequency])
 		band.RMSLevel = stats["rms"]
 		band.PeakLevel = stats["peak"]
 		band.CrestFactor = stats["crest"]
@@ -122,34 +111,16 @@ func (n *AudioNormalizer) getBandpassParams(freqStr string) (int, float64) {
 	return centerFreq, bandwidth
 }
 
-// parseFrequencyBandStats extracts RMS, peak, and crest factor from astats output
-func (n *AudioNormalizer) parseFrequencyBandStats(output string) map[string]float64 {
+// bandStatsFrom extracts RMS, peak, and crest factor from a band's astats report
+func (n *AudioNormalizer) bandStatsFrom(report audio.AstatsReport) map[string]float64 {
 	stats := make(map[string]float64)
 	
-	// Parse RMS level (dB)
-	// Example: "RMS level dB: -23.45"
-	rmsRe := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-	if match := rmsRe.FindStringSubmatch(output); len(match) > 1 {
-		if val, err := strconv.ParseFloat(match[1], 64); err == nil {
-			stats["rms"] = val
-		}
-	}
-	
-	// Parse Peak level (dB)
-	// Example: "Peak level dB: -12.34"
-	peakRe := regexp.MustCompile(`Peak level dB:\s+([-\d.]+)`)
-	if match := peakRe.FindStringSubmatch(output); len(match) > 1 {
-		if val, err := strconv.ParseFloat(match[1], 64); err == nil {
-			stats["peak"] = val
-		}
-	}
+	channel1 := report.Channel(1)
 	
-	// Parse Crest factor (ratio, not dB)
-	// Example: "Crest factor: 2.858335"
-	crestRe := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-	if match := crestRe.FindStringSubmatch(output); len(match) > 1 {
-		if val, err := strconv.ParseFloat(match[1], 64); err == nil {
-			stats["crest"] = val
+	// Crest factor is a ratio, not dB
+	for key, label := range map[string]string{"rms": "RMS level dB", "peak": "Peak level dB", "crest": "Crest factor"} {
+		if val, err := channel1.Float(label); err == nil {
+			stats[key] = val
 		}
 	}
 	
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxGainTrim is the largest manual trim in dB either way
+const maxGainTrim = 24.0
+
+// parseGainTrim reads a trim like "-3", "+1.5" or "-2 dB". Empty text is no trim.
+func parseGainTrim(text string) (float64, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.ToLower(text)), "db"))
+	if text == "" {
+		return 0, nil
+	}
+
+	trim, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+	if err != nil || math.IsNaN(trim) {
+		return 0, fmt.Errorf("gain trim %q is not a number", text)
+	}
+	if math.Abs(trim) > maxGainTrim {
+		return 0, fmt.Errorf("gain trim must be within ±%.0f dB", maxGainTrim)
+	}
+	return trim, nil
+}
+
+// setGainTrim stores the trim for a queued file, removing it when zero
+func (n *AudioNormalizer) setGainTrim(path string, trim float64) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if trim == 0 {
+		delete(n.gainTrims, path)
+		return
+	}
+	if n.gainTrims == nil {
+		n.gainTrims = make(map[string]float64)
+	}
+	n.gainTrims[path] = trim
+}
+
+// gainTrimFor returns the manual trim for a file in dB, 0 when there is none
+func (n *AudioNormalizer) gainTrimFor(path string) float64 {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.gainTrims[path]
+}
+
+// gainTrimText formats a stored trim for the queue row
+func (n *AudioNormalizer) gainTrimText(path string) string {
+	trim := n.gainTrimFor(path)
+	if trim == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(trim, 'f', -1, 64)
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"image"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// The background service and the JSON-RPC mode run the engine without a
+// window. The engine still reads its settings from the widgets, so they are
+// built on the fyne app below, which has a driver that draws nothing. It
+// replaces the test driver, which isn't meant to ship.
+
+// newHeadlessNormalizer builds the normalizer for a mode without a window, with
+// the active profile's settings
+func newHeadlessNormalizer(operator string) *AudioNormalizer {
+	a := newHeadlessApp()
+	n := newNormalizer(a, a.NewWindow("TNT"), operator)
+	n.headless = true
+	return n
+}
+
+// headlessApp is a fyne app without a display. It has no preferences, storage
+// or cloud provider, those are only used by the window's file dialogs.
+type headlessApp struct {
+	driver    *headlessDriver
+	settings  *headlessSettings
+	clipboard *headlessClipboard
+	icon      fyne.Resource
+}
+
+func newHeadlessApp() *headlessApp {
+	a := &headlessApp{
+		driver:    &headlessDriver{},
+		settings:  &headlessSettings{theme: theme.DefaultTheme()},
+		clipboard: &headlessClipboard{},
+	}
+	fyne.SetCurrentApp(a)
+	return a
+}
+
+func (a *headlessApp) NewWindow(title string) fyne.Window  { return a.driver.CreateWindow(title) }
+func (a *headlessApp) OpenURL(*url.URL) error              { return nil }
+func (a *headlessApp) Icon() fyne.Resource                 { return a.icon }
+func (a *headlessApp) SetIcon(icon fyne.Resource)          { a.icon = icon }
+func (a *headlessApp) Run()                                { a.driver.Run() }
+func (a *headlessApp) Quit()                               { a.driver.Quit() }
+func (a *headlessApp) Driver() fyne.Driver                 { return a.driver }
+func (a *headlessApp) UniqueID() string                    { return serviceName }
+func (a *headlessApp) SendNotification(*fyne.Notification) {}
+func (a *headlessApp) Settings() fyne.Settings             { return a.settings }
+func (a *headlessApp) Preferences() fyne.Preferences       { return nil }
+func (a *headlessApp) Storage() fyne.Storage               { return nil }
+func (a *headlessApp) Lifecycle() fyne.Lifecycle           { return headlessLifecycle{} }
+func (a *headlessApp) CloudProvider() fyne.CloudProvider   { return nil }
+func (a *headlessApp) SetCloudProvider(fyne.CloudProvider) {}
+func (a *headlessApp) Clipboard() fyne.Clipboard           { return a.clipboard }
+
+func (a *headlessApp) Metadata() fyne.AppMetadata {
+	return fyne.AppMetadata{ID: serviceName, Name: "TNT", Version: currentVersion}
+}
+
+// headlessDriver keeps the windows but never draws them. There's no event
+// loop either: work handed to the UI thread runs right away on the caller's
+// goroutine, as it did on the test driver.
+type headlessDriver struct {
+	mutex   sync.Mutex
+	windows []fyne.Window
+	quit    chan struct{}
+	once    sync.Once
+}
+
+func (d *headlessDriver) CreateWindow(title string) fyne.Window {
+	w := &headlessWindow{title: title, canvas: &headlessCanvas{}}
+	d.mutex.Lock()
+	d.windows = append(d.windows, w)
+	d.mutex.Unlock()
+	return w
+}
+
+func (d *headlessDriver) AllWindows() []fyne.Window {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return slices.Clone(d.windows)
+}
+
+// RenderedTextSize estimates the size, half the font size per character is
+// close enough for layouts nobody sees
+func (d *headlessDriver) RenderedTextSize(text string, size float32, _ fyne.TextStyle, _ fyne.Resource) (fyne.Size, float32) {
+	return fyne.NewSize(float32(len([]rune(text)))*size/2, size), size * 0.8
+}
+
+func (d *headlessDriver) CanvasForObject(fyne.CanvasObject) fyne.Canvas { return nil }
+func (d *headlessDriver) AbsolutePositionForObject(fyne.CanvasObject) fyne.Position {
+	return fyne.Position{}
+}
+func (d *headlessDriver) Device() fyne.Device               { return headlessDevice{} }
+func (d *headlessDriver) StartAnimation(*fyne.Animation)    {}
+func (d *headlessDriver) StopAnimation(*fyne.Animation)     {}
+func (d *headlessDriver) DoubleTapDelay() time.Duration     { return 300 * time.Millisecond }
+func (d *headlessDriver) SetDisableScreenBlanking(bool)     {}
+func (d *headlessDriver) DoFromGoroutine(fn func(), _ bool) { fn() }
+
+// Run blocks until Quit, like a real driver's event loop
+func (d *headlessDriver) Run() {
+	<-d.quitChan()
+}
+
+func (d *headlessDriver) Quit() {
+	d.once.Do(func() { close(d.quitChan()) })
+}
+
+func (d *headlessDriver) quitChan() chan struct{} {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.quit == nil {
+		d.quit = make(chan struct{})
+	}
+	return d.quit
+}
+
+// headlessWindow holds a window's content and settings, it's never shown
+type headlessWindow struct {
+	title      string
+	fixed      bool
+	padded     bool
+	fullScreen bool
+	icon       fyne.Resource
+	menu       *fyne.MainMenu
+	onClosed   func()
+	canvas     *headlessCanvas
+	clipboard  headlessClipboard
+}
+
+func (w *headlessWindow) Title() string                                { return w.title }
+func (w *headlessWindow) SetTitle(title string)                        { w.title = title }
+func (w *headlessWindow) FullScreen() bool                             { return w.fullScreen }
+func (w *headlessWindow) SetFullScreen(full bool)                      { w.fullScreen = full }
+func (w *headlessWindow) Resize(size fyne.Size)                        { w.canvas.resize(size) }
+func (w *headlessWindow) RequestFocus()                                {}
+func (w *headlessWindow) FixedSize() bool                              { return w.fixed }
+func (w *headlessWindow) SetFixedSize(fixed bool)                      { w.fixed = fixed }
+func (w *headlessWindow) CenterOnScreen()                              {}
+func (w *headlessWindow) Padded() bool                                 { return w.padded }
+func (w *headlessWindow) SetPadded(padded bool)                        { w.padded = padded }
+func (w *headlessWindow) Icon() fyne.Resource                          { return w.icon }
+func (w *headlessWindow) SetIcon(icon fyne.Resource)                   { w.icon = icon }
+func (w *headlessWindow) SetMaster()                                   {}
+func (w *headlessWindow) MainMenu() *fyne.MainMenu                     { return w.menu }
+func (w *headlessWindow) SetMainMenu(menu *fyne.MainMenu)              { w.menu = menu }
+func (w *headlessWindow) SetOnClosed(closed func())                    { w.onClosed = closed }
+func (w *headlessWindow) SetCloseIntercept(func())                     {}
+func (w *headlessWindow) SetOnDropped(func(fyne.Position, []fyne.URI)) {}
+func (w *headlessWindow) Show()                                        {}
+func (w *headlessWindow) Hide()                                        {}
+func (w *headlessWindow) ShowAndRun()                                  { fyne.CurrentApp().Run() }
+func (w *headlessWindow) Content() fyne.CanvasObject                   { return w.canvas.Content() }
+func (w *headlessWindow) SetContent(content fyne.CanvasObject)         { w.canvas.SetContent(content) }
+func (w *headlessWindow) Canvas() fyne.Canvas                          { return w.canvas }
+func (w *headlessWindow) Clipboard() fyne.Clipboard                    { return &w.clipboard }
+
+func (w *headlessWindow) Close() {
+	if w.onClosed != nil {
+		w.onClosed()
+	}
+}
+
+// headlessCanvas keeps the content and its size but draws nothing
+type headlessCanvas struct {
+	content   fyne.CanvasObject
+	size      fyne.Size
+	focused   fyne.Focusable
+	overlays  headlessOverlays
+	onRune    func(rune)
+	onKey     func(*fyne.KeyEvent)
+	shortcuts fyne.ShortcutHandler
+}
+
+func (c *headlessCanvas) Content() fyne.CanvasObject { return c.content }
+
+func (c *headlessCanvas) SetContent(content fyne.CanvasObject) {
+	c.content = content
+	c.resize(c.size)
+}
+
+func (c *headlessCanvas) resize(size fyne.Size) {
+	c.size = size
+	if c.content != nil && !size.IsZero() {
+		c.content.Resize(size)
+	}
+}
+
+func (c *headlessCanvas) Focus(f fyne.Focusable) {
+	c.Unfocus()
+	c.focused = f
+	if f != nil {
+		f.FocusGained()
+	}
+}
+
+func (c *headlessCanvas) Unfocus() {
+	if c.focused != nil {
+		c.focused.FocusLost()
+		c.focused = nil
+	}
+}
+
+func (c *headlessCanvas) Refresh(fyne.CanvasObject)                {}
+func (c *headlessCanvas) FocusNext()                               {}
+func (c *headlessCanvas) FocusPrevious()                           {}
+func (c *headlessCanvas) Focused() fyne.Focusable                  { return c.focused }
+func (c *headlessCanvas) Size() fyne.Size                          { return c.size }
+func (c *headlessCanvas) Scale() float32                           { return 1 }
+func (c *headlessCanvas) Overlays() fyne.OverlayStack              { return &c.overlays }
+func (c *headlessCanvas) OnTypedRune() func(rune)                  { return c.onRune }
+func (c *headlessCanvas) SetOnTypedRune(typed func(rune))          { c.onRune = typed }
+func (c *headlessCanvas) OnTypedKey() func(*fyne.KeyEvent)         { return c.onKey }
+func (c *headlessCanvas) SetOnTypedKey(typed func(*fyne.KeyEvent)) { c.onKey = typed }
+func (c *headlessCanvas) Capture() image.Image                     { return image.NewNRGBA(image.Rect(0, 0, 0, 0)) }
+func (c *headlessCanvas) InteractiveArea() (fyne.Position, fyne.Size) {
+	return fyne.Position{}, c.size
+}
+
+func (c *headlessCanvas) PixelCoordinateForPosition(pos fyne.Position) (int, int) {
+	return int(pos.X), int(pos.Y)
+}
+
+func (c *headlessCanvas) AddShortcut(shortcut fyne.Shortcut, handler func(fyne.Shortcut)) {
+	c.shortcuts.AddShortcut(shortcut, handler)
+}
+
+func (c *headlessCanvas) RemoveShortcut(shortcut fyne.Shortcut) {
+	c.shortcuts.RemoveShortcut(shortcut)
+}
+
+// headlessOverlays is the stack of popups on a headless canvas
+type headlessOverlays struct {
+	list []fyne.CanvasObject
+}
+
+func (o *headlessOverlays) Add(overlay fyne.CanvasObject) { o.list = append(o.list, overlay) }
+func (o *headlessOverlays) List() []fyne.CanvasObject     { return o.list }
+
+func (o *headlessOverlays) Remove(overlay fyne.CanvasObject) {
+	for i, object := range o.list {
+		if object == overlay {
+			o.list = o.list[:i]
+			return
+		}
+	}
+}
+
+func (o *headlessOverlays) Top() fyne.CanvasObject {
+	if len(o.list) == 0 {
+		return nil
+	}
+	return o.list[len(o.list)-1]
+}
+
+// headlessSettings holds the theme, the widgets look it up even undrawn
+type headlessSettings struct {
+	mutex     sync.Mutex
+	theme     fyne.Theme
+	listeners []func(fyne.Settings)
+	channels  []chan fyne.Settings
+}
+
+func (s *headlessSettings) Theme() fyne.Theme {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.theme
+}
+
+func (s *headlessSettings) SetTheme(t fyne.Theme) {
+	s.mutex.Lock()
+	s.theme = t
+	listeners := slices.Clone(s.listeners)
+	channels := slices.Clone(s.channels)
+	s.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(s)
+	}
+	for _, ch := range channels {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+func (s *headlessSettings) AddListener(listener func(fyne.Settings)) {
+	s.mutex.Lock()
+	s.listeners = append(s.listeners, listener)
+	s.mutex.Unlock()
+}
+
+func (s *headlessSettings) AddChangeListener(ch chan fyne.Settings) {
+	s.mutex.Lock()
+	s.channels = append(s.channels, ch)
+	s.mutex.Unlock()
+}
+
+func (s *headlessSettings) ThemeVariant() fyne.ThemeVariant { return theme.VariantLight }
+func (s *headlessSettings) Scale() float32                  { return 1 }
+func (s *headlessSettings) PrimaryColor() string            { return theme.ColorBlue }
+func (s *headlessSettings) BuildType() fyne.BuildType       { return fyne.BuildRelease }
+func (s *headlessSettings) ShowAnimations() bool            { return false }
+
+// headlessClipboard is a clipboard nobody else can paste from
+type headlessClipboard struct {
+	mutex   sync.Mutex
+	content string
+}
+
+func (c *headlessClipboard) Content() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.content
+}
+
+func (c *headlessClipboard) SetContent(content string) {
+	c.mutex.Lock()
+	c.content = content
+	c.mutex.Unlock()
+}
+
+// headlessLifecycle never fires, without a window there's no foreground to
+// enter or leave
+type headlessLifecycle struct{}
+
+func (headlessLifecycle) SetOnEnteredForeground(func()) {}
+func (headlessLifecycle) SetOnExitedForeground(func())  {}
+func (headlessLifecycle) SetOnStarted(func())           {}
+func (headlessLifecycle) SetOnStopped(func())           {}
+
+// headlessDevice describes a desktop without a screen
+type headlessDevice struct{}
+
+func (headlessDevice) Orientation() fyne.DeviceOrientation      { return fyne.OrientationVertical }
+func (headlessDevice) IsMobile() bool                           { return false }
+func (headlessDevice) IsBrowser() bool                          { return false }
+func (headlessDevice) HasKeyboard() bool                        { return false }
+func (headlessDevice) SystemScaleForWindow(fyne.Window) float32 { return 1 }
+func (headlessDevice) Locale() fyne.Locale                      { return "en" }
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// helpSection is one part of a Help window tab. Sections with a key are also
+// shown by the hint buttons next to the controls they describe, so the Help
+// window and the hints can't say different things.
+type helpSection struct {
+	Key     string
+	Heading string
+	Text    string
+}
+
+// helpTopic is one tab of the Help window
+type helpTopic struct {
+	Tab      string
+	Sections []helpSection
+}
+
+// helpTopics is the single source of TNT's help text
+var helpTopics = []helpTopic{
+	{
+		Tab: "Getting started",
+		Sections: []helpSection{
+			{Text: `TNT is designed for broadcast professionals to streamline audio workflows. The application provides three core capabilities:
+
+• Transcode - Convert between audio formats
+• Normalize - Ensure consistent loudness levels
+• Tag - Write ReplayGain metadata for playback guidance`},
+			{Heading: "FAST MODE", Text: "Simple mode offers three preset configurations for common use cases. Processing requires just four clicks, and files are processed individually in the background with results appearing in your output folder as they complete."},
+			{Heading: "ADVANCED MODE", Text: `Advanced mode provides granular control over encoding parameters including format selection, sample rates, bit depths, and bitrates. You can configure custom loudness normalization targets or write ReplayGain tags instead of normalizing.
+
+Note: Normalization alters the audio data, while tagging only writes metadata. These options are mutually exclusive.`},
+			{Heading: "WORKFLOW", Text: `1. Select Files - Choose individual files, or Select Folder for batch processing
+2. Output Folder - Specify destination for processed files
+3. Configure settings in Fast or Advanced mode
+4. Click Process`},
+			{Heading: "COMMAND PALETTE", Text: "Press Ctrl+K (Cmd+K on macOS) and type to find an action, a Fast preset or a settings page by name, then press Enter."},
+			{Text: "For more information visit https://www.fremen.fi/software/tnt and scroll to the bottom of the page."},
+		},
+	},
+	{
+		Tab: "Simple",
+		Sections: []helpSection{
+			{Heading: "SIMPLE MODE"},
+			{Key: "fast-presets", Text: fmt.Sprintf(`Simple mode provides four preset configurations optimized for common broadcast scenarios:
+
+• %s - Compressed format balancing quality and file size
+• %s - Universal playback support across all devices
+• %s - Uncompressed broadcast-quality audio
+• %s - Archive masters of vinyl and cassette transfers, with restoration, rumble filtering and 3 dB of true peak headroom
+
+Each preset handles format conversion with minimal configuration required. Simply select your desired output format from the four options.`, builtinFastPresets[0], builtinFastPresets[1], builtinFastPresets[2], builtinFastPresets[3])},
+			{Key: "normalize", Heading: "NORMALIZATION", Text: "The 'Normalize' checkbox applies loudness normalization to the standard chosen in Menu > Normalization, EBU R128 unless changed. With EBU R128, all processed files meet the -23 LUFS standard with -1 dBTP limiting, ensuring consistent playback levels across your content. Normalization alters the audio data and can't be combined with writing ReplayGain tags."},
+			{Heading: "WORKFLOW", Text: `Processing in Simple mode requires just four clicks:
+1. Select your files or folder
+2. Choose output destination
+3. Pick a preset format
+4. Click Process
+
+The application processes files individually in the background. Completed files appear in your output folder as they finish, allowing you to continue working while processing continues.`},
+		},
+	},
+	{
+		Tab: "Advanced",
+		Sections: []helpSection{
+			{Heading: "ADVANCED MODE", Text: "Advanced mode provides granular control over all encoding parameters."},
+			{Key: "format", Heading: "FORMAT SELECTION", Text: `Choose from AAC, Opus, MP3, PCM (Wave), or FLAC.
+
+Sample Rate: Available only for PCM (44.1 - 192 kHz)
+Bit Depth: Available only for PCM (16, 24, 32-float, 64-float)
+Bitrate: Available for AAC, Opus, and MP3 (12 kbps minimum, encoder-specific maximum)`},
+			{Heading: "Compression Level", Text: `Available for FLAC and Opus (slider from 0-10)
+• 0 = no compression
+• 10 = most compression`},
+			{Key: "loudness-targets", Heading: "LOUDNESS TARGETS", Text: `Target in LUFS and TP limit in dB control loudness processing for both normalization and ReplayGain tagging.
+
+Custom Loudness: When enabled, you can configure custom LUFS I and TP targets. Values are automatically converted to negative. When disabled, the system uses the standard chosen in Menu > Normalization, EBU R128 (-23 LUFS, -1 dBTP) unless changed.`},
+			{Heading: "PROCESSING OPTIONS"},
+			{Heading: "Normalize", Text: `Applies loudness correction using the BS.1770-5 algorithm
+• Measures to an older revision when one is chosen in Menu > Normalization: BS.1770-2/-3 leaves channels beyond 5.1 out, BS.1770-1 doesn't gate, so pauses count. Reports, logs and tags record the revision of every value
+• Measures mono files as dual mono when chosen in Menu > Normalization, so mono clips aired on a stereo chain land on target instead of about 3 LU quiet. The offset gain there only applies when loudnorm falls back to dynamic mode
+• Uses custom values if Custom Loudness is enabled
+• Uses the standard chosen in Menu > Normalization if Custom Loudness is disabled
+• Alters the audio data to match target loudness`},
+			{Key: "write-tags", Heading: "Write RG tags", Text: `Writes ReplayGain metadata to audio files
+• Uses custom values if Custom Loudness is enabled, otherwise the standard chosen in Menu > Normalization
+• Does not alter audio data, only writes metadata
+• Cannot be used with Normalize (mutually exclusive)
+• Cannot be used with PCM source files`},
+			{Key: "loudness-scan", Heading: "Scan loudness", Text: `Measures the queued files before processing and colors each row against the current target
+• Green: within ±1 LU of the target with the true peak under the limit
+• Yellow: within ±3 LU
+• Red: further off, or the true peak over the limit
+• Green files don't need their audio changed, Write RG tags with Do not transcode is enough for them
+• The colors follow the target when it's changed, files already scanned aren't measured again`},
+			{Key: "album-gain", Heading: "Treat selection as album", Text: `Writes ReplayGain album tags next to the track tags
+• All queued files, or the whole selected folder, are measured together as one album before tagging
+• Every file gets the same REPLAYGAIN_ALBUM_GAIN and REPLAYGAIN_ALBUM_PEAK, so players in album mode keep the level differences between tracks
+• The album gain is held to the same clipping rules and gain cap as the track gains
+• Only shown when Write RG tags is enabled`},
+			{Key: "strip-metadata", Heading: "Strip metadata", Text: `Leaves the source's tags and artwork out of the output
+• By default title, artist, album and the other tags of the source are copied over, and so is embedded cover art where the output format can hold it (MP3, AAC and FLAC)
+• With this enabled the output only gets the tags TNT writes itself: ReplayGain, station metadata, mapped tags and the air date
+• Opus and WAV outputs can't carry artwork in either case`},
+			{Heading: "Do not transcode", Text: `Preserves original audio encoding while writing tags
+• Only available when Write RG tags is enabled
+• Does not alter audio data, only writes metadata
+• Cannot be used with PCM source files
+• Useful for adding metadata without re-encoding
+• Every output is checked against its source: the audio packets have to be bit-identical, or the file is reported as failed
+• Checking this box disables processing
+• Files whose peak would go over the TP limit once the gain is applied are logged, tagged TNT_CLIP_WARNING and flagged in the batch report, or with "Lower the tagged gain of files that would clip" get a gain that fits`},
+			{Heading: "Optimize Opus for speech", Text: `Optimizes Opus encoding for voice content
+• Only shown when Opus is selected
+• Applies VoIP-optimized compression settings
+• For speech normalization with any format, use Speech normalization in the Processing tab
+• Do not use with music content`},
+		},
+	},
+	{
+		Tab: "Processing",
+		Sections: []helpSection{
+			{Text: "Setting 'Do not transcode' in the Advanced tab bypasses all processing."},
+			{Heading: "Gain trim", Text: "Each file in the queue has a ±dB field. The trim is applied before any analysis, so EQ and dynamics processing see the trimmed audio. When normalizing, the loudness target of that file moves by the same amount, so the bias survives normalization. Use it to keep a music bed deliberately quieter, for example. Trims up to ±24 dB are accepted."},
+			{Heading: "Trim", Text: "The scissors button of a file opens its waveform. Drag the in and out markers to cut a false start or a long tail, and only the part between them is processed. Anchors are still typed against the untrimmed file."},
+			{Heading: "Anchor", Text: `Dialogue-led material is judged by its voice, not by its average. Type the anchor region, such as the voice-over, into a file's anchor field as start-end, e.g. 0:12-1:05 or 00:00:12-00:01:05.5, and normalization brings that region to the target; the rest of the file gets the same gain. With "Normalize to an anchor marker" enabled in Preferences, WAV files carry their own anchor: a cue region labelled "anchor", or two markers labelled "anchor in" and "anchor out". A typed anchor wins over a marker. Anchors must be at least 3 seconds long.`},
+			{Key: "dynamics", Heading: "Dynamics processing", Text: `Dynamics processing controls how TNT manages the volume variations in your audio. The software analyzes peak levels, average energy, and dynamic range before applying any processing. While designed for spoken content, dynamic processing may deliver pleasing results when used on music content. The first two presets are usually relatively transparent, with the last "Broadcast" preset being an aggressive multi-band compressor.
+
+TNT uses a Dynamic Scoring system in determining the characteristics of each compression preset. The user shall choose the style of compression, while the program decides what exact values are used within each preset. The amount of compression will always increase when choosing a higher processing tier.
+
+Off No dynamics processing is applied. Use this when your audio is already properly compressed or when you need the original dynamics preserved.
+
+Light Gentle compression that reduces only the loudest peaks. The software identifies peak RMS levels and applies subtle compression with a 2.5:1 ratio. Attack and release times are set to preserve transients while smoothing out occasional loud moments. This preset maintains the natural character of your audio while preventing clipping.
+
+Light processing is appropriate for: well-recorded content that needs minimal adjustment, acoustic music where dynamics are intentional, and content where natural dynamics should be preserved.
+
+Using Light on a music track, whose Dynamic Score is 18.64, results in a new Dynamic Score of 17.33.
+
+Moderate Standard broadcast compression suitable for most content. TNT analyzes your audio's average RMS level and applies moderate compression with a 3.5:1 ratio. The software calculates makeup gain automatically based on how much compression is being applied, ensuring consistent output levels without manual adjustment.
+
+Moderate processing works for: podcasts, voice-overs, most music content, and general broadcast material that needs to sound consistent across different playback systems.
+
+For the same example track as in Light preset above, the new Dynamic Score is 14.58.
+
+Broadcast Aggressive multiband processing for maximum clarity and consistency. Instead of analyzing overall dynamics, the software splits your audio into five frequency bands (sub-bass, bass, low-mid, mid, and high) and analyzes each band independently. Each band receives compression tailored to its specific characteristics—bass frequencies get tighter control with longer attack times, while high frequencies receive faster compression to maintain clarity.
+
+The Broadcast preset uses adaptive ratios: bass content receives moderate compression (4.0:1 ratio) while high-frequency content gets more aggressive processing (up to 8.0:1 ratio). This ensures your audio maintains punch in the low end while achieving maximum intelligibility in the speech range. Attack and release times are frequency-dependent, ranging from 200ms in the bass to 100ms in the highs.
+
+Broadcast processing is designed for: radio content, streaming platforms with varied playback systems, content consumed on small speakers or mobile devices, and any situation where maximum loudness and clarity are required.
+
+Broadcasting preset will deliver varying results with music. For heavily compressed material (DS <9), distortion is likely to occur.
+
+For the same track as in two previous presets, the new Dynamic Score is 12.71`},
+			{Key: "eq", Heading: "EQ target curves", Text: `EQ processing analyzes your audio's frequency response across ten octave-spaced bands from 50Hz to 12.8kHz+. The software measures RMS level, peak level, and crest factor for each band, then compares these measurements against professional target curves. All EQ adjustments use an attenuation-focused philosophy—corrections are calculated, then halved before application, with a maximum adjustment of ±10 dB. This conservative approach maintains audio quality while achieving broadcast standards. Equalization is designed to work with spoken content. It will delivery varying results when used with music.
+
+Off
+No equalization is applied.
+
+Flat
+Targets a pink noise curve, which naturally contains more energy in the bass frequencies (-3 dB per octave rise from 1kHz). The Flat preset attenuates frequencies that exceed this curve while leaving frequencies below the curve unchanged. This prevents excessive energy buildup in any frequency range while maintaining the natural tonal balance of your content.
+
+Flat EQ is appropriate for material that's already well-balanced, and situations where you want to prevent frequency buildup without imposing a specific tonal character.
+
+Speech
+Optimized for vocal clarity and intelligibility. The Speech preset boosts presence frequencies (1.6kHz-6.4kHz) where consonants and speech intelligibility live, while attenuating sub-bass rumble and reducing boxiness in the 200-400Hz range.
+
+Speech EQ is designed for: podcasts, voice-overs, audiobooks, conference recordings, interviews, and any content where vocal clarity is paramount.
+
+Broadcast
+Aggressive clarity enhancement for playback on small speakers, mobile devices, and varied listening environments. The Broadcast curve emphasizes midrange intelligibility even more strongly than Speech mode, with deeper cuts in the bass and more aggressive presence boost. This ensures your content cuts through on phone speakers, laptop audio, and car radios.
+
+Broadcast EQ is intended for: radio content, streaming platforms, mobile-first content, situations where playback systems are unknown, and any content that must remain intelligible on poor speakers.`},
+			{Key: "dynamic-normalization", Heading: "Dynamic normalization", Text: "Evens out the level over the course of a file with FFmpeg's dynaudnorm, using a frame length and gain limits chosen from the file's own dynamics. It runs after EQ and before compression, and loudness is measured on its result."},
+			{Key: "speechnorm", Heading: "Speech normalization", Text: "Raises quiet passages of speech towards the level of the loud ones, so a guest far from the microphone is heard as well as the host. Light, Medium and Strong set how far quiet passages are raised. It runs after compression and before loudness is measured, with any output format."},
+			{Key: "restoration", Heading: "Restoration", Text: "Meant for digitized archive tapes and records. TNT first measures the file, then runs only the repairs it calls for: click removal when sharp spikes stand out in the top octaves, clip repair when the peaks are flattened, and noise reduction when hiss sits above -60 dBFS. Settings are conservative, so clean material passes through unchanged. Restoration runs before any other processing and is slow on long files."},
+			{Key: "digitization", Heading: "Digitized carriers", Text: "For transfers of vinyl, cassette and other legacy carriers made through consumer interfaces. The rumble filter removes everything below 25 Hz, where turntable rumble, warped records and tape transports put energy no programme has. Azimuth and balance correction measures how far one channel lags the other in the top octaves, the mark of a tape head or stylus that isn't square, and delays the leading channel to meet it. It also evens out a level difference of up to 3 dB between the channels. Wide stereo recordings whose channels don't correlate are left alone. The Digitization Fast preset turns on Restoration and the rumble filter, leaves EQ and dynamics off, and normalizes to -23 LUFS with 3 dB of true peak headroom into a 96 kHz 24-bit PCM master. Azimuth and balance correction follows its checkbox here."},
+			{Key: "bypass", Heading: "Bypass all processing", Text: "When enabled, this checkbox disables Restoration, the rumble filter, azimuth and balance correction, Dynamics, EQ and Speech normalization regardless of their selected settings. Use this when you want loudness normalization only, without any dynamics control or tonal shaping. The Bypass option is useful for: testing how your audio sounds with normalization alone, A/B comparing processed versus unprocessed versions, or situations where you've already applied processing in your DAW and only need format conversion and loudness compliance."},
+			{Key: "chain", Heading: "Processing order", Text: `When multiple processing stages are enabled, TNT applies them in this order:
+
+Restoration (if enabled)
+Rumble filter, azimuth and balance correction (if enabled)
+EQ adjustments (if enabled)
+De-esser (automatically applied when EQ is active)
+Dynamic normalization
+Dynamics processing (if enabled)
+Speech normalization (if enabled)
+Loudness normalization (if enabled)
+The bottom of the Processing tab draws this chain for your current settings, so you can see what a file will go through before processing it. Stages shown in grey only run when a file needs them. If the chain already contains processing your material has been through, such as compression applied in the DAW, turn that stage off rather than processing twice.
+
+This signal chain ensures frequency balance is corrected before dynamics processing, preventing the compressor from reacting to frequency imbalances. The de-esser removes harsh sibilance after EQ boosts but before compression, ensuring the compressor doesn't overreact to "s" sounds. Loudness normalization happens last, after all processing is complete, guaranteeing your target LUFS level is achieved accurately.`},
+			{Heading: "Notes", Text: `Processing stages run in floating point in one FFmpeg filter chain, or at 192kHz 64-bit when rendered to temp files, so nothing clips between stages. For 16-bit PCM output, the software applies triangular dithering after all processing to minimize quantization artifacts. Multiband processing uses linear-phase crossover filters to prevent phase distortion between frequency bands.
+
+The adaptive nature of TNT's processing means two identical preset selections may produce different filter parameters depending on the input audio's characteristics. This is intentional — the software adjusts its processing based on what it measures, ensuring optimal results for each file rather than applying static presets that may not suit the content.`},
+		},
+	},
+	{
+		Tab: "Watcher",
+		Sections: []helpSection{
+			{Text: `Watch mode automates repetitive processing tasks by monitoring a folder and automatically processing new files as they appear. For example, a newsdesk can configure TNT to watch their raw audio folder - whenever a reporter records new audio, TNT detects it within seconds and outputs the processed file to the specified destination. TNT must remain running (the window can be minimized or hidden).
+
+Watch mode uses your current UI settings. To change processing parameters, simply adjust the settings in the interface - all subsequent files will use the new configuration. Save your preferences to automatically restore your settings on startup.
+
+Watch mode only processes new files added after activation - it ignores existing files. To process a folder's current contents, select it via "Select Folder" first. Once complete, enable Watch mode to handle any newly added files.
+
+More folders can be watched at the same time from Menu > Watch folders, each with its own saved preset and output folder. With "Watch subfolders too" in Menu > Watch mode, files dropped into subfolders are processed as well and land in the same subfolders of the output folder.`},
+		},
+	},
+	{
+		Tab: "Audio formats",
+		Sections: []helpSection{
+			{Heading: "AUDIO FORMATS"},
+			{Heading: "AAC (Advanced Audio Coding)", Text: fmt.Sprintf(`AAC is a data compression method that at high bitrates can sound similar to a non-compressed file. In simple mode (%s), the bitrate is set to 256 kbit/s, which gives very good results. The maximum bitrate for this encoder is 512 kbit/s. At 320 kbit/s the encoder tends to lose almost all of its encoding artifacts. Thirty seconds of audio encoded with 256 kbit/s results in approximately 1 MB filesize.
+
+Two AAC encoders are available depending on platform:
+• Fraunhofer FDK-AAC (all platforms) - Industry-standard reference encoder
+• Apple AudioToolbox AAC (macOS only) - Native hardware-accelerated encoder optimized for Apple Silicon`, builtinFastPresets[0])},
+			{Heading: "Opus", Text: "Opus is a modern data compression method that can achieve very good results even with lower bitrates. Opus has a lower algorithmic delay, which makes it suitable for live applications. It's an open-source format. Its minimum bitrate is 6 kbit/s, though the UI limits the bitrate at 12 kbit/s at minimum. The maximum bitrate for this encoder is 510 kbit/s. Opus only encodes at 48, 24, 16, 12 or 8 kHz, TNT writes it at 48 kHz and converts other rates at the quality set in Menu > Functions > Resampling."},
+			{Heading: "MP3 (MPEG-I Layer 3)", Text: fmt.Sprintf("MP3 is an older, but one of the most compatible encoders available. It isn't as capable at lower bitrates as the two encoders above, but at its maximum of 320 kbit/s it's usable. Simple mode (%s) uses 320 kbit/s. Use this if you know the end-user can't decode AAC or Opus. Filesize for MP3 at 320 kbit/s for 30 second audio file is 1.2 MB.", builtinFastPresets[1])},
+			{Heading: "FLAC (Free Lossless Audio Codec)", Text: "FLAC is a lossless compression format that reduces file size without any quality loss. Unlike AAC, Opus, or MP3, FLAC preserves the original audio data perfectly while still achieving significant compression. File sizes are typically 40-60% of uncompressed PCM, depending on the compression level selected. FLAC is widely supported and ideal for archival or when perfect audio fidelity is required with reasonable file sizes."},
+			{Heading: "PCM (WAV)", Text: `PCM, or WAV in this tool is a pulse-code modulated, raw uncompressed audio stream. It's the highest quality, but it comes with a size-cost. This encoder doesn't have a bitrate setting, but has two other settings that result in a bitrate. First, sample rate (either 44.1, 48, 88.2, 96, 192 kHz) means "how often the original data is converted into audio in a second". With 48 kHz the audio is sampled forty-eight thousand times in a second. Second, the bit depth controls "how precisely we want to have each sample". The options are either 16, 24, 32 or 64, of which the last two are floating-point and used in specific scenarios. The file size for a thirty-second audio with 48 kHz, 24-bit audio is 8.64 MB.`},
+		},
+	},
+}
+
+// text joins a topic's sections for its Help window tab
+func (t helpTopic) text() string {
+	parts := make([]string, 0, len(t.Sections))
+	for _, s := range t.Sections {
+		parts = append(parts, strings.TrimSpace(s.Heading+"\n"+s.Text))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// findHelpSection returns the section with the given key
+func findHelpSection(key string) (helpSection, bool) {
+	for _, topic := range helpTopics {
+		for _, s := range topic.Sections {
+			if s.Key == key {
+				return s, true
+			}
+		}
+	}
+	return helpSection{}, false
+}
+
+// showHelp opens the Help window with a tab per topic
+func (n *AudioNormalizer) showHelp() {
+	tabs := container.NewAppTabs()
+	for _, topic := range helpTopics {
+		label := widget.NewLabel(topic.text())
+		label.Wrapping = fyne.TextWrapWord
+		tabs.Append(container.NewTabItem(topic.Tab, container.NewScroll(label)))
+	}
+	tabs.SetTabLocation(container.TabLocationTop)
+
+	helpWindow := fyne.CurrentApp().NewWindow("Help")
+	helpWindow.SetContent(tabs)
+	helpWindow.Resize(fyne.NewSize(600, 400))
+	helpWindow.Show()
+}
+
+// hintButton returns a small button showing the help section with the given
+// key, for placing next to the control it describes
+func (n *AudioNormalizer) hintButton(key string) *widget.Button {
+	section, ok := findHelpSection(key)
+	btn := widget.NewButtonWithIcon("", theme.QuestionIcon(), func() {
+		label := widget.NewLabel(section.Text)
+		label.Wrapping = fyne.TextWrapWord
+		title := section.Heading
+		if title == "" {
+			title = "Help"
+		}
+		hint := dialog.NewCustom(title, "Close", container.NewVScroll(label), n.window)
+		hint.Resize(fyne.NewSize(460, 320))
+		hint.Show()
+	})
+	btn.Importance = widget.LowImportance
+	if !ok {
+		btn.Hide()
+	}
+	return btn
+}
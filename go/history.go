@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const qcReviewsName = "qc_reviews.json"
+
+// QC states of a processed file
+const (
+	qcUnreviewed = "Unreviewed"
+	qcApproved   = "Approved"
+	qcRejected   = "Rejected"
+)
+
+var qcStates = []string{qcUnreviewed, qcApproved, qcRejected}
+
+// qcReview is the sign-off and notes for one processing history row
+type qcReview struct {
+	State    string    `json:"state"`
+	Notes    string    `json:"notes,omitempty"`
+	Reviewer string    `json:"reviewer,omitempty"`
+	Reviewed time.Time `json:"reviewed"`
+}
+
+// qcReviewStore keeps reviews next to the processing history. The history
+// is an append-only CSV other tools read, so reviews are kept apart and
+// joined to its rows by historyKey.
+type qcReviewStore struct {
+	mutex   sync.Mutex
+	path    string
+	Reviews map[string]qcReview `json:"reviews"`
+}
+
+func loadQCReviews() *qcReviewStore {
+	s := &qcReviewStore{path: filepath.Join(dataDir(), qcReviewsName)}
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, s)
+	}
+	if s.Reviews == nil {
+		s.Reviews = make(map[string]qcReview)
+	}
+	return s
+}
+
+// get returns the review of a history row, unreviewed when there is none
+func (s *qcReviewStore) get(key string) qcReview {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r, ok := s.Reviews[key]; ok {
+		return r
+	}
+	return qcReview{State: qcUnreviewed}
+}
+
+// set stores the review of a history row
+func (s *qcReviewStore) set(key string, r qcReview) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Reviews[key] = r
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// historyKey identifies a processing history row
+func historyKey(r auditRecord) string {
+	return r.Time.Format(time.RFC3339) + "|" + r.Input
+}
+
+// readProcessingHistory reads the processing history, newest first
+func readProcessingHistory() ([]auditRecord, error) {
+	f, err := os.Open(filepath.Join(dataDir(), auditLogName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []auditRecord
+	for _, row := range rows {
+		if len(row) < len(auditHeader) || row[0] == auditHeader[0] {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			continue
+		}
+		elapsed, _ := strconv.ParseFloat(row[11], 64)
+		records = append(records, auditRecord{
+			Time: t, OSUser: row[1], Operator: row[2], Profile: row[3], Host: row[4],
+			Input: row[5], Output: row[6], Result: row[7], Format: row[8], TargetI: row[9],
+			Settings: row[10], Elapsed: elapsed,
+		})
+	}
+	slices.Reverse(records)
+	return records, nil
+}
+
+// buildHistoryTab lists the processing history with each file's QC state and
+// notes, for the review step after processing
+func (n *AudioNormalizer) buildHistoryTab() fyne.CanvasObject {
+	text := widget.NewLabel(`
+History
+Every processed file with its QC state. Select a file to approve or reject it and write notes for the next person. Reviews are kept in qc_reviews.json next to the processing history.
+	`)
+	text.Wrapping = fyne.TextWrapWord
+
+	var records, shown []auditRecord
+
+	stateFilter := widget.NewSelect(append([]string{"All"}, qcStates...), nil)
+	stateFilter.SetSelected("All")
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Filter by file name")
+
+	stateGroup := widget.NewRadioGroup(qcStates, nil)
+	stateGroup.Horizontal = true
+	notes := widget.NewMultiLineEntry()
+	notes.SetPlaceHolder("Notes")
+	notes.SetMinRowsVisible(3)
+	reviewedLabel := widget.NewLabel("")
+	saveBtn := widget.NewButton("Save review", nil)
+	detail := container.NewVBox(stateGroup, notes, reviewedLabel, saveBtn)
+	detail.Hide()
+
+	var list *widget.List
+	applyFilter := func() {
+		query := strings.ToLower(strings.TrimSpace(search.Text))
+		shown = shown[:0]
+		for _, r := range records {
+			if stateFilter.Selected != "All" && n.qcReviews.get(historyKey(r)).State != stateFilter.Selected {
+				continue
+			}
+			if query != "" && !strings.Contains(strings.ToLower(filepath.Base(r.Input)), query) {
+				continue
+			}
+			shown = append(shown, r)
+		}
+		list.UnselectAll()
+		detail.Hide()
+		list.Refresh()
+	}
+	reload := func() {
+		var err error
+		if records, err = readProcessingHistory(); err != nil && !os.IsNotExist(err) {
+			dialog.ShowError(err, n.menuWindow)
+		}
+		applyFilter()
+	}
+
+	list = widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			r := shown[i]
+			o.(*widget.Label).SetText(fmt.Sprintf("%s  %s  %s  [%s]",
+				r.Time.Local().Format("2006-01-02 15:04"), filepath.Base(r.Input), r.Result, n.qcReviews.get(historyKey(r)).State))
+		},
+	)
+	list.OnSelected = func(i widget.ListItemID) {
+		r := shown[i]
+		review := n.qcReviews.get(historyKey(r))
+		stateGroup.SetSelected(review.State)
+		notes.SetText(review.Notes)
+		reviewedLabel.SetText("")
+		if !review.Reviewed.IsZero() {
+			reviewedLabel.SetText(fmt.Sprintf("Reviewed %s by %s", review.Reviewed.Local().Format("2006-01-02 15:04"), review.Reviewer))
+		}
+		saveBtn.OnTapped = func() {
+			n.mutex.Lock()
+			reviewer := n.operator
+			n.mutex.Unlock()
+			if reviewer == "" {
+				reviewer = osUserName()
+			}
+
+			review := qcReview{State: stateGroup.Selected, Notes: strings.TrimSpace(notes.Text), Reviewer: reviewer, Reviewed: time.Now()}
+			if err := n.qcReviews.set(historyKey(r), review); err != nil {
+				dialog.ShowError(err, n.menuWindow)
+				return
+			}
+			n.logToFile(n.logFile, fmt.Sprintf("QC %s by %s: %s", strings.ToLower(review.State), reviewer, r.Input))
+			applyFilter()
+		}
+		detail.Show()
+	}
+
+	stateFilter.OnChanged = func(string) { applyFilter() }
+	search.OnChanged = func(string) { applyFilter() }
+	refreshBtn := widget.NewButton("Refresh", reload)
+	reload()
+
+	return container.NewBorder(
+		container.NewVBox(
+			text,
+			container.NewBorder(nil, nil, stateFilter, refreshBtn, search),
+		),
+		detail,
+		nil, nil,
+		list,
+	)
+}
@@ -0,0 +1,49 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// AlbumLoudness is the loudness of a group of files taken as one programme
+type AlbumLoudness struct {
+	Integrated float64 // LUFS, gated over the blocks of every file together
+	Peak       float64 // dBTP, the highest true peak of any file
+}
+
+// MeasureAlbum measures a group of files as if played back to back, the way
+// ReplayGain 2.0 measures an album: the gating blocks of all files are pooled
+// before gating, so quiet tracks count less than in an average of track
+// loudness. watchdog gives the limits for each file and onFile is called as
+// each file is done.
+func MeasureAlbum(paths []string, watchdog func(path string) ffmpeg.Watchdog, onFile func(path string)) (AlbumLoudness, error) {
+	var acc LoudnessAccumulator
+	album := AlbumLoudness{Peak: math.Inf(-1)}
+
+	for _, path := range paths {
+		output, err := watchdog(path).Stream(func(line string) {
+			acc.AddFrameLine(line)
+		}, "-i", path, "-af", "ebur128=framelog=info:peak=true", "-f", "null", "-")
+		if err != nil {
+			return AlbumLoudness{}, fmt.Errorf("%s: %w: %s", path, err, ffmpeg.Tail(output, 3))
+		}
+
+		summary, err := ParseEbur128Summary(string(output))
+		if err != nil || !summary.HasPeak {
+			return AlbumLoudness{}, fmt.Errorf("%s: %w", path, ErrNoTruePeak)
+		}
+		album.Peak = max(album.Peak, summary.TruePeak)
+		if onFile != nil {
+			onFile(path)
+		}
+	}
+
+	integrated, _, err := acc.Integrated()
+	if err != nil {
+		return AlbumLoudness{}, err
+	}
+	album.Integrated = integrated
+	return album, nil
+}
@@ -0,0 +1,70 @@
+package audio
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// AnalysisBranch is one measurement of a combined analysis run: astats
+// behind an optional filter, such as a band of a multiband analysis
+type AnalysisBranch struct {
+	Name   string
+	Filter string
+}
+
+// Analyze decodes a file once and measures every branch on its own copy of
+// the audio, instead of decoding the file again for each. chain, when set,
+// runs on the audio before it is split, such as processing stages that
+// haven't been rendered. The reports are keyed by branch name.
+func Analyze(inputPath, chain string, branches []AnalysisBranch) (map[string]AstatsReport, error) {
+	if len(branches) == 0 {
+		return map[string]AstatsReport{}, nil
+	}
+
+	// asplit needs two outputs at least, anullsink takes the spare one
+	splits := max(len(branches), 2)
+	var graph strings.Builder
+	graph.WriteString("[0:a]")
+	if chain != "" {
+		graph.WriteString(chain + ",")
+	}
+	fmt.Fprintf(&graph, "asplit=%d", splits)
+	for i := range splits {
+		fmt.Fprintf(&graph, "[s%d]", i)
+	}
+	if len(branches) == 1 {
+		graph.WriteString(";[s1]anullsink")
+	}
+
+	var maps []string
+	for i, b := range branches {
+		fmt.Fprintf(&graph, ";[s%d]", i)
+		if b.Filter != "" {
+			graph.WriteString(b.Filter + ",")
+		}
+		fmt.Fprintf(&graph, "astats@b%d[o%d]", i, i)
+		maps = append(maps, "-map", fmt.Sprintf("[o%d]", i))
+	}
+
+	args := []string{"-hide_banner", "-i", inputPath, "-filter_complex", graph.String()}
+	args = append(args, maps...)
+	args = append(args, "-f", "null", "-")
+
+	output, err := ffmpeg.Run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, ffmpeg.Tail(output, 3))
+	}
+
+	instances, _ := parseAstatsInstances(string(output))
+	reports := make(map[string]AstatsReport, len(branches))
+	for i, b := range branches {
+		report, ok := instances[fmt.Sprintf("astats@b%d", i)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoAstats, b.Name)
+		}
+		reports[b.Name] = report
+	}
+	return reports, nil
+}
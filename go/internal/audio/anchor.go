@@ -0,0 +1,280 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// MinAnchorSeconds is the shortest anchor that gives a usable integrated loudness
+const MinAnchorSeconds = 3.0
+
+var (
+	ErrAnchorOrder = errors.New("anchor end must be after its start")
+	ErrAnchorShort = fmt.Errorf("anchor must be at least %.0f seconds long", MinAnchorSeconds)
+	ErrNoAnchor    = errors.New("no anchor marker found")
+)
+
+// AnchorRegion is the part of a file whose loudness is normalized to target,
+// such as the voice-over of a promo, in seconds from the start
+type AnchorRegion struct {
+	Start float64
+	End   float64
+}
+
+func (r AnchorRegion) String() string {
+	return FormatTimecode(r.Start) + "-" + FormatTimecode(r.End)
+}
+
+// ParseAnchor reads a region like "0:12-1:05.5", "00:00:12-00:01:05" or "12-65"
+func ParseAnchor(text string) (AnchorRegion, error) {
+	startText, endText, ok := strings.Cut(strings.TrimSpace(text), "-")
+	if !ok {
+		return AnchorRegion{}, fmt.Errorf("anchor %q must be written as start-end", text)
+	}
+
+	start, err := ParseTimecode(startText)
+	if err != nil {
+		return AnchorRegion{}, err
+	}
+	end, err := ParseTimecode(endText)
+	if err != nil {
+		return AnchorRegion{}, err
+	}
+
+	region := AnchorRegion{Start: start, End: end}
+	return region, region.validate()
+}
+
+func (r AnchorRegion) validate() error {
+	if r.End <= r.Start {
+		return ErrAnchorOrder
+	}
+	if r.End-r.Start < MinAnchorSeconds {
+		return ErrAnchorShort
+	}
+	return nil
+}
+
+// ParseTimecode reads seconds, mm:ss or hh:mm:ss, each with optional decimals
+func ParseTimecode(text string) (float64, error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, ",", "."))
+	parts := strings.Split(text, ":")
+	if text == "" || len(parts) > 3 {
+		return 0, fmt.Errorf("timecode %q is not seconds, mm:ss or hh:mm:ss", text)
+	}
+
+	var seconds float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || value < 0 || (i > 0 && value >= 60) {
+			return 0, fmt.Errorf("timecode %q is not seconds, mm:ss or hh:mm:ss", text)
+		}
+		seconds = seconds*60 + value
+	}
+	return seconds, nil
+}
+
+// FormatTimecode writes seconds as m:ss.s, or h:mm:ss.s for an hour or more
+func FormatTimecode(seconds float64) string {
+	h := int(seconds) / 3600
+	m := int(seconds) / 60 % 60
+	s := seconds - float64(h*3600+m*60)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%04.1f", h, m, s)
+	}
+	return fmt.Sprintf("%d:%04.1f", m, s)
+}
+
+// MeasureAnchor returns the integrated loudness of the anchor region
+func MeasureAnchor(inputPath string, region AnchorRegion) (float64, error) {
+	output, err := ffmpeg.Run(
+		"-hide_banner",
+		"-ss", strconv.FormatFloat(region.Start, 'f', 3, 64),
+		"-t", strconv.FormatFloat(region.End-region.Start, 'f', 3, 64),
+		"-i", inputPath,
+		"-af", "ebur128=framelog=quiet",
+		"-f", "null",
+		"-",
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	summary, err := ParseEbur128Summary(string(output))
+	if err != nil {
+		return 0, err
+	}
+	return summary.Integrated, nil
+}
+
+// ReadAnchorMarker looks for an anchor in a WAV file's cue markers: either a
+// region labelled "anchor", or a pair of markers labelled "anchor in" and
+// "anchor out". Labels are matched without regard to case.
+func ReadAnchorMarker(path string) (AnchorRegion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return AnchorRegion{}, err
+	}
+	defer f.Close()
+
+	cues, err := readWavCues(f)
+	if err != nil {
+		return AnchorRegion{}, err
+	}
+
+	var in, out *wavCue
+	for i := range cues {
+		c := &cues[i]
+		switch strings.ToLower(strings.TrimSpace(c.label)) {
+		case "anchor":
+			if c.length > 0 {
+				region := AnchorRegion{Start: c.seconds(), End: float64(c.position+c.length) / float64(c.sampleRate)}
+				return region, region.validate()
+			}
+			in = c
+		case "anchor in", "anchor start":
+			in = c
+		case "anchor out", "anchor end":
+			out = c
+		}
+	}
+
+	if in == nil || out == nil {
+		return AnchorRegion{}, ErrNoAnchor
+	}
+	region := AnchorRegion{Start: in.seconds(), End: out.seconds()}
+	return region, region.validate()
+}
+
+// wavCue is one cue point with its label and, for regions, length in samples
+type wavCue struct {
+	id         uint32
+	position   uint32
+	length     uint32
+	label      string
+	sampleRate uint32
+}
+
+func (c wavCue) seconds() float64 {
+	return float64(c.position) / float64(c.sampleRate)
+}
+
+// readWavCues reads the cue chunk and the labels and region lengths of the
+// adtl list from a RIFF WAVE file
+func readWavCues(r io.ReadSeeker) ([]wavCue, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, ErrNoAnchor
+	}
+
+	var sampleRate uint32
+	var cues []wavCue
+	labels := make(map[uint32]string)
+	lengths := make(map[uint32]uint32)
+
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			break
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+		padded := int64(size) + int64(size&1)
+
+		switch id {
+		case "fmt ", "cue ", "LIST":
+			// Marker chunks are small; anything large isn't what we're after
+			if size > 1<<20 {
+				return nil, ErrNoAnchor
+			}
+			data := make([]byte, padded)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, err
+			}
+			data = data[:size]
+
+			switch id {
+			case "fmt ":
+				if len(data) >= 8 {
+					sampleRate = binary.LittleEndian.Uint32(data[4:8])
+				}
+			case "cue ":
+				cues = parseCueChunk(data)
+			case "LIST":
+				parseAdtlList(data, labels, lengths)
+			}
+		default:
+			if _, err := r.Seek(padded, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if sampleRate == 0 || len(cues) == 0 {
+		return nil, ErrNoAnchor
+	}
+	for i := range cues {
+		cues[i].label = labels[cues[i].id]
+		cues[i].length = lengths[cues[i].id]
+		cues[i].sampleRate = sampleRate
+	}
+	return cues, nil
+}
+
+// parseCueChunk reads the cue points: 24 bytes each, the sample offset last
+func parseCueChunk(data []byte) []wavCue {
+	if len(data) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(data[0:4]))
+
+	var cues []wavCue
+	for i := 0; i < count && 4+(i+1)*24 <= len(data); i++ {
+		point := data[4+i*24 : 4+(i+1)*24]
+		cues = append(cues, wavCue{
+			id:       binary.LittleEndian.Uint32(point[0:4]),
+			position: binary.LittleEndian.Uint32(point[20:24]),
+		})
+	}
+	return cues
+}
+
+// parseAdtlList reads labl and ltxt sub-chunks of an associated data list
+func parseAdtlList(data []byte, labels map[uint32]string, lengths map[uint32]uint32) {
+	if len(data) < 4 || string(data[0:4]) != "adtl" {
+		return
+	}
+	data = data[4:]
+
+	for len(data) >= 8 {
+		id := string(data[0:4])
+		size := int(binary.LittleEndian.Uint32(data[4:8]))
+		if 8+size > len(data) {
+			return
+		}
+		body := data[8 : 8+size]
+
+		switch {
+		case id == "labl" && size >= 4:
+			labels[binary.LittleEndian.Uint32(body[0:4])] = strings.TrimRight(string(body[4:]), "\x00")
+		case id == "ltxt" && size >= 8:
+			lengths[binary.LittleEndian.Uint32(body[0:4])] = binary.LittleEndian.Uint32(body[4:8])
+		}
+
+		next := 8 + size + size&1
+		if next > len(data) {
+			return
+		}
+		data = data[next:]
+	}
+}
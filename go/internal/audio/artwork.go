@@ -0,0 +1,20 @@
+package audio
+
+import (
+	"errors"
+)
+
+var ErrNoArtwork = errors.New("no embedded artwork in the input")
+
+// ArtworkStream returns the index of the input's embedded cover art. Real
+// video streams don't count, only pictures attached to the audio.
+func ArtworkStream(inputPath string) (int, error) {
+	media, err := Inspect(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	if media.Artwork < 0 {
+		return 0, ErrNoArtwork
+	}
+	return media.Artwork, nil
+}
@@ -0,0 +1,225 @@
+package audio
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bwfMaxChunk caps the bext and iXML chunks read, real ones are a few kilobytes
+const bwfMaxChunk = 1 << 20
+
+// bextFixedSize is the fixed part of a bext chunk before the coding history
+const bextFixedSize = 602
+
+// rf64SizeMarker stands in for chunk sizes that live in the ds64 chunk
+const rf64SizeMarker = 0xffffffff
+
+// speechHintRe matches the words recorders and editors use to mark speech
+var speechHintRe = regexp.MustCompile(`(?i)\b(speech|dialog(ue)?|voice[- ]?over|vo)\b`)
+
+// codingRateRe finds the sample rate of a coding history line, F=48000
+var codingRateRe = regexp.MustCompile(`(?:^|,)\s*F=(\d+)`)
+
+// BWFInfo is what the bext and iXML chunks of a broadcast WAV tell about it
+type BWFInfo struct {
+	Description     string
+	Originator      string
+	OriginationDate string
+	CodingHistory   []string
+
+	Project string
+	Scene   string
+	Take    string
+	Tape    string
+	Note    string
+
+	// SampleRate is the file's own rate. OriginalSampleRate is the rate it
+	// was first recorded at, from the coding history or iXML, 0 when unknown.
+	SampleRate         int
+	OriginalSampleRate int
+
+	// Speech is set when the description, note, track names or coding
+	// history mark the recording as speech
+	Speech bool
+	// Source names the chunks the information was found in, for the log
+	Source string
+}
+
+// Empty reports whether the file carried neither bext nor iXML
+func (b BWFInfo) Empty() bool {
+	return b.Source == ""
+}
+
+// Resampled reports whether the file is at a different rate than recorded
+func (b BWFInfo) Resampled() bool {
+	return b.OriginalSampleRate > 0 && b.SampleRate > 0 && b.OriginalSampleRate != b.SampleRate
+}
+
+// ixmlDoc holds the iXML fields TNT uses
+type ixmlDoc struct {
+	Project string `xml:"PROJECT"`
+	Scene   string `xml:"SCENE"`
+	Take    string `xml:"TAKE"`
+	Tape    string `xml:"TAPE"`
+	Note    string `xml:"NOTE"`
+	Speed   struct {
+		FileSampleRate      string `xml:"FILE_SAMPLE_RATE"`
+		DigitizerSampleRate string `xml:"DIGITIZER_SAMPLE_RATE"`
+	} `xml:"SPEED"`
+	Tracks []struct {
+		Name string `xml:"NAME"`
+	} `xml:"TRACK_LIST>TRACK"`
+}
+
+// ReadBWF reads the bext and iXML chunks of a WAV, RF64 or BW64 file. Files
+// of other formats and WAVs without either chunk give an empty BWFInfo.
+func ReadBWF(path string) (BWFInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return BWFInfo{}, err
+	}
+	defer f.Close()
+
+	var header [12]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return BWFInfo{}, nil
+	}
+	switch string(header[0:4]) {
+	case "RIFF", "RF64", "BW64":
+	default:
+		return BWFInfo{}, nil
+	}
+	if string(header[8:12]) != "WAVE" {
+		return BWFInfo{}, nil
+	}
+
+	var info BWFInfo
+	var sources []string
+	var dataSize uint64
+
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			break
+		}
+		id := string(chunk[0:4])
+		size := uint64(binary.LittleEndian.Uint32(chunk[4:8]))
+		if id == "data" && size == rf64SizeMarker {
+			if dataSize == 0 {
+				break
+			}
+			size = dataSize
+		}
+
+		switch id {
+		case "ds64", "fmt ", "bext", "iXML":
+			if size > bwfMaxChunk {
+				return info, errors.New(strings.TrimSpace(id) + " chunk too large")
+			}
+			body := make([]byte, size)
+			if _, err := io.ReadFull(f, body); err != nil {
+				return info, err
+			}
+			switch id {
+			case "ds64":
+				if len(body) >= 16 {
+					dataSize = binary.LittleEndian.Uint64(body[8:16])
+				}
+			case "fmt ":
+				if len(body) >= 8 {
+					info.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+				}
+			case "bext":
+				if parseBext(body, &info) {
+					sources = append(sources, "bext")
+				}
+			case "iXML":
+				if parseIXML(body, &info) {
+					sources = append(sources, "iXML")
+				}
+			}
+			if size%2 == 1 {
+				f.Seek(1, io.SeekCurrent)
+			}
+		default:
+			// Chunks are padded to an even size
+			if _, err := f.Seek(int64(size+size%2), io.SeekCurrent); err != nil {
+				return info, err
+			}
+		}
+	}
+
+	info.Source = strings.Join(sources, ", ")
+	return info, nil
+}
+
+// parseBext decodes the text fields and coding history of a bext chunk
+func parseBext(body []byte, info *BWFInfo) bool {
+	if len(body) < bextFixedSize {
+		return false
+	}
+	field := func(from, to int) string {
+		return strings.TrimSpace(strings.TrimRight(string(body[from:to]), "\x00"))
+	}
+
+	info.Description = field(0, 256)
+	info.Originator = field(256, 288)
+	info.OriginationDate = field(320, 330)
+
+	history := strings.TrimRight(string(body[bextFixedSize:]), "\x00")
+	for _, line := range strings.FieldsFunc(history, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		if line = strings.TrimSpace(line); line != "" {
+			info.CodingHistory = append(info.CodingHistory, line)
+		}
+	}
+
+	// The first line describes the original recording
+	if len(info.CodingHistory) > 0 {
+		if m := codingRateRe.FindStringSubmatch(info.CodingHistory[0]); m != nil {
+			info.OriginalSampleRate, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	if speechHintRe.MatchString(info.Description) || speechHintRe.MatchString(history) {
+		info.Speech = true
+	}
+	return true
+}
+
+// parseIXML decodes scene, take and the other production fields of an iXML chunk
+func parseIXML(body []byte, info *BWFInfo) bool {
+	var doc ixmlDoc
+	if err := xml.Unmarshal([]byte(strings.TrimRight(string(body), "\x00")), &doc); err != nil {
+		return false
+	}
+
+	info.Project = strings.TrimSpace(doc.Project)
+	info.Scene = strings.TrimSpace(doc.Scene)
+	info.Take = strings.TrimSpace(doc.Take)
+	info.Tape = strings.TrimSpace(doc.Tape)
+	info.Note = strings.TrimSpace(doc.Note)
+
+	// A digitizer rate other than the file rate means the file was converted
+	if info.OriginalSampleRate == 0 {
+		info.OriginalSampleRate, _ = strconv.Atoi(strings.TrimSpace(doc.Speed.DigitizerSampleRate))
+	}
+	if info.SampleRate == 0 {
+		info.SampleRate, _ = strconv.Atoi(strings.TrimSpace(doc.Speed.FileSampleRate))
+	}
+
+	if speechHintRe.MatchString(info.Note) {
+		info.Speech = true
+	}
+	for _, track := range doc.Tracks {
+		if speechHintRe.MatchString(track.Name) {
+			info.Speech = true
+		}
+	}
+	return true
+}
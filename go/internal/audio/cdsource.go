@@ -0,0 +1,303 @@
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DeemphasisFilter undoes the 50/15 µs pre-emphasis of the CD standard
+const DeemphasisFilter = "aemphasis=mode=reproduction:type=cd"
+
+// cdLeadOutTrack is the track number FLAC cue sheets use for the lead-out
+const cdLeadOutTrack = 170
+
+// CDInfo is what a CD rip can tell about its origin
+type CDInfo struct {
+	PreEmphasis bool
+	ISRC        string
+	Catalog     string
+	// Source names where the information was found, for the log
+	Source string
+}
+
+func (c CDInfo) empty() bool {
+	return !c.PreEmphasis && c.ISRC == "" && c.Catalog == ""
+}
+
+// merge fills what c lacks from other
+func (c CDInfo) merge(other CDInfo) CDInfo {
+	if other.empty() {
+		return c
+	}
+	if c.empty() {
+		return other
+	}
+	c.PreEmphasis = c.PreEmphasis || other.PreEmphasis
+	if c.ISRC == "" {
+		c.ISRC = other.ISRC
+	}
+	if c.Catalog == "" {
+		c.Catalog = other.Catalog
+	}
+	c.Source += ", " + other.Source
+	return c
+}
+
+// cdTrack is one track of a cue sheet
+type cdTrack struct {
+	number      int
+	audio       bool
+	preEmphasis bool
+	isrc        string
+}
+
+// ReadCDInfo looks for the pre-emphasis flag, ISRC and catalog number of a
+// rip in its tags, in an embedded FLAC cue sheet and in a .cue file next to it
+func ReadCDInfo(path string) (CDInfo, error) {
+	info, err := readCDTags(path)
+	if err != nil {
+		return CDInfo{}, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".flac") {
+		if sheet, err := readFlacCueSheet(path); err == nil {
+			info = info.merge(sheet)
+		}
+	}
+
+	if sheet, err := readCueFileFor(path); err == nil {
+		info = info.merge(sheet)
+	}
+	return info, nil
+}
+
+// readCDTags reads the flags ripping software writes as tags
+func readCDTags(path string) (CDInfo, error) {
+	media, err := Inspect(path)
+	if err != nil {
+		return CDInfo{}, err
+	}
+
+	// The first of the spellings a file has wins
+	tag := func(keys ...string) (string, bool) {
+		for _, key := range keys {
+			if value, ok := media.Tags[key]; ok {
+				return strings.TrimSpace(value), true
+			}
+		}
+		return "", false
+	}
+
+	info := CDInfo{Source: "tags"}
+	if value, ok := tag("PRE_EMPHASIS", "PRE EMPHASIS", "PREEMPHASIS", "EMPHASIS"); ok {
+		info.PreEmphasis = isTruthy(value)
+	}
+	info.ISRC, _ = tag("ISRC", "TSRC")
+	info.Catalog, _ = tag("CATALOGNUMBER", "CATALOG", "MCN", "UPC", "BARCODE")
+	return info, nil
+}
+
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "no", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+// readFlacCueSheet reads the CUESHEET metadata block some rippers embed
+func readFlacCueSheet(path string) (CDInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return CDInfo{}, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var marker [4]byte
+	if _, err := io.ReadFull(r, marker[:]); err != nil || string(marker[:]) != "fLaC" {
+		return CDInfo{}, errors.New("not a FLAC file")
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return CDInfo{}, err
+		}
+		last := header[0]&0x80 != 0
+		blockType := header[0] & 0x7f
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+
+		if blockType == 5 {
+			block := make([]byte, size)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return CDInfo{}, err
+			}
+			return parseFlacCueSheet(block)
+		}
+		if _, err := r.Discard(size); err != nil {
+			return CDInfo{}, err
+		}
+		if last {
+			return CDInfo{}, errors.New("no cue sheet")
+		}
+	}
+}
+
+// parseFlacCueSheet decodes a CUESHEET block: catalog number, then the tracks
+// with their ISRC and flags
+func parseFlacCueSheet(block []byte) (CDInfo, error) {
+	const headerSize = 128 + 8 + 259 + 1
+	if len(block) < headerSize {
+		return CDInfo{}, errors.New("cue sheet too short")
+	}
+
+	catalog := strings.TrimRight(string(block[0:128]), "\x00")
+	count := int(block[headerSize-1])
+
+	var tracks []cdTrack
+	pos := headerSize
+	for i := 0; i < count; i++ {
+		if pos+36 > len(block) {
+			return CDInfo{}, errors.New("cue sheet truncated")
+		}
+		t := block[pos : pos+36]
+		tracks = append(tracks, cdTrack{
+			number:      int(t[8]),
+			isrc:        strings.TrimRight(string(t[9:21]), "\x00"),
+			audio:       t[21]&0x80 == 0,
+			preEmphasis: t[21]&0x40 != 0,
+		})
+
+		indexCount := int(t[35])
+		pos += 36 + indexCount*12
+	}
+
+	return cdInfoFromTracks(catalog, tracks, "FLAC cue sheet"), nil
+}
+
+// cdInfoFromTracks sums up the tracks belonging to one file. Pre-emphasis
+// counts when every audio track has it; an ISRC only when there is one track.
+func cdInfoFromTracks(catalog string, tracks []cdTrack, source string) CDInfo {
+	info := CDInfo{Source: source}
+	if strings.Trim(catalog, "0 ") != "" {
+		info.Catalog = catalog
+	}
+
+	var audioTracks []cdTrack
+	for _, t := range tracks {
+		if t.audio && t.number != cdLeadOutTrack {
+			audioTracks = append(audioTracks, t)
+		}
+	}
+	if len(audioTracks) == 0 {
+		return info
+	}
+
+	info.PreEmphasis = true
+	for _, t := range audioTracks {
+		info.PreEmphasis = info.PreEmphasis && t.preEmphasis
+	}
+	if len(audioTracks) == 1 && strings.Trim(audioTracks[0].isrc, "0 ") != "" {
+		info.ISRC = audioTracks[0].isrc
+	}
+	return info
+}
+
+// readCueFileFor finds the .cue file describing path: one with the same
+// name, or any cue sheet in the folder that lists the file
+func readCueFileFor(path string) (CDInfo, error) {
+	base := filepath.Base(path)
+	sameName := strings.TrimSuffix(path, filepath.Ext(path)) + ".cue"
+
+	candidates := []string{sameName}
+	if others, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.cue")); err == nil {
+		candidates = append(candidates, others...)
+	}
+
+	for _, cue := range candidates {
+		data, err := os.ReadFile(cue)
+		if err != nil {
+			continue
+		}
+		if info, ok := parseCueFile(string(data), base, cue == sameName); ok {
+			return info, nil
+		}
+	}
+	return CDInfo{}, os.ErrNotExist
+}
+
+// parseCueFile reads the tracks under the FILE entry for fileName. A cue sheet
+// with the file's own name counts even when its FILE entry names the original image.
+func parseCueFile(text, fileName string, sameName bool) (CDInfo, bool) {
+	var catalog string
+	var tracks []cdTrack
+	inFile := false
+	matched := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "CATALOG":
+			if len(fields) > 1 {
+				catalog = fields[1]
+			}
+		case "FILE":
+			name := cueFileName(line)
+			inFile = strings.EqualFold(filepath.Base(name), fileName) ||
+				strings.EqualFold(strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)), strings.TrimSuffix(fileName, filepath.Ext(fileName))) ||
+				sameName
+			matched = matched || inFile
+		case "TRACK":
+			if inFile && len(fields) > 2 {
+				var number int
+				for _, c := range fields[1] {
+					if c >= '0' && c <= '9' {
+						number = number*10 + int(c-'0')
+					}
+				}
+				tracks = append(tracks, cdTrack{number: number, audio: strings.EqualFold(fields[2], "AUDIO")})
+			}
+		case "FLAGS":
+			if inFile && len(tracks) > 0 {
+				for _, flag := range fields[1:] {
+					if strings.EqualFold(flag, "PRE") {
+						tracks[len(tracks)-1].preEmphasis = true
+					}
+				}
+			}
+		case "ISRC":
+			if inFile && len(tracks) > 0 && len(fields) > 1 {
+				tracks[len(tracks)-1].isrc = fields[1]
+			}
+		}
+	}
+
+	if !matched {
+		return CDInfo{}, false
+	}
+	return cdInfoFromTracks(catalog, tracks, "cue file"), true
+}
+
+// cueFileName returns the quoted or bare file name of a FILE line
+func cueFileName(line string) string {
+	rest := strings.TrimSpace(line)[len("FILE"):]
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, `"`) {
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			return rest[1 : end+1]
+		}
+	}
+	if fields := strings.Fields(rest); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// ChannelIssue describes a stereo file whose channels don't carry real stereo
+type ChannelIssue int
+
+const (
+	ChannelsOK ChannelIssue = iota
+	ChannelsDualMono
+	ChannelsLeftOnly
+	ChannelsRightOnly
+)
+
+const (
+	// silentChannelLevel is the RMS level in dBFS below which a channel counts as empty
+	silentChannelLevel = -80.0
+
+	// dualMonoMargin is how far in dB the L-R difference must sit under the
+	// signal for the channels to count as identical. Lossy codecs leave a
+	// small residue even on true dual-mono material.
+	dualMonoMargin = 50.0
+)
+
+// Channel fixes offered for dual-mono and one-sided recordings
+const (
+	ChannelFixAsk  = "Ask"
+	ChannelFixMono = "Fold to mono"
+	ChannelFixCopy = "Copy to both channels"
+)
+
+// ChannelFixes lists the fix choices in display order
+var ChannelFixes = []string{ChannelFixAsk, ChannelFixMono, ChannelFixCopy}
+
+func (c ChannelIssue) String() string {
+	switch c {
+	case ChannelsDualMono:
+		return "dual-mono (identical channels)"
+	case ChannelsLeftOnly:
+		return "one-sided (right channel silent)"
+	case ChannelsRightOnly:
+		return "one-sided (left channel silent)"
+	default:
+		return "ok"
+	}
+}
+
+// DetectChannelIssue checks a two channel file for dual-mono or one-sided
+// content. Files with any other channel count are reported as ChannelsOK.
+func DetectChannelIssue(inputPath string) (ChannelIssue, error) {
+	output, err := ffmpeg.Run("-i", inputPath, "-af", "astats", "-f", "null", "-")
+	if err != nil {
+		return ChannelsOK, err
+	}
+	stats, err := ParseAstats(string(output))
+	if err != nil {
+		return ChannelsOK, err
+	}
+	if len(stats.Channels) != 2 {
+		return ChannelsOK, nil
+	}
+
+	left, err := stats.Channel(1).Float("RMS level dB")
+	if err != nil {
+		return ChannelsOK, err
+	}
+	right, err := stats.Channel(2).Float("RMS level dB")
+	if err != nil {
+		return ChannelsOK, err
+	}
+
+	switch {
+	case left < silentChannelLevel && right < silentChannelLevel:
+		return ChannelsOK, nil
+	case right < silentChannelLevel:
+		return ChannelsLeftOnly, nil
+	case left < silentChannelLevel:
+		return ChannelsRightOnly, nil
+	}
+
+	// Dual-mono cancels out when one channel is subtracted from the other
+	output, err = ffmpeg.Run("-i", inputPath, "-af", "pan=mono|c0=c0-c1,astats", "-f", "null", "-")
+	if err != nil {
+		return ChannelsOK, err
+	}
+	diff, err := channelRMS(string(output), 1)
+	if err != nil {
+		return ChannelsOK, err
+	}
+
+	if diff < math.Max(left, right)-dualMonoMargin {
+		return ChannelsDualMono, nil
+	}
+	return ChannelsOK, nil
+}
+
+// channelRMS reads one channel's RMS level from astats output
+func channelRMS(stats string, channel int) (float64, error) {
+	report, err := ParseAstats(stats)
+	if err != nil {
+		return 0, err
+	}
+	if channel < 1 || channel > len(report.Channels) {
+		return 0, fmt.Errorf("channel %d not found", channel)
+	}
+	return report.Channel(channel).Float("RMS level dB")
+}
+
+// ChannelFixFilter returns the pan filter that applies a fix to a detected
+// issue. Dual-mono always folds to mono, copying would change nothing.
+func ChannelFixFilter(issue ChannelIssue, fix string) string {
+	source := "c0"
+	if issue == ChannelsRightOnly {
+		source = "c1"
+	}
+
+	switch {
+	case issue == ChannelsOK:
+		return ""
+	case issue == ChannelsDualMono || fix == ChannelFixMono:
+		return "pan=mono|c0=" + source
+	default:
+		return fmt.Sprintf("pan=stereo|c0=%s|c1=%s", source, source)
+	}
+}
+
+// channelLayouts maps FFmpeg's channel layout names to their channel count
+var channelLayouts = map[string]int{
+	"mono":      1,
+	"stereo":    2,
+	"2.1":       3,
+	"3.0":       3,
+	"quad":      4,
+	"4.0":       4,
+	"5.0":       5,
+	"5.0(side)": 5,
+	"5.1":       6,
+	"5.1(side)": 6,
+	"6.1":       7,
+	"7.1":       8,
+}
+
+var ErrNoChannelInfo = errors.New("no channel layout in the input header")
+
+// ProbeChannels returns the channel count of the first audio stream, read
+// from the input header without decoding the file
+func ProbeChannels(inputPath string) (int, error) {
+	media, err := Inspect(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	if media.Channels > 0 {
+		return media.Channels, nil
+	}
+	// Some containers only name the layout
+	if channels, ok := channelLayouts[media.ChannelLayout]; ok {
+		return channels, nil
+	}
+	return 0, ErrNoChannelInfo
+}
@@ -0,0 +1,63 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// StatRange is the span a statistic can plausibly take on real audio
+type StatRange struct {
+	Min, Max float64
+}
+
+var (
+	// LevelRange covers levels in dBFS, float sources can go over full scale
+	LevelRange = StatRange{-150, 30}
+	// NoiseFloorRange allows -inf, astats reports it for files with digital silence
+	NoiseFloorRange = StatRange{math.Inf(-1), 0}
+	// CrestRange covers crest factors, peak over RMS as a ratio
+	CrestRange = StatRange{1, 1000}
+	// DynamicRangeRange covers astats' dynamic range in dB
+	DynamicRangeRange = StatRange{0, 400}
+)
+
+// Confidence flags values of a parsed analysis that can't be trusted: a
+// statistic missing from FFmpeg's output, unreadable, or outside what real
+// audio measures. Such a value reads as 0, and settings derived from it are
+// nonsense.
+type Confidence struct {
+	Problems []string
+}
+
+// Reliable reports whether every parsed value was found and plausible
+func (c Confidence) Reliable() bool {
+	return len(c.Problems) == 0
+}
+
+func (c Confidence) String() string {
+	return strings.Join(c.Problems, ", ")
+}
+
+// Stat reads a statistic from an astats section, recording a problem when
+// the label is missing or the value unreadable or out of r. Unreliable
+// values read as 0.
+func (c *Confidence) Stat(section StatSection, label string, r StatRange) float64 {
+	raw, ok := section[label]
+	if !ok {
+		c.Problems = append(c.Problems, label+" missing")
+		return 0
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) {
+		c.Problems = append(c.Problems, fmt.Sprintf("%s unreadable (%s)", label, raw))
+		return 0
+	}
+	if value < r.Min || value > r.Max {
+		c.Problems = append(c.Problems, fmt.Sprintf("%s implausible (%s)", label, raw))
+		return 0
+	}
+	return value
+}
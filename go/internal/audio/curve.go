@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"math"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// LoudnessCurve is the loudness of a file over time, measured to a BS.1770
+// revision: ebur128's summary and its frames, one every 100 ms
+type LoudnessCurve struct {
+	Summary      Ebur128Summary
+	Frames       []Ebur128Frame
+	MaxMomentary float64
+	MaxShortTerm float64
+	Standard     Standard
+}
+
+// MeasureCurve measures a file with ebur128's frame log, mono as dual mono
+// when dualMono is set. The integrated loudness of an ungated revision comes
+// from the frames.
+func MeasureCurve(path string, standard Standard, dualMono bool) (LoudnessCurve, error) {
+	curve := LoudnessCurve{
+		Standard:     standard,
+		MaxMomentary: math.Inf(-1),
+		MaxShortTerm: math.Inf(-1),
+	}
+	channels, _ := ProbeChannels(path)
+	output, err := ffmpeg.Run(
+		"-i", path,
+		"-vn",
+		"-af", standard.MeasureFilter(channels, true, dualMono),
+		"-f", "null",
+		"-",
+	)
+	if err != nil {
+		return curve, err
+	}
+
+	curve.Summary, err = ParseEbur128Summary(string(output))
+	if err != nil {
+		return curve, err
+	}
+
+	var acc LoudnessAccumulator
+	for _, line := range strings.Split(string(output), "\n") {
+		frame, ok := ParseEbur128Frame(line)
+		if !ok {
+			continue
+		}
+		acc.AddFrameLine(line)
+		curve.Frames = append(curve.Frames, frame)
+		curve.MaxMomentary = max(curve.MaxMomentary, frame.Momentary)
+		curve.MaxShortTerm = max(curve.MaxShortTerm, frame.ShortTerm)
+	}
+	if len(curve.Frames) == 0 {
+		return curve, ErrEmptyAudio
+	}
+
+	if !standard.Gated() {
+		if curve.Summary.Integrated, err = acc.Ungated(); err != nil {
+			return curve, err
+		}
+	}
+	return curve, nil
+}
+
+// Duration is the length of the measured audio in seconds
+func (c LoudnessCurve) Duration() float64 {
+	if len(c.Frames) == 0 {
+		return 0
+	}
+	return c.Frames[len(c.Frames)-1].Time
+}
+
+// Points reduces the frames to at most n, keeping the loudest momentary and
+// short-term value of each stretch so short peaks stay visible in a chart
+func (c LoudnessCurve) Points(n int) []Ebur128Frame {
+	if n <= 0 || len(c.Frames) <= n {
+		return c.Frames
+	}
+	points := make([]Ebur128Frame, 0, n)
+	for i := range n {
+		stretch := c.Frames[i*len(c.Frames)/n : (i+1)*len(c.Frames)/n]
+		point := Ebur128Frame{Time: stretch[0].Time, Momentary: math.Inf(-1), ShortTerm: math.Inf(-1)}
+		for _, frame := range stretch {
+			point.Momentary = max(point.Momentary, frame.Momentary)
+			point.ShortTerm = max(point.ShortTerm, frame.ShortTerm)
+		}
+		points = append(points, point)
+	}
+	return points
+}
@@ -0,0 +1,145 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// RumbleFilter cuts at 24 dB per octave below 25 Hz, where turntable
+// rumble, warp wow and cassette transport thump sit and no programme does
+const RumbleFilter = "highpass=f=25:p=2,highpass=f=25:p=2"
+
+const (
+	// Azimuth is measured on the top octaves, where a tilted head shows up
+	// as a time offset between the channels
+	alignmentSampleRate = 96000
+	alignmentSeconds    = 30
+	alignmentHighpassHz = 2000
+	// alignmentMaxLag is 1 ms, azimuth errors larger than that are not
+	// azimuth but a stereo recording with spaced microphones
+	alignmentMaxLag = alignmentSampleRate / 1000
+	// alignmentMinCorrelation keeps wide stereo material from being shifted
+	alignmentMinCorrelation = 0.5
+
+	// Imbalances under minBalanceDB are inaudible, over maxBalanceDB they
+	// are more likely the mix than the playback chain
+	minBalanceDB = 0.3
+	maxBalanceDB = 3.0
+)
+
+var ErrNotStereo = errors.New("channel alignment needs a stereo file")
+
+// ChannelAlignment is the azimuth and level error between the channels of
+// a digitized stereo carrier
+type ChannelAlignment struct {
+	// Delay is how many seconds the right channel lags the left, negative
+	// when it leads
+	Delay float64
+	// BalanceDB is how much louder the left channel is than the right
+	BalanceDB float64
+	// Correlation is how alike the channels are at the measured delay, 0 to 1
+	Correlation float64
+}
+
+// MeasureAlignment measures the delay between the channels from the top
+// octaves of the first half minute, and their balance over the whole file
+func MeasureAlignment(inputPath string) (ChannelAlignment, error) {
+	channels, err := ProbeChannels(inputPath)
+	if err != nil {
+		return ChannelAlignment{}, err
+	}
+	if channels != 2 {
+		return ChannelAlignment{}, ErrNotStereo
+	}
+
+	var a ChannelAlignment
+
+	output, err := ffmpeg.Run("-i", inputPath, "-af", "astats=measure_overall=none:measure_perchannel=RMS_level", "-f", "null", "-")
+	if err != nil {
+		return a, err
+	}
+	if left, err := channelRMS(string(output), 1); err == nil {
+		if right, err := channelRMS(string(output), 2); err == nil && !math.IsInf(left, 0) && !math.IsInf(right, 0) {
+			a.BalanceDB = left - right
+		}
+	}
+
+	highpass := fmt.Sprintf("highpass=f=%d:p=2", alignmentHighpassHz)
+	left, err := decodeMonoAt(inputPath, "pan=mono|c0=c0,"+highpass, alignmentSampleRate, alignmentSeconds)
+	if err != nil {
+		return a, err
+	}
+	right, err := decodeMonoAt(inputPath, "pan=mono|c0=c1,"+highpass, alignmentSampleRate, alignmentSeconds)
+	if err != nil {
+		return a, err
+	}
+
+	lag, correlation := channelLag(left, right)
+	a.Delay = float64(lag) / alignmentSampleRate
+	a.Correlation = correlation
+	return a, nil
+}
+
+// channelLag finds the shift of right against left with the highest
+// normalized correlation
+func channelLag(left, right []float64) (int, float64) {
+	n := min(len(left), len(right))
+	if n <= 2*alignmentMaxLag {
+		return 0, 0
+	}
+
+	var leftEnergy, rightEnergy float64
+	for i := alignmentMaxLag; i < n-alignmentMaxLag; i++ {
+		leftEnergy += left[i] * left[i]
+		rightEnergy += right[i] * right[i]
+	}
+	norm := math.Sqrt(leftEnergy * rightEnergy)
+	if norm == 0 {
+		return 0, 0
+	}
+
+	best, bestLag := math.Inf(-1), 0
+	for lag := -alignmentMaxLag; lag <= alignmentMaxLag; lag++ {
+		var sum float64
+		for i := alignmentMaxLag; i < n-alignmentMaxLag; i++ {
+			sum += left[i] * right[i+lag]
+		}
+		if sum > best {
+			best, bestLag = sum, lag
+		}
+	}
+	return bestLag, best / norm
+}
+
+// Filter returns the filters correcting the alignment, "" when nothing
+// needs or can safely be corrected. The lagging channel's partner is
+// delayed to meet it and the level difference is split between the two.
+func (a ChannelAlignment) Filter() string {
+	var filters []string
+
+	samples := math.Round(a.Delay * alignmentSampleRate)
+	if samples != 0 && a.Correlation >= alignmentMinCorrelation {
+		ms := math.Abs(a.Delay) * 1000
+		if a.Delay > 0 {
+			filters = append(filters, fmt.Sprintf("adelay=delays=%.4f|0", ms))
+		} else {
+			filters = append(filters, fmt.Sprintf("adelay=delays=0|%.4f", ms))
+		}
+	}
+
+	if balance := math.Abs(a.BalanceDB); balance >= minBalanceDB && balance <= maxBalanceDB {
+		left := math.Pow(10, -a.BalanceDB/40)
+		right := math.Pow(10, a.BalanceDB/40)
+		filters = append(filters, fmt.Sprintf("pan=stereo|c0=%.4f*c0|c1=%.4f*c1", left, right))
+	}
+
+	return strings.Join(filters, ",")
+}
+
+func (a ChannelAlignment) String() string {
+	return fmt.Sprintf("right channel %+.1f µs (correlation %.2f), left %+.1f dB", a.Delay*1e6, a.Correlation, a.BalanceDB)
+}
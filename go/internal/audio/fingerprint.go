@@ -0,0 +1,253 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// Fingerprints follow the Haitsma-Kalker scheme chromaprint grew out of:
+// every frame gives 32 bits, each telling whether the energy difference of
+// two neighbouring bands rose or fell since the previous frame. The bits
+// survive re-encoding, resampling and level changes.
+const (
+	fingerprintSampleRate = 5512
+
+	// fingerprintMaxSeconds is how much of the start of a file is fingerprinted
+	fingerprintMaxSeconds = 120
+
+	// Frames of 0.37 s overlapping by 31/32, so a copy cut at any sample
+	// still lines up with the original frames closely enough
+	fingerprintFrameSize = 2048
+	fingerprintHopSize   = 64
+	fingerprintLowHz     = 300.0
+	fingerprintHighHz    = 2000.0
+
+	// fingerprintIndexStride indexes every nth frame, enough for matches to
+	// find each other while keeping the index of a large library small
+	fingerprintIndexStride = 16
+
+	// fingerprintMinVotes is how many frames must agree on an offset before
+	// the pair is compared in full
+	fingerprintMinVotes = 3
+
+	// fingerprintCommonFrames skips sub-fingerprints found this often in the
+	// index, like near-silence, which say nothing about what is playing
+	fingerprintCommonFrames = 1000
+
+	// fingerprintMinOverlap is the fewest frames two files must share, about five seconds
+	fingerprintMinOverlap = 430
+
+	// FingerprintMatchBER is the bit error rate under which two fingerprints
+	// are taken to be the same audio
+	FingerprintMatchBER = 0.35
+)
+
+var ErrTooShortToFingerprint = errors.New("not enough audio to fingerprint")
+
+// Fingerprint is one 32-bit sub-fingerprint per frame of a file's opening minutes
+type Fingerprint []uint32
+
+// ComputeFingerprint decodes the start of a file and fingerprints it
+func ComputeFingerprint(path string) (Fingerprint, error) {
+	samples, err := decodeMonoAt(path, "", fingerprintSampleRate, fingerprintMaxSeconds)
+	if err != nil {
+		return nil, err
+	}
+	return fingerprintSamples(samples)
+}
+
+// fingerprintSamples computes the fingerprint of mono samples at fingerprintSampleRate
+func fingerprintSamples(samples []float64) (Fingerprint, error) {
+	frames := (len(samples)-fingerprintFrameSize)/fingerprintHopSize + 1
+	if frames < fingerprintMinOverlap {
+		return nil, ErrTooShortToFingerprint
+	}
+
+	window := make([]float64, fingerprintFrameSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(fingerprintFrameSize-1))
+	}
+
+	// 33 bands spaced evenly on a log scale give 32 differences
+	binHz := float64(fingerprintSampleRate) / fingerprintFrameSize
+	var edges [34]int
+	for b := range edges {
+		hz := fingerprintLowHz * math.Pow(fingerprintHighHz/fingerprintLowHz, float64(b)/33)
+		edges[b] = int(math.Round(hz / binHz))
+	}
+
+	frame := make([]complex128, fingerprintFrameSize)
+	var prev [32]float64
+	print := make(Fingerprint, 0, frames)
+
+	for f := 0; f < frames; f++ {
+		pos := f * fingerprintHopSize
+		for i := range frame {
+			frame[i] = complex(samples[pos+i]*window[i], 0)
+		}
+		fft(frame)
+
+		var energy [33]float64
+		for b := range energy {
+			for k := edges[b]; k < max(edges[b+1], edges[b]+1); k++ {
+				energy[b] += sqAbs(frame[k])
+			}
+		}
+
+		var diff [32]float64
+		var sub uint32
+		for b := range diff {
+			diff[b] = energy[b] - energy[b+1]
+			if f > 0 && diff[b]-prev[b] > 0 {
+				sub |= 1 << b
+			}
+		}
+		prev = diff
+		if f > 0 {
+			print = append(print, sub)
+		}
+	}
+	return print, nil
+}
+
+// Bytes encodes the fingerprint for storage
+func (fp Fingerprint) Bytes() []byte {
+	data := make([]byte, 4*len(fp))
+	for i, sub := range fp {
+		binary.LittleEndian.PutUint32(data[4*i:], sub)
+	}
+	return data
+}
+
+// FingerprintFromBytes decodes a fingerprint stored with Bytes
+func FingerprintFromBytes(data []byte) Fingerprint {
+	fp := make(Fingerprint, len(data)/4)
+	for i := range fp {
+		fp[i] = binary.LittleEndian.Uint32(data[4*i:])
+	}
+	return fp
+}
+
+// compareAt returns the bit error rate of b shifted by offset frames against
+// a, and how many frames overlap
+func compareAt(a, b Fingerprint, offset int) (float64, int) {
+	start, end := max(0, -offset), min(len(a), len(b)-offset)
+	if end-start <= 0 {
+		return 1, 0
+	}
+	var errs int
+	for i := start; i < end; i++ {
+		errs += bits.OnesCount32(a[i] ^ b[i+offset])
+	}
+	return float64(errs) / float64(32*(end-start)), end - start
+}
+
+// DuplicateMatch is a pair of fingerprints that are the same audio
+type DuplicateMatch struct {
+	A, B   int
+	BER    float64
+	Offset int // frames B is shifted by against A
+}
+
+// indexEntry is where a sub-fingerprint occurs in the library
+type indexEntry struct {
+	print, pos int32
+}
+
+// FindDuplicates compares every fingerprint with every other through an
+// index of sub-fingerprints, so only pairs sharing frames are compared in full
+func FindDuplicates(prints []Fingerprint) []DuplicateMatch {
+	index := make(map[uint32][]indexEntry)
+	for i, fp := range prints {
+		for pos := 0; pos < len(fp); pos += fingerprintIndexStride {
+			// Silence and DC give all-zero frames that would match everything
+			if fp[pos] == 0 {
+				continue
+			}
+			index[fp[pos]] = append(index[fp[pos]], indexEntry{int32(i), int32(pos)})
+		}
+	}
+
+	var matches []DuplicateMatch
+	type candidate struct{ print, offset int }
+	for i, fp := range prints {
+		votes := make(map[candidate]int)
+		for pos, sub := range fp {
+			if sub == 0 {
+				continue
+			}
+			entries := index[sub]
+			if len(entries) > fingerprintCommonFrames {
+				continue
+			}
+			for _, e := range entries {
+				if int(e.print) > i {
+					votes[candidate{int(e.print), int(e.pos) - pos}]++
+				}
+			}
+		}
+
+		best := make(map[int]candidate)
+		for c, count := range votes {
+			if count < fingerprintMinVotes {
+				continue
+			}
+			if b, ok := best[c.print]; !ok || count > votes[b] {
+				best[c.print] = c
+			}
+		}
+
+		for _, c := range best {
+			ber, overlap := compareAt(fp, prints[c.print], c.offset)
+			if overlap >= fingerprintMinOverlap && ber < FingerprintMatchBER {
+				matches = append(matches, DuplicateMatch{A: i, B: c.print, BER: ber, Offset: c.offset})
+			}
+		}
+	}
+
+	slices.SortFunc(matches, func(x, y DuplicateMatch) int {
+		if x.A != y.A {
+			return x.A - y.A
+		}
+		return x.B - y.B
+	})
+	return matches
+}
+
+// ClusterMatches groups n fingerprints into clusters of the same audio,
+// returning only clusters of two or more
+func ClusterMatches(n int, matches []DuplicateMatch) [][]int {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, m := range matches {
+		if a, b := find(m.A), find(m.B); a != b {
+			parent[max(a, b)] = min(a, b)
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range n {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+
+	var clusters [][]int
+	for i := range n {
+		if members := groups[i]; len(members) > 1 {
+			clusters = append(clusters, members)
+		}
+	}
+	return clusters
+}
@@ -0,0 +1,39 @@
+package audio
+
+import (
+	"errors"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+var ErrNoPeak = errors.New("no peak level in the astats output")
+
+// IsFloatInput reports whether a file holds float PCM, which unlike integer
+// PCM can carry peaks above full scale
+func IsFloatInput(inputPath string) bool {
+	media, err := Inspect(inputPath)
+	return err == nil && media.Float()
+}
+
+// SamplePeak returns the highest sample peak over all channels in dBFS,
+// which for float audio can be above 0
+func SamplePeak(inputPath string) (float64, error) {
+	output, err := ffmpeg.Run("-i", inputPath, "-af", "astats=measure_overall=Peak_level:measure_perchannel=none", "-f", "null", "-")
+	if err != nil {
+		return 0, err
+	}
+	return parseOverallPeak(string(output))
+}
+
+// parseOverallPeak reads the overall peak from astats output
+func parseOverallPeak(output string) (float64, error) {
+	report, err := ParseAstats(output)
+	if err != nil {
+		return 0, ErrNoPeak
+	}
+	peak, err := report.Overall.Float("Peak level dB")
+	if err != nil {
+		return 0, ErrNoPeak
+	}
+	return peak, nil
+}
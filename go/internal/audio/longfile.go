@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// Gates and histogram range of BS.1770 and EBU Tech 3342. ebur128 logs
+// loudness to a tenth of a LU, so bins that fine lose nothing.
+const (
+	absoluteGate     = -70.0
+	integratedGate   = -10.0
+	rangeGate        = -20.0
+	histogramTop     = 10.0
+	histogramBinSize = 0.1
+	histogramBins    = int((histogramTop - absoluteGate) / histogramBinSize)
+)
+
+// progressInterval is how much audio passes between progress reports, in seconds
+const progressInterval = 600
+
+var (
+	ErrNoLoudBlocks = errors.New("no audio above the -70 LUFS gate")
+	ErrNoTruePeak   = errors.New("no true peak in the ebur128 summary")
+)
+
+// loudnessHistogram counts loudness values in fixed bins, so a file of any
+// length is measured in the same memory
+type loudnessHistogram [histogramBins + 1]uint64
+
+func (h *loudnessHistogram) add(lufs float64) {
+	if math.IsNaN(lufs) || lufs < absoluteGate {
+		return
+	}
+	bin := int(math.Round((lufs - absoluteGate) / histogramBinSize))
+	h[min(bin, histogramBins)]++
+}
+
+// binLoudness is the loudness a bin stands for
+func binLoudness(bin int) float64 {
+	return absoluteGate + float64(bin)*histogramBinSize
+}
+
+// meanAbove is the energy mean of the values at or above gate, in LUFS
+func (h *loudnessHistogram) meanAbove(gate float64) (float64, uint64) {
+	var energy float64
+	var count uint64
+	for bin, c := range h {
+		if c == 0 || binLoudness(bin) < gate {
+			continue
+		}
+		energy += float64(c) * math.Pow(10, binLoudness(bin)/10)
+		count += c
+	}
+	if count == 0 {
+		return math.Inf(-1), 0
+	}
+	return 10 * math.Log10(energy/float64(count)), count
+}
+
+// percentile returns the loudness below which p of the values at or above gate fall
+func (h *loudnessHistogram) percentile(gate, p float64) float64 {
+	var total uint64
+	for bin, c := range h {
+		if binLoudness(bin) >= gate {
+			total += c
+		}
+	}
+	if total == 0 {
+		return gate
+	}
+	rank := uint64(math.Round(p * float64(total-1)))
+	var seen uint64
+	for bin, c := range h {
+		if binLoudness(bin) < gate {
+			continue
+		}
+		if seen += c; seen > rank {
+			return binLoudness(bin)
+		}
+	}
+	return gate
+}
+
+// LoudnessAccumulator aggregates ebur128's momentary and short-term values
+// into integrated loudness and loudness range as they stream in. Momentary
+// values come every 100 ms over 400 ms, the gating blocks of BS.1770.
+type LoudnessAccumulator struct {
+	momentary loudnessHistogram
+	shortTerm loudnessHistogram
+	// every momentary value's energy, silence included, for ungated loudness
+	energy  float64
+	blocks  uint64
+	Seconds float64 // position of the last frame read
+}
+
+// AddFrameLine feeds one line of ebur128's frame log and reports whether it was a frame
+func (a *LoudnessAccumulator) AddFrameLine(line string) bool {
+	frame, ok := ParseEbur128Frame(line)
+	if !ok {
+		return false
+	}
+	a.Seconds = frame.Time
+	a.momentary.add(frame.Momentary)
+	if !math.IsNaN(frame.Momentary) {
+		a.energy += math.Pow(10, frame.Momentary/10)
+		a.blocks++
+	}
+	a.shortTerm.add(frame.ShortTerm)
+	return true
+}
+
+// Integrated returns the gated integrated loudness and the relative gate it used
+func (a *LoudnessAccumulator) Integrated() (float64, float64, error) {
+	ungated, count := a.momentary.meanAbove(absoluteGate)
+	if count == 0 {
+		return 0, 0, ErrNoLoudBlocks
+	}
+	gate := ungated + integratedGate
+	integrated, _ := a.momentary.meanAbove(gate)
+	return integrated, gate, nil
+}
+
+// Ungated returns the energy mean of every block, as BS.1770-1 measures
+func (a *LoudnessAccumulator) Ungated() (float64, error) {
+	if a.energy == 0 {
+		return 0, ErrNoLoudBlocks
+	}
+	return 10 * math.Log10(a.energy/float64(a.blocks)), nil
+}
+
+// IntegratedTo returns the integrated loudness to a revision of BS.1770.
+// The relative gate is the gated measurement's for every revision, the
+// threshold loudnorm's linear mode expects.
+func (a *LoudnessAccumulator) IntegratedTo(standard Standard) (float64, float64, error) {
+	integrated, gate, err := a.Integrated()
+	if err != nil || standard.Gated() {
+		return integrated, gate, err
+	}
+	ungated, err := a.Ungated()
+	return ungated, gate, err
+}
+
+// LRA returns the loudness range, the spread between the 10th and 95th
+// percentile of the gated short-term values
+func (a *LoudnessAccumulator) LRA() float64 {
+	ungated, count := a.shortTerm.meanAbove(absoluteGate)
+	if count == 0 {
+		return 0
+	}
+	gate := ungated + rangeGate
+	return a.shortTerm.percentile(gate, 0.95) - a.shortTerm.percentile(gate, 0.10)
+}
+
+// MeasureStreaming measures a file through the filter chain in one pass,
+// reading ebur128's frame log as FFmpeg writes it instead of collecting its
+// output. Memory stays the same however long the file is. The integrated
+// loudness is that of the standard's revision, mono measured as dual mono
+// when dualMono is set. onProgress gets the position in seconds every ten
+// minutes of audio.
+func MeasureStreaming(inputPath, filterChain string, standard Standard, dualMono bool, watchdog ffmpeg.Watchdog, onProgress func(float64)) (map[string]string, error) {
+	channels, _ := ProbeChannels(inputPath)
+	filter := standard.MeasureFilter(channels, true, dualMono)
+	if filterChain != "" {
+		filter = filterChain + "," + filter
+	}
+
+	var acc LoudnessAccumulator
+	reported := 0.0
+	output, err := watchdog.Stream(func(line string) {
+		if acc.AddFrameLine(line) && onProgress != nil && acc.Seconds-reported >= progressInterval {
+			reported = acc.Seconds
+			onProgress(acc.Seconds)
+		}
+	}, "-i", inputPath, "-af", filter, "-f", "null", "-")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, ffmpeg.Tail(output, 3))
+	}
+
+	integrated, gate, err := acc.IntegratedTo(standard)
+	if err != nil {
+		return nil, err
+	}
+
+	// The true peak is only in the summary at the end, which the tail keeps
+	summary, err := ParseEbur128Summary(string(output))
+	if err != nil || !summary.HasPeak || math.IsInf(summary.TruePeak, 0) {
+		return nil, ErrNoTruePeak
+	}
+
+	return map[string]string{
+		"input_i":       fmt.Sprintf("%.2f", integrated),
+		"input_tp":      fmt.Sprintf("%.1f", summary.TruePeak),
+		"input_lra":     fmt.Sprintf("%.2f", acc.LRA()),
+		"input_thresh":  fmt.Sprintf("%.2f", gate),
+		"target_offset": "0.00",
+	}, nil
+}
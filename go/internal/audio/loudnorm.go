@@ -0,0 +1,119 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Loudnorm fallback choices for files that can't be normalized in linear mode
+const (
+	FallbackDynamic  = "Allow dynamic"
+	FallbackPreLimit = "Pre-limit, then linear"
+	FallbackFail     = "Fail"
+)
+
+// LoudnormFallbacks lists the fallback choices in display order
+var LoudnormFallbacks = []string{FallbackDynamic, FallbackPreLimit, FallbackFail}
+
+// Choices for loudnorm's offset gain, which it applies ahead of its limiter
+// in dynamic mode. Linear mode works out its own gain and ignores it.
+const (
+	OffsetMeasured = "From first pass"
+	OffsetNone     = "None"
+	OffsetCustom   = "Custom"
+)
+
+// LoudnormOffsets lists the offset choices in display order
+var LoudnormOffsets = []string{OffsetMeasured, OffsetNone, OffsetCustom}
+
+// LoudnormOptions are the advanced loudnorm settings, passed to both passes
+type LoudnormOptions struct {
+	// DualMono measures mono files as they sound played on both sides of a
+	// stereo chain, so they end up 3 LU quieter than measured alone and on
+	// target on stereo playout
+	DualMono bool
+	// Offset is the offset gain in dB, "" for the first pass's target_offset
+	Offset string
+}
+
+// FirstPass returns the options of the measuring pass, appended to its arguments
+func (o LoudnormOptions) FirstPass() string {
+	if o.DualMono {
+		return ":dual_mono=true"
+	}
+	return ""
+}
+
+// SecondPass returns the offset and options of the normalizing pass after
+// the first pass measurements
+func (o LoudnormOptions) SecondPass(measured map[string]string) string {
+	offset := o.Offset
+	if offset == "" {
+		offset = measured["target_offset"]
+	}
+	return fmt.Sprintf("offset=%s:linear=true", offset) + o.FirstPass()
+}
+
+// preLimitMargin keeps the sample peak limiter clear of inter-sample overs
+const preLimitMargin = 1.0
+
+// LinearCheck is the outcome of checking a first pass measurement against
+// the conditions FFmpeg's loudnorm needs to stay in linear mode
+type LinearCheck struct {
+	Linear      bool
+	Gain        float64 // dB of gain linear mode would apply
+	PeakAfter   float64 // true peak in dBTP after that gain
+	MeasuredLRA float64
+	Reason      string
+}
+
+// CheckLinear reproduces loudnorm's own test: linear mode is used only when
+// the gain leaves the true peak under the target and the measured LRA fits
+// inside the LRA target. Otherwise FFmpeg silently switches to dynamic mode.
+func CheckLinear(targetI, targetTP, targetLRA float64, measured map[string]string) LinearCheck {
+	inputI, errI := strconv.ParseFloat(measured["input_i"], 64)
+	inputTP, errTP := strconv.ParseFloat(measured["input_tp"], 64)
+	inputLRA, errLRA := strconv.ParseFloat(measured["input_lra"], 64)
+	if errI != nil || errTP != nil || errLRA != nil || math.IsInf(inputI, 0) {
+		return LinearCheck{Reason: "measurement is incomplete"}
+	}
+
+	check := LinearCheck{
+		Gain:        targetI - inputI,
+		MeasuredLRA: inputLRA,
+	}
+	check.PeakAfter = inputTP + check.Gain
+
+	switch {
+	case check.PeakAfter > targetTP:
+		check.Reason = fmt.Sprintf("%.1f dB of gain would put the true peak at %.1f dBTP, over the %.1f dBTP target", check.Gain, check.PeakAfter, targetTP)
+	case inputLRA > targetLRA:
+		check.Reason = fmt.Sprintf("loudness range %.1f LU exceeds the %.1f LU target", inputLRA, targetLRA)
+	default:
+		check.Linear = true
+	}
+
+	return check
+}
+
+// PreLimitFilter returns an alimiter stage that lowers the peaks enough for
+// the linear gain to fit under targetTP, and the true peak to report to
+// loudnorm after it. filter is empty when the peaks already fit, and ok is
+// false when the required limiting is deeper than alimiter allows.
+func PreLimitFilter(check LinearCheck, targetTP float64) (filter string, peak float64, ok bool) {
+	if check.PeakAfter <= targetTP {
+		return "", 0, true
+	}
+
+	// alimiter works on sample peaks, so the ceiling leaves room for overs
+	ceiling := targetTP - check.Gain - preLimitMargin
+	limit := math.Min(math.Pow(10, ceiling/20), 1)
+
+	// alimiter accepts limits down to 0.0625 (-24 dB)
+	if limit < 0.0625 {
+		return "", 0, false
+	}
+
+	return fmt.Sprintf("alimiter=limit=%.4f:attack=5:release=50:level=disabled", limit), targetTP - check.Gain, true
+}
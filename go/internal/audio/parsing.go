@@ -2,62 +2,27 @@ package audio
 
 import (
 	"math"
-	"regexp"
-	"strconv"
-	"strings"
 )
 
 // ParseAstatsOutput parses FFmpeg astats filter output into DynamicsAnalysis
 func ParseAstatsOutput(output string) *DynamicsAnalysis {
 	result := &DynamicsAnalysis{}
 
-	// Extract Overall section
-	overallStart := strings.Index(output, "Overall")
-	if overallStart == -1 {
+	report, err := ParseAstats(output)
+	if err != nil || len(report.Overall) == 0 {
 		return result
 	}
-	overallSection := output[overallStart:]
 
-	// Parse: Peak level dB: -65.832755
-	peakRe := regexp.MustCompile(`Peak level dB:\s+([-\d.]+)`)
-	if match := peakRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.PeakLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: RMS peak dB: -75.013939
-	rmsPeakRe := regexp.MustCompile(`RMS peak dB:\s+([-\d.]+)`)
-	if match := rmsPeakRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSPeak, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: RMS trough dB: -78.685114
-	rmsTroughRe := regexp.MustCompile(`RMS trough dB:\s+([-\d.]+)`)
-	if match := rmsTroughRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSTrough, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: RMS level dB: -76.472639
-	rmsRe := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-	if match := rmsRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse from Channel 1 section (before Overall): Crest factor: 2.982689
-	crestRe := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-	if match := crestRe.FindStringSubmatch(output); len(match) > 1 {
-		result.CrestFactor, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse from Channel 1 section: Dynamic range: 51.779619
-	dynRe := regexp.MustCompile(`Dynamic range:\s+([-\d.]+)`)
-	if match := dynRe.FindStringSubmatch(output); len(match) > 1 {
-		result.DynamicRange, _ = strconv.ParseFloat(match[1], 64)
-	}
+	result.PeakLevel, _ = report.Overall.Float("Peak level dB")
+	result.RMSPeak, _ = report.Overall.Float("RMS peak dB")
+	result.RMSTrough, _ = report.Overall.Float("RMS trough dB")
+	result.RMSLevel, _ = report.Overall.Float("RMS level dB")
 
-	noiseFloorRe := regexp.MustCompile(`Noise floor dB:\s+([-\d.]+)`)
-	if match := noiseFloorRe.FindStringSubmatch(output); len(match) > 1 {
-		result.NoiseFloor, _ = strconv.ParseFloat(match[1], 64)
-	}
+	// Crest factor, dynamic range and noise floor from the Channel 1 section
+	channel1 := report.Channel(1)
+	result.CrestFactor, _ = channel1.Float("Crest factor")
+	result.DynamicRange, _ = channel1.Float("Dynamic range")
+	result.NoiseFloor, _ = channel1.Float("Noise floor dB")
 
 	return result
 }
@@ -66,40 +31,11 @@ func ParseAstatsOutput(output string) *DynamicsAnalysis {
 func ParseDynamicsScore(output string) *DynamicsScoreAnalysis {
 	result := &DynamicsScoreAnalysis{}
 
-	// Parse Channel 1 section
-	lines := strings.Split(output, "\n")
-	inChannel1 := false
-
-	for _, line := range lines {
-		if strings.Contains(line, "Channel: 1") {
-			inChannel1 = true
-			continue
-		}
-		if strings.Contains(line, "Channel: 2") {
-			break
-		}
-
-		if inChannel1 {
-			if strings.Contains(line, "RMS peak dB:") {
-				re := regexp.MustCompile(`RMS peak dB:\s+([-\d.]+)`)
-				if match := re.FindStringSubmatch(line); len(match) > 1 {
-					result.RMSPeak, _ = strconv.ParseFloat(match[1], 64)
-				}
-			}
-			if strings.Contains(line, "RMS level dB:") && result.RMSLevel == 0 {
-				re := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-				if match := re.FindStringSubmatch(line); len(match) > 1 {
-					result.RMSLevel, _ = strconv.ParseFloat(match[1], 64)
-				}
-			}
-			if strings.Contains(line, "Crest factor:") {
-				re := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-				if match := re.FindStringSubmatch(line); len(match) > 1 {
-					result.CrestFactor, _ = strconv.ParseFloat(match[1], 64)
-				}
-			}
-		}
-	}
+	report, _ := ParseAstats(output)
+	channel1 := report.Channel(1)
+	result.RMSPeak, _ = channel1.Float("RMS peak dB")
+	result.RMSLevel, _ = channel1.Float("RMS level dB")
+	result.CrestFactor, _ = channel1.Float("Crest factor")
 
 	// Calculate DS = sqrt(Crest) × (RMS_peak - RMS_level)
 	result.DynamicsScore = math.Sqrt(result.CrestFactor) * (result.RMSPeak - result.RMSLevel)
@@ -111,36 +47,15 @@ func ParseDynamicsScore(output string) *DynamicsScoreAnalysis {
 func ParseFrequencyBandOutput(output string, bandName string) *FrequencyBandAnalysis {
 	result := &FrequencyBandAnalysis{BandName: bandName}
 
-	// Find Overall section
-	overallStart := strings.Index(output, "Overall")
-	if overallStart == -1 {
+	report, err := ParseAstats(output)
+	if err != nil || len(report.Overall) == 0 {
 		return nil
 	}
-	overallSection := output[overallStart:]
-
-	// Parse: Peak level dB
-	peakRe := regexp.MustCompile(`Peak level dB:\s+([-\d.]+)`)
-	if match := peakRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.PeakLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
 
-	// Parse: RMS level dB
-	rmsRe := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-	if match := rmsRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: Crest factor
-	crestRe := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-	if match := crestRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.CrestFactor, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: Dynamic range
-	dynRe := regexp.MustCompile(`Dynamic range:\s+([-\d.]+)`)
-	if match := dynRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.DynamicRange, _ = strconv.ParseFloat(match[1], 64)
-	}
+	result.PeakLevel, _ = report.Overall.Float("Peak level dB")
+	result.RMSLevel, _ = report.Overall.Float("RMS level dB")
+	result.CrestFactor, _ = report.Overall.Float("Crest factor")
+	result.DynamicRange, _ = report.Overall.Float("Dynamic range")
 
 	return result
 }
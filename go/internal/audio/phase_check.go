@@ -4,8 +4,6 @@ import (
 	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
 	"fmt"
 	"os"
-	"regexp"
-	"strconv"
 	"math"
 )
 
@@ -27,8 +25,7 @@ func PhaseCheck(inputPath string, logFile *os.File) (inverted bool, offset float
 }
 
 func buildPhaseCheck(inputPath string, logFile *os.File) (string, error) {
-	cmd := ffmpeg.Command("-i", inputPath, "-af", "astats", "-f", "null", "-")
-	output, err := cmd.CombinedOutput()
+	output, err := ffmpeg.Run("-i", inputPath, "-af", "astats", "-f", "null", "-")
 	if err != nil {
 		if logFile != nil {
 			logFile.WriteString(fmt.Sprintf("astats failed: %v\n", err))
@@ -39,22 +36,26 @@ func buildPhaseCheck(inputPath string, logFile *os.File) (string, error) {
 }
 
 func parsePhaseCheck(output string) (ch1Min, ch1Max, ch2Min, ch2Max float64, err error) {
-	// Channel 1
-	ch1Re := regexp.MustCompile(`(?s)Channel: 1.*?Min level:\s+([-\d.]+).*?Max level:\s+([-\d.]+)`)
-	if m := ch1Re.FindStringSubmatch(output); len(m) > 2 {
-		ch1Min, _ = strconv.ParseFloat(m[1], 64)
-		ch1Max, _ = strconv.ParseFloat(m[2], 64)
-	} else {
-		return 0, 0, 0, 0, fmt.Errorf("channel 1 not found")
+	report, err := ParseAstats(output)
+	if err != nil {
+		return 0, 0, 0, 0, err
 	}
 
-	// Channel 2
-	ch2Re := regexp.MustCompile(`(?s)Channel: 2.*?Min level:\s+([-\d.]+).*?Max level:\s+([-\d.]+)`)
-	if m := ch2Re.FindStringSubmatch(output); len(m) > 2 {
-		ch2Min, _ = strconv.ParseFloat(m[1], 64)
-		ch2Max, _ = strconv.ParseFloat(m[2], 64)
-	} else {
-		return 0, 0, 0, 0, fmt.Errorf("channel 2 not found")
+	levels := func(channel int) (float64, float64, error) {
+		section := report.Channel(channel)
+		low, errMin := section.Float("Min level")
+		high, errMax := section.Float("Max level")
+		if errMin != nil || errMax != nil {
+			return 0, 0, fmt.Errorf("channel %d not found", channel)
+		}
+		return low, high, nil
+	}
+
+	if ch1Min, ch1Max, err = levels(1); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if ch2Min, ch2Max, err = levels(2); err != nil {
+		return 0, 0, 0, 0, err
 	}
 
 	return ch1Min, ch1Max, ch2Min, ch2Max, nil
@@ -0,0 +1,71 @@
+package audio
+
+import (
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// MediaInfo is what ffprobe reports about an input, reduced to the parts
+// TNT decides on. The audio fields describe the first audio stream.
+type MediaInfo struct {
+	Format string
+	// Duration is 0 when the container reports none, see HasDuration
+	Duration    float64
+	HasDuration bool
+
+	HasAudio      bool
+	Codec         string
+	CodecTag      string
+	SampleRate    int
+	Channels      int
+	ChannelLayout string
+	SampleFormat  string
+
+	// Artwork is the stream index of attached cover art, -1 without any
+	Artwork int
+	// Tags are the container tags with upper case keys
+	Tags map[string]string
+}
+
+// Inspect reads an input's headers with ffprobe
+func Inspect(inputPath string) (MediaInfo, error) {
+	probe, err := ffmpeg.Probe(inputPath)
+	if err != nil {
+		return MediaInfo{}, err
+	}
+
+	info := MediaInfo{Format: probe.Format.FormatName, Artwork: -1, Tags: make(map[string]string)}
+	info.Duration, info.HasDuration = probe.Format.Seconds()
+	for key, value := range probe.Format.Tags {
+		info.Tags[strings.ToUpper(key)] = value
+	}
+
+	for _, s := range probe.Streams {
+		switch {
+		case s.CodecType == "audio" && !info.HasAudio:
+			info.HasAudio = true
+			info.Codec = s.CodecName
+			info.CodecTag = s.CodecTagString
+			info.SampleRate = s.Rate()
+			info.Channels = s.Channels
+			info.ChannelLayout = s.ChannelLayout
+			info.SampleFormat = s.SampleFmt
+		case s.CodecType == "video" && s.Disposition["attached_pic"] == 1 && info.Artwork < 0:
+			info.Artwork = s.Index
+		}
+	}
+	return info, nil
+}
+
+// Float reports whether the audio is float PCM, which unlike integer PCM
+// can carry peaks above full scale
+func (m MediaInfo) Float() bool {
+	return strings.HasPrefix(m.Codec, "pcm_f32") || strings.HasPrefix(m.Codec, "pcm_f64")
+}
+
+// Protected reports whether the audio is encrypted, FairPlay or otherwise
+func (m MediaInfo) Protected() bool {
+	tag := strings.ToLower(m.CodecTag)
+	return tag == "drms" || tag == "enca"
+}
@@ -0,0 +1,214 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/cmplx"
+	"strconv"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+const (
+	qualitySampleRate = 48000
+
+	// qualityMaxSeconds bounds how much audio is compared, keeping memory flat on long files
+	qualityMaxSeconds = 120
+
+	qualityFrameSize = 2048
+	qualityHopSize   = 1024
+
+	// qualityMaxLag is the largest encoder delay, in samples, searched for when aligning
+	qualityMaxLag = 4096
+
+	// qualitySilence is the frame power in dBFS below which frames are skipped
+	qualitySilence = -70.0
+)
+
+var ErrTooShortToCompare = errors.New("not enough audio to compare")
+
+// QualityScore is an objective comparison of a lossy encode against its
+// lossless reference
+type QualityScore struct {
+	SpectralDistance float64 // mean log-spectral distance in dB, 20 Hz - 20 kHz, lower is better
+	SNR              float64 // waveform signal-to-noise ratio in dB, higher is better
+	Offset           int     // samples the encode was shifted by to line up with the reference
+}
+
+// CompareQuality decodes the reference through filter (may be empty) and the
+// encoded file, lines them up and measures how far the encode strays.
+func CompareQuality(referencePath, filter, encodedPath string) (QualityScore, error) {
+	ref, err := decodeMono(referencePath, filter)
+	if err != nil {
+		return QualityScore{}, err
+	}
+	enc, err := decodeMono(encodedPath, "")
+	if err != nil {
+		return QualityScore{}, err
+	}
+
+	offset := alignmentOffset(ref, enc)
+	if offset > 0 {
+		enc = enc[offset:]
+	} else if offset < 0 {
+		ref = ref[-offset:]
+	}
+	n := min(len(ref), len(enc))
+	if n < qualityFrameSize*4 {
+		return QualityScore{}, ErrTooShortToCompare
+	}
+	ref, enc = ref[:n], enc[:n]
+
+	lsd, err := logSpectralDistance(ref, enc)
+	if err != nil {
+		return QualityScore{}, err
+	}
+
+	return QualityScore{SpectralDistance: lsd, SNR: signalToNoise(ref, enc), Offset: offset}, nil
+}
+
+// decodeMono decodes a file to 48 kHz mono float samples
+func decodeMono(path, filter string) ([]float64, error) {
+	return decodeMonoAt(path, filter, qualitySampleRate, qualityMaxSeconds)
+}
+
+// decodeMonoAt decodes up to seconds of a file to mono float samples at sampleRate
+func decodeMonoAt(path, filter string, sampleRate, seconds int) ([]float64, error) {
+	args := []string{"-i", path, "-t", strconv.Itoa(seconds), "-vn"}
+	if filter != "" {
+		args = append(args, "-af", filter)
+	}
+	args = append(args, "-ac", "1", "-ar", strconv.Itoa(sampleRate), "-f", "f32le", "-")
+
+	raw, err := ffmpeg.Output(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]float64, len(raw)/4)
+	for i := range samples {
+		samples[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:])))
+	}
+	return samples, nil
+}
+
+// alignmentOffset finds the shift of enc against ref with the highest
+// correlation, compensating for encoder priming delay
+func alignmentOffset(ref, enc []float64) int {
+	// Correlate a one second window taken after the first second
+	start := qualitySampleRate
+	window := qualitySampleRate
+	if len(ref) < start+window+qualityMaxLag || len(enc) < start+window+qualityMaxLag {
+		start, window = qualityMaxLag, min(len(ref), len(enc))/2-qualityMaxLag
+		if window <= 0 {
+			return 0
+		}
+	}
+
+	best, bestLag := math.Inf(-1), 0
+	for lag := -qualityMaxLag; lag <= qualityMaxLag; lag++ {
+		var sum float64
+		for i := start; i < start+window; i++ {
+			sum += ref[i] * enc[i+lag]
+		}
+		if sum > best {
+			best, bestLag = sum, lag
+		}
+	}
+	return bestLag
+}
+
+// logSpectralDistance averages the RMS difference of the log power spectra
+// over all frames that aren't silent
+func logSpectralDistance(ref, enc []float64) (float64, error) {
+	window := make([]float64, qualityFrameSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(qualityFrameSize-1))
+	}
+
+	binHz := float64(qualitySampleRate) / qualityFrameSize
+	lo, hi := int(math.Ceil(20/binHz)), int(20000/binHz)
+
+	refFrame := make([]complex128, qualityFrameSize)
+	encFrame := make([]complex128, qualityFrameSize)
+
+	var total float64
+	var frames int
+	for pos := 0; pos+qualityFrameSize <= len(ref); pos += qualityHopSize {
+		var energy float64
+		for i := 0; i < qualityFrameSize; i++ {
+			energy += ref[pos+i] * ref[pos+i]
+			refFrame[i] = complex(ref[pos+i]*window[i], 0)
+			encFrame[i] = complex(enc[pos+i]*window[i], 0)
+		}
+		if 10*math.Log10(energy/qualityFrameSize+1e-20) < qualitySilence {
+			continue
+		}
+
+		fft(refFrame)
+		fft(encFrame)
+
+		var sum float64
+		for k := lo; k <= hi; k++ {
+			refPower := 10 * math.Log10(sqAbs(refFrame[k])+1e-12)
+			encPower := 10 * math.Log10(sqAbs(encFrame[k])+1e-12)
+			sum += (refPower - encPower) * (refPower - encPower)
+		}
+		total += math.Sqrt(sum / float64(hi-lo+1))
+		frames++
+	}
+
+	if frames == 0 {
+		return 0, ErrTooShortToCompare
+	}
+	return total / float64(frames), nil
+}
+
+// signalToNoise treats the difference between the signals as noise
+func signalToNoise(ref, enc []float64) float64 {
+	var signal, noise float64
+	for i := range ref {
+		d := ref[i] - enc[i]
+		signal += ref[i] * ref[i]
+		noise += d * d
+	}
+	if noise == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+func sqAbs(c complex128) float64 {
+	return real(c)*real(c) + imag(c)*imag(c)
+}
+
+// fft is an in-place iterative radix-2 FFT, len(x) must be a power of two
+func fft(x []complex128) {
+	n := len(x)
+
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := x[start+k]
+				v := x[start+k+size/2] * w
+				x[start+k] = u + v
+				x[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
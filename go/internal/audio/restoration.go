@@ -0,0 +1,143 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// Thresholds deciding which restoration stages a file needs. They are set
+// so a clean digital master passes through untouched.
+const (
+	// Hiss from tape and cheap converters sits above this floor
+	denoiseFloorDB = -60.0
+	// Repeated identical samples at the peak level mean flat-topped clipping
+	declipFlatFactor = 1.0
+	declipPeakDB     = -1.0
+	// Clicks stand far out of the top octaves; cymbals and sibilance don't
+	declickCrestDB = 32.0
+	declickBandHz  = 4000
+)
+
+var ErrNoOverallStats = errors.New("no overall statistics in astats output")
+
+// RestorationAnalysis holds the measurements the restoration stages are chosen from
+type RestorationAnalysis struct {
+	PeakDB       float64
+	FlatFactor   float64
+	NoiseFloorDB float64
+	// ClickCrestDB is the crest factor above declickBandHz
+	ClickCrestDB float64
+}
+
+// RestorationPlan is which restoration stages run on a file
+type RestorationPlan struct {
+	Declick bool
+	Declip  bool
+	Denoise bool
+	// NoiseFloorDB tells afftdn where the noise sits
+	NoiseFloorDB float64
+}
+
+// Empty reports whether the file needs no restoration
+func (p RestorationPlan) Empty() bool {
+	return !p.Declick && !p.Declip && !p.Denoise
+}
+
+func (p RestorationPlan) String() string {
+	var stages []string
+	if p.Declick {
+		stages = append(stages, "declick")
+	}
+	if p.Declip {
+		stages = append(stages, "declip")
+	}
+	if p.Denoise {
+		stages = append(stages, fmt.Sprintf("denoise (floor %.1f dB)", p.NoiseFloorDB))
+	}
+	if len(stages) == 0 {
+		return "none needed"
+	}
+	return strings.Join(stages, ", ")
+}
+
+// Filter returns the filter chain for the plan. Clicks are removed before
+// clipping is repaired, so click peaks aren't mistaken for clipped samples,
+// and noise reduction goes last so it doesn't learn the clicks as noise.
+func (p RestorationPlan) Filter() string {
+	var filters []string
+	if p.Declick {
+		filters = append(filters, "adeclick=t=4")
+	}
+	if p.Declip {
+		filters = append(filters, "adeclip")
+	}
+	if p.Denoise {
+		floor := math.Max(-80, math.Min(-20, p.NoiseFloorDB))
+		filters = append(filters, fmt.Sprintf("afftdn=nr=10:nf=%.1f:tn=1", floor))
+	}
+	return strings.Join(filters, ",")
+}
+
+// Plan picks the stages the measurements call for
+func (a RestorationAnalysis) Plan() RestorationPlan {
+	return RestorationPlan{
+		Declick:      a.ClickCrestDB > declickCrestDB,
+		Declip:       a.FlatFactor >= declipFlatFactor && a.PeakDB > declipPeakDB,
+		Denoise:      a.NoiseFloorDB > denoiseFloorDB,
+		NoiseFloorDB: a.NoiseFloorDB,
+	}
+}
+
+// AnalyzeRestoration measures clipping and noise on the whole signal and
+// clicks on its top octaves
+func AnalyzeRestoration(inputPath string) (RestorationAnalysis, error) {
+	var a RestorationAnalysis
+
+	output, err := ffmpeg.Run("-hide_banner", "-i", inputPath, "-af", "astats=measure_perchannel=none", "-f", "null", "-")
+	if err != nil {
+		return a, err
+	}
+	overall, err := parseOverallStats(string(output))
+	if err != nil {
+		return a, err
+	}
+	a.PeakDB = overall["Peak level dB"]
+	a.FlatFactor = overall["Flat factor"]
+	a.NoiseFloorDB = overall["Noise floor dB"]
+
+	output, err = ffmpeg.Run("-hide_banner", "-i", inputPath, "-af", fmt.Sprintf("highpass=f=%d:p=2,astats=measure_perchannel=none", declickBandHz), "-f", "null", "-")
+	if err != nil {
+		return a, err
+	}
+	band, err := parseOverallStats(string(output))
+	if err != nil {
+		return a, err
+	}
+	a.ClickCrestDB = band["Peak level dB"] - band["RMS level dB"]
+
+	return a, nil
+}
+
+// parseOverallStats reads the Overall section of astats output. Digital
+// silence gives -inf, which is kept as such.
+func parseOverallStats(output string) (map[string]float64, error) {
+	report, err := ParseAstats(output)
+	if err != nil {
+		return nil, ErrNoOverallStats
+	}
+
+	stats := make(map[string]float64)
+	for label := range report.Overall {
+		if value, err := report.Overall.Float(label); err == nil {
+			stats[label] = value
+		}
+	}
+	if len(stats) == 0 {
+		return nil, ErrNoOverallStats
+	}
+	return stats, nil
+}
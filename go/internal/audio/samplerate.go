@@ -0,0 +1,50 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sample rate conversion qualities offered for resampling the output
+const (
+	SRCStandard = "Standard"
+	SRCHigh     = "High"
+	SRCVeryHigh = "Very high"
+)
+
+// SRCQualities lists the conversion qualities in display order
+var SRCQualities = []string{SRCStandard, SRCHigh, SRCVeryHigh}
+
+// srcOptions are the aresample options of each quality. Standard is
+// FFmpeg's own resampler as used when nothing is asked for, the others use
+// SoX's, Very high at its highest precision.
+var srcOptions = map[string]string{
+	SRCStandard: "resampler=swr",
+	SRCHigh:     "resampler=soxr",
+	SRCVeryHigh: "resampler=soxr:precision=28:cheby=1",
+}
+
+var ErrNoSampleRate = errors.New("no sample rate in the input header")
+
+// ProbeSampleRate returns the sample rate of the first audio stream, read
+// from the input header without decoding the file
+func ProbeSampleRate(inputPath string) (int, error) {
+	media, err := Inspect(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	if media.SampleRate == 0 {
+		return 0, ErrNoSampleRate
+	}
+	return media.SampleRate, nil
+}
+
+// ResampleFilter converts to rate at a conversion quality, unknown
+// qualities getting the standard one
+func ResampleFilter(rate int, quality string) string {
+	options, ok := srcOptions[quality]
+	if !ok {
+		options = srcOptions[SRCStandard]
+	}
+	return fmt.Sprintf("aresample=%d:%s", rate, options)
+}
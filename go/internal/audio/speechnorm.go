@@ -0,0 +1,21 @@
+package audio
+
+// SpeechnormPresets lists the speech normalization choices in display order
+var SpeechnormPresets = []string{"Off", "Light", "Medium", "Aggressive"}
+
+// speechnormParams holds the speechnorm options for each preset. Aggressive
+// matches the values TNT used to apply to all Opus speech encodes.
+var speechnormParams = map[string]string{
+	"Light":      "e=3:c=2:r=0.0001:f=0.001:l=1",
+	"Medium":     "e=6.25:r=0.0001:f=0.001:l=1",
+	"Aggressive": "e=12.5:r=0.0001:l=1",
+}
+
+// SpeechnormFilter returns the speechnorm filter for a preset, or "" for Off
+func SpeechnormFilter(preset string) string {
+	params, ok := speechnormParams[preset]
+	if !ok {
+		return ""
+	}
+	return "speechnorm=" + params
+}
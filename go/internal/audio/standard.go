@@ -0,0 +1,74 @@
+package audio
+
+import "fmt"
+
+// Standard is the revision of ITU-R BS.1770 loudness is measured to. The
+// revisions share K-weighting and the 400 ms blocks, but differ in gating
+// and in which channels count. FFmpeg's ebur128 measures to -4.
+type Standard string
+
+const (
+	// BS1770_4 gates at -70 LUFS and 10 LU below the ungated level and
+	// weights every channel by its position. -5 only adds object-based
+	// layouts and measures channel-based audio alike.
+	BS1770_4 Standard = "BS.1770-4"
+	// BS1770_2 gates like -4 but only has weights for the five main
+	// channels of 5.1, further channels don't count. -3 changed the true
+	// peak meter's description, not the loudness.
+	BS1770_2 Standard = "BS.1770-2"
+	// BS1770_1 sums the five main channels without any gate, so pauses and
+	// silence pull the loudness down
+	BS1770_1 Standard = "BS.1770-1"
+)
+
+// Standards lists the revisions in the order they are offered
+var Standards = []Standard{BS1770_4, BS1770_2, BS1770_1}
+
+// ParseStandard returns the revision a label or name stands for, -4 when
+// it's empty or unknown
+func ParseStandard(text string) Standard {
+	for _, s := range Standards {
+		if text == string(s) || text == s.Label() {
+			return s
+		}
+	}
+	return BS1770_4
+}
+
+// Label names the revisions that measure alike
+func (s Standard) Label() string {
+	switch s {
+	case BS1770_2:
+		return "ITU-R BS.1770-2/-3"
+	case BS1770_1:
+		return "ITU-R BS.1770-1 (ungated)"
+	}
+	return "ITU-R BS.1770-4/-5"
+}
+
+// Gated reports whether the revision gates its blocks
+func (s Standard) Gated() bool {
+	return s != BS1770_1
+}
+
+// MeasureFilter returns the ebur128 measurement of a file with the given
+// channel count to the revision. Channels the revision has no weight for
+// are left out first: beyond 5.1 only the first six channels, the fronts,
+// LFE and the surround pair, are kept. The frame log is written when frames
+// is set, and always for ungated revisions, which are measured from it.
+// dualMono measures mono as it sounds played on both sides of a stereo
+// chain, 3 LU louder, and leaves other layouts alone.
+func (s Standard) MeasureFilter(channels int, frames, dualMono bool) string {
+	framelog := "quiet"
+	if frames || !s.Gated() {
+		framelog = "info"
+	}
+	filter := fmt.Sprintf("ebur128=framelog=%s:peak=true", framelog)
+	if dualMono {
+		filter += ":dualmono=true"
+	}
+	if s != BS1770_4 && channels > 6 {
+		filter = "pan=5.1|c0=c0|c1=c1|c2=c2|c3=c3|c4=c4|c5=c5," + filter
+	}
+	return filter
+}
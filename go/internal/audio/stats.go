@@ -0,0 +1,315 @@
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoAstats      = errors.New("no astats statistics in FFmpeg output")
+	ErrNoSummary     = errors.New("no ebur128 summary in FFmpeg output")
+	ErrNoLoudnormLog = errors.New("no loudnorm measurement in FFmpeg output")
+)
+
+// filterLine splits a filter's log line into the filter instance, such as
+// "Parsed_astats_0", and the message. Lines that aren't from a filter
+// return an empty name.
+func filterLine(line string) (name, message string) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasPrefix(line, "[") {
+		return "", line
+	}
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return "", line
+	}
+	name, _, _ = strings.Cut(line[1:end], " @ ")
+	return name, strings.TrimSpace(line[end+1:])
+}
+
+// splitStat splits "Peak level dB: -1.2" into its label and value
+func splitStat(message string) (label, value string, ok bool) {
+	label, value, ok = strings.Cut(message, ":")
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSpace(label), strings.TrimSpace(value), true
+}
+
+// StatSection is one block of astats statistics, values keyed by their label
+type StatSection map[string]string
+
+// Float returns a statistic as a number. -inf, inf and nan are numbers to
+// astats and read as such.
+func (s StatSection) Float(label string) (float64, error) {
+	value, ok := s[label]
+	if !ok {
+		return 0, fmt.Errorf("%s missing", label)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// AstatsReport is astats' statistics per channel and over all channels
+type AstatsReport struct {
+	Channels []StatSection
+	Overall  StatSection
+}
+
+// Channel returns the statistics of a channel counted from 1, empty when
+// the output has no such channel
+func (r AstatsReport) Channel(n int) StatSection {
+	if n < 1 || n > len(r.Channels) {
+		return StatSection{}
+	}
+	return r.Channels[n-1]
+}
+
+// ParseAstats reads the statistics astats prints when its run ends. With
+// several astats instances in one graph the first one is read.
+func ParseAstats(output string) (AstatsReport, error) {
+	reports, order := parseAstatsInstances(output)
+	if len(order) == 0 {
+		return AstatsReport{}, ErrNoAstats
+	}
+	return reports[order[0]], nil
+}
+
+// parseAstatsInstances reads the statistics of every astats instance in
+// the output, keyed by instance name, and the names in order of appearance
+func parseAstatsInstances(output string) (map[string]AstatsReport, []string) {
+	reports := make(map[string]AstatsReport)
+	var order []string
+	sections := make(map[string]StatSection)
+
+	for _, line := range strings.Split(output, "\n") {
+		name, message := filterLine(line)
+		if !strings.Contains(name, "astats") {
+			continue
+		}
+		report, seen := reports[name]
+		if !seen {
+			report.Overall = StatSection{}
+			order = append(order, name)
+		}
+
+		switch {
+		case strings.HasPrefix(message, "Channel:"):
+			sections[name] = StatSection{}
+			report.Channels = append(report.Channels, sections[name])
+		case message == "Overall":
+			sections[name] = report.Overall
+		case sections[name] != nil:
+			if label, value, ok := splitStat(message); ok {
+				sections[name][label] = value
+			}
+		}
+		reports[name] = report
+	}
+	return reports, order
+}
+
+// Ebur128Summary is the summary ebur128 prints when its run ends
+type Ebur128Summary struct {
+	Integrated float64
+	Threshold  float64
+	LRA        float64
+	LRALow     float64
+	LRAHigh    float64
+	// TruePeak is only measured with peak=true, see HasPeak
+	TruePeak float64
+	HasPeak  bool
+}
+
+// leadingFloat reads the number before the unit in "-23.1 LUFS"
+func leadingFloat(value string) (float64, error) {
+	number, _, _ := strings.Cut(value, " ")
+	return strconv.ParseFloat(number, 64)
+}
+
+// ParseEbur128Summary reads the last summary in FFmpeg output
+func ParseEbur128Summary(output string) (Ebur128Summary, error) {
+	var summary Ebur128Summary
+	lines := strings.Split(output, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if _, message := filterLine(line); message == "Summary:" {
+			start = i
+		}
+	}
+	if start < 0 {
+		return summary, ErrNoSummary
+	}
+
+	var group string
+	found := false
+	for _, line := range lines[start+1:] {
+		_, message := filterLine(line)
+		label, value, ok := splitStat(strings.TrimSpace(message))
+		if !ok {
+			continue
+		}
+		if value == "" {
+			group = label
+			continue
+		}
+		number, err := leadingFloat(value)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case group == "Integrated loudness" && label == "I":
+			summary.Integrated = number
+			found = true
+		case group == "Integrated loudness" && label == "Threshold":
+			summary.Threshold = number
+		case group == "Loudness range" && label == "LRA":
+			summary.LRA = number
+		case group == "Loudness range" && label == "LRA low":
+			summary.LRALow = number
+		case group == "Loudness range" && label == "LRA high":
+			summary.LRAHigh = number
+		case group == "True peak" && label == "Peak":
+			summary.TruePeak = number
+			summary.HasPeak = true
+		}
+	}
+
+	if !found {
+		return summary, ErrNoSummary
+	}
+	return summary, nil
+}
+
+// Ebur128Frame is one line of ebur128's frame log
+type Ebur128Frame struct {
+	Time      float64
+	Momentary float64
+	ShortTerm float64
+}
+
+// ParseEbur128Frame reads "t: 1.2  TARGET:-23 LUFS  M: -24.1 S: -25.0 ...",
+// ok is false for any other line
+func ParseEbur128Frame(line string) (Ebur128Frame, bool) {
+	_, message := filterLine(line)
+	fields := strings.Fields(strings.ReplaceAll(message, ":", ": "))
+
+	values := make(map[string]float64)
+	for i := 0; i+1 < len(fields); i++ {
+		if key, ok := strings.CutSuffix(fields[i], ":"); ok {
+			if v, err := strconv.ParseFloat(fields[i+1], 64); err == nil {
+				values[key] = v
+			}
+		}
+	}
+
+	t, okT := values["t"]
+	m, okM := values["M"]
+	s, okS := values["S"]
+	if !okT || !okM || !okS {
+		return Ebur128Frame{}, false
+	}
+	return Ebur128Frame{Time: t, Momentary: m, ShortTerm: s}, true
+}
+
+// loudnormValue is a number loudnorm prints as a JSON string, "-inf" for silence
+type loudnormValue float64
+
+func (v *loudnormValue) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return err
+	}
+	*v = loudnormValue(f)
+	return nil
+}
+
+// LoudnormStats is the measurement loudnorm prints with print_format=json
+type LoudnormStats struct {
+	InputI       float64
+	InputTP      float64
+	InputLRA     float64
+	InputThresh  float64
+	OutputI      float64
+	OutputTP     float64
+	OutputLRA    float64
+	OutputThresh float64
+	TargetOffset float64
+	// NormalizationType is "dynamic" or "linear"
+	NormalizationType string
+}
+
+// Map returns the measurement keyed like loudnorm's JSON, the form the
+// second pass and reports take it in
+func (s LoudnormStats) Map() map[string]string {
+	format := func(v float64) string {
+		if math.IsInf(v, -1) {
+			return "-inf"
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return map[string]string{
+		"input_i":            format(s.InputI),
+		"input_tp":           format(s.InputTP),
+		"input_lra":          format(s.InputLRA),
+		"input_thresh":       format(s.InputThresh),
+		"output_i":           format(s.OutputI),
+		"output_tp":          format(s.OutputTP),
+		"output_lra":         format(s.OutputLRA),
+		"output_thresh":      format(s.OutputThresh),
+		"target_offset":      format(s.TargetOffset),
+		"normalization_type": s.NormalizationType,
+	}
+}
+
+// ParseLoudnormJSON reads the measurement block loudnorm prints at the end
+// of a run with print_format=json
+func ParseLoudnormJSON(output string) (LoudnormStats, error) {
+	at := strings.LastIndex(output, `"input_i"`)
+	if at < 0 {
+		return LoudnormStats{}, ErrNoLoudnormLog
+	}
+	start := strings.LastIndex(output[:at], "{")
+	end := strings.Index(output[at:], "}")
+	if start < 0 || end < 0 {
+		return LoudnormStats{}, ErrNoLoudnormLog
+	}
+
+	var raw struct {
+		InputI            loudnormValue `json:"input_i"`
+		InputTP           loudnormValue `json:"input_tp"`
+		InputLRA          loudnormValue `json:"input_lra"`
+		InputThresh       loudnormValue `json:"input_thresh"`
+		OutputI           loudnormValue `json:"output_i"`
+		OutputTP          loudnormValue `json:"output_tp"`
+		OutputLRA         loudnormValue `json:"output_lra"`
+		OutputThresh      loudnormValue `json:"output_thresh"`
+		NormalizationType string        `json:"normalization_type"`
+		TargetOffset      loudnormValue `json:"target_offset"`
+	}
+	if err := json.Unmarshal([]byte(output[start:at+end+1]), &raw); err != nil {
+		return LoudnormStats{}, fmt.Errorf("%w: %v", ErrNoLoudnormLog, err)
+	}
+
+	return LoudnormStats{
+		InputI:            float64(raw.InputI),
+		InputTP:           float64(raw.InputTP),
+		InputLRA:          float64(raw.InputLRA),
+		InputThresh:       float64(raw.InputThresh),
+		OutputI:           float64(raw.OutputI),
+		OutputTP:          float64(raw.OutputTP),
+		OutputLRA:         float64(raw.OutputLRA),
+		OutputThresh:      float64(raw.OutputThresh),
+		TargetOffset:      float64(raw.TargetOffset),
+		NormalizationType: raw.NormalizationType,
+	}, nil
+}
@@ -26,6 +26,8 @@ type DynamicsScoreAnalysis struct {
 	RMSLevel      float64
 	CrestFactor   float64
 	DynamicsScore float64
+	// Confidence flags values that weren't parsed or are implausible
+	Confidence Confidence
 }
 
 // CompressionModifiers holds multipliers applied based on Dynamics Score
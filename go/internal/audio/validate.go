@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MinDuration is the shortest input in seconds accepted for processing
+const MinDuration = 0.1
+
+var (
+	ErrEmptyFile  = errors.New("file is empty (0 bytes)")
+	ErrProtected  = errors.New("file is DRM protected and cannot be decoded")
+	ErrNoAudio    = errors.New("file contains no audio stream")
+	ErrUnreadable = errors.New("file is not a readable audio file")
+	ErrTooShort   = errors.New("file is too short to process")
+	ErrNoDuration = errors.New("file reports no duration")
+)
+
+// ValidateInput checks that a file can be processed before it is queued.
+// The returned error carries the reason the file was refused.
+func ValidateInput(inputPath string) error {
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return ErrEmptyFile
+	}
+
+	// FairPlay protected iTunes purchases
+	if strings.ToLower(filepath.Ext(inputPath)) == ".m4p" {
+		return ErrProtected
+	}
+
+	media, err := Inspect(inputPath)
+	if err != nil {
+		return ErrUnreadable
+	}
+
+	if media.Protected() {
+		return ErrProtected
+	}
+	if !media.HasAudio {
+		return ErrNoAudio
+	}
+	if media.HasDuration && media.Duration < MinDuration {
+		return ErrTooShort
+	}
+
+	return nil
+}
+
+// ProbeDuration returns the duration the input's container reports,
+// without decoding the file
+func ProbeDuration(inputPath string) (float64, error) {
+	media, err := Inspect(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	if !media.HasDuration {
+		return 0, ErrNoDuration
+	}
+	return media.Duration, nil
+}
@@ -0,0 +1,38 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// waveformSampleRate keeps the decode of an hour-long file small, the
+// envelope drawn on screen doesn't need more
+const waveformSampleRate = 2000
+
+var ErrEmptyAudio = errors.New("no audio decoded")
+
+// Waveform returns the peak level, 0 to 1, of each of points equal slices
+// of a file, and the file's duration in seconds
+func Waveform(path string, points int) ([]float64, float64, error) {
+	raw, err := ffmpeg.Output("-i", path, "-vn", "-ac", "1", "-ar", strconv.Itoa(waveformSampleRate), "-f", "f32le", "-")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	samples := len(raw) / 4
+	if samples == 0 || points <= 0 {
+		return nil, 0, ErrEmptyAudio
+	}
+
+	peaks := make([]float64, points)
+	for i := range samples {
+		v := math.Abs(float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))))
+		p := i * points / samples
+		peaks[p] = max(peaks[p], min(v, 1))
+	}
+	return peaks, float64(samples) / waveformSampleRate, nil
+}
@@ -1,16 +1,22 @@
 package config
 
+import (
+	"math"
+	"slices"
+)
+
 // CodecMap maps UI codec names to FFmpeg encoder names
 // This is shared across all platforms
 var CodecMap = map[string]string{
-	"Opus":                          "libopus",
-	"AAC":                           "libfdk_aac",
-	"MPEG-II L3":                    "libmp3lame",
-	"PCM":                           "PCM",
-	"FLAC":                          "flac",
-	"Small file (AAC 256kbps)":      "libfdk_aac",
-	"Most compatible (MP3 160kbps)": "libmp3lame",
-	"Production (PCM 48kHz/24bit)":  "PCM",
+	"Opus":                           "libopus",
+	"AAC":                            "libfdk_aac",
+	"MPEG-II L3":                     "libmp3lame",
+	"PCM":                            "PCM",
+	"FLAC":                           "flac",
+	"Small file (AAC 256kbps)":       "libfdk_aac",
+	"Most compatible (MP3 320kbps)":  "libmp3lame",
+	"Production (PCM 48kHz/24bit)":   "PCM",
+	"Digitization (PCM 96kHz/24bit)": "PCM",
 }
 
 // GetCodec returns the FFmpeg encoder name for a given UI codec name
@@ -20,3 +26,90 @@ func GetCodec(uiName string) string {
 	}
 	return uiName
 }
+
+// Codec describes how an output codec is encoded, stored and configured.
+// Adding a codec to Codecs and a UI name to CodecMap is enough for the UI
+// and the pipeline to offer it.
+type Codec struct {
+	// Encoder is the FFmpeg encoder, PCM picks a pcm_* encoder by bit depth
+	Encoder string
+	Ext     string
+	// Muxer is the FFmpeg muxer of Ext; "ipod" outputs carry their tags
+	// only with -movflags use_metadata_tags
+	Muxer string
+	// Lossy encoders discard audio information, their outputs can be scored
+	Lossy bool
+	// Bitrate codecs take -b:a, in kbps between MinKbps and MaxKbps
+	Bitrate bool
+	MinKbps int
+	MaxKbps int
+	// CompressionLevels is the highest -compression_level, 0 when the codec
+	// has none. Inverted levels trade quality, higher meaning less compression.
+	CompressionLevels int
+	InvertedLevels    bool
+	// BitDepth and SampleRate codecs are written at the chosen depth and
+	// rate, the others at 48 kHz
+	BitDepth   bool
+	SampleRate bool
+	// Rates are the only sample rates the encoder takes, nil for any
+	Rates []int
+}
+
+// Codecs is the registry of output codecs by encoder name
+var Codecs = map[string]Codec{
+	"libopus":    {Encoder: "libopus", Ext: ".opus", Muxer: "opus", Lossy: true, Bitrate: true, MinKbps: 12, MaxKbps: 510, CompressionLevels: 10, InvertedLevels: true, Rates: []int{48000, 24000, 16000, 12000, 8000}},
+	"libfdk_aac": {Encoder: "libfdk_aac", Ext: ".m4a", Muxer: "ipod", Lossy: true, Bitrate: true, MinKbps: 12, MaxKbps: 512},
+	"aac":        {Encoder: "aac", Ext: ".m4a", Muxer: "ipod", Lossy: true, Bitrate: true, MinKbps: 12, MaxKbps: 512},
+	"aac_at":     {Encoder: "aac_at", Ext: ".m4a", Muxer: "ipod", Lossy: true, Bitrate: true, MinKbps: 12, MaxKbps: 320},
+	"libmp3lame": {Encoder: "libmp3lame", Ext: ".mp3", Muxer: "mp3", Lossy: true, Bitrate: true, MinKbps: 12, MaxKbps: 320},
+	"mp2":        {Encoder: "mp2", Ext: ".mp2", Muxer: "mp2", Lossy: true, Bitrate: true, MinKbps: 32, MaxKbps: 384},
+	"PCM":        {Encoder: "PCM", Ext: ".wav", Muxer: "wav", BitDepth: true, SampleRate: true},
+	"flac":       {Encoder: "flac", Ext: ".flac", Muxer: "flac", CompressionLevels: 12},
+}
+
+// LookupCodec returns the registry entry of an encoder
+func LookupCodec(encoder string) (Codec, bool) {
+	codec, ok := Codecs[encoder]
+	return codec, ok
+}
+
+// copyMuxers are the muxers of containers TNT doesn't encode to but writes
+// when it copies an input's stream, e.g. to only retag it
+var copyMuxers = map[string]string{
+	".ogg":  "ogg",
+	".aac":  "adts",
+	".wma":  "asf",
+	".aiff": "aiff",
+	".aif":  "aiff",
+}
+
+// MuxerForExt returns the FFmpeg muxer that writes a lowercase extension,
+// or "" when unknown
+func MuxerForExt(ext string) string {
+	for _, codec := range Codecs {
+		if codec.Ext == ext {
+			return codec.Muxer
+		}
+	}
+	return copyMuxers[ext]
+}
+
+// CompressionLevel maps the UI's 0-10 data compression setting, 0 being
+// off, to the codec's -compression_level
+func (c Codec) CompressionLevel(setting int) int {
+	level := int(math.Round(float64(setting) * float64(c.CompressionLevels) / 10.0))
+	if c.InvertedLevels {
+		level = c.CompressionLevels - level
+	}
+	return level
+}
+
+// SupportsRate reports whether the encoder takes a sample rate as it is
+func (c Codec) SupportsRate(rate int) bool {
+	return c.Rates == nil || slices.Contains(c.Rates, rate)
+}
+
+// ClampKbps keeps a bitrate within the codec's limits
+func (c Codec) ClampKbps(kbps int) int {
+	return min(max(kbps, c.MinKbps), c.MaxKbps)
+}
@@ -0,0 +1,51 @@
+package ffmpeg
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// Limits on the CPU FFmpeg may take, set from the preferences. Every run
+// started afterwards follows them.
+var (
+	threads     atomic.Int32
+	lowPriority atomic.Bool
+)
+
+// SetThreads limits the decoding, filtering and encoding of every run to n
+// threads. 0 leaves the thread count to FFmpeg, which uses every core.
+func SetThreads(n int) {
+	threads.Store(int32(max(n, 0)))
+}
+
+// SetLowPriority starts every run at a lower CPU priority, so the machine
+// stays usable while a large batch runs
+func SetLowPriority(low bool) {
+	lowPriority.Store(low)
+}
+
+// threadArgs adds the thread limit to a run that reads an input: for its
+// filters, for the decoder of each input and for the encoder of its output,
+// the last argument
+func threadArgs(args []string) []string {
+	n := threads.Load()
+	if n <= 0 || len(args) < 2 {
+		return args
+	}
+	count := strconv.Itoa(int(n))
+
+	out := make([]string, 0, len(args)+8)
+	out = append(out, "-filter_threads", count, "-filter_complex_threads", count)
+	hasInput := false
+	for i, arg := range args {
+		if arg == "-i" && i+1 < len(args) || i == len(args)-1 && hasInput {
+			out = append(out, "-threads", count)
+		}
+		hasInput = hasInput || arg == "-i"
+		out = append(out, arg)
+	}
+	if !hasInput {
+		return args
+	}
+	return out
+}
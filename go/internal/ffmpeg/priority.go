@@ -0,0 +1,86 @@
+package ffmpeg
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// Interactive jobs, such as a quick normalize started while a batch runs,
+// take the CPU from background runs until they finish. The counters are
+// guarded by activeMutex.
+var (
+	interactive  int
+	yieldStarted time.Time
+	// yieldTotal is how long background runs have yielded, not counting the current yield
+	yieldTotal time.Duration
+	yieldDone  = make(chan struct{})
+)
+
+// BeginInteractive makes background runs yield until the returned function is
+// called. Runs that are already going are paused or lowered, and background
+// runs started in the meantime yield from the start.
+func BeginInteractive() (end func()) {
+	activeMutex.Lock()
+	interactive++
+	if interactive == 1 {
+		yieldStarted = time.Now()
+		yieldDone = make(chan struct{})
+		setYield(true)
+	}
+	activeMutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			activeMutex.Lock()
+			defer activeMutex.Unlock()
+
+			interactive--
+			if interactive == 0 {
+				yieldTotal += time.Since(yieldStarted)
+				setYield(false)
+				close(yieldDone)
+			}
+		})
+	}
+}
+
+// setYield pauses or resumes every background run, the caller holds activeMutex.
+// Resumed runs go back to low priority when the preferences ask for it.
+func setYield(yield bool) {
+	for cmd, background := range active {
+		if background {
+			platform.YieldProcessGroup(cmd, yield)
+			if !yield && lowPriority.Load() {
+				platform.LowerProcessPriority(cmd)
+			}
+		}
+	}
+}
+
+// WaitInteractive blocks while an interactive job runs, so background work
+// doesn't start anything new until it is done
+func WaitInteractive() {
+	activeMutex.Lock()
+	if interactive == 0 {
+		activeMutex.Unlock()
+		return
+	}
+	done := yieldDone
+	activeMutex.Unlock()
+	<-done
+}
+
+// yielded returns how long background runs have yielded in total, so run
+// limits can leave that time out
+func yielded() time.Duration {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	if interactive > 0 {
+		return yieldTotal + time.Since(yieldStarted)
+	}
+	return yieldTotal
+}
@@ -0,0 +1,84 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+var ErrProbe = errors.New("ffprobe could not read the file")
+
+// ProbeResult is what ffprobe reports about a file's container and streams
+type ProbeResult struct {
+	Format  ProbeFormat   `json:"format"`
+	Streams []ProbeStream `json:"streams"`
+}
+
+// ProbeFormat is the container part of ffprobe's report
+type ProbeFormat struct {
+	FormatName string            `json:"format_name"`
+	Duration   string            `json:"duration"`
+	BitRate    string            `json:"bit_rate"`
+	Tags       map[string]string `json:"tags"`
+}
+
+// ProbeStream is one stream of ffprobe's report. Numbers ffprobe prints
+// as strings are kept as strings and read through the accessors.
+type ProbeStream struct {
+	Index            int               `json:"index"`
+	CodecType        string            `json:"codec_type"`
+	CodecName        string            `json:"codec_name"`
+	CodecTagString   string            `json:"codec_tag_string"`
+	SampleFmt        string            `json:"sample_fmt"`
+	SampleRate       string            `json:"sample_rate"`
+	Channels         int               `json:"channels"`
+	ChannelLayout    string            `json:"channel_layout"`
+	BitsPerRawSample string            `json:"bits_per_raw_sample"`
+	Duration         string            `json:"duration"`
+	Disposition      map[string]int    `json:"disposition"`
+	Tags             map[string]string `json:"tags"`
+}
+
+// Seconds returns the container duration, ok is false when it reports none
+func (f ProbeFormat) Seconds() (float64, bool) {
+	d, err := strconv.ParseFloat(f.Duration, 64)
+	return d, err == nil
+}
+
+// Rate returns the stream's sample rate in Hz, 0 when it has none
+func (s ProbeStream) Rate() int {
+	rate, _ := strconv.Atoi(s.SampleRate)
+	return rate
+}
+
+// Probe runs ffprobe on a file and decodes its JSON report. Nothing is
+// decoded, only the headers are read.
+func Probe(path string) (*ProbeResult, error) {
+	cmd := exec.Command(ProbePath, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", pathArg(path))
+	platform.HideWindow(cmd)
+	platform.PrepareProcessGroup(cmd)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := start(cmd, false); err != nil {
+		return nil, err
+	}
+	defer finish(cmd)
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrProbe, Tail(stderr.Bytes(), 3))
+	}
+
+	var result ProbeResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProbe, err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,66 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os/exec"
+	"sync"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// active holds every FFmpeg process that is currently running, and whether
+// it is background work that yields to interactive jobs
+var (
+	activeMutex sync.Mutex
+	active      = make(map[*exec.Cmd]bool)
+)
+
+// start launches the command in its own process group and tracks it until finish
+func start(cmd *exec.Cmd, background bool) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// A run without a group would leave FFmpeg's children behind when it is
+	// cancelled, so it doesn't go ahead
+	if err := platform.AttachProcessGroup(cmd); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("process group: %w", err)
+	}
+	if lowPriority.Load() {
+		platform.LowerProcessPriority(cmd)
+	}
+
+	activeMutex.Lock()
+	active[cmd] = background
+	if background && interactive > 0 {
+		platform.YieldProcessGroup(cmd, true)
+	}
+	activeMutex.Unlock()
+	return nil
+}
+
+// finish stops tracking a command after Wait has returned
+func finish(cmd *exec.Cmd) {
+	activeMutex.Lock()
+	delete(active, cmd)
+	activeMutex.Unlock()
+
+	platform.ReleaseProcessGroup(cmd)
+}
+
+// Kill terminates an FFmpeg process together with any children it spawned
+func Kill(cmd *exec.Cmd) error {
+	return platform.KillProcessGroup(cmd)
+}
+
+// KillAll terminates every running FFmpeg process, used when TNT quits
+func KillAll() {
+	activeMutex.Lock()
+	defer activeMutex.Unlock()
+
+	for cmd := range active {
+		platform.KillProcessGroup(cmd)
+	}
+}
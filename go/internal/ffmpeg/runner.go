@@ -1,46 +1,187 @@
 package ffmpeg
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/fremen-fi/tnt/go/platform"
 )
 
 var Path string
 
+// ProbePath is the ffprobe binary shipped next to FFmpeg
+var ProbePath string
+
 func init() {
-	Path = extractFFmpeg()
+	Path = extractBinary("ffmpeg", platform.FFmpegBinary)
+	ProbePath = extractBinary("ffprobe", platform.FFprobeBinary)
 }
 
-// extractFFmpeg writes the embedded FFmpeg binary to a temp location and returns the path
-func extractFFmpeg() string {
+// extractBinary writes an embedded binary to a temp location and returns the path
+func extractBinary(name string, data []byte) string {
 	tmpDir := os.TempDir()
 
-	var name string
 	if runtime.GOOS == "windows" {
-		name = "ffmpeg.exe"
-	} else {
-		name = "ffmpeg"
+		name += ".exe"
 	}
 
-	ffmpegPath := filepath.Join(tmpDir, name)
-	os.WriteFile(ffmpegPath, platform.FFmpegBinary, 0755)
-	return ffmpegPath
+	path := filepath.Join(tmpDir, name)
+	os.WriteFile(path, data, 0755)
+	return path
 }
 
 // Command creates an exec.Cmd for FFmpeg with the given arguments
 // It automatically applies platform-specific settings (like hiding console on Windows)
+// and the thread limit, see SetThreads. It only runs once start has put it in
+// its process group, on Windows it starts suspended until then.
 func Command(args ...string) *exec.Cmd {
-	cmd := exec.Command(Path, args...)
+	cmd := exec.Command(Path, pathSafeArgs(threadArgs(args))...)
+	platform.HideWindow(cmd)
+	platform.PrepareProcessGroup(cmd)
+	return cmd
+}
+
+// CommandContext is Command for runs that stop when ctx is cancelled. The
+// whole process group is killed, not only FFmpeg itself.
+func CommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, Path, pathSafeArgs(threadArgs(args))...)
+	cmd.Cancel = func() error { return Kill(cmd) }
 	platform.HideWindow(cmd)
+	platform.PrepareProcessGroup(cmd)
 	return cmd
 }
 
-// Run executes FFmpeg with the given arguments and returns combined output
+// flagOptions are the options that take no value. Every other option takes
+// the argument after it.
+var flagOptions = map[string]bool{
+	"-y": true, "-n": true, "-vn": true, "-an": true, "-sn": true, "-dn": true,
+	"-hide_banner": true, "-nostdin": true, "-stats": true, "-nostats": true,
+	"-xerror": true, "-shortest": true, "-copyts": true, "-re": true,
+	"-accurate_seek": true, "-noaccurate_seek": true,
+	"-version": true, "-filters": true, "-encoders": true, "-decoders": true, "-formats": true,
+}
+
+// pathOptions are the options whose value is a file
+var pathOptions = map[string]bool{
+	"-i": true, "-passlogfile": true, "-attach": true, "-progress": true,
+	"-filter_script": true, "-filter_complex_script": true, "-vstats_file": true, "-sdp_file": true,
+}
+
+// pathSafeArgs makes every file argument safe to pass to FFmpeg: the values
+// of the options that take a file and every output, the arguments that are
+// neither an option nor its value, and the last unless it's a flag. The input of the
+// lavfi format is a filter graph, not a file. Arguments are passed to FFmpeg
+// as-is, never joined into a shell string.
+func pathSafeArgs(args []string) []string {
+	safe := make([]string, len(args))
+	copy(safe, args)
+
+	// -f applies to the next input or output only
+	format := ""
+	for i := 0; i < len(safe); i++ {
+		option := safe[i]
+		switch {
+		case !isOption(option) || i == len(safe)-1 && !flagOptions[option]:
+			safe[i] = pathArg(option)
+			format = ""
+		case flagOptions[option]:
+		case i+1 < len(safe):
+			i++
+			switch {
+			case option == "-f":
+				format = safe[i]
+			case option == "-i" && format == "lavfi":
+				format = ""
+			case pathOptions[option]:
+				safe[i] = pathArg(safe[i])
+				if option == "-i" {
+					format = ""
+				}
+			}
+		}
+	}
+	return safe
+}
+
+// isOption reports whether an argument is an option rather than a file whose
+// name starts with a dash: option names are letters, digits, underscores and
+// stream specifiers
+func isOption(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	return strings.IndexFunc(arg[1:], func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':')
+	}) < 0
+}
+
+// pathArg returns a file path in its long-path form, behind the file:
+// protocol when FFmpeg would read it as an option, with its leading dash, or
+// as a protocol, with a colon in its first part like "Show: Episode 1.wav".
+// Standard input and output, pipes and URLs are left alone.
+func pathArg(path string) string {
+	if path == "-" || strings.HasPrefix(path, "pipe:") || strings.HasPrefix(path, "file:") || strings.Contains(path, "://") {
+		return path
+	}
+	path = platform.LongPath(path)
+	if strings.HasPrefix(path, "-") || readsAsProtocol(path) {
+		return "file:" + path
+	}
+	return path
+}
+
+// readsAsProtocol reports whether FFmpeg would take the start of path up to
+// a colon for a protocol name. A drive letter is a path on Windows.
+func readsAsProtocol(path string) bool {
+	name, _, found := strings.Cut(path, ":")
+	if !found || name == "" || filepath.VolumeName(path) != "" {
+		return false
+	}
+	return strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.')
+	}) < 0
+}
+
+// Run executes FFmpeg with the given arguments and returns combined output.
+// The process is tracked so KillAll can stop it when TNT quits.
 func Run(args ...string) ([]byte, error) {
 	cmd := Command(args...)
-	return cmd.CombinedOutput()
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := start(cmd, false); err != nil {
+		return nil, err
+	}
+	defer finish(cmd)
+
+	err := cmd.Wait()
+	return output.Bytes(), err
+}
+
+// Output executes FFmpeg and returns only what it writes to stdout, for
+// decoding raw audio through a pipe. stderr is returned as part of the error.
+func Output(args ...string) ([]byte, error) {
+	cmd := Command(args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := start(cmd, false); err != nil {
+		return nil, err
+	}
+	defer finish(cmd)
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, Tail(stderr.Bytes(), 3))
+	}
+	return stdout.Bytes(), nil
 }
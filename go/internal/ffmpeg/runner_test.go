@@ -0,0 +1,90 @@
+package ffmpeg
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+func TestPathSafeArgs(t *testing.T) {
+	long := "/archive/" + strings.Repeat("Ääniarkisto ", 25) + "/ohjelma.wav"
+	if len(long) <= 260 {
+		t.Fatalf("long path is only %d characters", len(long))
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "unicode",
+			args: []string{"-i", "/in/🎙️ Haastattelu.wav", "-af", "volume=2", "-y", "/out/مقابلة ✓.mp3"},
+			want: []string{"-i", platform.LongPath("/in/🎙️ Haastattelu.wav"), "-af", "volume=2", "-y", platform.LongPath("/out/مقابلة ✓.mp3")},
+		},
+		{
+			name: "leading dash",
+			args: []string{"-i", "-take 1.wav", "-y", "-take 1_norm.wav"},
+			want: []string{"-i", "file:-take 1.wav", "-y", "file:-take 1_norm.wav"},
+		},
+		{
+			name: "colon reads as protocol",
+			args: []string{"-i", "News: 12.00.wav", "out.wav"},
+			want: []string{"-i", "file:News: 12.00.wav", "out.wav"},
+		},
+		{
+			name: "quotes",
+			args: []string{"-i", `/in/It's "live".wav`, "-metadata", `title="Live" -23`, `/out/It's "live".mp3`},
+			want: []string{"-i", platform.LongPath(`/in/It's "live".wav`), "-metadata", `title="Live" -23`, platform.LongPath(`/out/It's "live".mp3`)},
+		},
+		{
+			name: "long path",
+			args: []string{"-i", long, "-y", long + ".mp3"},
+			want: []string{"-i", platform.LongPath(long), "-y", platform.LongPath(long + ".mp3")},
+		},
+		{
+			name: "secondary outputs and pass log",
+			args: []string{"-i", "-in.wav", "-passlogfile", "-log", "-map", "0:a", "-a.mp3", "-map", "0:a", "-b.ogg"},
+			want: []string{"-i", "file:-in.wav", "-passlogfile", "file:-log", "-map", "0:a", "file:-a.mp3", "-map", "0:a", "file:-b.ogg"},
+		},
+		{
+			name: "pipes, null output and lavfi",
+			args: []string{"-f", "lavfi", "-i", "sine=frequency=880:duration=1", "-i", "pipe:0", "-progress", "pipe:1", "-f", "null", "-"},
+			want: []string{"-f", "lavfi", "-i", "sine=frequency=880:duration=1", "-i", "pipe:0", "-progress", "pipe:1", "-f", "null", "-"},
+		},
+		{
+			name: "no files",
+			args: []string{"-hide_banner", "-version"},
+			want: []string{"-hide_banner", "-version"},
+		},
+		{
+			name: "negative option values",
+			args: []string{"-i", "in.wav", "-ss", "-5", "-af", "volume=-3dB", "out.wav"},
+			want: []string{"-i", "in.wav", "-ss", "-5", "-af", "volume=-3dB", "out.wav"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathSafeArgs(tt.args); !slices.Equal(got, tt.want) {
+				t.Errorf("pathSafeArgs(%q)\n got %q\nwant %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathSafeArgsAfterThreadArgs(t *testing.T) {
+	SetThreads(2)
+	defer SetThreads(0)
+
+	got := pathSafeArgs(threadArgs([]string{"-i", "-in.wav", "-y", "-out.wav"}))
+	want := []string{
+		"-filter_threads", "2", "-filter_complex_threads", "2",
+		"-threads", "2", "-i", "file:-in.wav", "-y", "-threads", "2", "file:-out.wav",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
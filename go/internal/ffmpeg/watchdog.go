@@ -0,0 +1,229 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTimeout   = errors.New("ffmpeg exceeded the job time limit")
+	ErrStalled   = errors.New("ffmpeg stopped making progress")
+	ErrCancelled = errors.New("ffmpeg run was cancelled")
+)
+
+// Watchdog limits how long a single FFmpeg run may take
+type Watchdog struct {
+	Timeout      time.Duration // Overall limit for the run, 0 disables it
+	StallTimeout time.Duration // Longest allowed gap between progress updates, 0 disables it
+	// Background runs yield to interactive jobs, see BeginInteractive. Time
+	// spent yielding doesn't count against the limits.
+	Background bool
+	// ReadRate paces the reading of every input to this many times realtime,
+	// trading speed for a quieter machine. 0 reads at full speed.
+	ReadRate float64
+	// Context cancels the run, killing FFmpeg. Nil never cancels.
+	Context context.Context
+	// Length is the duration of the input, 0 when unknown
+	Length time.Duration
+	// Progress is called with FFmpeg's position in the output as it advances
+	Progress func(position time.Duration)
+}
+
+// streamTailLines is how much of a streamed run's stderr is kept for diagnostics
+const streamTailLines = 40
+
+// Run executes FFmpeg with progress reporting on stdout and kills it when the
+// overall timeout passes or the reported position stops advancing for
+// StallTimeout. The returned output is FFmpeg's stderr, kept for diagnostics.
+func (w Watchdog) Run(args ...string) ([]byte, error) {
+	return w.run(nil, args)
+}
+
+// Stream is Run for runs that log too much to hold in memory, such as a
+// frame log of a file many hours long. Every stderr line goes to onLine as it
+// arrives, and only the last lines are returned.
+func (w Watchdog) Stream(onLine func(string), args ...string) ([]byte, error) {
+	return w.run(onLine, args)
+}
+
+func (w Watchdog) run(onLine func(string), args []string) ([]byte, error) {
+	full := []string{"-nostdin", "-progress", "pipe:1"}
+	if onLine != nil {
+		// The status line is rewritten with carriage returns and never ends
+		full = append(full, "-nostats")
+	}
+	ctx := w.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Err() != nil {
+		return nil, ErrCancelled
+	}
+	cmd := CommandContext(ctx, append(full, w.paced(args)...)...)
+
+	var stderr bytes.Buffer
+	var stderrPipe io.Reader
+	if onLine == nil {
+		cmd.Stderr = &stderr
+	} else {
+		pipe, err := cmd.StderrPipe()
+		if err != nil {
+			return nil, err
+		}
+		stderrPipe = pipe
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := start(cmd, w.Background); err != nil {
+		return nil, err
+	}
+	defer finish(cmd)
+
+	// Wait closes the pipes, so every reader has to be done before it is called
+	var readers sync.WaitGroup
+	if stderrPipe != nil {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			var tail []string
+			scanner := bufio.NewScanner(stderrPipe)
+			scanner.Split(scanLogLines)
+			for scanner.Scan() {
+				line := scanner.Text()
+				onLine(line)
+				if tail = append(tail, line); len(tail) > streamTailLines {
+					tail = tail[1:]
+				}
+			}
+			stderr.WriteString(strings.Join(tail, "\n"))
+		}()
+	}
+
+	progress := make(chan struct{}, 1)
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		scanner := bufio.NewScanner(stdout)
+		last := ""
+		for scanner.Scan() {
+			line := scanner.Text()
+			// out_time_us only changes while FFmpeg is actually moving through the file
+			if strings.HasPrefix(line, "out_time_us=") && line != last {
+				last = line
+				select {
+				case progress <- struct{}{}:
+				default:
+				}
+				if us, err := strconv.ParseInt(strings.TrimPrefix(line, "out_time_us="), 10, 64); err == nil && w.Progress != nil {
+					w.Progress(time.Duration(us) * time.Microsecond)
+				}
+			}
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		readers.Wait()
+		done <- cmd.Wait()
+	}()
+
+	yieldedAtStart := yielded()
+	yieldedAtProgress := yieldedAtStart
+	var extended time.Duration
+
+	var deadline <-chan time.Time
+	var limit *time.Timer
+	if w.Timeout > 0 {
+		limit = time.NewTimer(w.Timeout)
+		defer limit.Stop()
+		deadline = limit.C
+	}
+
+	var stall *time.Timer
+	var stalled <-chan time.Time
+	if w.StallTimeout > 0 {
+		stall = time.NewTimer(w.StallTimeout)
+		defer stall.Stop()
+		stalled = stall.C
+	}
+
+	for {
+		select {
+		case err := <-done:
+			if ctx.Err() != nil {
+				return stderr.Bytes(), ErrCancelled
+			}
+			return stderr.Bytes(), err
+		case <-progress:
+			if stall != nil {
+				stall.Reset(w.StallTimeout)
+			}
+			yieldedAtProgress = yielded()
+		case <-deadline:
+			if paused := yielded() - yieldedAtStart; w.Background && paused > extended {
+				limit.Reset(paused - extended)
+				extended = paused
+				continue
+			}
+			Kill(cmd)
+			<-done
+			return stderr.Bytes(), ErrTimeout
+		case <-stalled:
+			// A paused run makes no progress, that's no reason to kill it
+			if now := yielded(); w.Background && now > yieldedAtProgress {
+				yieldedAtProgress = now
+				stall.Reset(w.StallTimeout)
+				continue
+			}
+			Kill(cmd)
+			<-done
+			return stderr.Bytes(), ErrStalled
+		}
+	}
+}
+
+// scanLogLines splits FFmpeg's log at either line ending
+func scanLogLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Tail returns the last n lines of FFmpeg output for failure diagnostics
+func Tail(output []byte, n int) string {
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paced adds the read rate before every input, -readrate is an input option
+func (w Watchdog) paced(args []string) []string {
+	if w.ReadRate <= 0 {
+		return args
+	}
+	rate := strconv.FormatFloat(w.ReadRate, 'f', -1, 64)
+	out := make([]string, 0, len(args)+4)
+	for i, arg := range args {
+		if arg == "-i" && i+1 < len(args) {
+			out = append(out, "-readrate", rate)
+		}
+		out = append(out, arg)
+	}
+	return out
+}
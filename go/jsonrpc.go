@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/internal/license"
+)
+
+const (
+	// rpcQueueSize is how many jobs can wait behind the running one
+	rpcQueueSize = 1000
+	// rpcMaxLine bounds a request line, a job can list thousands of files
+	rpcMaxLine = 16 << 20
+)
+
+var (
+	ErrRPCMethod    = errors.New("unknown method")
+	ErrRPCNoFiles   = errors.New("no files to process")
+	ErrRPCQueueFull = errors.New("job queue is full")
+	ErrRPCPreset    = errors.New("no saved preset by that name")
+)
+
+// rpcRequest is one line read from stdin. The id is echoed back in the
+// response and can be any JSON value.
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// rpcResponse answers a request, with either a result or an error
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// rpcEvent reports progress of the jobs, interleaved with the responses
+type rpcEvent struct {
+	Event   string         `json:"event"`
+	Job     int            `json:"job,omitempty"`
+	Files   int            `json:"files,omitempty"`
+	File    string         `json:"file,omitempty"`
+	Result  string         `json:"result,omitempty"`
+	Results map[string]int `json:"results,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+// rpcJob is the params of a process request. The preset is one saved in the
+// Presets tab, without one the saved preferences apply. The output folder
+// overrides both.
+type rpcJob struct {
+	Files     []string `json:"files"`
+	Preset    string   `json:"preset"`
+	OutputDir string   `json:"output_dir"`
+
+	id  int
+	cfg ProcessConfig
+}
+
+// rpcStatus is the result of a status request
+type rpcStatus struct {
+	Version string `json:"version"`
+	Profile string `json:"profile"`
+	Running int    `json:"running,omitempty"`
+	Queued  int    `json:"queued"`
+}
+
+// jsonRPC speaks line-delimited JSON over stdio for wrapper scripts, which
+// can queue jobs without the service's HTTP API
+type jsonRPC struct {
+	mutex   sync.Mutex
+	out     *json.Encoder
+	jobs    chan rpcJob
+	nextJob int
+	running int
+}
+
+// send writes one line to stdout
+func (r *jsonRPC) send(v any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.out.Encode(v)
+}
+
+// runJSONRPC runs jobs read from stdin until stdin closes or a shutdown
+// request arrives, then finishes the queued jobs and exits. Like the service
+// it uses widgets on the headless app, so jobs get the same settings and
+// pipeline as in the window.
+func runJSONRPC(args launchArgs) {
+	activeProfile = args.Profile
+
+	// Only protocol lines may reach stdout, anything else printed goes to stderr
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	n := newHeadlessNormalizer(args.Operator)
+	n.rpc = &jsonRPC{out: json.NewEncoder(stdout), jobs: make(chan rpcJob, rpcQueueSize)}
+
+	defer func() {
+		ffmpeg.KillAll()
+		n.releaseAllZips()
+		n.batchHistory.flush()
+		if n.logFile != nil {
+			n.logFile.Close()
+		}
+	}()
+
+	if err := n.license.check(license.FeatureAPI); err != nil {
+		n.rpc.send(rpcEvent{Event: "exit", Message: err.Error()})
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for job := range n.rpc.jobs {
+			n.runRPCJob(job)
+		}
+	}()
+
+	n.rpc.send(rpcEvent{Event: "ready", Message: fmt.Sprintf("TNT %s (%s)", currentVersion, profileLabel())})
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64<<10), rpcMaxLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !n.handleRPC(line) {
+			break
+		}
+	}
+
+	close(n.rpc.jobs)
+	<-done
+	n.rpc.send(rpcEvent{Event: "exit"})
+}
+
+// handleRPC answers one request line and reports whether to read on
+func (n *AudioNormalizer) handleRPC(line []byte) bool {
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		n.rpc.send(rpcResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return true
+	}
+
+	switch req.Method {
+	case "process":
+		job, err := n.queueRPCJob(req.Params)
+		if err != nil {
+			n.rpc.send(rpcResponse{ID: req.ID, Error: err.Error()})
+			return true
+		}
+		n.rpc.send(rpcResponse{ID: req.ID, Result: map[string]int{"job": job}})
+	case "status":
+		n.rpc.mutex.Lock()
+		status := rpcStatus{Version: currentVersion, Profile: profileLabel(), Running: n.rpc.running, Queued: len(n.rpc.jobs)}
+		n.rpc.mutex.Unlock()
+		n.rpc.send(rpcResponse{ID: req.ID, Result: status})
+	case "shutdown":
+		n.rpc.send(rpcResponse{ID: req.ID, Result: map[string]int{"queued": len(n.rpc.jobs)}})
+		return false
+	default:
+		n.rpc.send(rpcResponse{ID: req.ID, Error: fmt.Sprintf("%v: %q", ErrRPCMethod, req.Method)})
+	}
+	return true
+}
+
+// queueRPCJob checks a process request and queues it, returning the job number
+func (n *AudioNormalizer) queueRPCJob(params json.RawMessage) (int, error) {
+	var job rpcJob
+	if err := json.Unmarshal(params, &job); err != nil {
+		return 0, fmt.Errorf("invalid params: %w", err)
+	}
+	if len(job.Files) == 0 {
+		return 0, ErrRPCNoFiles
+	}
+	if reason := updateBlockReason(); reason != "" {
+		return 0, errors.New(reason)
+	}
+
+	// Settings are taken when the job is queued, like a batch started in the window
+	job.cfg = n.getProcessConfig()
+	if job.Preset != "" {
+		preset, ok := n.presets.find(job.Preset)
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrRPCPreset, job.Preset)
+		}
+		job.cfg = preset.applyTo(job.cfg)
+	}
+	if job.OutputDir != "" {
+		job.cfg.OutputDir = job.OutputDir
+	}
+	job.cfg.background = true
+
+	n.rpc.mutex.Lock()
+	n.rpc.nextJob++
+	job.id = n.rpc.nextJob
+	n.rpc.mutex.Unlock()
+
+	select {
+	case n.rpc.jobs <- job:
+		return job.id, nil
+	default:
+		return 0, ErrRPCQueueFull
+	}
+}
+
+// runRPCJob processes a job's files in parallel, reporting each as it finishes
+func (n *AudioNormalizer) runRPCJob(job rpcJob) {
+	n.rpc.mutex.Lock()
+	n.rpc.running = job.id
+	n.rpc.mutex.Unlock()
+	defer func() {
+		n.rpc.mutex.Lock()
+		n.rpc.running = 0
+		n.rpc.mutex.Unlock()
+	}()
+
+	n.countBatch(job.cfg, len(job.Files))
+	n.rpc.send(rpcEvent{Event: "job_started", Job: job.id, Files: len(job.Files)})
+
+	// Same-named files from different folders must not overwrite each other
+	n.planOutputs(slices.Clone(job.Files), job.cfg)
+	defer n.clearPlannedOutputs()
+
+	files := make(chan string, len(job.Files))
+	for _, f := range job.Files {
+		files <- f
+	}
+	close(files)
+
+	var mutex sync.Mutex
+	results := make(map[string]int)
+
+	var wg sync.WaitGroup
+	for range n.workerCount() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range files {
+				result := "failed"
+				n.runJob(file, func() { result = n.processRPCFile(file, job.cfg) })
+				mutex.Lock()
+				results[result]++
+				mutex.Unlock()
+				n.rpc.send(rpcEvent{Event: "file_done", Job: job.id, File: file, Result: result})
+			}
+		}()
+	}
+	wg.Wait()
+
+	n.rpc.send(rpcEvent{Event: "job_done", Job: job.id, Results: results})
+}
+
+// processRPCFile runs one file of a job and returns its outcome
+func (n *AudioNormalizer) processRPCFile(file string, cfg ProcessConfig) string {
+	if updateBlockReason() != "" {
+		return "blocked"
+	}
+	if err := audio.ValidateInput(file); err != nil {
+		n.refuseFile(file, err)
+		return "refused"
+	}
+	return n.processOne(file, cfg)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/config"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	ladderReportName    = "ladder_report.csv"
+	ladderReferenceName = "reference.wav"
+)
+
+var ladderReportHeader = []string{"rung", "file", "size_bytes", "kbps", "spectral_distance_db", "snr_db", "notes"}
+
+// ladderRungs are the test encodes a bitrate ladder can contain, cheapest first per codec
+var ladderRungs = []extraTarget{
+	{Name: "AAC 64 kbps", Ext: ".m4a", Args: []string{"-c:a", config.GetCodec("AAC"), "-b:a", "64000"}},
+	{Name: "AAC 96 kbps", Ext: ".m4a", Args: []string{"-c:a", config.GetCodec("AAC"), "-b:a", "96000"}},
+	{Name: "AAC 128 kbps", Ext: ".m4a", Args: []string{"-c:a", config.GetCodec("AAC"), "-b:a", "128000"}},
+	{Name: "AAC 192 kbps", Ext: ".m4a", Args: []string{"-c:a", config.GetCodec("AAC"), "-b:a", "192000"}},
+	{Name: "AAC 256 kbps", Ext: ".m4a", Args: []string{"-c:a", config.GetCodec("AAC"), "-b:a", "256000"}},
+	{Name: "Opus 32 kbps", Ext: ".opus", Args: []string{"-c:a", "libopus", "-b:a", "32000"}},
+	{Name: "Opus 64 kbps", Ext: ".opus", Args: []string{"-c:a", "libopus", "-b:a", "64000"}},
+	{Name: "Opus 96 kbps", Ext: ".opus", Args: []string{"-c:a", "libopus", "-b:a", "96000"}},
+	{Name: "Opus 128 kbps", Ext: ".opus", Args: []string{"-c:a", "libopus", "-b:a", "128000"}},
+	{Name: "MP3 128 kbps", Ext: ".mp3", Args: []string{"-c:a", "libmp3lame", "-b:a", "128000"}},
+	{Name: "MP3 192 kbps", Ext: ".mp3", Args: []string{"-c:a", "libmp3lame", "-b:a", "192000"}},
+	{Name: "MP3 320 kbps", Ext: ".mp3", Args: []string{"-c:a", "libmp3lame", "-b:a", "320000"}},
+}
+
+// ladderRungNames returns the rung names in display order
+func ladderRungNames() []string {
+	names := make([]string, len(ladderRungs))
+	for i, r := range ladderRungs {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// ladderFileName turns a rung name into a file name, e.g. "AAC 128 kbps" -> "aac_128kbps"
+func ladderFileName(rung extraTarget) string {
+	name := strings.ToLower(strings.ReplaceAll(rung.Name, " kbps", "kbps"))
+	return strings.ReplaceAll(name, " ", "_") + rung.Ext
+}
+
+// ladderDir returns the folder the comparison package for inputPath is written to
+func (n *AudioNormalizer) ladderDir(inputPath string) string {
+	parent := n.outputDir
+	if parent == "" {
+		parent = filepath.Dir(inputPath)
+	}
+	stem := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	return filepath.Join(parent, stem+"_ladder")
+}
+
+// runBitrateLadder encodes inputPath at every selected rung and writes the
+// encodes, a loudness-matched reference and a CSV report to one folder.
+// All rungs are encoded from the same normalized reference so they only
+// differ by codec and bitrate.
+func (n *AudioNormalizer) runBitrateLadder(inputPath string, rungs []string, options audio.LoudnormOptions) {
+	name := filepath.Base(inputPath)
+	dir := n.ladderDir(inputPath)
+
+	if err := os.MkdirAll(platform.LongPath(dir), 0755); err != nil {
+		n.logStatus(fmt.Sprintf("✗ Cannot create ladder folder: %v", err))
+		return
+	}
+
+	watchdog := n.jobWatchdog(inputPath)
+	target, targetTp := n.normalizationTargets()
+
+	measured := n.measureLoudness(inputPath, measureForLoudnorm, target, targetTp, options)
+	if measured == nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", name))
+		return
+	}
+
+	loudnormFilter := fmt.Sprintf(
+		"loudnorm=I=%s:TP=%s:LRA=5:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:%s",
+		target, targetTp, measured["input_i"], measured["input_tp"], measured["input_lra"], measured["input_thresh"], options.SecondPass(measured),
+	)
+
+	referencePath := filepath.Join(dir, ladderReferenceName)
+	n.logStatus(fmt.Sprintf("→ Ladder reference at %s LUFS: %s", target, name))
+
+	args := []string{"-i", inputPath, "-vn", "-af", loudnormFilter, "-ar", "48000", "-acodec", "pcm_s24le", "-y", referencePath}
+	n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", args))
+	if output, err := watchdog.Run(args...); err != nil {
+		n.logWatchdogFailure(inputPath, err, output)
+		n.logStatus(fmt.Sprintf("✗ Failed to create ladder reference: %s - %v", name, err))
+		return
+	}
+
+	duration, _ := audio.ProbeDuration(referencePath)
+
+	reportPath := filepath.Join(dir, ladderReportName)
+	os.Remove(platform.LongPath(reportPath))
+
+	for _, rungName := range rungs {
+		rung, found := findLadderRung(rungName)
+		if !found {
+			continue
+		}
+
+		rungPath := filepath.Join(dir, ladderFileName(rung))
+		row := []string{rung.Name, filepath.Base(rungPath), "", "", "", "", ""}
+
+		args := []string{"-i", referencePath, "-vn", "-ar", "48000"}
+		args = append(args, rung.Args...)
+		args = append(args, "-y", rungPath)
+
+		n.logStatus(fmt.Sprintf("→ Ladder %s: %s", rung.Name, name))
+		n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", args))
+
+		if output, err := watchdog.Run(args...); err != nil {
+			n.logWatchdogFailure(inputPath, err, output)
+			n.logStatus(fmt.Sprintf("✗ Failed %s: %s - %v", rung.Name, name, err))
+			row[6] = "encode failed"
+			n.writeLadderRow(reportPath, row)
+			continue
+		}
+
+		if info, err := os.Stat(platform.LongPath(rungPath)); err == nil {
+			row[2] = strconv.FormatInt(info.Size(), 10)
+			if duration > 0 {
+				row[3] = fmt.Sprintf("%.1f", float64(info.Size())*8/duration/1000)
+			}
+		}
+
+		score, err := audio.CompareQuality(referencePath, "", rungPath)
+		if err != nil {
+			row[6] = err.Error()
+			n.logStatus(fmt.Sprintf("⚠ %s encoded, no quality score: %v", rung.Name, err))
+		} else {
+			row[4] = fmt.Sprintf("%.2f", score.SpectralDistance)
+			row[5] = fmt.Sprintf("%.1f", score.SNR)
+			n.logStatus(fmt.Sprintf("✓ %s: %s kbps, spectral distance %.2f dB", rung.Name, row[3], score.SpectralDistance))
+		}
+
+		n.writeLadderRow(reportPath, row)
+	}
+
+	n.logStatus(fmt.Sprintf("✓ Ladder complete: %s", dir))
+	n.logToFile(n.logFile, fmt.Sprintf("Bitrate ladder for %s written to %s", inputPath, dir))
+}
+
+// findLadderRung looks up a rung by its display name
+func findLadderRung(name string) (extraTarget, bool) {
+	for _, r := range ladderRungs {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return extraTarget{}, false
+}
+
+// writeLadderRow appends one rung to the ladder report
+func (n *AudioNormalizer) writeLadderRow(path string, row []string) {
+	if err := appendCSVRow(path, ladderReportHeader, row); err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to write ladder report: %v", err))
+		n.logToFile(n.logFile, fmt.Sprintf("Ladder report write failed: %v", err))
+	}
+}
+
+// buildLadderTab builds the Functions tab that runs a bitrate ladder on one file
+func (n *AudioNormalizer) buildLadderTab() fyne.CanvasObject {
+	text := widget.NewLabel(`
+Bitrate ladder
+Pick a file to encode it at every checked codec and bitrate. The file is first normalized to the current target, and every rung is encoded from that same reference so they can be compared by ear at matched loudness. The encodes, the reference and ladder_report.csv (file sizes and quality scores) are written to a <name>_ladder folder in the output folder.
+		`)
+	text.Wrapping = fyne.TextWrapWord
+
+	rungs := widget.NewCheckGroup(ladderRungNames(), nil)
+	rungs.SetSelected([]string{"AAC 96 kbps", "AAC 128 kbps", "Opus 64 kbps", "Opus 96 kbps", "MP3 192 kbps"})
+
+	var runBtn *widget.Button
+	runBtn = widget.NewButton("Choose file and run", func() {
+		if len(rungs.Selected) == 0 {
+			dialog.ShowInformation("Bitrate ladder", "Check at least one rung.", n.menuWindow)
+			return
+		}
+
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			path := reader.URI().Path()
+			reader.Close()
+
+			selected := append([]string(nil), rungs.Selected...)
+			options := n.loudnormOptions()
+			runBtn.Disable()
+			go func() {
+				defer fyne.Do(runBtn.Enable)
+				if err := audio.ValidateInput(path); err != nil {
+					n.refuseFile(path, err)
+					return
+				}
+				n.runBitrateLadder(path, selected, options)
+			}()
+		}, n.menuWindow)
+	})
+
+	return container.NewVBox(text, rungs, runBtn)
+}
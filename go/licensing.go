@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/license"
+)
+
+// licensePublicKey is the base64 Ed25519 key license keys are signed with,
+// set for the commercial build with -ldflags "-X main.licensePublicKey=...".
+// Builds without one need no license and have every feature.
+var licensePublicKey string
+
+// licenseGrace keeps Pro features working this long after a license expires
+const licenseGrace = 14 * 24 * time.Hour
+
+var (
+	ErrLicenseRequired = errors.New("a TNT Pro license is required")
+	ErrLicenseExpired  = errors.New("the TNT Pro license has expired")
+	ErrLicenseFeature  = errors.New("the TNT Pro license doesn't include")
+)
+
+// licenseFeatureNames names the Pro features in messages
+var licenseFeatureNames = map[string]string{
+	license.FeatureWatch:       "watch mode",
+	license.FeatureAPI:         "the API server",
+	license.FeatureMultiFormat: "multi-format output",
+}
+
+// licenseStore holds the activated license key, kept in license.key shared
+// by all profiles
+type licenseStore struct {
+	mutex sync.Mutex
+	path  string
+	key   *license.Key
+	// err is why the stored key was rejected
+	err error
+}
+
+// licensingEnabled reports whether this build gates the Pro features
+func licensingEnabled() bool {
+	return licensePublicKey != ""
+}
+
+func loadLicense() *licenseStore {
+	s := &licenseStore{path: filepath.Join(configRoot(), "TNT", "license.key")}
+	if !licensingEnabled() {
+		return s
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return s
+	}
+	if key, err := parseLicense(string(data)); err != nil {
+		s.err = err
+	} else {
+		s.key = &key
+	}
+	return s
+}
+
+func parseLicense(text string) (license.Key, error) {
+	pub, err := license.ParsePublicKey(licensePublicKey)
+	if err != nil {
+		return license.Key{}, err
+	}
+	return license.Parse(text, pub)
+}
+
+// activate verifies a key and stores it, replacing the current one
+func (s *licenseStore) activate(text string) error {
+	key, err := parseLicense(text)
+	if err != nil {
+		return err
+	}
+	if key.StateAt(time.Now(), licenseGrace) == license.Expired {
+		return ErrLicenseExpired
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, []byte(strings.TrimSpace(text)+"\n"), 0644); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.key, s.err = &key, nil
+	s.mutex.Unlock()
+	return nil
+}
+
+// remove deletes the stored key
+func (s *licenseStore) remove() error {
+	s.mutex.Lock()
+	s.key, s.err = nil, nil
+	s.mutex.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// check returns nil when a Pro feature may be used
+func (s *licenseStore) check(feature string) error {
+	if !licensingEnabled() {
+		return nil
+	}
+	s.mutex.Lock()
+	key := s.key
+	s.mutex.Unlock()
+
+	name := licenseFeatureNames[feature]
+	switch {
+	case key == nil:
+		return fmt.Errorf("%w for %s", ErrLicenseRequired, name)
+	case key.StateAt(time.Now(), licenseGrace) == license.Expired:
+		return fmt.Errorf("%w, %s is disabled", ErrLicenseExpired, name)
+	case !key.Allows(feature):
+		return fmt.Errorf("%w %s", ErrLicenseFeature, name)
+	}
+	return nil
+}
+
+// status describes the license for the License tab and the log
+func (s *licenseStore) status() string {
+	if !licensingEnabled() {
+		return "This build doesn't need a license, every feature is available."
+	}
+	s.mutex.Lock()
+	key, err := s.key, s.err
+	s.mutex.Unlock()
+
+	if key == nil {
+		if err != nil {
+			return fmt.Sprintf("✗ The stored license key was rejected: %v. Pro features are disabled.", err)
+		}
+		return "No license. Pro features are disabled."
+	}
+
+	var features []string
+	for _, f := range key.Features {
+		if name, ok := licenseFeatureNames[f]; ok {
+			features = append(features, name)
+		}
+	}
+	covers := strings.Join(features, ", ")
+
+	switch key.StateAt(time.Now(), licenseGrace) {
+	case license.Grace:
+		return fmt.Sprintf("⚠ The license of %s expired on %s. %s keep working until %s, renew the license before then.",
+			key.Licensee, key.Expires.Format(time.DateOnly), covers, key.GraceEnds(licenseGrace).Format(time.DateOnly))
+	case license.Expired:
+		return fmt.Sprintf("✗ The license of %s expired on %s. Pro features are disabled.", key.Licensee, key.Expires.Format(time.DateOnly))
+	}
+	if key.Expires.IsZero() {
+		return fmt.Sprintf("✓ Licensed to %s: %s.", key.Licensee, covers)
+	}
+	return fmt.Sprintf("✓ Licensed to %s until %s: %s.", key.Licensee, key.Expires.Format(time.DateOnly), covers)
+}
+
+// warnLicenseGrace tells at startup that the license is in its grace period
+func (n *AudioNormalizer) warnLicenseGrace() {
+	n.license.mutex.Lock()
+	key := n.license.key
+	n.license.mutex.Unlock()
+
+	if licensingEnabled() && key != nil && key.StateAt(time.Now(), licenseGrace) == license.Grace {
+		status := n.license.status()
+		n.logStatus(status)
+		n.logToFile(n.logFile, status)
+	}
+}
+
+// buildLicenseTab shows the license status and activates keys
+func (n *AudioNormalizer) buildLicenseTab() fyne.CanvasObject {
+	text := widget.NewLabel(`
+License
+Watch mode, the API server and multi-format output are Pro features. License keys are verified on this computer, no connection is needed. After a license expires the features keep working for 14 days, time to renew it.
+	`)
+	text.Wrapping = fyne.TextWrapWord
+
+	status := widget.NewLabel(n.license.status())
+	status.Wrapping = fyne.TextWrapWord
+
+	if !licensingEnabled() {
+		return container.NewVBox(text, status)
+	}
+
+	keyEntry := widget.NewMultiLineEntry()
+	keyEntry.SetPlaceHolder("Paste the license key")
+	keyEntry.SetMinRowsVisible(3)
+	keyEntry.Wrapping = fyne.TextWrapBreak
+
+	activateBtn := widget.NewButton("Activate", func() {
+		if err := n.license.activate(keyEntry.Text); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		keyEntry.SetText("")
+		status.SetText(n.license.status())
+		n.logToFile(n.logFile, "License activated: "+n.license.status())
+	})
+	removeBtn := widget.NewButton("Remove license", func() {
+		dialog.ShowConfirm("Remove license", "Pro features are disabled until a key is activated again.", func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := n.license.remove(); err != nil {
+				dialog.ShowError(err, n.menuWindow)
+			}
+			status.SetText(n.license.status())
+		}, n.menuWindow)
+	})
+
+	return container.NewVBox(text, status, widget.NewSeparator(), keyEntry, container.NewHBox(activateBtn, removeBtn))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	logName = "tnt.log"
+	// logMaxBytes rotates a busy day's log before it gets unwieldy to send or open
+	logMaxBytes = 10 << 20
+	// logRetentionDefault is how many rotated logs are kept unless set otherwise
+	logRetentionDefault = "30"
+)
+
+// logRetentionOptions are the numbers of rotated logs offered to keep
+var logRetentionOptions = []string{"7", "14", "30", "90", "365"}
+
+// rotatedLogName names a log rotated on the given day, numbered when a day
+// fills more than one
+func rotatedLogName(day string, part int) string {
+	if part <= 1 {
+		return fmt.Sprintf("tnt-%s.log", day)
+	}
+	return fmt.Sprintf("tnt-%s-%d.log", day, part)
+}
+
+// rotateLogIfNeeded moves the log aside when the day changed since its last
+// line or it passed logMaxBytes. The open file is copied and truncated
+// rather than renamed, so every holder of n.logFile keeps writing to the
+// current log. The caller holds logMutex.
+func (n *AudioNormalizer) rotateLogIfNeeded(now time.Time) {
+	day := now.Format(time.DateOnly)
+	if n.logDay == "" {
+		n.logDay = day
+	}
+	if n.logFile == nil || (day == n.logDay && n.logSize < logMaxBytes) {
+		return
+	}
+
+	if err := rotateLog(n.logFile, n.logDay); err != nil {
+		n.logFile.WriteString(fmt.Sprintf("[%s] Log rotation failed: %v\n", now.Format("2006-01-02 15:04:05"), err))
+	} else {
+		n.logSize = 0
+		pruneLogs(n.logRetention())
+	}
+	n.logDay = day
+}
+
+// rotateLog copies the log to the first free name of the day and empties it
+func rotateLog(f *os.File, day string) error {
+	dir := filepath.Dir(f.Name())
+	part := 1
+	for {
+		if _, err := os.Stat(filepath.Join(dir, rotatedLogName(day, part))); os.IsNotExist(err) {
+			break
+		}
+		part++
+	}
+
+	src, err := os.Open(f.Name())
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Join(dir, rotatedLogName(day, part)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	// The log is opened for appending, so writes continue at the new end
+	return f.Truncate(0)
+}
+
+// rotatedLogs lists the rotated logs in dataDir, oldest first
+func rotatedLogs() []string {
+	entries, _ := os.ReadDir(dataDir())
+	var logs []string
+	for _, e := range entries {
+		if name := e.Name(); strings.HasPrefix(name, "tnt-") && strings.HasSuffix(name, ".log") {
+			logs = append(logs, filepath.Join(dataDir(), name))
+		}
+	}
+	// Names sort by day, a day's later parts after its first
+	slices.SortFunc(logs, func(a, b string) int {
+		return compareLogNames(filepath.Base(a), filepath.Base(b))
+	})
+	return logs
+}
+
+// compareLogNames orders rotated log names by day and then by part
+func compareLogNames(a, b string) int {
+	dayA, partA := splitLogName(a)
+	dayB, partB := splitLogName(b)
+	if c := strings.Compare(dayA, dayB); c != 0 {
+		return c
+	}
+	return partA - partB
+}
+
+// splitLogName returns the day and part of a rotated log name
+func splitLogName(name string) (string, int) {
+	base := strings.TrimSuffix(strings.TrimPrefix(name, "tnt-"), ".log")
+	if len(base) > len(time.DateOnly) {
+		if part, err := strconv.Atoi(base[len(time.DateOnly)+1:]); err == nil {
+			return base[:len(time.DateOnly)], part
+		}
+	}
+	return base, 1
+}
+
+// pruneLogs deletes the oldest rotated logs beyond keep
+func pruneLogs(keep int) {
+	logs := rotatedLogs()
+	for len(logs) > keep {
+		os.Remove(logs[0])
+		logs = logs[1:]
+	}
+}
+
+// logRetention returns how many rotated logs to keep
+func (n *AudioNormalizer) logRetention() int {
+	selected := logRetentionDefault
+	if n.logRetentionDrop != nil && n.logRetentionDrop.Selected != "" {
+		selected = n.logRetentionDrop.Selected
+	}
+	keep, err := strconv.Atoi(selected)
+	if err != nil || keep < 1 {
+		keep, _ = strconv.Atoi(logRetentionDefault)
+	}
+	return keep
+}
+
+// openLogFolder shows the current and rotated logs in the file manager
+func (n *AudioNormalizer) openLogFolder() {
+	if err := platform.OpenFile(dataDir()); err != nil {
+		dialog.ShowError(err, n.window)
+	}
+}
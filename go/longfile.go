@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// longFileDefaultHours is the length from which a file is processed in long-file mode
+const longFileDefaultHours = 3.0
+
+// longFileThreshold returns the length in seconds set in the preferences
+func longFileThreshold(cfg ProcessConfig) float64 {
+	if hours, err := strconv.ParseFloat(strings.TrimSpace(cfg.LongFileHours), 64); err == nil && hours > 0 {
+		return hours * 3600
+	}
+	return longFileDefaultHours * 3600
+}
+
+// isLongFile reports whether a file is long enough for long-file mode. Such
+// files, like a day of logger recording, would need hundreds of gigabytes of
+// 192 kHz intermediates, so their stages are always piped into the final
+// encode and their loudness is measured streaming.
+func (n *AudioNormalizer) isLongFile(inputPath string, cfg ProcessConfig) bool {
+	if !cfg.LongFile || cfg.noTranscode {
+		return false
+	}
+	duration, err := audio.ProbeDuration(inputPath)
+	if err != nil || duration < longFileThreshold(cfg) {
+		return false
+	}
+
+	n.logStatus(fmt.Sprintf("Long-file mode (%s): %s", audio.FormatTimecode(duration), filepath.Base(inputPath)))
+	return true
+}
+
+// measureLongFile measures the audio the piped stages produce, streaming the
+// measurement so its memory doesn't grow with the file
+func (n *AudioNormalizer) measureLongFile(inputPath, workingPath string, piped []string, options audio.LoudnormOptions, watchdog ffmpeg.Watchdog) map[string]string {
+	name := filepath.Base(inputPath)
+	n.logStatus(fmt.Sprintf("→ Measuring: %s", name))
+
+	standard := n.measurementStandard()
+	measured, err := audio.MeasureStreaming(workingPath, strings.Join(piped, ","), standard, options.DualMono, watchdog, func(seconds float64) {
+		n.logStatus(fmt.Sprintf("→ Measured %s: %s", audio.FormatTimecode(seconds), name))
+	})
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Streaming measurement of %s failed: %v", inputPath, err))
+		n.logWatchdogFailure(inputPath, err, nil)
+		return nil
+	}
+
+	// The same ebur128 measurement, read as it streams
+	measured[measuredByKey] = backendEbur128
+	measured[standardKey] = string(standard)
+	n.logToFile(n.logFile, fmt.Sprintf("Long-file measurement of %s to %s: I %s LUFS, TP %s dBTP, LRA %s LU, threshold %s LUFS",
+		inputPath, standard, measured["input_i"], measured["input_tp"], measured["input_lra"], measured["input_thresh"]))
+	return measured
+}
+
+// withPiped puts the piped stage filters in front of a filter chain
+func withPiped(piped []string, chain string) string {
+	if chain != "" {
+		piped = append(piped[:len(piped):len(piped)], chain)
+	}
+	return strings.Join(piped, ",")
+}
+
+// streamStages reports whether a file's stages are chained into its final
+// encode instead of each being rendered to a 192 kHz 64-bit intermediate.
+// Later analysis and measurement read the file through the chain. Long
+// files always stream. Others use intermediates when the preferences ask
+// for them, or when a dialogue anchor has to be measured on the processed
+// audio.
+func (n *AudioNormalizer) streamStages(inputPath string, cfg ProcessConfig, long bool) bool {
+	if long {
+		return true
+	}
+	if cfg.noTranscode || cfg.StageTempFiles {
+		return false
+	}
+	if cfg.UseLoudnorm {
+		if _, source, err := n.anchorFor(inputPath, cfg); !errors.Is(err, audio.ErrNoAnchor) {
+			n.logToFile(n.logFile, fmt.Sprintf("Anchor of %s from %s, stages rendered to intermediates", inputPath, source))
+			return false
+		}
+	}
+	return true
+}
+
+// pipeStage adds a stage's filters to the final encode in place of
+// rendering them to an intermediate
+func (n *AudioNormalizer) pipeStage(piped []string, stage, inputPath string, filters ...string) []string {
+	n.logStatus(fmt.Sprintf("✓ %s joins the final pass: %s", stage, filepath.Base(inputPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("%s of %s piped into the final encode: %s", stage, inputPath, strings.Join(filters, ",")))
+	return append(piped, filters...)
+}
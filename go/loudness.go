@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// Loudness is measured by one of two FFmpeg filters. Both follow ITU-R
+// BS.1770, but they gate and estimate true peak a little differently and can
+// disagree by a few tenths. ebur128 is the reference: every figure that is
+// tagged, reported or compared comes from it. loudnorm measures only for its
+// own second pass, which needs loudnorm's figures and target offset.
+const (
+	backendEbur128  = "ebur128"
+	backendLoudnorm = "loudnorm"
+	// measuredByKey holds the backend in a measurement map
+	measuredByKey = "measured_by"
+	// standardKey holds the BS.1770 revision the values were measured to
+	standardKey = "standard"
+)
+
+// measurementStandard returns the BS.1770 revision set in the preferences.
+// Delivery specs written against older revisions gate differently or leave
+// channels out.
+func (n *AudioNormalizer) measurementStandard() audio.Standard {
+	return audio.ParseStandard(n.measurementStandardDrop.Selected)
+}
+
+// maxLoudnormOffset is the largest offset gain in dB loudnorm accepts
+const maxLoudnormOffset = 99.0
+
+var ErrOffsetRange = errors.New("offset must be between -99 and 99 dB")
+
+// parseOffset reads a custom offset gain in dB
+func parseOffset(text string) (float64, error) {
+	offset, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return 0, err
+	}
+	if offset < -maxLoudnormOffset || offset > maxLoudnormOffset {
+		return 0, ErrOffsetRange
+	}
+	return offset, nil
+}
+
+// validateOffset accepts an empty entry, for the first pass's offset, or an offset gain
+func validateOffset(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	_, err := parseOffset(text)
+	return err
+}
+
+// loudnormOptions returns the advanced loudnorm settings in the preferences.
+// A custom offset that is empty or invalid falls back to the first pass's.
+func (n *AudioNormalizer) loudnormOptions() audio.LoudnormOptions {
+	options := audio.LoudnormOptions{DualMono: n.loudnormDualMonoCheck.Checked}
+	switch n.loudnormOffsetDrop.Selected {
+	case audio.OffsetNone:
+		options.Offset = "0"
+	case audio.OffsetCustom:
+		if offset, err := parseOffset(n.loudnormOffsetEntry.Text); err == nil {
+			options.Offset = strconv.FormatFloat(offset, 'f', 2, 64)
+		}
+	}
+	return options
+}
+
+// measurePurpose says what a measurement is used for, which picks the backend
+type measurePurpose int
+
+const (
+	// measureForReport figures are tagged, written to reports or shown
+	measureForReport measurePurpose = iota
+	// measureForLoudnorm figures feed loudnorm's second pass
+	measureForLoudnorm
+)
+
+// measurePurposeFor returns the purpose of a job's measurement: a normalized
+// file is measured for loudnorm, a tagged one for its tags
+func measurePurposeFor(cfg ProcessConfig) measurePurpose {
+	if cfg.UseLoudnorm {
+		return measureForLoudnorm
+	}
+	return measureForReport
+}
+
+// measureLoudness measures a file's integrated loudness, true peak, loudness
+// range and gating threshold, keyed input_i, input_tp, input_lra and
+// input_thresh in LUFS, dBTP and LU, with the backend under measured_by.
+// Measurements for loudnorm also carry target_offset towards target. Returns
+// nil when the file can't be measured.
+func (n *AudioNormalizer) measureLoudness(inputPath string, purpose measurePurpose, target, targetTp string, options audio.LoudnormOptions) map[string]string {
+	return n.measurePiped(inputPath, nil, purpose, target, targetTp, options)
+}
+
+// measurePiped is measureLoudness of the audio the piped stage filters make
+// of a file, see longfile.go
+func (n *AudioNormalizer) measurePiped(inputPath string, piped []string, purpose measurePurpose, target, targetTp string, options audio.LoudnormOptions) map[string]string {
+	n.logStatus(fmt.Sprintf("→ Measuring: %s", filepath.Base(inputPath)))
+
+	var measured map[string]string
+	backend := backendEbur128
+	if purpose == measureForLoudnorm {
+		backend = backendLoudnorm
+		measured = n.loudnormPass(inputPath, piped, target, targetTp, options)
+		// loudnorm measures to -4/-5. Its linear mode takes the gain from
+		// input_i, so the revision's loudness is what ends up on target.
+		if standard := n.measurementStandard(); measured != nil && standard != audio.BS1770_4 {
+			reference := n.ebur128Pass(inputPath, piped, options)
+			if reference["input_i"] == "" {
+				return nil
+			}
+			n.logToFile(n.logFile, fmt.Sprintf("Integrated loudness of %s to %s: %s LUFS, loudnorm's %s LUFS", inputPath, standard, reference["input_i"], measured["input_i"]))
+			measured["input_i"] = reference["input_i"]
+		}
+	} else {
+		measured = n.ebur128Pass(inputPath, piped, options)
+	}
+	if measured == nil || measured["input_i"] == "" {
+		return nil
+	}
+
+	measured[measuredByKey] = backend
+	measured[standardKey] = string(n.measurementStandard())
+	n.logToFile(n.logFile, fmt.Sprintf("Measured %s with %s to %s: I %s LUFS, TP %s dBTP, LRA %s LU, threshold %s LUFS",
+		inputPath, backend, measured[standardKey], measured["input_i"], measured["input_tp"], measured["input_lra"], measured["input_thresh"]))
+	return measured
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// Loudness report formats offered in the settings
+const (
+	loudnessReportOff     = "Off"
+	loudnessReportHTML    = "HTML"
+	loudnessReportHTMLPDF = "HTML and PDF"
+)
+
+var loudnessReportFormats = []string{loudnessReportOff, loudnessReportHTML, loudnessReportHTMLPDF}
+
+// chartPoints is how many points of the loudness curve are drawn, an
+// hour-long file has 36000 frames
+const chartPoints = 720
+
+// chartRange is the span in LU the loudness chart shows below its top
+const chartRange = 40.0
+
+// reportRow is one named value of a loudness report
+type reportRow struct {
+	Name  string
+	Value string
+}
+
+// loudnessReport is the compliance report of one output file
+type loudnessReport struct {
+	Input    string
+	Output   string
+	Date     time.Time
+	Version  string
+	FFmpeg   string
+	TargetI  float64
+	TargetTp float64
+	Curve    audio.LoudnessCurve
+	Settings []reportRow
+}
+
+// reportPathFor returns the report path with the given extension next to an output file
+func reportPathFor(outputPath, ext string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".loudness" + ext
+}
+
+// reportFFmpegVersion is the first line of ffmpeg -version, read once
+var reportFFmpegVersion = sync.OnceValue(func() string {
+	return checkFFmpegVersion().Detail
+})
+
+// compliant reports whether the output is within the loudness tolerance of
+// its target and under the true peak limit, see compliance.go
+func (r loudnessReport) compliant() bool {
+	s := r.Curve.Summary
+	return math.Abs(s.Integrated-r.TargetI) <= complianceTolerance && (!s.HasPeak || s.TruePeak <= r.TargetTp)
+}
+
+// verdict is the compliance result as shown in the report
+func (r loudnessReport) verdict() string {
+	if r.compliant() {
+		return fmt.Sprintf("Compliant (within %.0f LU of target, true peak under the limit)", complianceTolerance)
+	}
+	return "Not compliant"
+}
+
+// measurements lists the measured values of the report
+func (r loudnessReport) measurements() []reportRow {
+	s := r.Curve.Summary
+	truePeak := "not measured"
+	if s.HasPeak {
+		truePeak = fmt.Sprintf("%.1f dBTP", s.TruePeak)
+	}
+	return []reportRow{
+		{"Integrated loudness", fmt.Sprintf("%.1f LUFS", s.Integrated)},
+		{"Target", fmt.Sprintf("%.1f LUFS, %.1f dBTP", r.TargetI, r.TargetTp)},
+		{"Loudness range", fmt.Sprintf("%.1f LU", s.LRA)},
+		{"Maximum true peak", truePeak},
+		{"Maximum momentary loudness", fmt.Sprintf("%.1f LUFS", r.Curve.MaxMomentary)},
+		{"Maximum short-term loudness", fmt.Sprintf("%.1f LUFS", r.Curve.MaxShortTerm)},
+		{"Duration", formatReportTime(r.Curve.Duration())},
+		{"Measured to", r.Curve.Standard.Label()},
+		{"Result", r.verdict()},
+	}
+}
+
+// formatReportTime formats seconds as h:mm:ss, or m:ss under an hour
+func formatReportTime(seconds float64) string {
+	total := int(math.Round(seconds))
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// reportSettings lists the settings a file was processed with
+func reportSettings(cfg ProcessConfig) []reportRow {
+	mode := "Dynamic normalization"
+	switch {
+	case cfg.bypassProc && cfg.writeTags:
+		mode = "ReplayGain tags only"
+	case cfg.UseLoudnorm:
+		mode = "Loudness normalization"
+	case cfg.bypassProc:
+		mode = "Processing bypassed"
+	}
+
+	rows := []reportRow{{"Mode", mode}}
+	if cfg.PresetName != "" {
+		rows = append(rows, reportRow{"Preset", cfg.PresetName})
+	}
+	format := cfg.Format
+	if cfg.noTranscode {
+		format = "Source format kept"
+	}
+	for _, detail := range []string{cfg.SampleRate, cfg.BitDepth, cfg.Bitrate} {
+		if detail != "" && !cfg.noTranscode {
+			format += ", " + detail
+		}
+	}
+	rows = append(rows, reportRow{"Output format", format})
+	if !cfg.bypassProc {
+		rows = append(rows,
+			reportRow{"Dynamics", cfg.DynamicsPreset},
+			reportRow{"EQ", cfg.EqTarget},
+		)
+	}
+	if cfg.Loudnorm.DualMono {
+		rows = append(rows, reportRow{"Mono files", "Measured as dual mono"})
+	}
+	if cfg.IsSpeech {
+		rows = append(rows, reportRow{"Speech", cfg.Speechnorm})
+	}
+	if cfg.Restoration {
+		rows = append(rows, reportRow{"Restoration", "On"})
+	}
+	if cfg.measuredBy != "" {
+		rows = append(rows, reportRow{"Measured by", cfg.measuredBy})
+	}
+	return rows
+}
+
+// reportScale maps the loudness curve into a chart, fractions of its width
+// from the left and of its height from the top
+type reportScale struct {
+	duration float64
+	top      float64
+}
+
+func newReportScale(r loudnessReport) reportScale {
+	top := math.Ceil((max(r.Curve.MaxMomentary, r.TargetI)+3)/5) * 5
+	return reportScale{duration: max(r.Curve.Duration(), 1), top: top}
+}
+
+func (s reportScale) x(seconds float64) float64 {
+	return seconds / s.duration
+}
+
+func (s reportScale) y(lufs float64) float64 {
+	return min(max((s.top-lufs)/chartRange, 0), 1)
+}
+
+// chartTick is a labelled position on an axis of the chart, 0 to 1
+type chartTick struct {
+	At    float64
+	Label string
+}
+
+// grid returns the loudness lines of the chart, every 10 LU
+func (s reportScale) grid() []chartTick {
+	var ticks []chartTick
+	for lufs := s.top; lufs >= s.top-chartRange; lufs -= 10 {
+		ticks = append(ticks, chartTick{s.y(lufs), strconv.FormatFloat(lufs, 'f', 0, 64)})
+	}
+	return ticks
+}
+
+// timeTicks returns five times along the chart
+func (s reportScale) timeTicks() []chartTick {
+	var ticks []chartTick
+	for i := range 5 {
+		seconds := s.duration * float64(i) / 4
+		ticks = append(ticks, chartTick{s.x(seconds), formatReportTime(seconds)})
+	}
+	return ticks
+}
+
+// svgChart is the loudness chart of the HTML report in SVG units
+type svgChart struct {
+	Width, Height float64
+	// ViewBox leaves room for the axis labels, LabelY is where the times go
+	ViewBox   string
+	LabelY    float64
+	Momentary string
+	ShortTerm string
+	TargetY   float64
+	Grid      []chartTick
+	Ticks     []chartTick
+}
+
+func (r loudnessReport) svgChart() svgChart {
+	const width, height = 800.0, 300.0
+	scale := newReportScale(r)
+
+	var momentary, shortTerm strings.Builder
+	for _, p := range r.Curve.Points(chartPoints) {
+		x := scale.x(p.Time) * width
+		fmt.Fprintf(&momentary, "%.1f,%.1f ", x, scale.y(p.Momentary)*height)
+		fmt.Fprintf(&shortTerm, "%.1f,%.1f ", x, scale.y(p.ShortTerm)*height)
+	}
+
+	chart := svgChart{
+		Width:     width,
+		Height:    height,
+		ViewBox:   fmt.Sprintf("-40 -10 %.0f %.0f", width+60, height+40),
+		LabelY:    height + 18,
+		Momentary: momentary.String(),
+		ShortTerm: shortTerm.String(),
+		TargetY:   scale.y(r.TargetI) * height,
+	}
+	for _, t := range scale.grid() {
+		chart.Grid = append(chart.Grid, chartTick{t.At * height, t.Label})
+	}
+	for _, t := range scale.timeTicks() {
+		chart.Ticks = append(chart.Ticks, chartTick{t.At * width, t.Label})
+	}
+	return chart
+}
+
+var loudnessReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Loudness report: {{.Name}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #141e30; margin: 2em auto; max-width: 860px; }
+h1 { font-size: 1.4em; border-left: 6px solid #de797c; padding-left: 0.5em; }
+h2 { font-size: 1.1em; margin-top: 1.6em; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; }
+td:first-child { width: 40%; color: #555; }
+.ok { color: #2e7d32; font-weight: bold; }
+.off { color: #c62828; font-weight: bold; }
+.legend span { margin-right: 1.5em; }
+footer { margin-top: 2em; font-size: 0.85em; color: #777; }
+</style>
+</head>
+<body>
+<h1>Loudness report: {{.Name}}</h1>
+<p class="{{if .Compliant}}ok{{else}}off{{end}}">{{.Verdict}}</p>
+
+<h2>Measurements</h2>
+<table>
+{{range .Measurements}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+
+<h2>Loudness over time</h2>
+<svg viewBox="{{.Chart.ViewBox}}" width="100%" role="img" aria-label="Momentary and short-term loudness over time">
+{{range .Chart.Grid}}<line x1="0" x2="{{$.Chart.Width}}" y1="{{.At}}" y2="{{.At}}" stroke="#ddd"/>
+<text x="-6" y="{{.At}}" font-size="11" text-anchor="end" dominant-baseline="middle">{{.Label}}</text>
+{{end}}{{range .Chart.Ticks}}<text x="{{.At}}" y="{{$.Chart.LabelY}}" font-size="11" text-anchor="middle">{{.Label}}</text>
+{{end}}<polyline points="{{.Chart.Momentary}}" fill="none" stroke="#9fb3c8" stroke-width="1"/>
+<polyline points="{{.Chart.ShortTerm}}" fill="none" stroke="#141e30" stroke-width="1.5"/>
+<line x1="0" x2="{{.Chart.Width}}" y1="{{.Chart.TargetY}}" y2="{{.Chart.TargetY}}" stroke="#de797c" stroke-width="1.5" stroke-dasharray="6 4"/>
+</svg>
+<p class="legend"><span style="color:#9fb3c8">■ Momentary</span><span style="color:#141e30">■ Short-term</span><span style="color:#de797c">■ Target</span> (LUFS)</p>
+
+<h2>Processing</h2>
+<table>
+<tr><td>Input</td><td>{{.Input}}</td></tr>
+<tr><td>Output</td><td>{{.Output}}</td></tr>
+{{range .Settings}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>
+{{end}}<tr><td>TNT version</td><td>{{.Version}}</td></tr>
+<tr><td>FFmpeg</td><td>{{.FFmpeg}}</td></tr>
+</table>
+
+<footer>Generated by TNT on {{.Date.Format "2006-01-02 15:04:05 MST"}}</footer>
+</body>
+</html>
+`))
+
+// writeHTML renders the report as a standalone HTML page with an SVG chart
+func (r loudnessReport) writeHTML(path string) error {
+	f, err := os.Create(platform.LongPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return loudnessReportTemplate.Execute(f, map[string]any{
+		"Name":         filepath.Base(r.Output),
+		"Compliant":    r.compliant(),
+		"Verdict":      r.verdict(),
+		"Measurements": r.measurements(),
+		"Chart":        r.svgChart(),
+		"Input":        r.Input,
+		"Output":       r.Output,
+		"Settings":     r.Settings,
+		"Version":      r.Version,
+		"FFmpeg":       r.FFmpeg,
+		"Date":         r.Date,
+	})
+}
+
+// generateLoudnessReport measures a finished output over time and writes its
+// compliance report next to it, as HTML and, when chosen, as PDF
+func (n *AudioNormalizer) generateLoudnessReport(inputPath, outputPath, target, targetTp string, cfg ProcessConfig) {
+	curve, err := audio.MeasureCurve(outputPath, audio.ParseStandard(cfg.measuredTo), cfg.Loudnorm.DualMono)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Could not measure output for the loudness report: %s", filepath.Base(outputPath)))
+		n.logToFile(n.logFile, fmt.Sprintf("Loudness report measurement of %s failed: %v", outputPath, err))
+		return
+	}
+
+	targetI, _ := strconv.ParseFloat(target, 64)
+	tp, _ := strconv.ParseFloat(targetTp, 64)
+	report := loudnessReport{
+		Input:    inputPath,
+		Output:   outputPath,
+		Date:     time.Now(),
+		Version:  currentVersion,
+		FFmpeg:   reportFFmpegVersion(),
+		TargetI:  targetI,
+		TargetTp: tp,
+		Curve:    curve,
+		Settings: reportSettings(cfg),
+	}
+
+	paths := []string{reportPathFor(outputPath, ".html")}
+	writers := []func(string) error{report.writeHTML}
+	if cfg.LoudnessReport == loudnessReportHTMLPDF {
+		paths = append(paths, reportPathFor(outputPath, ".pdf"))
+		writers = append(writers, report.writePDF)
+	}
+	for i, path := range paths {
+		if err := writers[i](path); err != nil {
+			n.logStatus(fmt.Sprintf("✗ Failed to write loudness report: %v", err))
+			n.logToFile(n.logFile, fmt.Sprintf("Loudness report write failed for %s: %v", path, err))
+			continue
+		}
+		n.logToFile(n.logFile, fmt.Sprintf("Loudness report written: %s", path))
+	}
+}
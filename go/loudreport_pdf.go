@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// A4 in PDF points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// pdfColor is an RGB fill or stroke color, 0 to 1 per channel
+type pdfColor [3]float64
+
+var (
+	pdfNavy   = pdfColor{0.08, 0.12, 0.19}
+	pdfAccent = pdfColor{0.87, 0.47, 0.49}
+	pdfGrey   = pdfColor{0.45, 0.45, 0.45}
+	pdfLight  = pdfColor{0.87, 0.87, 0.87}
+	pdfBlue   = pdfColor{0.62, 0.70, 0.78}
+	pdfGreen  = pdfColor{0.18, 0.49, 0.20}
+	pdfRed    = pdfColor{0.78, 0.16, 0.16}
+)
+
+// pdfPage is the content stream of a one-page PDF. It only draws what the
+// loudness report needs: Helvetica text, lines and filled rectangles.
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// pdfText escapes text for a PDF string in WinAnsiEncoding, characters it
+// can't encode become "?"
+func pdfText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80 || r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// fitText shortens text to about what fits in width at a font size,
+// keeping its end, where the file name of a path is
+func fitText(text string, width, size float64) string {
+	// Helvetica averages about half the font size per character
+	chars := int(width / (size * 0.5))
+	runes := []rune(text)
+	if len(runes) <= chars || chars < 4 {
+		return text
+	}
+	return "..." + string(runes[len(runes)-chars+3:])
+}
+
+func (p *pdfPage) text(x, y, size float64, bold bool, col pdfColor, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT %.3f %.3f %.3f rg /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n",
+		col[0], col[1], col[2], font, size, x, y, pdfText(text))
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2, width float64, col pdfColor, dashed bool) {
+	dash := "[] 0"
+	if dashed {
+		dash = "[6 4] 0"
+	}
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f RG %.2f w %s d %.1f %.1f m %.1f %.1f l S\n",
+		col[0], col[1], col[2], width, dash, x1, y1, x2, y2)
+}
+
+func (p *pdfPage) polyline(xs, ys []float64, width float64, col pdfColor) {
+	if len(xs) < 2 {
+		return
+	}
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f RG %.2f w [] 0 d %.1f %.1f m", col[0], col[1], col[2], width, xs[0], ys[0])
+	for i := 1; i < len(xs); i++ {
+		fmt.Fprintf(&p.content, " %.1f %.1f l", xs[i], ys[i])
+	}
+	p.content.WriteString(" S\n")
+}
+
+func (p *pdfPage) rect(x, y, w, h float64, col pdfColor) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f rg %.1f %.1f %.1f %.1f re f\n", col[0], col[1], col[2], x, y, w, h)
+}
+
+// bytes wraps the content stream into a complete PDF file
+func (p *pdfPage) bytes() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// writePDF renders the report as a one-page A4 PDF
+func (r loudnessReport) writePDF(path string) error {
+	var p pdfPage
+	width := pdfPageWidth - 2*pdfMargin
+	valueX := pdfMargin + 190
+	y := pdfPageHeight - pdfMargin
+
+	p.rect(pdfMargin, y-4, 4, 20, pdfAccent)
+	p.text(pdfMargin+12, y, 16, true, pdfNavy, fitText("Loudness report: "+filepath.Base(r.Output), width-12, 16))
+	y -= 24
+	verdictColor := pdfRed
+	if r.compliant() {
+		verdictColor = pdfGreen
+	}
+	p.text(pdfMargin, y, 11, true, verdictColor, r.verdict())
+
+	rows := func(title string, rows []reportRow) {
+		y -= 28
+		p.text(pdfMargin, y, 12, true, pdfNavy, title)
+		y -= 6
+		for _, row := range rows {
+			y -= 15
+			p.text(pdfMargin, y, 9, false, pdfGrey, row.Name)
+			p.text(valueX, y, 9, false, pdfNavy, fitText(row.Value, pdfPageWidth-pdfMargin-valueX, 9))
+			p.line(pdfMargin, y-4, pdfPageWidth-pdfMargin, y-4, 0.5, pdfLight, false)
+		}
+	}
+	rows("Measurements", r.measurements())
+
+	// The chart, with room on the left for the loudness labels
+	y -= 30
+	p.text(pdfMargin, y, 12, true, pdfNavy, "Loudness over time")
+	chartLeft, chartWidth, chartHeight := pdfMargin+30, width-30, 170.0
+	chartTop := y - 14
+	chartX := func(f float64) float64 { return chartLeft + f*chartWidth }
+	chartY := func(f float64) float64 { return chartTop - f*chartHeight }
+
+	scale := newReportScale(r)
+	for _, t := range scale.grid() {
+		p.line(chartLeft, chartY(t.At), chartLeft+chartWidth, chartY(t.At), 0.5, pdfLight, false)
+		p.text(pdfMargin, chartY(t.At)-3, 8, false, pdfGrey, t.Label)
+	}
+	for _, t := range scale.timeTicks() {
+		p.text(chartX(t.At)-10, chartY(1)-12, 8, false, pdfGrey, t.Label)
+	}
+	points := r.Curve.Points(chartPoints)
+	xs := make([]float64, len(points))
+	momentary := make([]float64, len(points))
+	shortTerm := make([]float64, len(points))
+	for i, point := range points {
+		xs[i] = chartX(scale.x(point.Time))
+		momentary[i] = chartY(scale.y(point.Momentary))
+		shortTerm[i] = chartY(scale.y(point.ShortTerm))
+	}
+	p.polyline(xs, momentary, 0.5, pdfBlue)
+	p.polyline(xs, shortTerm, 1, pdfNavy)
+	targetY := chartY(scale.y(r.TargetI))
+	p.line(chartLeft, targetY, chartLeft+chartWidth, targetY, 1, pdfAccent, true)
+
+	y = chartY(1) - 28
+	legendX := chartLeft
+	for _, entry := range []struct {
+		label string
+		col   pdfColor
+	}{{"Momentary", pdfBlue}, {"Short-term", pdfNavy}, {"Target", pdfAccent}} {
+		p.rect(legendX, y, 8, 8, entry.col)
+		p.text(legendX+12, y, 8, false, pdfGrey, entry.label)
+		legendX += 80
+	}
+	p.text(legendX, y, 8, false, pdfGrey, "(LUFS)")
+
+	processing := append([]reportRow{{"Input", r.Input}, {"Output", r.Output}}, r.Settings...)
+	processing = append(processing, reportRow{"TNT version", r.Version}, reportRow{"FFmpeg", r.FFmpeg})
+	rows("Processing", processing)
+
+	p.text(pdfMargin, pdfMargin-20, 8, false, pdfGrey, "Generated by TNT on "+r.Date.Format("2006-01-02 15:04:05 MST"))
+
+	return os.WriteFile(platform.LongPath(path), p.bytes(), 0644)
+}
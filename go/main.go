@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
@@ -12,12 +13,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -31,6 +32,7 @@ import (
 	"github.com/fremen-fi/tnt/go/internal/audio"
 	"github.com/fremen-fi/tnt/go/internal/config"
 	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/internal/license"
 	"github.com/fremen-fi/tnt/go/platform"
 )
 
@@ -48,6 +50,11 @@ type VersionInfo struct {
 	OS           []string            `json:"os"`
 	DownloadURL  []map[string]string `json:"download_url"`
 	ReleaseNotes string              `json:"release_notes"`
+	// FFmpeg builds by platform key, updated apart from TNT, see ffmpeg_update.go
+	FFmpeg       map[string]FFmpegBundle `json:"ffmpeg"`
+	// Versions below the minimum, or before a critical release, may not process
+	MinimumVersion string `json:"minimum_version"`
+	Critical     bool                `json:"critical"`
 }
 
 type AudioNormalizer struct {
@@ -56,10 +63,40 @@ type AudioNormalizer struct {
 	files        []string
 	outputDir    string
 	processBtn   *widget.Button
+	quickBtn     *widget.Button
 	progressBar  *widget.ProgressBar
+	etaLabel     *widget.Label
+	speedModel   *speedModel
 	statusLog    *widget.Entry
+	statusLines  *statusLogBuffer
+	// coalesced redraws of the status log and the queue, see throttle.go
+	statusRefresh *uiThrottle
+	queueRefresh *uiThrottle
 	outputLabel  *widget.Label
 
+	// background service, see service.go. A headless normalizer never
+	// prompts; questions are answered with the safe default.
+	headless       bool
+	// line-delimited JSON over stdio for wrapper scripts, see jsonrpc.go
+	rpc *jsonRPC
+	serviceState   serviceState
+	serviceStarted time.Time
+	serviceMutex   sync.Mutex
+	// forwarding of warnings and errors to syslog or the event log, see systemlog.go
+	systemLogCheck *widget.Check
+	systemLog      platform.SystemLog
+	// opt-in anonymous usage statistics, see usage.go
+	usageStatsCheck *widget.Check
+	usageStats      *usageStats
+	// components caching settings hear of every save, see preferences_store.go
+	prefsMutex     sync.Mutex
+	prefsListeners []chan Preferences
+	// rotation of the log by day and size, see logrotate.go
+	logMutex         sync.Mutex
+	logDay           string
+	logSize          int64
+	logRetentionDrop *widget.Select
+
 	modeTabs *container.AppTabs
 	modeWarning *widget.Label
 
@@ -69,6 +106,11 @@ type AudioNormalizer struct {
 
 	// Simple mode
 	simpleGroupButtons *widget.RadioGroup
+	fastReplaceBuiltins bool
+	// main window preset dropdown and the preset chosen in it, see presets.go
+	presetDrop *widget.Select
+	activePreset string
+	presets *presetStore
 	simpleGroup *fyne.Container
 
 	// Advanced mode
@@ -79,10 +121,18 @@ type AudioNormalizer struct {
 	normalizeTarget *widget.Entry
 	normalizeTargetTp *widget.Entry
 	advancedContainer *fyne.Container
+	extraTargetsGroup *widget.CheckGroup
 
 	// Common
 	loudnormCheck *widget.Check
 	loudnormCustomCheck *widget.Check
+	loudnormFallback *widget.Select
+	// advanced loudnorm options, see loudness.go
+	loudnormDualMonoCheck *widget.Check
+	loudnormOffsetDrop *widget.Select
+	loudnormOffsetEntry *widget.Entry
+	// BS.1770 revision loudness is measured to, see loudness.go
+	measurementStandardDrop *widget.Select
 	loudnormLabel *widget.Label
 	writeTagsLabel *widget.Label
 	normalizeTargetLabel *widget.Label
@@ -101,30 +151,204 @@ type AudioNormalizer struct {
 	//dynNormLabel *widget.Label
 	dynNorm *widget.Check
 	dynNormLabel *widget.Label
+	speechnormDrop *widget.Select
+	restorationCheck *widget.Check
+	rumbleCheck *widget.Check
+	channelAlignCheck *widget.Check
 	bypassProc *widget.Check
-
-	multibandFilter string
+	// block diagram of the stages that will run, see chain.go
+	chainDiagram *fyne.Container
 
 	logFile *os.File
 
 	// watchmode
 	watchMode *widget.Check
+	watchMeasureOnly *widget.Check
+	watchAlertCheck *widget.Check
+	watchRescanDrop *widget.Select
+	watchPaceDrop *widget.Select
+	watchRetryDrop *widget.Select
+	// folders watched with their own preset and output, see watchfolders.go
+	watchFolders *watchFolderStore
+	// waiting for watched files to finish arriving, see stabilize.go
+	watchQuietDrop *widget.Select
+	watchProbeCheck *widget.Check
+	watchSettling watchSettling
+	// subfolders of watched folders, see watchtree.go
+	watchRecursiveCheck *widget.Check
+	watchQuarantineDrop *widget.Select
+	// failure lines of the watched file being handled, see quarantine.go
+	watchFailures failureCapture
+	watchHistory *watchHistory
+	// successful runs by content and settings, see batch_history.go
+	batchHistory *batchHistory
+	// QC sign-off and notes per processing history row, see history.go
+	qcReviews *qcReviewStore
+	// outputs renamed for one batch so same-named files don't collide, see collisions.go
+	plannedOutputs map[string]string
+	incrementalCheck *widget.Check
+	// chime and badge when work finishes, see notify.go
+	notifySoundDrop *widget.Select
+	notifySoundFileEntry *widget.Entry
+	notifyBadgeCheck *widget.Check
+	// speech and scene/take hints from broadcast WAVs, see bwf.go
+	bwfHintsCheck *widget.Check
+	bwfTemplateEntry *widget.Entry
+	// marked preview copies for outside use, see preview.go
+	previewCopyCheck *widget.Check
+	previewMarkDrop *widget.Select
+	previewOffsetEntry *widget.Entry
+	previewLevelEntry *widget.Entry
+	previewIdentEntry *widget.Entry
+	// second output at a web loudness target, see webversion.go
+	webVersionCheck *widget.Check
+	webTargetEntry *widget.Entry
+	webTargetTpEntry *widget.Entry
+	webFormatDrop *widget.Select
+	// comparison with external analyzers, see qccompare.go
+	qcToleranceEntry *widget.Entry
+	// piped processing of recordings many hours long, see longfile.go
+	longFileCheck *widget.Check
+	longFileHoursEntry *widget.Entry
+	stageTempFilesCheck *widget.Check
+	// worker count and FFmpeg CPU limits, see performance.go
+	workersEntry *widget.Entry
+	ffmpegThreadsEntry *widget.Entry
+	lowPriorityCheck *widget.Check
+	// series drift tracking, see series.go
+	seriesEntry *widget.Entry
+	seriesAlertCheck *widget.Check
+	seriesHistory *seriesHistory
+	// JSON and CSV report of each batch, see report.go
+	batchReportCheck *widget.Check
+	// conversion to the rates of fixed-rate encoders, see resample.go
+	srcQualityDrop *widget.Select
+	// ReplayGain album gain of a batch, see album.go
+	albumModeCheck *widget.Check
+	// source tags and artwork taken over, see metadatacopy.go
+	stripMetadataCheck *widget.Check
+	inForeground atomic.Bool
 	watching bool
 	watcherStop chan bool
 	jobQueue chan string
 	inputDir string
 	watcherWarnLabel *widget.Label
+	// listening tap on the watched file being processed, see tap.go
+	tapBtn *widget.Button
+	tapStopBtn *widget.Button
+	watchCurrent string
 
 	watcherMutex sync.Mutex
 
 	// phase check items
 	checkPhaseBtn *widget.Check
 
+	// loudness badge
+	loudnessBadgeCheck *widget.Check
+
+	// loudness compliance report, see loudreport.go
+	loudnessReportDrop *widget.Select
+
+	// objective quality score of lossy outputs
+	qualityScoreCheck *widget.Check
+
+	// station metadata
+	metaPublisher *widget.Entry
+	metaCopyright *widget.Entry
+	metaEncodedBy *widget.Entry
+	// per-file tags from templates, see tagtemplate.go
+	tagTemplatesEntry *widget.Entry
+	loudnessTagsDrop *widget.Select
+
+	// tags imported from a CSV, keyed by tagMapKey
+	tagMap map[string]tagMapping
+	tagMapLabel *widget.Label
+
+	// audit trail
+	operator string
+	askOperatorCheck *widget.Check
+	auditSidecarCheck *widget.Check
+
+	// manual per-file gain trims in dB, keyed by path
+	gainTrims map[string]float64
+	// in and out points set in the trim editor, by input path, see trim.go
+	trims map[string]trimRange
+	// anchor regions typed into the queue, by input path
+	anchors map[string]audio.AnchorRegion
+	anchorMarkersCheck *widget.Check
+
+	// CD rips, see cdsource.go
+	keepEmphasisCheck *widget.Check
+
+	// filters for Select Folder scans, see folderfilter.go
+	folderMinEntry *widget.Entry
+	folderMaxEntry *widget.Entry
+	folderSinceEntry *widget.Entry
+	folderSkipHiddenCheck *widget.Check
+
+	// music library mode, see musiclib.go
+	musicLibCheck *widget.Check
+	maxTrackGainEntry *widget.Entry
+	preventClipCheck *widget.Check
+	// clip guard for tags written without transcoding, see clipguard.go
+	tagClipCapCheck *widget.Check
+	musicLibFlagged atomic.Int32
+
+	// a batch is running, so Quick normalizes a chosen file ahead of it, see quick.go
+	batchRunning atomic.Bool
+	// progress of files being processed, shown in their rows, see fileprogress.go
+	rowProgress sync.Map
+	fileListRefresh *uiThrottle
+	// loudness of queued files from the pre-flight scan, see compliance.go
+	compliance sync.Map
+	// pause and cancel of the running batch, see batchcontrol.go
+	batchControl *batchControl
+	pauseBtn *widget.Button
+	cancelBtn *widget.Button
+
+	// audio extracted from ZIP deliveries, keyed by extracted path
+	zipSources map[string]zipSource
+	zipTempDirs []string
+	zipKeepFoldersCheck *widget.Check
+
+	// output timestamps and air date
+	timestampDrop *widget.Select
+	airDateEntry *widget.Entry
+	airDateTagCheck *widget.Check
+
+	// channel mapping
+	channelCheck *widget.Check
+	channelFixDrop *widget.Select
+
 	// batch processing
 	batchMode bool
 
 	menuWindow fyne.Window
 	menuMutex  sync.Mutex
+	// the Menu window and its tabs while it is open, for the command palette
+	openMenu func()
+	menuTabs *container.AppTabs
+	functionsTabs *container.AppTabs
+	// command palette, see palette.go
+	paletteActions []paletteCommand
+	palette *widget.PopUp
+
+	// file browser
+	browserWindow fyne.Window
+	recentFolders []string
+	favoriteFolders []string
+	// security-scoped bookmarks of remembered folders, macOS only
+	folderBookmarks map[string][]byte
+
+	// output folder pre-flight
+	outputCheckMutex sync.Mutex
+	outputAborted bool
+
+	// Pro features, see licensing.go
+	license *licenseStore
+
+	// end-of-day summary, see shiftreport.go
+	shiftReport *shiftReportStore
 
 	mutex sync.Mutex
 }
@@ -145,7 +369,87 @@ type ProcessConfig struct {
 	bypassProc bool
 	EqTarget string
 	DynNorm bool
+	Restoration bool
+	// digitized carriers, see digitization.go
+	RumbleFilter bool
+	ChannelAlign bool
 	PhaseCheck bool
+	LoudnessBadge bool
+	LoudnessReport string
+	LoudnormFallback string
+	Loudnorm audio.LoudnormOptions
+	Speechnorm string
+	ChannelCheck bool
+	ChannelFix string
+	channelFilter string
+	ExtraTargets []string
+	TargetI string
+	TargetTp string
+	Metadata map[string]string
+	TagTemplates []tagTemplate
+	LoudnessTags string
+	QualityScore bool
+	PresetName string
+	Analyzers []AnalyzerPlugin
+	analysis map[string]analyzerResult
+	// background batch work yields to interactive jobs, see quickNow
+	background bool
+	// measuredBy is the loudness backend of the job's stored values, and
+	// measuredTo the BS.1770 revision, see loudness.go
+	measuredBy string
+	measuredTo string
+	// readRate paces watch jobs to a multiple of realtime, see watchReadRate
+	readRate float64
+	// watchRoot is the watched folder a file arrived under, its subfolders
+	// are mirrored into the output folder, see watchtree.go
+	watchRoot string
+	// simulation jobs stay out of the processing history, see simulation.go
+	simulation bool
+	OutputDir string
+	FilenameTemplate string
+	TimestampMode string
+	AirDate time.Time
+	AirDateTag bool
+	AuditSidecar bool
+	ZipKeepFolders bool
+	AnchorMarkers bool
+	MusicLibrary bool
+	MaxTrackGain string
+	PreventClipping bool
+	TagClipCap bool
+	KeepEmphasis bool
+	Incremental bool
+	BWFHints bool
+	BWFTemplate string
+	PreviewCopy bool
+	PreviewMark string
+	PreviewOffset string
+	PreviewLevel string
+	PreviewIdent string
+	WebVersion bool
+	WebTarget string
+	WebTargetTp string
+	WebFormat string
+	LongFile bool
+	LongFileHours string
+	StageTempFiles bool
+	Series []seriesRule
+	BatchReport bool
+	SRCQuality string
+	// range of a clip list processed as its own output, see cliplist.go
+	clip *clipEntry
+	AlbumMode bool
+	StripMetadata bool
+	// album gain tagged next to the track gain, see album.go
+	album *albumGain
+	// row of the batch report filled in while processing, see report.go
+	report *fileReport
+	// cancels the FFmpeg runs of a batch, see batchcontrol.go
+	ctx context.Context
+	// stage filters of a long file, run in its final encodes, see longfile.go
+	piped []string
+	// production metadata of a broadcast WAV, read per file
+	bwf audio.BWFInfo
 }
 
 type DynamicsAnalysis struct {
@@ -156,6 +460,8 @@ type DynamicsAnalysis struct {
 	DynamicRange  float64
 	RMSLevel      float64
 	NoiseFloor float64
+	// Confidence flags values that weren't parsed or are implausible
+	Confidence audio.Confidence
 }
 
 type FrequencyBandAnalysis struct {
@@ -164,6 +470,7 @@ type FrequencyBandAnalysis struct {
 	RMSLevel     float64
 	CrestFactor  float64
 	DynamicRange float64
+	Confidence   audio.Confidence
 }
 
 func getPlatformKey() string {
@@ -201,11 +508,31 @@ func checkForUpdates(currentVersion string, window fyne.Window, logFile *os.File
 		return
 	}
 
+	go updateFFmpeg(versionInfo, logFile)
+
+	// The feed can retire this version, blocking processing until it is updated
+	reason := updatePolicy(versionInfo, currentVersion)
+	setUpdateBlock(currentVersion, reason)
+
 	logToFile(logFile, fmt.Sprintf("Current: %s, Remote: %s", currentVersion, versionInfo.Version))
 	comparison := compareVersions(versionInfo.Version, currentVersion)
 	logToFile(logFile, fmt.Sprintf("Comparison result: %d", comparison))
 
-	if comparison > 0 {
+	if reason != "" {
+		logToFile(logFile, fmt.Sprintf("Processing blocked: %s", reason))
+		fyne.Do(func() {
+			dialog.ShowConfirm(
+				"Update Required",
+				fmt.Sprintf("%s\n\n%s", reason, versionInfo.ReleaseNotes),
+				func(download bool) {
+					if download {
+						downloadAndInstallUpdate(versionInfo, window)
+					}
+				},
+				window,
+			)
+		})
+	} else if comparison > 0 {
 		logToFile(logFile, "Update available, showing dialog...")
 		fyne.Do(func() {
 			dialog.ShowConfirm(
@@ -383,62 +710,48 @@ case "penguin":
 	}()
 }
 
-func extractFFmpeg() string {
-	// Extract to temp location
-	tmpDir := os.TempDir()
-
-	var name string
-	if runtime.GOOS == "windows" {
-		name = "ffmpeg.exe"
-	} else {
-		name = "ffmpeg"
-	}
-
-	ffmpegPath := filepath.Join(tmpDir, name)
-	os.WriteFile(ffmpegPath, platform.FFmpegBinary, 0755)
-	return ffmpegPath
-}
-
-var ffmpegPath string
-
-func init() {
-	ffmpegPath = extractFFmpeg()
-}
-
 func (n *AudioNormalizer) initLogFile() *os.File {
-	configDir, _ := os.UserConfigDir()
-	logDir := filepath.Join(configDir, "TNT")
+	logDir := dataDir()
 	os.MkdirAll(logDir, 0755)
 
-	logPath := filepath.Join(logDir, "tnt.log")
-
-	if data, err := os.ReadFile(logPath); err == nil {
-		lines := strings.Count(string(data), "\n")
-		if lines > 1000 { // Keep last 1000 lines
-			allLines := strings.Split(string(data), "\n")
-			keepLines := allLines[len(allLines)-1000:]
-			os.WriteFile(logPath, []byte(strings.Join(keepLines, "\n")), 0644)
-		}
-	}
+	logPath := filepath.Join(logDir, logName)
 
 	logfile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil
 	}
 
+	// A log left from an earlier day or grown too big is rotated on the first line
+	if info, err := logfile.Stat(); err == nil {
+		n.logSize = info.Size()
+		if info.Size() > 0 {
+			n.logDay = info.ModTime().Format(time.DateOnly)
+		}
+	}
+
 	return logfile
 }
 
 func (n *AudioNormalizer) logToFile(logFile *os.File, message string) {
 	if logFile != nil {
-		timestamp := time.Now().Format("2006-01-02 15:04:05")
-		logFile.WriteString(fmt.Sprintf("[%s] %s\n", timestamp, message))
+		n.logMutex.Lock()
+		defer n.logMutex.Unlock()
+
+		now := time.Now()
+		if logFile == n.logFile {
+			n.rotateLogIfNeeded(now)
+		}
+		written, _ := logFile.WriteString(fmt.Sprintf("[%s] %s\n", now.Format("2006-01-02 15:04:05"), message))
+		if logFile == n.logFile {
+			n.logSize += int64(written)
+		}
 	}
 }
 
-func (n *AudioNormalizer) sendLogReport() {
-	configDir, _ := os.UserConfigDir()
-	logPath := filepath.Join(configDir, "TNT", "tnt.log")
+// mailLogReport opens the email client with the log attached, for when the
+// feedback form can't reach the support server
+func (n *AudioNormalizer) mailLogReport() {
+	logPath := filepath.Join(dataDir(), logName)
 
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
 		dialog.ShowInformation("No Log File", "No log file found. Try processing some files first.", n.window)
@@ -454,6 +767,7 @@ func (n *AudioNormalizer) sendLogReport() {
 	switch runtime.GOOS {
 	case "darwin":
 		// macOS: Use osascript to create email with attachment
+		// Values are escaped so paths containing quotes or backslashes survive
 		script := fmt.Sprintf(`tell application "Mail"
 			set theMessage to make new outgoing message with properties {subject:"%s", content:"%s", visible:true}
 			tell theMessage
@@ -461,7 +775,7 @@ func (n *AudioNormalizer) sendLogReport() {
 				make new attachment with properties {file name:POSIX file "%s"}
 			end tell
 			activate
-		end tell`, subject, body, logPath)
+		end tell`, appleScriptEscape(subject), appleScriptEscape(body), appleScriptEscape(logPath))
 		cmd = exec.Command("osascript", "-e", script)
 	case "linux":
 		cmd = exec.Command("xdg-email",
@@ -480,7 +794,8 @@ func (n *AudioNormalizer) sendLogReport() {
 		mailtoURL := fmt.Sprintf("mailto:appsupport@collinsgroup.fi?subject=%s&body=%s",
 			strings.ReplaceAll(subject, " ", "%20"),
 			strings.ReplaceAll(body, "\n", "%0D%0A"))
-		exec.Command("cmd", "/c", "start", mailtoURL)
+		// Not through cmd, which would split the URL at its &
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", mailtoURL)
 
 	}
 
@@ -502,75 +817,59 @@ func (n *AudioNormalizer) sendLogReport() {
 	}
 }
 
-func (n *AudioNormalizer) analyzeDynamics(inputPath string) *DynamicsAnalysis {
-	cmd := ffmpeg.Command(
-		"-i", inputPath,
-		"-af", "astats=metadata=1:length=0.05",
-		"-f", "null",
-		"-",
-	)
-
+// appleScriptEscape escapes a value for use inside an AppleScript string literal
+func appleScriptEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		n.logToFile(n.logFile, fmt.Sprintf("astats failed: %v", err))
-		return nil
+// analysisReliable warns when an analysis has values that can't be trusted,
+// so the stage built on it can be left out rather than run with nonsense
+// settings
+func (n *AudioNormalizer) analysisReliable(inputPath, analysis string, c audio.Confidence) bool {
+	if c.Reliable() {
+		return true
 	}
-
-	// LOG THE RAW OUTPUT
-	//n.logToFile(n.logFile, "=== RAW ASTATS OUTPUT START ===")
-	//n.logToFile(n.logFile, string(output))
-	//n.logToFile(n.logFile, "=== RAW ASTATS OUTPUT END ===")
-
-	return n.parseAstatsOutput(string(output))
+	n.logStatus(fmt.Sprintf("⚠ %s analysis unreliable: %s - %s", analysis, filepath.Base(inputPath), c))
+	n.logToFile(n.logFile, fmt.Sprintf("%s analysis of %s unreliable: %s", analysis, inputPath, c))
+	return false
 }
 
 func (n *AudioNormalizer) analyzeFrequencyBands(inputPath string) map[string]*FrequencyBandAnalysis {
-	bands := map[string]string{
-		"sub":     "lowpass=f=80",
-		"bass":    "highpass=f=80,lowpass=f=250",
-		"low_mid": "highpass=f=250,lowpass=f=1000",
-		"mid":     "highpass=f=1000,lowpass=f=4000",
-		"high":    "highpass=f=4000",
+	reports, err := audio.Analyze(inputPath, "", multibandBranches())
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Band analysis failed: %v", err))
+		return nil
 	}
+	return n.bandsFromStats(inputPath, reports, 0)
+}
 
+// bandsFromStats reads the multiband analysis out of a combined analysis
+// run. offset is a gain in dB applied after the measurement, the levels
+// move with it while crest factors and ranges stay.
+func (n *AudioNormalizer) bandsFromStats(inputPath string, reports map[string]audio.AstatsReport, offset float64) map[string]*FrequencyBandAnalysis {
 	results := make(map[string]*FrequencyBandAnalysis)
 
 	n.logToFile(n.logFile, fmt.Sprintf("=== FREQUENCY BAND ANALYSIS START: %s ===", filepath.Base(inputPath)))
 
-	for bandName, filter := range bands {
-		cmd := exec.Command(
-			ffmpegPath,
-			"-i", inputPath,
-			"-af", fmt.Sprintf("%s,astats", filter),
-			"-f", "null",
-			"-",
-		)
-
-
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			n.logToFile(n.logFile, fmt.Sprintf("Band %s analysis failed: %v", bandName, err))
+	for bandName := range audio.FrequencyBandFilters() {
+		report, ok := reports[bandName]
+		if !ok {
 			continue
 		}
-
-		// Log raw output for this band
-		//n.logToFile(n.logFile, fmt.Sprintf("--- RAW OUTPUT FOR BAND: %s ---", bandName))
-		//n.logToFile(n.logFile, string(output))
-		//n.logToFile(n.logFile, "--- END RAW OUTPUT ---")
-
-		// Parse the output
-		analysis := n.parseFrequencyBandOutput(string(output), bandName)
-		if analysis != nil {
-			results[bandName] = analysis
-
-			// Log parsed results
-			n.logToFile(n.logFile, fmt.Sprintf("Band %s Results:", bandName))
-			n.logToFile(n.logFile, fmt.Sprintf("  Peak: %.2f dBFS", analysis.PeakLevel))
-			n.logToFile(n.logFile, fmt.Sprintf("  RMS: %.2f dBFS", analysis.RMSLevel))
-			n.logToFile(n.logFile, fmt.Sprintf("  Crest: %.2f", analysis.CrestFactor))
-			n.logToFile(n.logFile, fmt.Sprintf("  Range: %.2f dB", analysis.DynamicRange))
+		analysis := n.bandFromStats(report, bandName)
+		if analysis == nil {
+			continue
 		}
+		analysis.PeakLevel += offset
+		analysis.RMSLevel += offset
+		results[bandName] = analysis
+
+		// Log parsed results
+		n.logToFile(n.logFile, fmt.Sprintf("Band %s Results:", bandName))
+		n.logToFile(n.logFile, fmt.Sprintf("  Peak: %.2f dBFS", analysis.PeakLevel))
+		n.logToFile(n.logFile, fmt.Sprintf("  RMS: %.2f dBFS", analysis.RMSLevel))
+		n.logToFile(n.logFile, fmt.Sprintf("  Crest: %.2f", analysis.CrestFactor))
+		n.logToFile(n.logFile, fmt.Sprintf("  Range: %.2f dB", analysis.DynamicRange))
 	}
 
 	n.logToFile(n.logFile, "=== FREQUENCY BAND ANALYSIS END ===")
@@ -578,39 +877,20 @@ func (n *AudioNormalizer) analyzeFrequencyBands(inputPath string) map[string]*Fr
 	return results
 }
 
-func (n *AudioNormalizer) parseFrequencyBandOutput(output string, bandName string) *FrequencyBandAnalysis {
+func (n *AudioNormalizer) bandFromStats(report audio.AstatsReport, bandName string) *FrequencyBandAnalysis {
 	result := &FrequencyBandAnalysis{BandName: bandName}
+	c := &result.Confidence
 
-	// Find Overall section
-	overallStart := strings.Index(output, "Overall")
-	if overallStart == -1 {
+	if len(report.Overall) == 0 {
 		return nil
 	}
-	overallSection := output[overallStart:]
-
-	// Parse: Peak level dB: -65.832755
-	peakRe := regexp.MustCompile(`Peak level dB:\s+([-\d.]+)`)
-	if match := peakRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.PeakLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: RMS level dB: -76.472639
-	rmsRe := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-	if match := rmsRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
 
-	// Parse: Crest factor: 2.982689 (from channel section)
-	crestRe := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-	if match := crestRe.FindStringSubmatch(output); len(match) > 1 {
-		result.CrestFactor, _ = strconv.ParseFloat(match[1], 64)
-	}
+	result.PeakLevel = c.Stat(report.Overall, "Peak level dB", audio.LevelRange)
+	result.RMSLevel = c.Stat(report.Overall, "RMS level dB", audio.LevelRange)
 
-	// Parse: Dynamic range: 51.779619 (from channel section)
-	dynRe := regexp.MustCompile(`Dynamic range:\s+([-\d.]+)`)
-	if match := dynRe.FindStringSubmatch(output); len(match) > 1 {
-		result.DynamicRange, _ = strconv.ParseFloat(match[1], 64)
-	}
+	// Crest factor and dynamic range from the channel section
+	result.CrestFactor = c.Stat(report.Channel(1), "Crest factor", audio.CrestRange)
+	result.DynamicRange = c.Stat(report.Channel(1), "Dynamic range", audio.DynamicRangeRange)
 
 	return result
 }
@@ -864,97 +1144,29 @@ func (n *AudioNormalizer) buildBandAcompressor(band *FrequencyBandAnalysis, atta
 	//thresholdLin, ratio, attackMs, releaseMs, makeupLin)
 }
 
-func (n *AudioNormalizer) measureLoudnessFromFilter(inputPath string, filterChain string) map[string]string {
-	n.logStatus(fmt.Sprintf("→ Measuring compressed audio: %s", filepath.Base(inputPath)))
-
-	target := "-23"
-	if n.loudnormCustomCheck.Checked && n.normalizeTarget.Text != "" {
-		if strings.Contains(n.normalizeTarget.Text, "-") {
-			target = n.normalizeTarget.Text
-		} else {
-			target = "-" + n.normalizeTarget.Text
-		}
-	}
-
-	targetTp := "-1"
-	if n.loudnormCustomCheck.Checked && n.normalizeTargetTp.Text != "" {
-		if strings.Contains(n.normalizeTargetTp.Text, "-") {
-			targetTp = n.normalizeTargetTp.Text
-		} else {
-			targetTp = "-" + n.normalizeTargetTp.Text
-		}
-	}
-
-	cmd := exec.Command(
-		ffmpegPath,
-		"-i", inputPath,
-		"-af", fmt.Sprintf("%s,loudnorm=linear=false:I=%s:TP=%s:LRA=5:print_format=json", filterChain, target, targetTp),
-		"-f", "null",
-		"-",
-	)
-
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil
-	}
-
-	return n.parseLoudnormJSON(string(output))
-}
-
-func (n *AudioNormalizer) parseAstatsOutput(output string) *DynamicsAnalysis {
+func (n *AudioNormalizer) dynamicsFromStats(report audio.AstatsReport) *DynamicsAnalysis {
 	result := &DynamicsAnalysis{}
+	c := &result.Confidence
 
-	// Look for "Overall" section and parse from there
-	// Format: [Parsed_astats_0 @ 0xXXXXXXXXX] Peak level dB: -65.832755
-
-	// Extract Overall section
-	overallStart := strings.Index(output, "Overall")
-	if overallStart == -1 {
+	if len(report.Overall) == 0 {
+		c.Problems = append(c.Problems, "no overall statistics")
 		return result
 	}
-	overallSection := output[overallStart:]
-
-	// Parse: Peak level dB: -65.832755
-	peakRe := regexp.MustCompile(`Peak level dB:\s+([-\d.]+)`)
-	if match := peakRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.PeakLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: RMS peak dB: -75.013939
-	rmsPeakRe := regexp.MustCompile(`RMS peak dB:\s+([-\d.]+)`)
-	if match := rmsPeakRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSPeak, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse: RMS trough dB: -78.685114
-	rmsTroughRe := regexp.MustCompile(`RMS trough dB:\s+([-\d.]+)`)
-	if match := rmsTroughRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSTrough, _ = strconv.ParseFloat(match[1], 64)
-	}
+	overall := report.Overall
 
-	// Parse: RMS level dB: -76.472639
-	rmsRe := regexp.MustCompile(`RMS level dB:\s+([-\d.]+)`)
-	if match := rmsRe.FindStringSubmatch(overallSection); len(match) > 1 {
-		result.RMSLevel, _ = strconv.ParseFloat(match[1], 64)
-	}
-
-	// Parse from Channel 1 section (before Overall): Crest factor: 2.982689
-	crestRe := regexp.MustCompile(`Crest factor:\s+([-\d.]+)`)
-	if match := crestRe.FindStringSubmatch(output); len(match) > 1 {
-		result.CrestFactor, _ = strconv.ParseFloat(match[1], 64)
-	}
+	result.PeakLevel = c.Stat(overall, "Peak level dB", audio.LevelRange)
+	result.RMSPeak = c.Stat(overall, "RMS peak dB", audio.LevelRange)
+	result.RMSLevel = c.Stat(overall, "RMS level dB", audio.LevelRange)
 
-	// Parse from Channel 1 section: Dynamic range: 51.779619
-	dynRe := regexp.MustCompile(`Dynamic range:\s+([-\d.]+)`)
-	if match := dynRe.FindStringSubmatch(output); len(match) > 1 {
-		result.DynamicRange, _ = strconv.ParseFloat(match[1], 64)
-	}
+	// RMS trough is -inf wherever the file has digital silence, and is only logged
+	var trough audio.Confidence
+	result.RMSTrough = trough.Stat(overall, "RMS trough dB", audio.LevelRange)
 
-	noiseFloorRe := regexp.MustCompile(`Noise floor dB:\s+([-\d.]+)`)
-	if match := noiseFloorRe.FindStringSubmatch(output); len(match) > 1 {
-		result.NoiseFloor, _ = strconv.ParseFloat(match[1], 64)
-	}
+	// Crest factor, dynamic range and noise floor come from the Channel 1 section
+	channel1 := report.Channel(1)
+	result.CrestFactor = c.Stat(channel1, "Crest factor", audio.CrestRange)
+	result.DynamicRange = c.Stat(channel1, "Dynamic range", audio.DynamicRangeRange)
+	result.NoiseFloor = c.Stat(channel1, "Noise floor dB", audio.NoiseFloorRange)
 
 	return result
 }
@@ -1129,26 +1341,7 @@ func calculateMakeupGain(analysis *DynamicsAnalysis, threshold, ratio float64) f
 }
 
 func (n *AudioNormalizer) getDuration(inputPath string) (float64, error) {
-	cmd := ffmpeg.Command( "-i", inputPath, "-f", "null", "-")
-
-
-	output, _ := cmd.CombinedOutput()
-	outputStr := string(output)
-
-	// Parse "Duration: 00:01:04.03"
-	re := regexp.MustCompile(`Duration: (\d{2}):(\d{2}):(\d{2}\.\d{2})`)
-	matches := re.FindStringSubmatch(outputStr)
-
-	if len(matches) < 4 {
-		return 0, fmt.Errorf("could not parse duration")
-	}
-
-	hours, _ := strconv.ParseFloat(matches[1], 64)
-	minutes, _ := strconv.ParseFloat(matches[2], 64)
-	seconds, _ := strconv.ParseFloat(matches[3], 64)
-
-	totalSeconds := hours*3600 + minutes*60 + seconds
-	return totalSeconds, nil
+	return audio.ProbeDuration(inputPath)
 }
 
 func (n *AudioNormalizer) calculateOutputSize(config ProcessConfig) (int64, error) {
@@ -1162,6 +1355,7 @@ func (n *AudioNormalizer) calculateOutputSize(config ProcessConfig) (int64, erro
 		}
 
 		var fileSize int64
+		channels := outputChannels(file, config)
 
 		if config.Format == "PCM" {
 			// PCM: sample_rate × (bit_depth / 8) × channels × duration
@@ -1181,11 +1375,13 @@ func (n *AudioNormalizer) calculateOutputSize(config ProcessConfig) (int64, erro
 				bitDepthBits = 24
 			}
 
-			channels := 2.0 // Stereo
-			fileSize = int64(sampleRate * (bitDepthBits / 8) * channels * duration)
+			fileSize = int64(sampleRate * (bitDepthBits / 8) * float64(channels) * duration)
 		} else {
-			// Lossy: (bitrate_kbps × 1000 / 8) × duration
+			// Lossy: (bitrate_kbps × 1000 / 8) × duration, mono at half the bitrate
 			bitrate, _ := strconv.ParseFloat(config.Bitrate, 64)
+			if channels == 1 {
+				bitrate = monoKbps(bitrate)
+			}
 			fileSize = int64((bitrate * 1000 / 8) * duration)
 		}
 
@@ -1252,22 +1448,104 @@ type Preferences struct {
 	EqPreset string `json:"eq_preset"`
 	DynPreset string `json:"dyn_preset"`
 	DynNorm bool `json:"dyn_norm_enabled"`
+	Restoration bool `json:"restoration"`
+	RumbleFilter bool `json:"rumble_filter"`
+	ChannelAlign bool `json:"channel_align"`
 	SelectedTab string `json:"selected_tab"`
 	PhaseCheck bool `json:"phase_check_auto"`
+	LoudnessBadge bool `json:"loudness_badge"`
+	LoudnessReport string `json:"loudness_report"`
+	LoudnormFallback string `json:"loudnorm_fallback"`
+	LoudnormDualMono bool `json:"loudnorm_dual_mono"`
+	LoudnormOffset string `json:"loudnorm_offset"`
+	LoudnormOffsetCustom string `json:"loudnorm_offset_custom"`
+	MeasurementStandard string `json:"measurement_standard"`
+	SpeechnormPreset string `json:"speechnorm_preset"`
+	WatchMeasureOnly bool `json:"watch_measure_only"`
+	WatchAlert bool `json:"watch_alert"`
+	WatchRescan string `json:"watch_rescan_minutes"`
+	WatchPace string `json:"watch_pace_realtime"`
+	WatchRetries string `json:"watch_retries"`
+	WatchQuiet string `json:"watch_quiet_seconds"`
+	WatchProbe bool `json:"watch_probe"`
+	WatchRecursive bool `json:"watch_recursive"`
+	WatchQuarantine string `json:"watch_quarantine"`
+	ChannelCheck bool `json:"channel_check"`
+	ChannelFix string `json:"channel_fix"`
+	RecentFolders []string `json:"recent_folders"`
+	FavoriteFolders []string `json:"favorite_folders"`
+	ExtraTargets []string `json:"extra_targets"`
+	FastReplaceBuiltins bool `json:"fast_replace_builtins"`
+	MetaPublisher string `json:"meta_publisher"`
+	MetaCopyright string `json:"meta_copyright"`
+	MetaEncodedBy string `json:"meta_encoded_by"`
+	TagTemplates string `json:"tag_templates"`
+	LoudnessTags string `json:"loudness_tags"`
+	QualityScore bool `json:"quality_score"`
+	TimestampMode string `json:"timestamp_mode"`
+	AirDateTag bool `json:"air_date_tag"`
+	AskOperator bool `json:"ask_operator"`
+	AuditSidecar bool `json:"audit_sidecar"`
+	ZipKeepFolders bool `json:"zip_keep_folders"`
+	AnchorMarkers bool `json:"anchor_markers"`
+	MusicLibrary bool `json:"music_library"`
+	MaxTrackGain string `json:"max_track_gain"`
+	PreventClipping bool `json:"prevent_clipping"`
+	TagClipCap bool `json:"tag_clip_cap"`
+	KeepEmphasis bool `json:"keep_emphasis"`
+	FolderMinDuration string `json:"folder_min_duration"`
+	FolderMaxDuration string `json:"folder_max_duration"`
+	FolderSince string `json:"folder_since"`
+	FolderSkipHidden bool `json:"folder_skip_hidden"`
+	Incremental bool `json:"incremental"`
+	NotifySound string `json:"notify_sound"`
+	NotifySoundFile string `json:"notify_sound_file"`
+	NotifyBadge bool `json:"notify_badge"`
+	BWFHints bool `json:"bwf_hints"`
+	BWFTemplate string `json:"bwf_filename_template"`
+	PreviewCopy bool `json:"preview_copy"`
+	PreviewMark string `json:"preview_mark"`
+	PreviewOffset string `json:"preview_offset"`
+	PreviewLevel string `json:"preview_level"`
+	PreviewIdent string `json:"preview_ident"`
+	WebVersion bool `json:"web_version"`
+	WebTarget string `json:"web_target"`
+	WebTargetTp string `json:"web_target_tp"`
+	WebFormat string `json:"web_format"`
+	QCTolerance string `json:"qc_tolerance"`
+	LongFile bool `json:"long_file"`
+	LongFileHours string `json:"long_file_hours"`
+	StageTempFiles bool `json:"stage_temp_files"`
+	Workers string `json:"workers"`
+	FFmpegThreads string `json:"ffmpeg_threads"`
+	LowPriority bool `json:"low_priority"`
+	Series string `json:"series"`
+	SeriesAlert bool `json:"series_alert"`
+	BatchReport bool `json:"batch_report"`
+	SRCQuality string `json:"src_quality"`
+	AlbumMode bool `json:"album_mode"`
+	StripMetadata bool `json:"strip_metadata"`
+	SystemLog bool `json:"system_log"`
+	UsageStats bool `json:"usage_stats"`
+	LogRetention string `json:"log_retention"`
+	FolderBookmarks map[string][]byte `json:"folder_bookmarks,omitempty"`
 }
 
 func (n *AudioNormalizer) loadPreferences() {
-	configDir, _ := os.UserConfigDir()
-	prefsPath := filepath.Join(configDir, "TNT", "preferences.json")
-
-	data, err := os.ReadFile(prefsPath)
+	prefs, err := readPreferences()
 	if err != nil {
+		if !os.IsNotExist(err) {
+			n.logToFile(n.logFile, fmt.Sprintf("Preferences not loaded: %v", err))
+		}
 		return
 	}
+	n.resolveFolderBookmarks(&prefs)
 
-	var prefs Preferences
-	json.Unmarshal(data, &prefs)
+	n.applyPreferences(prefs)
+}
 
+// applyPreferences sets the widgets from stored preferences
+func (n *AudioNormalizer) applyPreferences(prefs Preferences) {
 	n.modeToggle.SetChecked(prefs.AdvancedMode)
 	n.outputDir = prefs.LastOutputDir
 	if n.outputDir != "" {
@@ -1288,7 +1566,122 @@ func (n *AudioNormalizer) loadPreferences() {
 	n.EqDrop.SetSelected(prefs.EqPreset)
 	n.dynamicsDrop.SetSelected(prefs.DynPreset)
 	n.dynNorm.SetChecked(prefs.DynNorm)
+	n.restorationCheck.SetChecked(prefs.Restoration)
+	n.rumbleCheck.SetChecked(prefs.RumbleFilter)
+	n.channelAlignCheck.SetChecked(prefs.ChannelAlign)
 	n.checkPhaseBtn.SetChecked(prefs.PhaseCheck)
+	n.loudnessBadgeCheck.SetChecked(prefs.LoudnessBadge)
+	if prefs.LoudnessReport != "" {
+		n.loudnessReportDrop.SetSelected(prefs.LoudnessReport)
+	}
+	if prefs.LoudnormFallback != "" {
+		n.loudnormFallback.SetSelected(prefs.LoudnormFallback)
+	}
+	n.loudnormDualMonoCheck.SetChecked(prefs.LoudnormDualMono)
+	n.loudnormOffsetEntry.SetText(prefs.LoudnormOffsetCustom)
+	if prefs.LoudnormOffset != "" {
+		n.loudnormOffsetDrop.SetSelected(prefs.LoudnormOffset)
+	}
+	if prefs.MeasurementStandard != "" {
+		n.measurementStandardDrop.SetSelected(audio.ParseStandard(prefs.MeasurementStandard).Label())
+	}
+	if prefs.SpeechnormPreset != "" {
+		n.speechnormDrop.SetSelected(prefs.SpeechnormPreset)
+	}
+	n.watchMeasureOnly.SetChecked(prefs.WatchMeasureOnly)
+	n.watchAlertCheck.SetChecked(prefs.WatchAlert)
+	if prefs.WatchRescan != "" {
+		n.watchRescanDrop.SetSelected(prefs.WatchRescan)
+	}
+	if prefs.WatchPace != "" {
+		n.watchPaceDrop.SetSelected(prefs.WatchPace)
+	}
+	if prefs.WatchQuiet != "" {
+		n.watchQuietDrop.SetSelected(prefs.WatchQuiet)
+	}
+	n.watchProbeCheck.SetChecked(prefs.WatchProbe)
+	n.watchRecursiveCheck.SetChecked(prefs.WatchRecursive)
+	if prefs.WatchRetries != "" {
+		n.watchRetryDrop.SetSelected(prefs.WatchRetries)
+	}
+	if prefs.WatchQuarantine != "" {
+		n.watchQuarantineDrop.SetSelected(prefs.WatchQuarantine)
+	}
+	n.channelCheck.SetChecked(prefs.ChannelCheck)
+	n.recentFolders = prefs.RecentFolders
+	n.favoriteFolders = prefs.FavoriteFolders
+	n.extraTargetsGroup.SetSelected(prefs.ExtraTargets)
+	n.fastReplaceBuiltins = prefs.FastReplaceBuiltins
+	n.metaPublisher.SetText(prefs.MetaPublisher)
+	n.metaCopyright.SetText(prefs.MetaCopyright)
+	n.metaEncodedBy.SetText(prefs.MetaEncodedBy)
+	n.tagTemplatesEntry.SetText(prefs.TagTemplates)
+	if prefs.LoudnessTags != "" {
+		n.loudnessTagsDrop.SetSelected(prefs.LoudnessTags)
+	}
+	n.qualityScoreCheck.SetChecked(prefs.QualityScore)
+	if prefs.TimestampMode != "" {
+		n.timestampDrop.SetSelected(prefs.TimestampMode)
+	}
+	n.airDateTagCheck.SetChecked(prefs.AirDateTag)
+	n.askOperatorCheck.SetChecked(prefs.AskOperator)
+	n.auditSidecarCheck.SetChecked(prefs.AuditSidecar)
+	n.zipKeepFoldersCheck.SetChecked(prefs.ZipKeepFolders)
+	n.anchorMarkersCheck.SetChecked(prefs.AnchorMarkers)
+	n.musicLibCheck.SetChecked(prefs.MusicLibrary)
+	n.maxTrackGainEntry.SetText(prefs.MaxTrackGain)
+	n.preventClipCheck.SetChecked(prefs.PreventClipping)
+	n.tagClipCapCheck.SetChecked(prefs.TagClipCap)
+	n.keepEmphasisCheck.SetChecked(prefs.KeepEmphasis)
+	n.folderMinEntry.SetText(prefs.FolderMinDuration)
+	n.folderMaxEntry.SetText(prefs.FolderMaxDuration)
+	n.folderSinceEntry.SetText(prefs.FolderSince)
+	n.folderSkipHiddenCheck.SetChecked(prefs.FolderSkipHidden)
+	n.incrementalCheck.SetChecked(prefs.Incremental)
+	if prefs.NotifySound != "" {
+		n.notifySoundDrop.SetSelected(prefs.NotifySound)
+	}
+	n.notifySoundFileEntry.SetText(prefs.NotifySoundFile)
+	n.notifyBadgeCheck.SetChecked(prefs.NotifyBadge)
+	n.bwfHintsCheck.SetChecked(prefs.BWFHints)
+	n.bwfTemplateEntry.SetText(prefs.BWFTemplate)
+	n.previewCopyCheck.SetChecked(prefs.PreviewCopy)
+	if prefs.PreviewMark != "" {
+		n.previewMarkDrop.SetSelected(prefs.PreviewMark)
+	}
+	n.previewOffsetEntry.SetText(prefs.PreviewOffset)
+	n.previewLevelEntry.SetText(prefs.PreviewLevel)
+	n.previewIdentEntry.SetText(prefs.PreviewIdent)
+	n.webVersionCheck.SetChecked(prefs.WebVersion)
+	n.webTargetEntry.SetText(prefs.WebTarget)
+	n.webTargetTpEntry.SetText(prefs.WebTargetTp)
+	if prefs.WebFormat != "" {
+		n.webFormatDrop.SetSelected(prefs.WebFormat)
+	}
+	n.qcToleranceEntry.SetText(prefs.QCTolerance)
+	n.longFileCheck.SetChecked(prefs.LongFile)
+	n.longFileHoursEntry.SetText(prefs.LongFileHours)
+	n.stageTempFilesCheck.SetChecked(prefs.StageTempFiles)
+	n.workersEntry.SetText(prefs.Workers)
+	n.ffmpegThreadsEntry.SetText(prefs.FFmpegThreads)
+	n.lowPriorityCheck.SetChecked(prefs.LowPriority)
+	n.applyCPULimits()
+	n.seriesEntry.SetText(prefs.Series)
+	n.seriesAlertCheck.SetChecked(prefs.SeriesAlert)
+	n.batchReportCheck.SetChecked(prefs.BatchReport)
+	if prefs.SRCQuality != "" {
+		n.srcQualityDrop.SetSelected(prefs.SRCQuality)
+	}
+	n.albumModeCheck.SetChecked(prefs.AlbumMode)
+	n.stripMetadataCheck.SetChecked(prefs.StripMetadata)
+	n.systemLogCheck.SetChecked(prefs.SystemLog)
+	n.usageStatsCheck.SetChecked(prefs.UsageStats)
+	if prefs.LogRetention != "" {
+		n.logRetentionDrop.SetSelected(prefs.LogRetention)
+	}
+	if prefs.ChannelFix != "" {
+		n.channelFixDrop.SetSelected(prefs.ChannelFix)
+	}
 	if prefs.SelectedTab == "Fast" {
 		n.modeTabs.Select(n.modeTabs.Items[0])
 	} else {
@@ -1297,7 +1690,19 @@ func (n *AudioNormalizer) loadPreferences() {
 }
 
 func (n *AudioNormalizer) savePreferences() {
-	prefs := Preferences{
+	prefs := n.currentPreferences()
+	prefs.FolderBookmarks = n.updateFolderBookmarks()
+
+	if err := writePreferences(prefs); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Preferences not saved: %v", err))
+		return
+	}
+	n.notifyPreferences(prefs)
+}
+
+// currentPreferences collects the preferences from the widgets
+func (n *AudioNormalizer) currentPreferences() Preferences {
+	return Preferences{
 		AdvancedMode: n.advancedMode,
 		LastOutputDir: n.outputDir,
 		SimpleMode: n.simpleGroupButtons.Selected,
@@ -1314,23 +1719,93 @@ func (n *AudioNormalizer) savePreferences() {
 		EqPreset: n.EqDrop.Selected,
 		DynPreset: n.dynamicsDrop.Selected,
 		DynNorm: n.dynNorm.Checked,
+		Restoration: n.restorationCheck.Checked,
+		RumbleFilter: n.rumbleCheck.Checked,
+		ChannelAlign: n.channelAlignCheck.Checked,
 		SelectedTab: n.modeTabs.Selected().Text,
 		PhaseCheck: n.checkPhaseBtn.Checked,
+		LoudnessBadge: n.loudnessBadgeCheck.Checked,
+		LoudnessReport: n.loudnessReportDrop.Selected,
+		LoudnormFallback: n.loudnormFallback.Selected,
+		LoudnormDualMono: n.loudnormDualMonoCheck.Checked,
+		LoudnormOffset: n.loudnormOffsetDrop.Selected,
+		LoudnormOffsetCustom: n.loudnormOffsetEntry.Text,
+		MeasurementStandard: string(n.measurementStandard()),
+		SpeechnormPreset: n.speechnormDrop.Selected,
+		WatchMeasureOnly: n.watchMeasureOnly.Checked,
+		WatchAlert: n.watchAlertCheck.Checked,
+		WatchRescan: n.watchRescanDrop.Selected,
+		WatchPace: n.watchPaceDrop.Selected,
+		WatchRetries: n.watchRetryDrop.Selected,
+		WatchQuiet: n.watchQuietDrop.Selected,
+		WatchProbe: n.watchProbeCheck.Checked,
+		WatchRecursive: n.watchRecursiveCheck.Checked,
+		WatchQuarantine: n.watchQuarantineDrop.Selected,
+		ChannelCheck: n.channelCheck.Checked,
+		ChannelFix: n.channelFixDrop.Selected,
+		RecentFolders: n.recentFolders,
+		FavoriteFolders: n.favoriteFolders,
+		ExtraTargets: n.extraTargetsGroup.Selected,
+		FastReplaceBuiltins: n.fastReplaceBuiltins,
+		MetaPublisher: n.metaPublisher.Text,
+		MetaCopyright: n.metaCopyright.Text,
+		MetaEncodedBy: n.metaEncodedBy.Text,
+		TagTemplates: n.tagTemplatesEntry.Text,
+		LoudnessTags: n.loudnessTagsDrop.Selected,
+		QualityScore: n.qualityScoreCheck.Checked,
+		TimestampMode: n.timestampDrop.Selected,
+		AirDateTag: n.airDateTagCheck.Checked,
+		AskOperator: n.askOperatorCheck.Checked,
+		AuditSidecar: n.auditSidecarCheck.Checked,
+		ZipKeepFolders: n.zipKeepFoldersCheck.Checked,
+		AnchorMarkers: n.anchorMarkersCheck.Checked,
+		MusicLibrary: n.musicLibCheck.Checked,
+		MaxTrackGain: n.maxTrackGainEntry.Text,
+		PreventClipping: n.preventClipCheck.Checked,
+		TagClipCap: n.tagClipCapCheck.Checked,
+		KeepEmphasis: n.keepEmphasisCheck.Checked,
+		FolderMinDuration: n.folderMinEntry.Text,
+		FolderMaxDuration: n.folderMaxEntry.Text,
+		FolderSince: n.folderSinceEntry.Text,
+		FolderSkipHidden: n.folderSkipHiddenCheck.Checked,
+		Incremental: n.incrementalCheck.Checked,
+		NotifySound: n.notifySoundDrop.Selected,
+		NotifySoundFile: n.notifySoundFileEntry.Text,
+		NotifyBadge: n.notifyBadgeCheck.Checked,
+		BWFHints: n.bwfHintsCheck.Checked,
+		BWFTemplate: n.bwfTemplateEntry.Text,
+		PreviewCopy: n.previewCopyCheck.Checked,
+		PreviewMark: n.previewMarkDrop.Selected,
+		PreviewOffset: n.previewOffsetEntry.Text,
+		PreviewLevel: n.previewLevelEntry.Text,
+		PreviewIdent: n.previewIdentEntry.Text,
+		WebVersion: n.webVersionCheck.Checked,
+		WebTarget: n.webTargetEntry.Text,
+		WebTargetTp: n.webTargetTpEntry.Text,
+		WebFormat: n.webFormatDrop.Selected,
+		QCTolerance: n.qcToleranceEntry.Text,
+		LongFile: n.longFileCheck.Checked,
+		LongFileHours: n.longFileHoursEntry.Text,
+		StageTempFiles: n.stageTempFilesCheck.Checked,
+		Workers: n.workersEntry.Text,
+		FFmpegThreads: n.ffmpegThreadsEntry.Text,
+		LowPriority: n.lowPriorityCheck.Checked,
+		Series: n.seriesEntry.Text,
+		SeriesAlert: n.seriesAlertCheck.Checked,
+		BatchReport: n.batchReportCheck.Checked,
+		SRCQuality: n.srcQualityDrop.Selected,
+		AlbumMode: n.albumModeCheck.Checked,
+		StripMetadata: n.stripMetadataCheck.Checked,
+		SystemLog: n.systemLogCheck.Checked,
+		UsageStats: n.usageStatsCheck.Checked,
+		LogRetention: n.logRetentionDrop.Selected,
 	}
-
-	configDir, _ := os.UserConfigDir()
-	prefsDir := filepath.Join(configDir, "TNT")
-	os.MkdirAll(prefsDir, 0755)
-
-	data, _ := json.MarshalIndent(prefs, "", "  ")
-	os.WriteFile(filepath.Join(prefsDir, "preferences.json"), data, 0644)
 }
 
 func (n *AudioNormalizer) resetPreferences() {
-	configDir, _ := os.UserConfigDir()
-	prefsPath := filepath.Join(configDir, "TNT", "preferences.json")
-
-	os.Remove(prefsPath)
+	withPreferencesLock(true, func() error {
+		return os.Remove(preferencesPath())
+	})
 
 	dialog.ShowInformation("Preferences Reset", "Preferences have been reset. Restart TNT to apply defaults.", n.window)
 }
@@ -1351,21 +1826,67 @@ func (n *AudioNormalizer) updateNormalizationLabel(standard string) {
 	}
 }
 
-func (n *AudioNormalizer) startWatching() {
+func (n *AudioNormalizer) startWatching() error {
+	if err := n.license.check(license.FeatureWatch); err != nil {
+		n.logStatus(fmt.Sprintf("✗ Watch mode not started: %v", err))
+		return err
+	}
+	// Outputs landing in the watched folder would be picked up as new files
+	if !n.watchMeasureOnly.Checked && n.inputDir != "" {
+		if _, err := n.checkOutputOverlap(n.inputDir); err != nil {
+			n.logStatus(fmt.Sprintf("✗ Watch mode not started: %v", err))
+			return err
+		}
+	}
+	dirs := n.watchedDirs()
+	if len(dirs) == 0 {
+		n.logStatus(fmt.Sprintf("✗ Watch mode not started: %v", ErrNothingToWatch))
+		return ErrNothingToWatch
+	}
+
 	n.watcherMutex.Lock()
 	if n.watching {
 		n.watcherMutex.Unlock()
-		return
+		return nil
 	}
 	n.watching = true
 	n.watcherStop = make(chan bool)
 	n.jobQueue = make(chan string, 100)
 	n.watcherMutex.Unlock()
 
-	n.logStatus("Watch mode started")
+	n.outputCheckMutex.Lock()
+	n.outputAborted = false
+	n.outputCheckMutex.Unlock()
+
+	if n.watchMeasureOnly.Checked {
+		n.logStatus(fmt.Sprintf("Watch mode started (measure only, logging to %s)", n.monitorLogPath()))
+	} else {
+		n.logStatus("Watch mode started")
+	}
 	n.logToFile(n.logFile, "started watching")
-	go n.watchDirectory()
-	go n.processWatchQueue()
+
+	for _, dir := range dirs {
+		files, err := n.watchedFiles(dir)
+		if err == nil {
+			err = n.watchHistory.baseline(files)
+		}
+		if err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("watch history baseline of %s failed, %v", dir, err))
+		}
+	}
+
+	// A panic in the watcher or the queue restarts it rather than ending the watch, see recovery.go
+	go n.superviseWorker("Watch mode watcher", n.watcherStop, func() { n.watchDirectory(dirs) })
+	go n.superviseWorker("Watch mode queue", n.watcherStop, n.processWatchQueue)
+
+	interval := n.watchRescanInterval()
+	if interval > 0 {
+		n.logToFile(n.logFile, fmt.Sprintf("rescanning watched folder every %s", interval))
+	}
+	for _, dir := range dirs {
+		go n.reconcileWatchFolder(dir, interval)
+	}
+	return nil
 }
 
 func (n *AudioNormalizer) stopWatching() {
@@ -1378,41 +1899,53 @@ func (n *AudioNormalizer) stopWatching() {
 		for len(n.jobQueue) > 0 {
 			<-n.jobQueue
 		}
+		n.watchHistory.reset()
 		n.logStatus("Watch mode stopped")
 		n.logToFile(n.logFile, "stopped watching")
 	}
 }
 
-func (n *AudioNormalizer) watchDirectory() {
+func (n *AudioNormalizer) watchDirectory(dirs []string) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		n.logStatus("Failed to create watcher: " + err.Error())
+		n.reportEvent(eventWatchFailed, true, "Failed to create watcher: " + err.Error())
 		n.logToFile(n.logFile, "watcher creation fail, " + err.Error())
 		return
 	}
 	defer watcher.Close()
 
-	err = watcher.Add(n.inputDir)
-	if err != nil {
-		n.logStatus("Failed to watch directory: " + err.Error())
-		n.logToFile(n.logFile, "dir creation fail, " + err.Error())
-		return
+	for _, dir := range dirs {
+		if err := n.addWatchTree(watcher, dir); err != nil {
+			n.logStatus("Failed to watch directory: " + err.Error())
+			n.reportEvent(eventWatchFailed, true, fmt.Sprintf("Failed to watch %s: %v", dir, err))
+			n.logToFile(n.logFile, "dir creation fail, " + err.Error())
+			return
+		}
 	}
 
 	for {
 		select {
 			case event := <-watcher.Events:
-				if event.Op&fsnotify.Create == fsnotify.Create && isAudioFile(event.Name) {
-					select {
-						case n.jobQueue <- event.Name:
-						case <-n.watcherStop:
-							return
+				// Unfinished .part outputs are never picked up, even when the
+				// output folder is inside the watched tree
+				if isPartFile(event.Name) {
+					continue
+				}
+				if event.Op&fsnotify.Create == fsnotify.Create && n.watchRecursiveCheck.Checked {
+					if info, err := os.Stat(platform.LongPath(event.Name)); err == nil && info.IsDir() {
+						n.watchNewSubfolder(watcher, event.Name)
+						continue
 					}
 				}
+				if event.Op&fsnotify.Create == fsnotify.Create && (isAudioFile(event.Name) || isZipFile(event.Name)) {
+					n.enqueueWhenStable(event.Name)
+				}
 			case <-n.watcherStop:
 				return
 			case err := <-watcher.Errors:
 				n.logStatus("Watcher error: " + err.Error())
+				n.reportEvent(eventWatchFailed, true, fmt.Sprintf("Watcher error on %s: %v", strings.Join(dirs, ", "), err))
 				n.logToFile(n.logFile, "watcher error, " + err.Error())
 		}
 	}
@@ -1422,42 +1955,189 @@ func (n *AudioNormalizer) processWatchQueue() {
 	for {
 		select {
 			case file := <-n.jobQueue:
-				n.processFile(file, n.getProcessConfig())
+				n.watchFailures.begin(file)
+				// A file that panics fails, and is retried or quarantined like any other failure
+				result := "failed"
+				n.runJob(file, func() {
+					if isZipFile(file) {
+						result = n.handleWatchedZip(file)
+					} else {
+						result = n.handleWatchedFile(file)
+					}
+				})
+				n.recordWatched(file, result)
 			case <-n.watcherStop:
 				return
 		}
 	}
 }
 
+// handleWatchedFile measures or processes one watched file and returns the
+// outcome for the watch history
+func (n *AudioNormalizer) handleWatchedFile(file string) string {
+	n.setWatchCurrent(file)
+	defer n.setWatchCurrent("")
+
+	if updateBlockReason() != "" {
+		n.logStatus(fmt.Sprintf("⊗ Not processed, update required: %s", filepath.Base(file)))
+		return "blocked"
+	}
+	if err := audio.ValidateInput(file); err != nil {
+		n.refuseFile(file, err)
+		return "refused"
+	}
+	// Monitoring never writes next to or over the watched files
+	if n.watchMeasureOnly.Checked {
+		n.monitorFile(file)
+		return "measured"
+	}
+	cfg, err := n.watchConfig(file)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Not processed, %v: %s", err, filepath.Base(file)))
+		return "failed"
+	}
+	cfg.readRate = n.watchReadRate()
+	if n.watchRecursiveCheck.Checked {
+		cfg.watchRoot = n.watchRootFor(file)
+	}
+	return n.processOne(file, cfg)
+}
+
+// processOne runs the preflight checks and the pipeline on one validated
+// file and returns the outcome
+func (n *AudioNormalizer) processOne(file string, cfg ProcessConfig) string {
+	if cfg.ChannelCheck {
+		cfg.channelFilter = n.channelPreflight(file, cfg)
+	}
+	if len(cfg.Analyzers) > 0 {
+		var allowed bool
+		if cfg.analysis, allowed = n.runAnalyzers(file, cfg); !allowed {
+			return "rejected"
+		}
+	}
+	if !n.ensureWritableOutput(cfg) {
+		return "skipped"
+	}
+	if n.processFile(file, cfg) {
+		return "processed"
+	}
+	return "failed"
+}
+
 func main() {
 	os.Setenv("FYNE_DISABLE_HARDWARE_ACCELERATION", "1")
 
+	args := parseLaunchArgs()
+	configDirOverride = args.ConfigDir
+
+	// A verified FFmpeg from the version feed replaces the embedded one
+	useCachedFFmpeg()
+	loadUpdateBlock(currentVersion)
+
+	if args.Service {
+		runService(args)
+		return
+	}
+	if args.JSONRPC {
+		runJSONRPC(args)
+		return
+	}
+
 	a := app.NewWithID("com.collinsgroup.tnt")
 	a.Settings().SetTheme(&appleTheme{})
 
-	w := a.NewWindow("TNT - Transcode, Normalize, Tag")
+	var norm *AudioNormalizer
+
+	// Don't leave FFmpeg running in the background after the window closes
+	a.Lifecycle().SetOnStopped(func() {
+		ffmpeg.KillAll()
+		if norm != nil {
+			norm.releaseAllZips()
+			norm.batchHistory.flush()
+			if norm.logFile != nil {
+				norm.logFile.Close()
+			}
+		}
+	})
+
+	start := func(profile string) {
+		activeProfile = profile
+		norm = startMainWindow(a, args.Operator)
+	}
+
+	// Shared machines pick a profile at startup unless one was given on the command line
+	if profiles := listProfiles(); !args.ProfileGiven && len(profiles) > 0 {
+		showProfilePicker(a, profiles, start)
+	} else {
+		start(args.Profile)
+	}
+
+	a.Run()
+}
+
+// startMainWindow builds and shows the main window for the active profile
+func startMainWindow(a fyne.App, operator string) *AudioNormalizer {
+	title := "TNT - Transcode, Normalize, Tag"
+	if activeProfile != "" {
+		title += " (" + activeProfile + ")"
+	}
+
+	w := a.NewWindow(title)
 	w.Resize(fyne.NewSize(650, 600))
 
+	norm := newNormalizer(a, w, operator)
+
+	// A badge is only set while the operator is elsewhere
+	norm.inForeground.Store(true)
+	a.Lifecycle().SetOnEnteredForeground(norm.enteredForeground)
+	a.Lifecycle().SetOnExitedForeground(func() {
+		norm.inForeground.Store(false)
+	})
+
+	go checkForUpdates(currentVersion, w, norm.logFile)
+	go norm.sendUsageStats()
+
+	w.Show()
+
+	if norm.operator == "" && norm.askOperatorCheck.Checked {
+		norm.askOperator()
+	}
+	return norm
+}
+
+// newNormalizer builds the normalizer and its widgets with the active profile's settings
+func newNormalizer(a fyne.App, w fyne.Window, operator string) *AudioNormalizer {
 	norm := &AudioNormalizer{
 		window: w,
 		files:  make([]string, 0),
-	}
-
-	norm.setupUI(a)
-	norm.loadPreferences()
-
+		speedModel: loadSpeedModel(),
+		presets: loadPresetStore(),
+		watchHistory: loadWatchHistory(),
+		watchFolders: loadWatchFolders(),
+		license: loadLicense(),
+		shiftReport: loadShiftReport(),
+		seriesHistory: loadSeriesHistory(),
+		batchHistory: loadBatchHistory(),
+		qcReviews: loadQCReviews(),
+		usageStats: loadUsageStats(),
+		operator: operator,
+	}
+
+	// Opened before the preferences so restoring folder access can be logged
 	norm.logFile = norm.initLogFile()
 	fmt.Printf("Log file handle: %v\n", norm.logFile)
 	if norm.logFile != nil {
-		defer norm.logFile.Close()
 		fmt.Printf("Log file path: %s\n", norm.logFile.Name())
 	} else {
 		fmt.Println("Failed to create log file")
 	}
 
-	go checkForUpdates(currentVersion, w, norm.logFile)
-
-	w.ShowAndRun()
+	norm.setupUI(a)
+	norm.loadPreferences()
+	norm.refreshFastPresets()
+	norm.warnLicenseGrace()
+	go norm.runShiftReports()
+	return norm
 }
 
 func getLogoForTheme(a fyne.App) fyne.Resource {
@@ -1473,11 +2153,7 @@ func (n *AudioNormalizer) removeFile(index int) {
 
 	n.files = append(n.files[:index], n.files[index+1:]...)
 
-	fyne.Do(func() {
-		n.fileList.Refresh()
-		n.updateProcessButton()
-		n.checkPCM()
-	})
+	n.queueRefresh.Trigger()
 }
 
 func (n *AudioNormalizer) updateAdvancedControls() {
@@ -1519,8 +2195,17 @@ func (n *AudioNormalizer) selectFiles() {
 		defer reader.Close()
 
 		path := reader.URI().Path()
-		if isAudioFile(path) {
-			n.addFile(path)
+		n.rememberFolder(filepath.Dir(path))
+		if isZipFile(path) {
+			go n.addZip(path)
+		} else if isAudioFile(path) {
+			go func() {
+				if err := audio.ValidateInput(path); err != nil {
+					n.refuseFile(path, err)
+					return
+				}
+				n.addFile(path)
+			}()
 		}
 	}, n.window)
 	n.batchMode = false
@@ -1532,28 +2217,72 @@ func (n *AudioNormalizer) selectFolder() {
 			return
 		}
 
+		// Outputs written into the scanned folder would be queued on the next scan
+		nested, err := n.checkOutputOverlap(uri.Path())
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%w. Choose another output folder first.", err), n.window)
+			return
+		}
+		n.warnNestedOutput(uri.Path(), nested)
+
 		n.inputDir = uri.Path()
+		n.rememberFolder(uri.Path())
 
 		n.batchMode = true
 
 		n.logStatus("Scanning folder...")
 		n.logToFile(n.logFile, "Scanning folder")
 
+		filter := n.folderFilter()
+
 		go func() {
-			audioFiles := []string{}
+			var candidates []string
+			var zipFiles []string
+			var refused, filtered atomic.Int32
 			filepath.WalkDir(uri.Path(), func(path string, d fs.DirEntry, err error) error {
 				if err != nil {
 					return nil
 				}
 				if d.IsDir() {
+					if path != uri.Path() && filter.skipDir(path) {
+						return filepath.SkipDir
+					}
+					if slices.ContainsFunc(nested, func(dir string) bool { return pathKey(dir) == pathKey(path) }) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !isZipFile(path) && !isAudioFile(path) {
 					return nil
 				}
-				if isAudioFile(path) {
-					audioFiles = append(audioFiles, path)
+				if reason := filter.skipFile(path, d); reason != "" {
+					n.logToFile(n.logFile, fmt.Sprintf("Folder filter skipped %s: %s", path, reason))
+					filtered.Add(1)
+					return nil
+				}
+				if isZipFile(path) {
+					zipFiles = append(zipFiles, path)
+					return nil
 				}
+				candidates = append(candidates, path)
 				return nil
 			})
 
+			// The walk only lists the files, probing them is what takes time
+			audioFiles := checkFiles(candidates, func(path string) bool {
+				if reason := filter.skipDuration(path); reason != "" {
+					n.logToFile(n.logFile, fmt.Sprintf("Folder filter skipped %s: %s", path, reason))
+					filtered.Add(1)
+					return false
+				}
+				if err := audio.ValidateInput(path); err != nil {
+					n.refuseFile(path, err)
+					refused.Add(1)
+					return false
+				}
+				return true
+			})
+
 			n.mutex.Lock()
 			for _, file := range audioFiles {
 				// Check for duplicates inline
@@ -1569,11 +2298,20 @@ func (n *AudioNormalizer) selectFolder() {
 			}
 			n.mutex.Unlock()
 
+			n.queueRefresh.Trigger()
 			fyne.Do(func() {
-				n.fileList.Refresh()
-				n.updateProcessButton()
 				n.logStatus(fmt.Sprintf("Added %d audio files from folder", len(audioFiles)))
+				if refused.Load() > 0 {
+					n.logStatus(fmt.Sprintf("Refused %d files, see above for reasons", refused.Load()))
+				}
+				if filtered.Load() > 0 {
+					n.logStatus(fmt.Sprintf("Skipped %d files by the folder filters", filtered.Load()))
+				}
 			})
+
+			for _, zipPath := range zipFiles {
+				n.addZip(zipPath)
+			}
 		}()
 	}, n.window)
 }
@@ -1584,11 +2322,21 @@ func (n *AudioNormalizer) selectOutputFolder() {
 			return
 		}
 
+		// The scanned or watched folder can't take the outputs, a subfolder of it can
+		if (n.batchMode || n.watchMode.Checked) && pathWithin(uri.Path(), n.inputDir) {
+			if pathKey(uri.Path()) == pathKey(n.inputDir) {
+				dialog.ShowError(ErrOutputIsInput, n.window)
+				return
+			}
+			n.logStatus(fmt.Sprintf("⚠ Output folder is inside %s and is left out of scans", filepath.Base(n.inputDir)))
+		}
+
 		n.mutex.Lock()
 		n.outputDir = uri.Path()
 		n.outputLabel.SetText(filepath.Base(n.outputDir))
 		n.mutex.Unlock()
 
+		n.dropQueuedOutputs()
 		n.updateProcessButton()
 	}, n.window)
 }
@@ -1667,30 +2415,79 @@ func (n *AudioNormalizer) addFile(path string) {
 	*/
 
 	n.files = append(n.files, path)
-	fyne.Do(func() {
-		n.fileList.Refresh()
-		n.updateProcessButton()
-		n.checkPCM()
-	})
+	n.queueRefresh.Trigger()
 
 }
 
-func (n *AudioNormalizer) updateProcessButton() {
-	if len(n.files) > 0 && n.outputDir != "" {
-		n.processBtn.Enable()
-	} else {
-		n.processBtn.Disable()
-	}
+// refuseFile reports a file that was not queued and why
+func (n *AudioNormalizer) refuseFile(path string, reason error) {
+	n.logStatus(fmt.Sprintf("⊗ Refused: %s - %v", filepath.Base(path), reason))
+	n.logToFile(n.logFile, fmt.Sprintf("Refused %s: %v", path, reason))
 }
 
-func (n *AudioNormalizer) getProcessConfig() ProcessConfig {
-	if n.modeTabs.Selected() == n.modeTabs.Items[0] {
-		n.advancedMode = false
-	} else {
-		n.advancedMode = true
+// validateWorkers is how many files are probed at once when many are added together
+const validateWorkers = 4
+
+// checkFiles runs check on the files, validateWorkers at a time, and returns
+// the ones it passed in their original order
+func checkFiles(files []string, check func(string) bool) []string {
+	passed := make([]bool, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(validateWorkers, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				passed[i] = check(files[i])
+			}
+		}()
 	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-	config := ProcessConfig{
+	var accepted []string
+	for i, file := range files {
+		if passed[i] {
+			accepted = append(accepted, file)
+		}
+	}
+	return accepted
+}
+
+// acceptFiles validates files before they are queued, refusing the ones that
+// can't be processed, and returns the rest
+func (n *AudioNormalizer) acceptFiles(files []string) []string {
+	return checkFiles(files, func(file string) bool {
+		if err := audio.ValidateInput(file); err != nil {
+			n.refuseFile(file, err)
+			return false
+		}
+		return true
+	})
+}
+
+func (n *AudioNormalizer) updateProcessButton() {
+	if len(n.files) > 0 && (n.outputDir != "" || n.selectedPresetOutputDir() != "") {
+		n.processBtn.Enable()
+		n.quickBtn.Enable()
+	} else {
+		n.processBtn.Disable()
+		n.quickBtn.Disable()
+	}
+}
+
+func (n *AudioNormalizer) getProcessConfig() ProcessConfig {
+	if n.modeTabs.Selected() == n.modeTabs.Items[0] {
+		n.advancedMode = false
+	} else {
+		n.advancedMode = true
+	}
+
+	config := ProcessConfig{
 		UseLoudnorm: n.loudnormCheck.Checked,
 		IsSpeech: n.IsSpeechCheck.Checked,
 		originIsAAC: n.checkOriginAAC(),
@@ -1701,15 +2498,82 @@ func (n *AudioNormalizer) getProcessConfig() ProcessConfig {
 		DynamicsPreset: n.dynamicsDrop.Selected,
 		EqTarget: n.EqDrop.Selected,
 		DynNorm: n.dynNorm.Checked,
+		Restoration: n.restorationCheck.Checked,
+		RumbleFilter: n.rumbleCheck.Checked,
+		ChannelAlign: n.channelAlignCheck.Checked,
 		PhaseCheck: n.checkPhaseBtn.Checked,
+		LoudnessBadge: n.loudnessBadgeCheck.Checked,
+		LoudnessReport: n.loudnessReportDrop.Selected,
+		LoudnormFallback: n.loudnormFallback.Selected,
+		Loudnorm: n.loudnormOptions(),
+		Speechnorm: n.speechnormDrop.Selected,
+		ChannelCheck: n.channelCheck.Checked,
+		ChannelFix: n.channelFixDrop.Selected,
+		Metadata: n.stationMetadata(),
+		LoudnessTags: n.loudnessTagsDrop.Selected,
+		QualityScore: n.qualityScoreCheck.Checked,
+		TimestampMode: n.timestampDrop.Selected,
+		AirDateTag: n.airDateTagCheck.Checked,
+		AuditSidecar: n.auditSidecarCheck.Checked,
+		ZipKeepFolders: n.zipKeepFoldersCheck.Checked,
+		AnchorMarkers: n.anchorMarkersCheck.Checked,
+		MusicLibrary: n.musicLibCheck.Checked,
+		MaxTrackGain: n.maxTrackGainEntry.Text,
+		PreventClipping: n.preventClipCheck.Checked,
+		TagClipCap: n.tagClipCapCheck.Checked,
+		KeepEmphasis: n.keepEmphasisCheck.Checked,
+		Incremental: n.incrementalCheck.Checked,
+		BWFHints: n.bwfHintsCheck.Checked,
+		BWFTemplate: n.bwfTemplateEntry.Text,
+		PreviewCopy: n.previewCopyCheck.Checked,
+		PreviewMark: n.previewMarkDrop.Selected,
+		PreviewOffset: n.previewOffsetEntry.Text,
+		PreviewLevel: n.previewLevelEntry.Text,
+		PreviewIdent: n.previewIdentEntry.Text,
+		WebVersion: n.webVersionCheck.Checked,
+		WebTarget: n.webTargetEntry.Text,
+		WebTargetTp: n.webTargetTpEntry.Text,
+		WebFormat: n.webFormatDrop.Selected,
+		LongFile: n.longFileCheck.Checked,
+		LongFileHours: n.longFileHoursEntry.Text,
+		StageTempFiles: n.stageTempFilesCheck.Checked,
+		BatchReport: n.batchReportCheck.Checked,
+		SRCQuality: n.srcQualityDrop.Selected,
+		AlbumMode: n.albumModeCheck.Checked,
+		StripMetadata: n.stripMetadataCheck.Checked,
+	}
+
+	if templates, err := parseTagTemplates(n.tagTemplatesEntry.Text); err != nil {
+		n.logStatus(fmt.Sprintf("⚠ %v, tag templates not written", err))
+	} else {
+		config.TagTemplates = templates
+	}
+
+	if series, err := parseSeriesLines(n.seriesEntry.Text); err != nil {
+		n.logStatus(fmt.Sprintf("⚠ %v, series not tracked", err))
+	} else {
+		config.Series = series
+	}
+
+	if config.TimestampMode == TimestampAirDate || config.AirDateTag {
+		if airDate, err := parseAirDate(n.airDateEntry.Text); err != nil {
+			n.logStatus(fmt.Sprintf("⚠ %v, air date ignored", err))
+		} else {
+			config.AirDate = airDate
+		}
 	}
 
 	if n.advancedMode {
+		config.ExtraTargets = n.extraTargetsGroup.Selected
 		config.Format = n.formatSelect.Selected
 		config.SampleRate = n.sampleRate.Selected
 		config.BitDepth = n.bitDepth.Selected
 		config.Bitrate = n.bitrateEntry.Text
 		config.writeTags = n.writeTags.Checked
+		// The loaded preset adds what the Advanced tab has no controls for
+		if preset, ok := n.presets.find(n.activePreset); ok {
+			config = preset.applyExtras(config)
+		}
 	} else {
 		switch n.simpleGroupButtons.Selected {
 		case "Small file (AAC 256kbps)":
@@ -1722,39 +2586,102 @@ func (n *AudioNormalizer) getProcessConfig() ProcessConfig {
 			config.Format = "PCM"
 			config.SampleRate = "48000"
 			config.BitDepth = "24"
+		case digitizationPreset:
+			config = digitizationConfig(config)
+		default:
+			// Presets pinned to the Fast tab
+			if preset, ok := n.presets.find(n.simpleGroupButtons.Selected); ok {
+				config = preset.applyTo(config)
+			}
 		}
 	}
 
+	// Music libraries are levelled by the player from track gain tags, the audio itself is left alone
+	if config.MusicLibrary {
+		config.writeTags = true
+		config.UseLoudnorm = false
+	}
+
 	return config
 }
 
 func (n *AudioNormalizer) process() {
+	if n.blockedByUpdate() {
+		return
+	}
+	// Quick stays available, it takes priority over the batch
 	n.processBtn.Disable()
+	n.batchRunning.Store(true)
 	n.progressBar.Show()
 	n.progressBar.SetValue(0)
+	n.statusLines.Reset()
 	n.statusLog.SetText("")
 
 	config := n.getProcessConfig()
+	config.background = true
+	n.musicLibFlagged.Store(0)
+
+	control := newBatchControl()
+	config.ctx = control.ctx
+	n.mutex.Lock()
+	n.batchControl = control
+	n.mutex.Unlock()
+	n.pauseBtn.SetText("Pause")
+	n.pauseBtn.Enable()
+	n.pauseBtn.Show()
+	n.cancelBtn.Enable()
+	n.cancelBtn.Show()
 
-	workers := runtime.NumCPU() - 1
+	n.outputCheckMutex.Lock()
+	n.outputAborted = false
+	n.outputCheckMutex.Unlock()
 
-	workers = max(1, workers)
+	workers := n.workerCount()
 
-	/* modernize above, old below
-	if workers < 1 {
-		workers = 1
+	n.countBatch(config, len(n.files))
+	n.logStatus(fmt.Sprintf("Processing %d files with %d workers...", len(n.files), workers))
+	if len(n.tagMap) > 0 {
+		n.logStatus(n.tagMapSummary())
 	}
-	*/
 
-	// EXAMPLE REPLACEMENT PATTERN
-	// 2. x = a; if a < b { x = b }                =>      x = max(a, b)
+	go func() {
+		// A single short clip with nothing but loudness to fix skips the
+		// batch and goes down the quick path, see quick.go
+		if wantsQuickPath(n.files, config) {
+			n.logStatus("Single short file, using quick normalize")
+			n.quickBatch(n.files[0], config)
+			n.finishBatch(control)
+			return
+		}
 
+		// Same-named files from different folders must not overwrite each other
+		n.planOutputs(slices.Clone(n.files), config)
+		defer n.clearPlannedOutputs()
 
-	n.logStatus(fmt.Sprintf("Processing %d files with %d workers...", len(n.files), workers))
+		// Every file of an album is tagged with the gain of all of them
+		config.album = n.measureAlbumGain(slices.Clone(n.files), config)
+
+		eta := newBatchETA(config, workers)
+		updateETA := func() {
+			etaText := eta.text(n.speedModel)
+			fyne.Do(func() {
+				n.etaLabel.SetText(etaText)
+			})
+		}
+		fyne.Do(func() {
+			n.etaLabel.SetText("")
+			n.etaLabel.Show()
+		})
+		go eta.probe(slices.Clone(n.files), updateETA)
+
+		var report *batchReport
+		if config.BatchReport {
+			report = newBatchReport(n.files)
+		}
 
-	go func() {
 		jobs := make(chan string, len(n.files))
 		results := make(chan bool, len(n.files))
+		var unchanged atomic.Int32
 
 		var wg sync.WaitGroup
 
@@ -1763,34 +2690,95 @@ func (n *AudioNormalizer) process() {
 			go func() {
 				defer wg.Done()
 				for file := range jobs {
-					shouldProcess := true
-
-					if config.PhaseCheck {
-						inverted, offset, err := audio.PhaseCheck(file, n.logFile)
-						if err != nil {
-							n.logStatus(fmt.Sprintf("✗ Phase check failed for %s: %v", filepath.Base(file), err))
-						} else if inverted {
-							n.logStatus(fmt.Sprintf("⚠ Phase inverted (offset: %.6f): %s", offset, filepath.Base(file)))
-
-							if offset == 0 && inverted {
-								shouldProcess = n.showConfirmDialog("Track is perfectly out of phase", fmt.Sprintf("%s appears to be perfectly out of phase, meaning it will render to complete silence in monophonic receivers. It is advisable to not process this file and fix the phase issue first. Do you want to process?", filepath.Base(file)))
-							} else {
-								// Ask on UI thread, block worker
-								shouldProcess = n.showConfirmDialog(
-									"Phase Inverted",
-									fmt.Sprintf("%s appears phase-inverted. Continue?", filepath.Base(file)),
-								)
+					// A panicking file fails without taking its worker down, see recovery.go
+					if n.runJob(file, func() {
+						// Nothing new starts while paused, and a cancelled batch
+						// leaves the rest of the queue out of its results
+						if !control.wait() {
+							eta.finish(file)
+							return
+						}
+						// Nothing new starts while a quick normalize has priority
+						ffmpeg.WaitInteractive()
+						shouldProcess := true
+
+						if config.Incremental && n.alreadyProcessed(file, config) {
+							n.logStatus(fmt.Sprintf("⊗ Unchanged since the last run: %s", filepath.Base(file)))
+							unchanged.Add(1)
+							if r := report.file(file); r != nil {
+								r.Result = reportUnchanged
+							}
+							eta.finish(file)
+							results <- false
+							return
+						}
+
+						// Phase only exists between two channels
+						if config.PhaseCheck && fileIsMono(file) {
+							n.logToFile(n.logFile, fmt.Sprintf("Phase check skipped for mono file %s", file))
+						} else if config.PhaseCheck {
+							inverted, offset, err := audio.PhaseCheck(file, n.logFile)
+							if err != nil {
+								n.logStatus(fmt.Sprintf("✗ Phase check failed for %s: %v", filepath.Base(file), err))
+							} else if inverted {
+								n.logStatus(fmt.Sprintf("⚠ Phase inverted (offset: %.6f): %s", offset, filepath.Base(file)))
+
+								if offset == 0 && inverted {
+									shouldProcess = n.showConfirmDialog("Track is perfectly out of phase", fmt.Sprintf("%s appears to be perfectly out of phase, meaning it will render to complete silence in monophonic receivers. It is advisable to not process this file and fix the phase issue first. Do you want to process?", filepath.Base(file)))
+								} else {
+									// Ask on UI thread, block worker
+									shouldProcess = n.showConfirmDialog(
+										"Phase Inverted",
+										fmt.Sprintf("%s appears phase-inverted. Continue?", filepath.Base(file)),
+									)
+								}
 							}
 						}
-					}
 
-					if shouldProcess {
-						success := n.processFile(file, config)
-						results <- success
-					} else {
-						n.logStatus(fmt.Sprintf("⊗ Skipped: %s", filepath.Base(file)))
-						results <- false
+						fileConfig := config
+						fileConfig.report = report.file(file)
+						if shouldProcess && config.ChannelCheck {
+							fileConfig.channelFilter = n.channelPreflight(file, config)
+						}
+						if shouldProcess && len(config.Analyzers) > 0 {
+							fileConfig.analysis, shouldProcess = n.runAnalyzers(file, config)
+						}
+
+						if shouldProcess && !n.ensureWritableOutput(fileConfig) {
+							n.logStatus(fmt.Sprintf("⊗ Skipped: %s", filepath.Base(file)))
+							eta.finish(file)
+							results <- false
+							return
+						}
+
+						if shouldProcess {
+							started := time.Now()
+							success := n.processFile(file, fileConfig)
+							if success {
+								n.speedModel.record(eta.key, eta.duration(file), time.Since(started))
+							}
+							eta.finish(file)
+							if !success && control.cancelled() {
+								n.logStatus(fmt.Sprintf("⊗ Cancelled: %s", filepath.Base(file)))
+								if r := fileConfig.report; r != nil {
+									r.Result = reportCancelled
+								}
+								return
+							}
+							results <- success
+						} else {
+							eta.finish(file)
+							n.logStatus(fmt.Sprintf("⊗ Skipped: %s", filepath.Base(file)))
+							results <- false
+						}
+					}) {
+						continue
+					}
+					eta.finish(file)
+					if r := report.file(file); r != nil {
+						r.Result = reportFailed
 					}
+					results <- false
 				}
 			}()
 		}
@@ -1805,97 +2793,177 @@ func (n *AudioNormalizer) process() {
 			close(results)
 		}()
 
-		processed := 0
+		// Results of a large batch come in faster than they can be drawn
+		var processed atomic.Int64
+		progressRefresh := newUIThrottle(func() {
+			n.progressBar.SetValue(float64(processed.Load()) / float64(len(n.files)))
+			n.etaLabel.SetText(eta.text(n.speedModel))
+		})
+
 		successful := 0
 		for success := range results {
-			processed++
+			processed.Add(1)
 			if success {
 				successful++
 			}
-			progress := float64(processed) / float64(len(n.files))
-			fyne.Do(func() {
-				n.progressBar.SetValue(progress)
-			})
+			progressRefresh.Trigger()
 		}
 
-		n.logStatus(fmt.Sprintf("\nComplete: %d/%d files processed successfully", successful, len(n.files)))
-		fyne.Do(func() {
-			n.processBtn.Enable()
-		})
+		n.batchHistory.flush()
+		if report != nil {
+			n.writeBatchReport(report, config)
+		}
+
+		if control.cancelled() {
+			n.logStatus(fmt.Sprintf("\nCancelled: %d/%d files processed successfully, %d not processed", successful, len(n.files), len(n.files)-int(processed.Load())))
+		} else if skipped := unchanged.Load(); skipped > 0 {
+			n.logStatus(fmt.Sprintf("\nComplete: %d/%d files processed successfully, %d unchanged since the last run", successful, len(n.files), skipped))
+		} else {
+			n.logStatus(fmt.Sprintf("\nComplete: %d/%d files processed successfully", successful, len(n.files)))
+		}
+		if flagged := n.musicLibFlagged.Load(); config.MusicLibrary && flagged > 0 {
+			n.logStatus(fmt.Sprintf("⚠ %d tracks need review, listed in %s", flagged, n.musicLibReportPath(config)))
+		}
+		n.notifyOperator(fmt.Sprintf("%d/%d", successful, len(n.files)))
+		n.finishBatch(control)
 	}()
 }
 
-func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool {
+// finishBatch puts the controls back once a batch is done, however it ran
+func (n *AudioNormalizer) finishBatch(control *batchControl) {
+	n.batchRunning.Store(false)
+	n.mutex.Lock()
+	n.batchControl = nil
+	n.mutex.Unlock()
+	control.stop()
+	fyne.Do(func() {
+		n.processBtn.Enable()
+		n.quickBtn.Enable()
+		n.etaLabel.Hide()
+		n.pauseBtn.Hide()
+		n.cancelBtn.Hide()
+	})
+}
+
+// normalizationTargets returns the LUFS and TP targets of the saved normalization standard
+func (n *AudioNormalizer) normalizationTargets() (string, string) {
+	target := "-23"
+	targetTp := "-1"
+
+	switch n.normalizationStandard {
+	case "EBU R128 (-23 LUFS)":
+		target = "-23"
+		targetTp = "-1"
+	case "USA ATSC A/85 (-24 LUFS)":
+		target = "-24"
+		targetTp = "-2"
+	case "Custom":
+		// Only use input fields when Custom is selected
+		if n.normalizeTarget.Text != "" {
+			if strings.Contains(n.normalizeTarget.Text, "-") {
+				target = n.normalizeTarget.Text
+			} else {
+				target = "-" + n.normalizeTarget.Text
+			}
+		}
+		if n.normalizeTargetTp.Text != "" {
+			if strings.Contains(n.normalizeTargetTp.Text, "-") {
+				targetTp = n.normalizeTargetTp.Text
+			} else {
+				targetTp = "-" + n.normalizeTargetTp.Text
+			}
+		}
+	default:
+		target = "-23"
+		targetTp = "-1"
+	}
+
+	return target, targetTp
+}
+
+func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) (ok bool) {
 	n.logToFile(n.logFile, fmt.Sprintf("DEBUG config values: EqTarget='%s', DynamicsPreset='%s', bypassProc=%v",
 	cfg.EqTarget, cfg.DynamicsPreset, cfg.bypassProc))
-	actualCodec := cfg.Format
 	var workingPath string = inputPath
 	var tempFiles []string
 	defer func() { cleanupTempFiles(tempFiles) }()
 
-	if platformCodec := getPlatformCodecMap()[cfg.Format]; platformCodec != "" {
-		actualCodec = platformCodec
-	} else if codec := config.GetCodec(cfg.Format); codec != "" {
-		actualCodec = codec
+	// Every attempt goes into the processing history, failed ones included
+	started := time.Now()
+	var auditOutput string
+	defer func() { n.recordAudit(inputPath, auditOutput, cfg, ok, started) }()
+
+	// Keyed before cfg is adjusted below, the same way the batch looks it up
+	var incrementalKey string
+	if cfg.Incremental {
+		incrementalKey, _ = n.incrementalKey(inputPath, cfg)
 	}
+	defer func() {
+		if ok {
+			n.recordProcessed(incrementalKey, inputPath, auditOutput)
+		}
+	}()
+
+	actualCodec := codecFor(cfg.Format)
+	defer func() { cfg.report.finish(ok, auditOutput, actualCodec) }()
 
 	n.logToFile(n.logFile, fmt.Sprintf("DEBUG: cfg.Format=%s, actualCodec=%s", cfg.Format, actualCodec))
 
-	baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
-
-	// Determine output extension
-	var ext string
-	switch actualCodec {
-	case "libopus":
-		ext = ".opus"
-	case "libfdk_aac":
-		ext = ".m4a"
-	case "aac":
-		ext = ".m4a"
-	case "libmp3lame":
-		ext = ".mp3"
-	case "PCM":
-		ext = ".wav"
-	case "aac_at":
-		ext = ".m4a"
-	case "flac":
-		ext = ".flac"
-	default:
-		ext = filepath.Ext(inputPath)
-	}
+	// Every FFmpeg run of this job is bounded so a corrupt file can't stall a worker
+	watchdog := n.jobWatchdog(inputPath)
+	watchdog.Background = cfg.background
+	watchdog.ReadRate = cfg.readRate
+	watchdog.Context = cfg.ctx
+	progress := n.trackProgress(inputPath, &watchdog)
+	defer progress.done()
 
-	var outputPath string
-	var outputDir string
+	// Tags imported from a CSV, the file's own air date wins over the typed one
+	mapped, hasMapping := n.tagMappingFor(inputPath)
+	if hasMapping && !mapped.AirDate.IsZero() {
+		cfg.AirDate = mapped.AirDate
+		cfg.AirDateTag = true
+	}
 
-	if n.batchMode && n.inputDir != "" {
-		relPath, err := filepath.Rel(n.inputDir, filepath.Dir(inputPath))
-		if err != nil {
-			relPath = ""
-		}
+	// Broadcast WAVs can mark speech and carry scene and take for naming
+	cfg.bwf = n.readBWF(inputPath)
+	if cfg.BWFHints {
+		cfg = n.applyBWFHints(inputPath, cfg)
+	}
 
-		outputDir = filepath.Join(n.outputDir, relPath)
+	// Get target from saved normalization standard, unless the config carries its own
+	target, targetTp := n.fileTargets(inputPath, cfg)
+	if trim := n.gainTrimFor(inputPath); trim != 0 && cfg.UseLoudnorm && !cfg.noTranscode {
+		n.logToFile(n.logFile, fmt.Sprintf("Gain trim moves loudness target for %s to %s LUFS", inputPath, target))
+	}
 
-		os.MkdirAll(outputDir, 0755)
-	} else {
-		outputDir = n.outputDir
+	outputPath, outputDir, outputRoot := n.outputPathFor(inputPath, actualCodec, target, cfg)
+	// Mirrored subfolders are created as needed, the output root has to exist already
+	if outputDir != outputRoot {
+		os.MkdirAll(platform.LongPath(outputDir), 0755)
 	}
 
 	originalExt := filepath.Ext(inputPath)
 
-	if cfg.UseLoudnorm {
-		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s.normalized%s", baseName, ext))
-	} else if cfg.writeTags && cfg.noTranscode {
-		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s.tagged%s", baseName, originalExt))
-	} else if cfg.writeTags {
-		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s.tagged%s", baseName, ext))
-	} else {
-		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s%s", baseName, ext))
-	}
-
+	auditOutput = outputPath
 	n.logStatus(fmt.Sprintf("Processing: %s, outputting to %s", filepath.Base(inputPath), outputPath))
 
 	var measured map[string]string
 
+	// Stage filters are piped into the final encode rather than rendered to
+	// 192 kHz intermediates, and analysis reads the file through them
+	long := n.isLongFile(inputPath, cfg)
+	streamed := n.streamStages(inputPath, cfg, long)
+	var piped []string
+
+	// Mono and stereo files can share a batch, the encode adapts to each
+	channels := outputChannels(inputPath, cfg)
+
+	// Float sources can peak above full scale, which integer output would clip
+	floatOvers := n.floatOvers(inputPath, actualCodec, cfg)
+	if floatOvers && actualCodec == "flac" {
+		n.logStatus(fmt.Sprintf("⚠ Float input peaks above 0 dBFS and will clip in FLAC: %s", filepath.Base(inputPath)))
+	}
+
 	// Build ffmpeg command
 	args := []string{"-i", workingPath, "-vn"}
 
@@ -1916,14 +2984,19 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 		case "64 (float)":
 			codec = "pcm_f64le"
 		}
+		if floatOvers {
+			n.logStatus(fmt.Sprintf("⚠ Float input peaks above 0 dBFS, writing 32-bit float to keep them: %s", filepath.Base(inputPath)))
+			codec = "pcm_f32le"
+		}
 		args = append(args, "-acodec", codec)
 	} else if !n.noTranscode.Checked {
 		args = append(args, "-ar", "48000")
 		args = append(args, "-c:a", actualCodec)
 	}
 
-		needsFullNumber := (actualCodec == "libfdk_aac" || actualCodec == "aac" || actualCodec == "libopus" || actualCodec == "libmp3lame")
-		noBitrateUsed := actualCodec == "PCM" || actualCodec == "flac"
+		codecInfo, _ := config.LookupCodec(actualCodec)
+		needsFullNumber := codecInfo.Bitrate
+		noBitrateUsed := !codecInfo.Bitrate
 
 		bitrateStr := cfg.Bitrate
 
@@ -1949,6 +3022,20 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 				bitrate = 128
 			}
 		}
+		if needsFullNumber && bitrate > codecInfo.MaxKbps*1000 {
+			n.logStatus(fmt.Sprintf("⚠ %d kbps is above the %d kbps maximum of %s, using the maximum", bitrate/1000, codecInfo.MaxKbps, actualCodec))
+			bitrate = codecInfo.MaxKbps * 1000
+		}
+
+		// Mono files in a batch of stereo ones get half the bitrate
+		if channels == 1 && !noBitrateUsed {
+			if needsFullNumber {
+				bitrate = int(monoKbps(float64(bitrate)/1000) * 1000)
+			} else {
+				bitrate = int(monoKbps(float64(bitrate)))
+			}
+			n.logToFile(n.logFile, fmt.Sprintf("Mono output for %s, bitrate %d", inputPath, bitrate))
+		}
 
 		if !noBitrateUsed {
 			if needsFullNumber {
@@ -1958,58 +3045,19 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 			}
 		}
 
-	// Add speech optimization for Opus
-	if cfg.IsSpeech && actualCodec == "libopus" && !n.noTranscode.Checked {
-		args = append(args, "-application", "voip")
-	} else if !cfg.IsSpeech && actualCodec == "libopus" && !n.noTranscode.Checked {
-		args = append(args, "-application", "audio")
+	// Add speech optimization for Opus, chosen per file
+	if actualCodec == "libopus" && !n.noTranscode.Checked {
+		args = append(args, "-application", opusApplication(cfg, channels))
 	}
 
-	usesDataCompression := actualCodec == "flac" || actualCodec == "libopus"
+	usesDataCompression := codecInfo.CompressionLevels > 0
 
 	if usesDataCompression {
-		var level int
-		if actualCodec == "libopus" {
-			level = 10 - int(cfg.dataCompLevel)
-		} else if actualCodec == "flac" {
-			level = int(math.Round(float64(cfg.dataCompLevel) * 12.0 / 10.0))
-		}
+		level := codecInfo.CompressionLevel(int(cfg.dataCompLevel))
 		args = append(args, "-compression_level", fmt.Sprintf("%d", level))
 	}
 
-	// Get target from saved normalization standard
-	target := "-23"
-	targetTp := "-1"
-
-	switch n.normalizationStandard {
-	case "EBU R128 (-23 LUFS)":
-		target = "-23"
-		targetTp = "-1"
-	case "USA ATSC A/85 (-24 LUFS)":
-		target = "-24"
-		targetTp = "-2"
-	case "Custom":
-		// Only use input fields when Custom is selected
-		if n.normalizeTarget.Text != "" {
-			if strings.Contains(n.normalizeTarget.Text, "-") {
-				target = n.normalizeTarget.Text
-			} else {
-				target = "-" + n.normalizeTarget.Text
-			}
-		}
-		if n.normalizeTargetTp.Text != "" {
-			if strings.Contains(n.normalizeTargetTp.Text, "-") {
-				targetTp = n.normalizeTargetTp.Text
-			} else {
-				targetTp = "-" + n.normalizeTargetTp.Text
-			}
-		}
-	default:
-		target = "-23"
-		targetTp = "-1"
-	}
-
-	// Staged processing with temp files (192kHz 64-bit to prevent clipping)
+	// Staged processing, piped or with temp files (192kHz 64-bit to prevent clipping)
 	var eqFilter string
 	var dynamicsFilter string
 	var multibandFilter string
@@ -2021,9 +3069,106 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 	cfg.EqTarget != "Off",
 	!cfg.bypassProc))
 
+	// Restoration runs first, on the source at its own sample rate, where
+	// clipped runs and clicks haven't been smoothed by resampling yet
+	if cfg.Restoration && !cfg.bypassProc {
+		if cfg.noTranscode {
+			n.logStatus(fmt.Sprintf("⚠ Restoration skipped without transcoding: %s", filepath.Base(inputPath)))
+		} else if streamed {
+			filter, ok := n.restorationFilter(inputPath, workingPath)
+			if !ok {
+				return false
+			}
+			if filter != "" {
+				piped = n.pipeStage(piped, "Restoration", inputPath, filter)
+			}
+		} else if restoredPath, ok := n.restore(inputPath, workingPath, watchdog); !ok {
+			return false
+		} else if restoredPath != workingPath {
+			tempFiles = append(tempFiles, restoredPath)
+			n.logToFile(n.logFile, fmt.Sprintf("Added temp file: %s (total: %d)", restoredPath, len(tempFiles)))
+			workingPath = restoredPath
+		}
+	}
+
+	cd := n.readCDSource(inputPath)
+
+	// Stage 0: De-emphasis, manual gain trim and channel mapping fix from
+	// pre-flight, so every later stage and all analysis see the fixed audio
+	var stage0Filters []string
+	if cd.PreEmphasis && !cfg.KeepEmphasis {
+		if cfg.noTranscode {
+			n.logStatus(fmt.Sprintf("⚠ Pre-emphasis left in place without transcoding: %s", filepath.Base(inputPath)))
+		} else {
+			stage0Filters = append(stage0Filters, audio.DeemphasisFilter)
+		}
+	}
+	if trim := n.gainTrimFor(inputPath); trim != 0 {
+		if cfg.noTranscode {
+			n.logStatus(fmt.Sprintf("⚠ Gain trim ignored without transcoding: %s", filepath.Base(inputPath)))
+		} else {
+			stage0Filters = append(stage0Filters, fmt.Sprintf("volume=%.2fdB", trim))
+			n.logToFile(n.logFile, fmt.Sprintf("Gain trim for %s: %+.2f dB", inputPath, trim))
+		}
+	}
+	if cfg.channelFilter != "" {
+		stage0Filters = append(stage0Filters, cfg.channelFilter)
+	}
+	if (cfg.RumbleFilter || cfg.ChannelAlign) && !cfg.bypassProc {
+		if cfg.noTranscode {
+			n.logStatus(fmt.Sprintf("⚠ Rumble filter and channel alignment skipped without transcoding: %s", filepath.Base(inputPath)))
+		} else {
+			stage0Filters = append(stage0Filters, n.digitizationFilters(inputPath, cfg)...)
+		}
+	}
+	kept, trimmed := n.keptRange(inputPath, cfg)
+	if trimmed && cfg.noTranscode {
+		n.logStatus(fmt.Sprintf("⚠ Trim ignored without transcoding: %s", filepath.Base(inputPath)))
+		trimmed = false
+	} else if trimmed {
+		n.logToFile(n.logFile, fmt.Sprintf("Trim for %s: %s", inputPath, kept))
+	}
+
+	if trimmed && streamed {
+		stage0Filters = append([]string{kept.filter()}, stage0Filters...)
+	}
+
+	if len(stage0Filters) > 0 && streamed {
+		piped = n.pipeStage(piped, "Audio preparation", inputPath, stage0Filters...)
+	} else if len(stage0Filters) > 0 || trimmed {
+		chanTempPath := filepath.Join(os.TempDir(), fmt.Sprintf("tnt_chan_%d.wav", time.Now().UnixNano()))
+		tempFiles = append(tempFiles, chanTempPath)
+		n.logToFile(n.logFile, fmt.Sprintf("Added temp file: %s (total: %d)", chanTempPath, len(tempFiles)))
+
+		n.logStatus(fmt.Sprintf("→ Preparing audio (trim, de-emphasis, gain trim, channel layout): %s", filepath.Base(inputPath)))
+		var stage0Args []string
+		if trimmed {
+			stage0Args = kept.inputArgs()
+		}
+		stage0Args = append(stage0Args, "-i", workingPath)
+		if len(stage0Filters) > 0 {
+			stage0Args = append(stage0Args, "-af", strings.Join(stage0Filters, ","))
+		}
+		stageOutput, err := watchdog.Run(append(stage0Args,
+			"-ar", "192000",
+			"-acodec", "pcm_f64le",
+			"-y", chanTempPath,
+		)...)
+
+		if err != nil {
+			n.logStatus(fmt.Sprintf("✗ Failed to prepare audio: %s", filepath.Base(inputPath)))
+			n.logToFile(n.logFile, fmt.Sprintf("Stage 0 failed: %v", err))
+			n.logWatchdogFailure(inputPath, err, stageOutput)
+			return false
+		}
+
+		workingPath = chanTempPath
+		n.logStatus(fmt.Sprintf("✓ Audio prepared: %s", filepath.Base(inputPath)))
+	}
+
 	// Stage 1: EQ analysis and application
 	if cfg.EqTarget != "" && cfg.EqTarget != "Off" && !cfg.bypassProc {
-		eqBandAnalysis := n.analyzeFrequencyResponseBands(workingPath)
+		eqBandAnalysis := n.analyzeFrequencyResponseBands(workingPath, withPiped(piped, ""))
 		if eqBandAnalysis == nil || len(eqBandAnalysis) == 0 {
 			n.logStatus(fmt.Sprintf("✗ Failed to analyze frequency response: %s", filepath.Base(inputPath)))
 			return false
@@ -2038,7 +3183,9 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 		eqFilter = n.buildEqFilter(eqBandAnalysis, cfg.EqTarget)
 		n.logToFile(n.logFile, fmt.Sprintf("DEBUG: eqFilter value = '%s'", eqFilter))
 
-		if eqFilter != "" {
+		if eqFilter != "" && streamed {
+			piped = n.pipeStage(piped, "EQ", inputPath, eqFilter, "deesser=i=1.0:m=1.0:f=0.05:s=o")
+		} else if eqFilter != "" {
 			eqTempPath := filepath.Join(os.TempDir(), fmt.Sprintf("tnt_eq_%d.wav", time.Now().UnixNano()))
 			tempFiles = append(tempFiles, eqTempPath)
 			n.logToFile(n.logFile, fmt.Sprintf("Added temp file: %s (total: %d)", eqTempPath, len(tempFiles)))
@@ -2047,19 +3194,20 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 
 			fullEqFilter := eqFilter + ",deesser=i=1.0:m=1.0:f=0.05:s=o"
 
-			cmd := ffmpeg.Command(
+			eqArgs := []string{
 				"-i", workingPath,
 				"-af", fullEqFilter,
 				"-ar", "192000",
 				"-acodec", "pcm_f64le",
 				"-y", eqTempPath,
-			)
+			}
 
-			n.logToFile(n.logFile, fmt.Sprintf("%s", cmd))
+			n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", eqArgs))
 
-			if err := cmd.Run(); err != nil {
+			if stageOutput, err := watchdog.Run(eqArgs...); err != nil {
 				n.logStatus(fmt.Sprintf("✗ Failed to apply EQ: %s", filepath.Base(inputPath)))
 				n.logToFile(n.logFile, fmt.Sprintf("EQ application failed: %v", err))
+				n.logWatchdogFailure(inputPath, err, stageOutput)
 				return false
 			}
 
@@ -2079,34 +3227,54 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 	cfg.DynamicsPreset != "Off",
 	!cfg.bypassProc))
 
+	// Stages measuring the same audio share one analysis run, see analysis.go
+	var analyses analysisCache
+	broadcast := cfg.DynamicsPreset == "Broadcast"
+
 	var dsAnalysis *audio.DynamicsScoreAnalysis
 	if !cfg.bypassProc && (cfg.DynamicsPreset != "" && cfg.DynamicsPreset != "Off") {
-		dsAnalysis = n.calculateDynamicsScore(inputPath)
-		if dsAnalysis == nil {
+		n.logStatus(fmt.Sprintf("→ Calculating Dynamics Score: %s", filepath.Base(inputPath)))
+		// The bands come along when nothing has changed the audio before compression
+		pass, err := n.analyze(&analyses, inputPath, "", broadcast && workingPath == inputPath && len(piped) == 0)
+		if err != nil {
 			n.logStatus(fmt.Sprintf("✗ Failed to calculate Dynamics Score: %s", filepath.Base(inputPath)))
 			return false
 		}
+		dsAnalysis = n.dynamicsScoreFromStats(pass.full())
+		// Without a trustworthy score the presets apply unmodified
+		if !n.analysisReliable(inputPath, "Dynamics Score", dsAnalysis.Confidence) {
+			dsAnalysis = nil
+		}
+		cfg.report.dynamicsScore(dsAnalysis)
 	}
 
 	// Stage 2: Dynaudnorm if enabled (analyze and apply to temp before loudness measurement)
 	if cfg.DynNorm && !cfg.bypassProc {
-		dynamicsAnalysis := n.analyzeDynamics(workingPath)
-		if dynamicsAnalysis == nil {
+		pass, err := n.analyze(&analyses, workingPath, withPiped(piped, ""), broadcast)
+		if err != nil {
 			n.logStatus(fmt.Sprintf("✗ Failed to analyze for dynaudnorm: %s", filepath.Base(inputPath)))
 			return false
 		}
+		dynamicsAnalysis := n.dynamicsFromStats(pass.full())
 
-		dynParams := n.analyzeDynaudnormParams(dynamicsAnalysis)
+		var dynParams *DynaudnormParams
+		if n.analysisReliable(inputPath, "Dynamic normalization", dynamicsAnalysis.Confidence) {
+			dynParams = n.analyzeDynaudnormParams(dynamicsAnalysis)
+		} else {
+			n.logStatus(fmt.Sprintf("⚠ Dynamic normalization skipped: %s", filepath.Base(inputPath)))
+		}
 		if dynParams != nil {
 			dynaudnormFilter = n.buildDynaudnormFilter(dynParams)
 
-			if dynaudnormFilter != "" {
+			if dynaudnormFilter != "" && streamed {
+				piped = n.pipeStage(piped, "Dynamic normalization", inputPath, dynaudnormFilter)
+			} else if dynaudnormFilter != "" {
 				dynTempPath := filepath.Join(os.TempDir(), fmt.Sprintf("tnt_dyn_%d.wav", time.Now().UnixNano()))
 				tempFiles = append(tempFiles, dynTempPath)
 				n.logToFile(n.logFile, fmt.Sprintf("Added temp file: %s (total: %d)", dynTempPath, len(tempFiles)))
 
 				n.logStatus(fmt.Sprintf("→ Applying dynamic normalization: %s", filepath.Base(inputPath)))
-				cmd := ffmpeg.Command(
+				stageOutput, err := watchdog.Run(
 					"-i", workingPath,
 					"-af", dynaudnormFilter,
 					"-ar", "192000",
@@ -2114,27 +3282,19 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 					"-y", dynTempPath,
 				)
 
-
-				if err := cmd.Run(); err != nil {
+				if err != nil {
 					n.logStatus(fmt.Sprintf("✗ Failed to apply dynaudnorm: %s", filepath.Base(inputPath)))
 					n.logToFile(n.logFile, fmt.Sprintf("Dynaudnorm application failed: %v", err))
+					n.logWatchdogFailure(inputPath, err, stageOutput)
 					return false
 				}
 
 				workingPath = dynTempPath
 				n.logStatus(fmt.Sprintf("✓ Dynamic normalization applied: %s", filepath.Base(inputPath)))
 
-				// Now measure the fully processed audio for loudnorm
-				if cfg.UseLoudnorm {
-					measured = n.measureLoudness(workingPath)
-					if measured == nil {
-						n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", filepath.Base(inputPath)))
-						return false
-					}
-				}
-
-				if cfg.writeTags {
-					measured = n.measureLoudnessEbuR128(workingPath)
+				// Now measure the fully processed audio
+				if cfg.UseLoudnorm || cfg.writeTags {
+					measured = n.measureLoudness(workingPath, measurePurposeFor(cfg), target, targetTp, cfg.Loudnorm)
 					if measured == nil {
 						n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", filepath.Base(inputPath)))
 						return false
@@ -2147,39 +3307,43 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 	// Stage 3: Dynamics analysis and application
 	if cfg.DynamicsPreset != "" && cfg.DynamicsPreset != "Off" && !cfg.bypassProc {
 
+		pass, err := n.analyze(&analyses, workingPath, withPiped(piped, ""), broadcast)
+		if err != nil {
+			n.logStatus(fmt.Sprintf("✗ Failed to analyze dynamics: %s", filepath.Base(inputPath)))
+			return false
+		}
+
 		// Check if MBC needs input attenuation for hot peaks
 		var attenuatedPath string = workingPath
+		var attenuationFilter string
+		var inputAttenuationDb float64
 		if cfg.DynamicsPreset == "Broadcast" {
-			// Quick peak check
-			cmd := ffmpeg.Command( "-i", workingPath, "-af", "astats", "-f", "null", "-")
-
-			output, _ := cmd.CombinedOutput()
-
-			peakRe := regexp.MustCompile(`Peak level dB:\s+([-\d.]+)`)
-			if match := peakRe.FindStringSubmatch(string(output)); len(match) > 1 {
-				peakLevel, _ := strconv.ParseFloat(match[1], 64)
-
+			// Peak check from the same run, over every channel and above full scale for float sources
+			if peakLevel, err := pass.full().Overall.Float("Peak level dB"); err == nil {
 				if peakLevel > -5.0 {
 					targetPeak := -6.0
-					inputAttenuationDb := targetPeak - peakLevel
+					inputAttenuationDb = targetPeak - peakLevel
 					inputVolumeLinear := math.Pow(10, inputAttenuationDb/20)
-
+					attenuationFilter = fmt.Sprintf("volume=%.6f", inputVolumeLinear)
+				}
+				// Piped stages take the attenuation in front of the compressor
+				if attenuationFilter != "" && !streamed {
 					attenuatedPath = filepath.Join(os.TempDir(), fmt.Sprintf("tnt_atten_%d.wav", time.Now().UnixNano()))
 					tempFiles = append(tempFiles, attenuatedPath)
 
 					n.logToFile(n.logFile, fmt.Sprintf("Hot peaks detected (%.2f dBFS), creating attenuated temp: %.2f dB", peakLevel, inputAttenuationDb))
 
-					cmd := ffmpeg.Command(
+					stageOutput, err := watchdog.Run(
 						"-i", workingPath,
-						"-af", fmt.Sprintf("volume=%.6f", inputVolumeLinear),
+						"-af", attenuationFilter,
 						"-ar", "192000",
 						"-acodec", "pcm_f64le",
 						"-y", attenuatedPath,
 					)
 
-
-					if err := cmd.Run(); err != nil {
+					if err != nil {
 						n.logStatus(fmt.Sprintf("✗ Failed to create attenuated temp: %s", filepath.Base(inputPath)))
+						n.logWatchdogFailure(inputPath, err, stageOutput)
 						return false
 					}
 				}
@@ -2187,20 +3351,24 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 		}
 
 		if cfg.DynamicsPreset == "Broadcast" {
-			// MBC: analyze frequency bands from EQ'd file
-			bandAnalysis := n.analyzeFrequencyBands(attenuatedPath)
+			// MBC: frequency bands of the EQ'd file, at the attenuated level
+			bandAnalysis := n.bandsFromStats(inputPath, pass.reports, inputAttenuationDb)
 			if bandAnalysis == nil || len(bandAnalysis) == 0 {
 				n.logStatus(fmt.Sprintf("✗ Failed to analyze frequency bands: %s", filepath.Base(inputPath)))
 				return false
 			}
-			multibandFilter = n.buildMultibandCompression(bandAnalysis, dsAnalysis, cfg.DynamicsPreset)
-		} else {
-			// SBC: analyze dynamics from EQ'd file
-			dynamicsAnalysis := n.analyzeDynamics(workingPath)
-			if dynamicsAnalysis == nil {
-				n.logStatus(fmt.Sprintf("✗ Failed to analyze dynamics: %s", filepath.Base(inputPath)))
-				return false
+			reliable := true
+			for _, band := range bandAnalysis {
+				reliable = n.analysisReliable(inputPath, "Band "+band.BandName, band.Confidence) && reliable
 			}
+			if reliable {
+				multibandFilter = n.buildMultibandCompression(bandAnalysis, dsAnalysis, cfg.DynamicsPreset)
+			} else {
+				n.logStatus(fmt.Sprintf("⚠ Compression skipped: %s", filepath.Base(inputPath)))
+			}
+		} else {
+			// SBC: dynamics of the EQ'd file
+			dynamicsAnalysis := n.dynamicsFromStats(pass.full())
 
 			n.logToFile(n.logFile, fmt.Sprintf("Dynamics Analysis for %s:", filepath.Base(inputPath)))
 			n.logToFile(n.logFile, fmt.Sprintf("  Peak Level: %.2f dBFS", dynamicsAnalysis.PeakLevel))
@@ -2210,16 +3378,29 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 			n.logToFile(n.logFile, fmt.Sprintf("  Crest Factor: %.2f", dynamicsAnalysis.CrestFactor))
 			n.logToFile(n.logFile, fmt.Sprintf("  Dynamic Range: %.2f dB", dynamicsAnalysis.DynamicRange))
 
-			dynamicsFilter = n.calculateAdaptiveCompression(dynamicsAnalysis, dsAnalysis, cfg.DynamicsPreset)
+			if n.analysisReliable(inputPath, "Dynamics", dynamicsAnalysis.Confidence) {
+				dynamicsFilter = n.calculateAdaptiveCompression(dynamicsAnalysis, dsAnalysis, cfg.DynamicsPreset)
+			} else {
+				n.logStatus(fmt.Sprintf("⚠ Compression skipped: %s", filepath.Base(inputPath)))
+			}
 		}
 
+		// The filters are built, analysis data isn't needed for the long encode stages
+		dsAnalysis, pass = nil, nil
+		analyses.release()
+
 		// Apply whichever compression filter was built
 		compressionFilter := multibandFilter
 		if compressionFilter == "" {
 			compressionFilter = dynamicsFilter
 		}
 
-		if compressionFilter != "" {
+		if compressionFilter != "" && streamed && attenuationFilter != "" {
+			n.logToFile(n.logFile, fmt.Sprintf("Hot peaks detected, attenuating %.2f dB ahead of compression", inputAttenuationDb))
+			piped = n.pipeStage(piped, "Compression", inputPath, attenuationFilter, compressionFilter)
+		} else if compressionFilter != "" && streamed {
+			piped = n.pipeStage(piped, "Compression", inputPath, compressionFilter)
+		} else if compressionFilter != "" {
 			compTempPath := filepath.Join(os.TempDir(), fmt.Sprintf("tnt_comp_%d.wav", time.Now().UnixNano()))
 			tempFiles = append(tempFiles, compTempPath)
 			n.logToFile(n.logFile, fmt.Sprintf("Added temp file: %s (total: %d)", compTempPath, len(tempFiles)))
@@ -2232,7 +3413,7 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 				compressionInput = attenuatedPath
 			}
 
-			cmd := ffmpeg.Command(
+			stageOutput, err := watchdog.Run(
 				"-i", compressionInput,
 				"-af", compressionFilter,
 				"-ar", "192000",
@@ -2240,10 +3421,10 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 				"-y", compTempPath,
 			)
 
-
-			if err := cmd.Run(); err != nil {
+			if err != nil {
 				n.logStatus(fmt.Sprintf("✗ Failed to apply compression: %s", filepath.Base(inputPath)))
 				n.logToFile(n.logFile, fmt.Sprintf("Compression application failed: %v", err))
+				n.logWatchdogFailure(inputPath, err, stageOutput)
 				return false
 			}
 
@@ -2251,28 +3432,82 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 			n.logStatus(fmt.Sprintf("✓ Compression applied: %s", filepath.Base(inputPath)))
 		}
 	}
+	// Nothing after the dynamics stages analyzes, so a run cached for dynamic
+	// normalization alone isn't kept through the encodes either
+	dsAnalysis = nil
+	analyses.release()
 
 	n.logToFile(n.logFile, "")
 	n.logToFile(n.logFile, fmt.Sprintf("args: %s", args))
 	n.logToFile(n.logFile, "")
 
 
-	// Stage 4: Measure loudness for normalization (after all processing)
-	if cfg.UseLoudnorm {
-		measured = n.measureLoudness(workingPath)
-		if measured == nil {
+	// Stage 3b: Speech normalization, applied before measuring so loudnorm sees its result
+	speechnormFilter := audio.SpeechnormFilter(cfg.Speechnorm)
+	if speechnormFilter != "" && !cfg.bypassProc && streamed {
+		piped = n.pipeStage(piped, fmt.Sprintf("Speech normalization (%s)", cfg.Speechnorm), inputPath, speechnormFilter)
+	} else if speechnormFilter != "" && !cfg.bypassProc {
+		speechTempPath := filepath.Join(os.TempDir(), fmt.Sprintf("tnt_speech_%d.wav", time.Now().UnixNano()))
+		tempFiles = append(tempFiles, speechTempPath)
+		n.logToFile(n.logFile, fmt.Sprintf("Added temp file: %s (total: %d)", speechTempPath, len(tempFiles)))
+
+		n.logStatus(fmt.Sprintf("→ Applying speech normalization (%s): %s", cfg.Speechnorm, filepath.Base(inputPath)))
+		stageOutput, err := watchdog.Run(
+			"-i", workingPath,
+			"-af", speechnormFilter,
+			"-ar", "192000",
+			"-acodec", "pcm_f64le",
+			"-y", speechTempPath,
+		)
+
+		if err != nil {
+			n.logStatus(fmt.Sprintf("✗ Failed to apply speech normalization: %s", filepath.Base(inputPath)))
+			n.logToFile(n.logFile, fmt.Sprintf("Speechnorm application failed: %v", err))
+			n.logWatchdogFailure(inputPath, err, stageOutput)
+			return false
+		}
+
+		workingPath = speechTempPath
+		n.logStatus(fmt.Sprintf("✓ Speech normalization applied: %s", filepath.Base(inputPath)))
+	}
+
+	// Stage 4: Measure loudness for normalization (after all processing).
+	// A long file is measured once, through its piped stages.
+	if long && (cfg.UseLoudnorm || cfg.writeTags) {
+		if measured = n.measureLongFile(inputPath, workingPath, piped, cfg.Loudnorm, watchdog); measured == nil {
 			n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", filepath.Base(inputPath)))
 			return false
 		}
 	}
 
-	if cfg.writeTags {
-		measured = n.measureLoudnessEbuR128(workingPath)
+	if (cfg.UseLoudnorm || cfg.writeTags) && !long {
+		measured = n.measurePiped(workingPath, piped, measurePurposeFor(cfg), target, targetTp, cfg.Loudnorm)
 		if measured == nil {
 			n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", filepath.Base(inputPath)))
 			return false
 		}
 	}
+	if measured != nil {
+		cfg.measuredBy = measured[measuredByKey]
+		cfg.measuredTo = measured[standardKey]
+		cfg.report.measured(measured)
+	}
+
+	// Dialogue-anchored normalization puts the anchor on target rather than the programme average.
+	// The anchor of a long file is measured unprocessed, so it can't be used with piped stages.
+	if cfg.UseLoudnorm && measured != nil && long && len(piped) > 0 {
+		if region, source, err := n.anchorFor(inputPath, cfg); err == nil {
+			n.logStatus(fmt.Sprintf("⚠ Anchor %s (%s) ignored in long-file mode: %s", region, source, filepath.Base(inputPath)))
+		}
+	} else if cfg.UseLoudnorm && measured != nil {
+		anchored, err := n.anchoredTarget(inputPath, workingPath, cfg, target, measured)
+		if err != nil {
+			n.logStatus(fmt.Sprintf("✗ Anchor normalization failed: %s - %v", filepath.Base(inputPath), err))
+			n.logToFile(n.logFile, fmt.Sprintf("Anchor normalization of %s failed: %v", inputPath, err))
+			return false
+		}
+		target = anchored
+	}
 
 	n.logToFile(n.logFile, "")
 	n.logToFile(n.logFile, fmt.Sprintf("args: %s", args))
@@ -2280,19 +3515,11 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 
 	var loudnormFilterChain string
 	if cfg.UseLoudnorm && measured != nil {
-		if cfg.IsSpeech {
-			loudnormFilterChain = fmt.Sprintf(
-				"speechnorm=e=12.5:r=0.0001:l=1,loudnorm=I=%s:TP=%s:LRA=5.0:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:linear=true",
-				target, targetTp,
-				measured["input_i"], measured["input_tp"], measured["input_lra"], measured["input_thresh"],
-			)
-		} else {
-			loudnormFilterChain = fmt.Sprintf(
-				"loudnorm=I=%s:TP=%s:LRA=5.0:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
-				target, targetTp,
-				measured["input_i"], measured["input_tp"], measured["input_lra"], measured["input_thresh"], measured["target_offset"],
-			)
+		var ok bool
+		if loudnormFilterChain, ok = n.loudnormFilter(inputPath, cfg, target, targetTp, measured); !ok {
+			return false
 		}
+		cfg.report.gain(target)
 	}
 
 	n.logToFile(n.logFile, "")
@@ -2304,6 +3531,10 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 	var finalFilterChain string
 	var filterStages []string
 
+	// Piped stages run here, ahead of normalization
+	filterStages = append(filterStages, piped...)
+	cfg.piped = piped
+
 	if loudnormFilterChain != "" {
 		filterStages = append(filterStages, loudnormFilterChain)
 	}
@@ -2312,10 +3543,20 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 		finalFilterChain = strings.Join(filterStages, ",")
 	}
 
-	args[1] = workingPath
+	// Extra targets share the loudness filter but not the 16-bit dither below
+	extraFilterChain := finalFilterChain
+
+	// Fixed-rate encoders get an explicit conversion instead of FFmpeg's default
+	if resample := n.outputResample(inputPath, codecInfo, cfg); resample != "" {
+		filterStages = append(filterStages, resample)
+		finalFilterChain = strings.Join(filterStages, ",")
+	}
+
+	// The intermediates carry neither the source's tags nor its artwork
+	args = append(n.sourceArgs(inputPath, workingPath, outputPath, cfg), args[3:]...)
 
 	// Add dithering for 16-bit PCM output
-	if actualCodec == "PCM" && cfg.BitDepth == "16" {
+	if actualCodec == "PCM" && cfg.BitDepth == "16" && !floatOvers {
 		if finalFilterChain != "" {
 			finalFilterChain = finalFilterChain + ",aresample=resampler=soxr:dither_method=triangular"
 		} else {
@@ -2350,23 +3591,81 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 		}
 	}
 
-	resultsInM4A := (actualCodec == "libfdk_aac" || actualCodec == "aac") || (cfg.originIsAAC && cfg.noTranscode)
-	useMovFlags :=  resultsInM4A && cfg.writeTags && measured != nil
+	resultsInM4A := codecInfo.Muxer == "ipod" || (cfg.originIsAAC && cfg.noTranscode)
+	updateLoudnessTags := cfg.LoudnessTags == LoudnessTagsUpdate && cfg.UseLoudnorm && !cfg.writeTags && measured != nil
+	cdArgs := cdTagArgs(cd)
+	useMovFlags :=  resultsInM4A && ((cfg.writeTags && measured != nil) || len(cfg.Metadata) > 0 || len(cfg.TagTemplates) > 0 || updateLoudnessTags || hasMapping || (cfg.AirDateTag && !cfg.AirDate.IsZero()) || len(cdArgs) > 0)
 
 	if useMovFlags {
 		args = append(args, "-movflags", "use_metadata_tags")
 	}
 
+	var tagArgs []string
+
+	// Loudness tags copied over from the input no longer describe the output.
+	// Clearing comes first, so tags written below take precedence.
+	if cfg.LoudnessTags != LoudnessTagsPreserve {
+		tagArgs = append(tagArgs, stripLoudnessTagArgs()...)
+	}
+	if updateLoudnessTags {
+		tagArgs = append(tagArgs, updatedLoudnessTagArgs(measured, target, targetTp, actualCodec)...)
+	}
+	args = append(args, tagArgs...)
+
 	if cfg.writeTags && measured != nil {
 		inputI, _ := strconv.ParseFloat(measured["input_i"], 64)
 		targetFloat, _ := strconv.ParseFloat(target, 64)
 		gain := targetFloat - inputI
+		// Untranscoded files can't be limited, players have to apply the gain as tagged
+		var clipArgs []string
+		if cfg.MusicLibrary {
+			gain = n.musicLibraryGain(inputPath, cfg, measured, target, targetTp)
+		} else if cfg.noTranscode {
+			gain, clipArgs = n.tagClipGuard(inputPath, cfg, measured, target, targetTp)
+		}
+		cfg.report.trackGain(gain)
 
-		args = append(args,
+		rgArgs := []string{
 			"-metadata", fmt.Sprintf("REPLAYGAIN_TRACK_GAIN=%.2f dB", gain),
 			"-metadata", fmt.Sprintf("REPLAYGAIN_TRACK_PEAK=%.6f", rgTpInLin),
 			"-metadata", "REPLAYGAIN_REFERENCE_LOUDNESS=" + target + " LUFS",
-		)
+		}
+		rgArgs = append(rgArgs, cfg.album.tagArgs()...)
+		rgArgs = append(rgArgs, clipArgs...)
+		tagArgs = append(tagArgs, rgArgs...)
+		args = append(args, rgArgs...)
+		n.logToFile(n.logFile, fmt.Sprintf("ReplayGain tags of %s from the %s measurement to %s", inputPath, measured[measuredByKey], measured[standardKey]))
+	}
+
+	// Station ownership tags go into every output, extra targets included
+	if stationArgs := metadataArgs(cfg.Metadata); len(stationArgs) > 0 {
+		tagArgs = append(tagArgs, stationArgs...)
+		args = append(args, stationArgs...)
+	}
+
+	// Templated tags go after the station tags, per-file tags below override them
+	if len(cfg.TagTemplates) > 0 {
+		templateArgs := tagTemplateArgs(cfg.TagTemplates, inputPath, cfg.PresetName, target, time.Now())
+		tagArgs = append(tagArgs, templateArgs...)
+		args = append(args, templateArgs...)
+	}
+
+	// ISRC and catalog number from the rip, mapped tags below can override them
+	if len(cdArgs) > 0 {
+		tagArgs = append(tagArgs, cdArgs...)
+		args = append(args, cdArgs...)
+	}
+
+	if hasMapping {
+		mappedArgs := mapped.args()
+		tagArgs = append(tagArgs, mappedArgs...)
+		args = append(args, mappedArgs...)
+	}
+
+	if cfg.AirDateTag && !cfg.AirDate.IsZero() {
+		dateArgs := airDateArgs(cfg.AirDate)
+		tagArgs = append(tagArgs, dateArgs...)
+		args = append(args, dateArgs...)
 	}
 
 	n.logToFile(n.logFile, "")
@@ -2377,24 +3676,48 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 	n.logToFile(n.logFile, "")
 
 
-	args = append(args, "-y", outputPath)
-
-	fullCmdLog := ffmpegPath + " " + strings.Join(args, " ")
-	n.logToFile(n.logFile, fullCmdLog)
+	// Write to a .part file and rename on success, so a crash or a downstream
+	// watcher never sees a half-written output
+	writePath := outputPath
+	if muxer := muxerForOutput(outputPath); muxer != "" {
+		writePath = partPathFor(outputPath)
+		args = append(args, "-f", muxer)
+	}
 
-	cmd := ffmpeg.Command( args...)
+	args = append(args, "-y", writePath)
 
+	n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", args))
 
-	output, err := cmd.CombinedOutput()
+	output, err := watchdog.Run(args...)
 	n.logToFile(n.logFile, fmt.Sprintf("FFmpeg output: %s", string(output)))
 
 	if err != nil {
+		if writePath != outputPath {
+			os.Remove(platform.LongPath(writePath))
+		}
+		n.logWatchdogFailure(inputPath, err, output)
 		n.logStatus(fmt.Sprintf("✗ Failed: %s - %v", filepath.Base(inputPath), err))
 		n.logToFile(n.logFile, fmt.Sprintf("Failed %s - %v", filepath.Base(inputPath), err))
 		n.logToFile(n.logFile, fmt.Sprintf("Error path - cleaning up %d temp files", len(tempFiles)))
 		return false
 	}
 
+	if writePath != outputPath {
+		if err := os.Rename(platform.LongPath(writePath), platform.LongPath(outputPath)); err != nil {
+			os.Remove(platform.LongPath(writePath))
+			n.logStatus(fmt.Sprintf("✗ Failed to finalize output: %s - %v", filepath.Base(outputPath), err))
+			n.logToFile(n.logFile, fmt.Sprintf("Rename %s failed: %v", writePath, err))
+			return false
+		}
+	}
+
+	// The checkbox promises untouched audio, so the promise is checked
+	if cfg.noTranscode && !n.verifyPassthrough(inputPath, outputPath, cfg) {
+		return false
+	}
+
+	n.setOutputTime(inputPath, outputPath, cfg)
+
 	if cfg.BitDepth != "" {
 		n.logToFile(n.logFile, fmt.Sprintf("cfg.Bitdepth= %s", cfg.BitDepth))
 	}
@@ -2428,40 +3751,111 @@ func (n *AudioNormalizer) processFile(inputPath string, cfg ProcessConfig) bool
 		n.logToFile(n.logFile, fmt.Sprintf("TP target: %s", targetTp))
 	}
 
+	if cfg.QualityScore && !cfg.noTranscode && isLossyCodec(actualCodec) {
+		n.logQualityScore(workingPath, extraFilterChain, outputPath)
+	}
+
+	if len(cfg.ExtraTargets) > 0 && !cfg.noTranscode {
+		if !n.encodeExtraTargets(inputPath, workingPath, outputPath, extraFilterChain, tagArgs, cfg, watchdog) {
+			return false
+		}
+	}
+
+	// The web version shares the measurements, only the loudnorm target differs
+	if cfg.WebVersion && cfg.UseLoudnorm && measured != nil && !cfg.noTranscode {
+		if !n.encodeWebVersion(inputPath, workingPath, outputPath, target, measured, tagArgs, cfg, watchdog) {
+			return false
+		}
+	}
+
+	// The clean output above goes to air, the marked copy leaves the station
+	if cfg.PreviewCopy && !cfg.noTranscode {
+		if !n.encodePreviewCopy(inputPath, workingPath, outputPath, extraFilterChain, tagArgs, cfg, watchdog) {
+			return false
+		}
+	}
+
+	if cfg.LoudnessBadge {
+		n.generateLoudnessBadge(outputPath, target, cfg.Loudnorm)
+	}
+
+	if cfg.LoudnessReport != "" && cfg.LoudnessReport != loudnessReportOff {
+		n.generateLoudnessReport(inputPath, outputPath, target, targetTp, cfg)
+	}
+
+	if len(cfg.Series) > 0 {
+		n.trackSeries(inputPath, outputPath, cfg.Series, cfg.Loudnorm)
+	}
+
 	n.logStatus(fmt.Sprintf("✓ Success: %s", filepath.Base(inputPath)))
 	n.logToFile(n.logFile, fmt.Sprintf("✓ Success: %s", filepath.Base(inputPath)))
 	n.logStatus("")
-	n.logStatus(fmt.Sprintf("Your files can be found from %s. Thank you.", n.outputDir))
+	n.logStatus(fmt.Sprintf("Your files can be found from %s. Thank you.", outputRoot))
 
 	n.logToFile(n.logFile, fmt.Sprintf("Cleaning up %d temp files", len(tempFiles)))
 	return true
 }
 
-func (n *AudioNormalizer) parseEBUR128Output(output string) map[string]string {
-	result := make(map[string]string)
-
-	// Parse: "I:         -22.6 LUFS"
-	iRe := regexp.MustCompile(`I:\s+([-\d.]+)\s+LUFS`)
-	if match := iRe.FindStringSubmatch(output); len(match) > 1 {
-		result["input_i"] = match[1]
+// loudnormFilter builds the second loudnorm pass towards target from the
+// first pass measurements, handling linear mode the way cfg asks. It returns
+// false when the file can't be normalized as asked.
+func (n *AudioNormalizer) loudnormFilter(inputPath string, cfg ProcessConfig, target, targetTp string, measured map[string]string) (string, bool) {
+	loudnormLRA := "5.0"
+	measuredTp := measured["input_tp"]
+	var preLimitFilter string
+
+	// loudnorm quietly drops to dynamic mode when linear gain can't fit, so check first
+	targetI, _ := strconv.ParseFloat(target, 64)
+	targetTpVal, _ := strconv.ParseFloat(targetTp, 64)
+	check := audio.CheckLinear(targetI, targetTpVal, 5.0, measured)
+	if !check.Linear {
+		n.logToFile(n.logFile, fmt.Sprintf("Linear loudnorm not possible for %s: %s", inputPath, check.Reason))
+
+		switch cfg.LoudnormFallback {
+		case audio.FallbackFail:
+			n.logStatus(fmt.Sprintf("✗ Linear normalization not possible: %s - %s", filepath.Base(inputPath), check.Reason))
+			return "", false
+		case audio.FallbackPreLimit:
+			filter, peak, ok := audio.PreLimitFilter(check, targetTpVal)
+			if !ok {
+				n.logStatus(fmt.Sprintf("✗ Too much limiting needed for linear normalization: %s", filepath.Base(inputPath)))
+				return "", false
+			}
+			if filter != "" {
+				preLimitFilter = filter + ","
+				measuredTp = fmt.Sprintf("%.2f", peak)
+			}
+			// Linear mode doesn't use the LRA target, it only has to admit the measured range
+			if check.MeasuredLRA > 5.0 {
+				loudnormLRA = fmt.Sprintf("%.1f", math.Min(math.Ceil(check.MeasuredLRA)+1, 50))
+			}
+			n.logStatus(fmt.Sprintf("⚠ Pre-limiting for linear normalization: %s - %s", filepath.Base(inputPath), check.Reason))
+		default:
+			n.logStatus(fmt.Sprintf("⚠ Normalization falls back to dynamic mode: %s - %s", filepath.Base(inputPath), check.Reason))
+		}
 	}
 
-	// Parse: "LRA:         6.4 LU"
-	lraRe := regexp.MustCompile(`LRA:\s+([-\d.]+)\s+LU`)
-	if match := lraRe.FindStringSubmatch(output); len(match) > 1 {
-		result["input_lra"] = match[1]
-	}
+	return fmt.Sprintf(
+		"%sloudnorm=I=%s:TP=%s:LRA=%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:%s",
+		preLimitFilter, target, targetTp, loudnormLRA,
+		measured["input_i"], measuredTp, measured["input_lra"], measured["input_thresh"], cfg.Loudnorm.SecondPass(measured),
+	), true
+}
+
+func (n *AudioNormalizer) parseEBUR128Output(output string) map[string]string {
+	result := make(map[string]string)
 
-	// Parse: "Threshold: -34.1 LUFS"
-	threshRe := regexp.MustCompile(`Threshold:\s+([-\d.]+)\s+LUFS`)
-	if match := threshRe.FindStringSubmatch(output); len(match) > 1 {
-		result["input_thresh"] = match[1]
+	summary, err := audio.ParseEbur128Summary(output)
+	if err != nil {
+		return result
 	}
 
-	// Parse: "Peak: n.y dBFS"
-	pkRe := regexp.MustCompile(`Peak:\s+([-\d.]+)\s+dBFS`)
-	if match := pkRe.FindStringSubmatch(output); len(match) > 1 {
-		result["input_tp"] = match[1]
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	result["input_i"] = format(summary.Integrated)
+	result["input_lra"] = format(summary.LRA)
+	result["input_thresh"] = format(summary.Threshold)
+	if summary.HasPeak {
+		result["input_tp"] = format(summary.TruePeak)
 	}
 
 	n.logStatus(result["input_i"])
@@ -2472,57 +3866,48 @@ func (n *AudioNormalizer) parseEBUR128Output(output string) map[string]string {
 	return result
 }
 
-func (n *AudioNormalizer) measureLoudnessEbuR128(inputPath string) map[string]string {
-	cmd := exec.Command(
-		ffmpegPath,
+// ebur128Pass measures a file with the ebur128 filter after the piped
+// stage filters, to the BS.1770 revision in the preferences, see loudness.go
+func (n *AudioNormalizer) ebur128Pass(inputPath string, piped []string, options audio.LoudnormOptions) map[string]string {
+	standard := n.measurementStandard()
+	channels, _ := audio.ProbeChannels(inputPath)
+	output, err := ffmpeg.Run(
 		"-i", inputPath,
-		"-af", "ebur128=framelog=quiet:peak=true",
+		"-af", withPiped(piped, standard.MeasureFilter(channels, false, options.DualMono)),
 		"-f", "null",
 		"-",
 	)
-
-
-	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil
 	}
 
-	return n.parseEBUR128Output(string(output))
-}
-
-func (n *AudioNormalizer) measureLoudness(inputPath string) map[string]string {
-	n.logStatus(fmt.Sprintf("→ Measuring: %s", filepath.Base(inputPath)))
-
-	target := "-23"
-
-	if (n.loudnormCustomCheck.Checked || n.writeTags.Checked) && n.normalizeTarget.Text != "" {
-		if strings.Contains(n.normalizeTarget.Text, "-") {
-			target = n.normalizeTarget.Text
-		} else {
-			target = "-" + n.normalizeTarget.Text
-		}
+	result := n.parseEBUR128Output(string(output))
+	if standard.Gated() || result["input_i"] == "" {
+		return result
 	}
 
-	targetTp := "-1"
-
-	if (n.loudnormCustomCheck.Checked || n.writeTags.Checked) && n.normalizeTargetTp.Text != "" {
-		if strings.Contains(n.normalizeTargetTp.Text, "-") {
-			targetTp = n.normalizeTargetTp.Text
-		} else {
-			targetTp = "-" + n.normalizeTargetTp.Text
-		}
+	// ebur128 only reports the gated loudness, the ungated one comes from its frames
+	var acc audio.LoudnessAccumulator
+	for _, line := range strings.Split(string(output), "\n") {
+		acc.AddFrameLine(line)
+	}
+	ungated, err := acc.Ungated()
+	if err != nil {
+		return nil
 	}
+	result["input_i"] = strconv.FormatFloat(ungated, 'f', 1, 64)
+	return result
+}
 
-	cmd := exec.Command(
-		ffmpegPath,
+// loudnormPass runs loudnorm's first pass towards a target after the piped
+// stage filters, see loudness.go
+func (n *AudioNormalizer) loudnormPass(inputPath string, piped []string, target, targetTp string, options audio.LoudnormOptions) map[string]string {
+	output, err := ffmpeg.Run(
 		"-i", inputPath,
-		"-af", fmt.Sprintf("loudnorm=linear=false:I=%s:TP=%s:LRA=5:print_format=json", target, targetTp),
+		"-af", withPiped(piped, fmt.Sprintf("loudnorm=linear=false:I=%s:TP=%s:LRA=5:print_format=json%s", target, targetTp, options.FirstPass())),
 		"-f", "null",
 		"-",
 	)
-
-
-	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil
 	}
@@ -2531,44 +3916,32 @@ func (n *AudioNormalizer) measureLoudness(inputPath string) map[string]string {
 }
 
 func (n *AudioNormalizer) parseLoudnormJSON(output string) map[string]string {
-	// Find JSON block in output
-	re := regexp.MustCompile(`(?s)\{[^\}]*"input_i"[^\}]*\}`)
-	jsonMatch := re.FindString(output)
-
-	if jsonMatch == "" {
+	stats, err := audio.ParseLoudnormJSON(output)
+	if err != nil {
 		return nil
 	}
 
-	n.logStatus(fmt.Sprintf("Measured JSON: %s", jsonMatch))
-
-	var data map[string]any
-	if err := json.Unmarshal([]byte(jsonMatch), &data); err != nil {
-		return nil
-}
-
-	result := make(map[string]string)
-	for key, value := range data {
-		if str, ok := value.(string); ok {
-			result[key] = str
-		}
-	}
+	result := stats.Map()
+	n.logStatus(fmt.Sprintf("Measured: I %s, TP %s, LRA %s, threshold %s, offset %s",
+		result["input_i"], result["input_tp"], result["input_lra"], result["input_thresh"], result["target_offset"]))
 
 	return result
 }
 
 func (n *AudioNormalizer) logStatus(message string) {
-	fyne.Do(func() {
-		current := n.statusLog.Text
-		if current != "" {
-			current += "\n"
-		}
-		n.statusLog.SetText(current + message)
-	})
+	n.countFailure(message)
+	n.watchFailures.add(message)
+	if n.headless {
+		n.logServiceStatus(message)
+		return
+	}
+	n.statusLines.Append(message)
+	n.statusRefresh.Trigger()
 }
 
 func isAudioFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
-	audioExts := []string{".mp3", ".wav", ".flac", ".m4a", ".aac", ".ogg", ".opus", ".wma", ".aiff", ".aif", ".ape"}
+	audioExts := []string{".mp3", ".wav", ".flac", ".m4a", ".aac", ".ogg", ".opus", ".wma", ".aiff", ".aif", ".ape", ".m4p"}
 
 	acceptedExt := slices.Contains(audioExts, ext); if acceptedExt {
 		return true
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stationMetadata returns the ownership tags written into every output,
+// keyed by FFmpeg metadata name. Empty fields are left out.
+func (n *AudioNormalizer) stationMetadata() map[string]string {
+	meta := make(map[string]string)
+
+	fields := map[string]string{
+		"publisher":  n.metaPublisher.Text,
+		"copyright":  n.metaCopyright.Text,
+		"encoded_by": n.metaEncodedBy.Text,
+	}
+	for key, value := range fields {
+		if value = strings.TrimSpace(value); value != "" {
+			meta[key] = value
+		}
+	}
+
+	return meta
+}
+
+// metadataArgs turns metadata into FFmpeg -metadata arguments in a stable order
+func metadataArgs(meta map[string]string) []string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	for _, key := range keys {
+		args = append(args, "-metadata", fmt.Sprintf("%s=%s", key, meta[key]))
+	}
+	return args
+}
+
+// Policies for loudness tags that inputs already carry
+const (
+	LoudnessTagsStrip    = "Strip"
+	LoudnessTagsPreserve = "Preserve"
+	LoudnessTagsUpdate   = "Update"
+)
+
+// LoudnessTagPolicies lists the loudness tag policies in display order
+var LoudnessTagPolicies = []string{LoudnessTagsStrip, LoudnessTagsPreserve, LoudnessTagsUpdate}
+
+// loudnessTagKeys are the tags that describe a file's loudness and go stale
+// once the audio is processed
+var loudnessTagKeys = []string{
+	"REPLAYGAIN_TRACK_GAIN",
+	"REPLAYGAIN_TRACK_PEAK",
+	"REPLAYGAIN_ALBUM_GAIN",
+	"REPLAYGAIN_ALBUM_PEAK",
+	"REPLAYGAIN_REFERENCE_LOUDNESS",
+	"R128_TRACK_GAIN",
+	"R128_ALBUM_GAIN",
+	"iTunNORM",
+}
+
+// stripLoudnessTagArgs clears inherited loudness tags. Ogg based formats keep
+// their tags on the stream rather than the container, so both are cleared.
+func stripLoudnessTagArgs() []string {
+	var args []string
+	for _, key := range loudnessTagKeys {
+		args = append(args, "-metadata", key+"=", "-metadata:s:a:0", key+"=")
+	}
+	return args
+}
+
+// updatedLoudnessTagArgs describes a normalized output: no further gain is
+// needed to reach the target, and the peak sits at most at the TP target
+func updatedLoudnessTagArgs(measured map[string]string, target, targetTp, codec string) []string {
+	targetI, _ := strconv.ParseFloat(target, 64)
+	maxTp, _ := strconv.ParseFloat(targetTp, 64)
+
+	peak := maxTp
+	if inputI, err := strconv.ParseFloat(measured["input_i"], 64); err == nil {
+		if inputTp, err := strconv.ParseFloat(measured["input_tp"], 64); err == nil {
+			peak = math.Min(inputTp+targetI-inputI, maxTp)
+		}
+	}
+
+	args := []string{
+		"-metadata", "REPLAYGAIN_TRACK_GAIN=0.00 dB",
+		"-metadata", fmt.Sprintf("REPLAYGAIN_TRACK_PEAK=%.6f", math.Pow(10, peak/20)),
+		"-metadata", "REPLAYGAIN_REFERENCE_LOUDNESS=" + target + " LUFS",
+	}
+
+	// Opus players apply R128_TRACK_GAIN, a Q7.8 gain towards -23 LUFS
+	if codec == "libopus" {
+		args = append(args, "-metadata", fmt.Sprintf("R128_TRACK_GAIN=%d", int(math.Round((-23-targetI)*256))))
+	}
+
+	return args
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// artworkExts are the output containers that can carry embedded cover art.
+// Ogg and WAV have no place for it in FFmpeg.
+var artworkExts = map[string]bool{
+	".mp3":  true,
+	".m4a":  true,
+	".flac": true,
+}
+
+// oggExts are the inputs that keep their tags on the audio stream rather
+// than in the file header
+var oggExts = map[string]bool{
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+}
+
+// sourceArgs open the audio to encode and take the output's tags and
+// artwork from the original input. The processing stages write
+// intermediates that keep neither, and FFmpeg would otherwise drop the
+// artwork of an MP3 going to AAC or of a FLAC going to Opus. With Strip
+// metadata nothing is taken over, only the tags TNT writes itself.
+func (n *AudioNormalizer) sourceArgs(inputPath, workingPath, outputPath string, cfg ProcessConfig) []string {
+	if cfg.StripMetadata {
+		return []string{"-i", workingPath, "-vn", "-map_metadata", "-1", "-map_metadata:s:a", "-1", "-map_chapters", "-1"}
+	}
+
+	source := "1"
+	if oggExts[strings.ToLower(filepath.Ext(inputPath))] {
+		source = "1:s:a:0"
+	}
+	args := []string{"-i", workingPath, "-i", inputPath, "-map", "0:a:0", "-map_metadata", source}
+
+	picture, err := audio.ArtworkStream(inputPath)
+	if err != nil {
+		return args
+	}
+	if !artworkExts[strings.ToLower(filepath.Ext(outputPath))] {
+		n.logToFile(n.logFile, fmt.Sprintf("Artwork of %s not carried, %s outputs can't embed it", inputPath, filepath.Ext(outputPath)))
+		return args
+	}
+	n.logToFile(n.logFile, fmt.Sprintf("Artwork of %s copied from stream %d", inputPath, picture))
+	return append(args, "-map", fmt.Sprintf("1:%d", picture), "-c:v", "copy", "-disposition:v:0", "attached_pic")
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	monitorLogName = "tnt_loudness_monitor.csv"
+
+	// monitorTolerance is how far in LU integrated loudness may sit from the
+	// target before a monitored file is flagged
+	monitorTolerance = 1.0
+)
+
+var monitorHeader = []string{"time", "file", "integrated_lufs", "true_peak_dbtp", "lra_lu", "target_lufs", "target_tp", "in_spec", "notes", "measured_by", "standard"}
+
+// monitorLogPath returns the CSV used by measurement-only watch mode. It goes
+// to the output folder when one is set, so the watched inbox is left untouched.
+func (n *AudioNormalizer) monitorLogPath() string {
+	if n.outputDir != "" {
+		return filepath.Join(n.outputDir, monitorLogName)
+	}
+	return filepath.Join(dataDir(), monitorLogName)
+}
+
+// monitorTargets returns the loudness and true peak targets a watched file
+// is checked against, those it would be normalized to: its watch folder's
+// preset's, or the saved standard's
+func (n *AudioNormalizer) monitorTargets(inputPath string) (string, string) {
+	cfg, err := n.watchConfig(inputPath)
+	if err != nil {
+		return n.normalizationTargets()
+	}
+	return n.fileTargets(inputPath, cfg)
+}
+
+// monitorFile measures a watched file without modifying it and records the result
+func (n *AudioNormalizer) monitorFile(inputPath string) {
+	name := filepath.Base(inputPath)
+	target, targetTp := n.monitorTargets(inputPath)
+
+	row := []string{time.Now().Format(time.RFC3339), inputPath, "", "", "", target, targetTp, "", "", "", ""}
+
+	measured := n.measureLoudness(inputPath, measureForReport, "", "", n.loudnormOptions())
+	if measured == nil || measured["input_i"] == "" {
+		row[7] = "unknown"
+		row[8] = "measurement failed"
+		n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", name))
+		n.writeMonitorRow(row)
+		return
+	}
+
+	row[2] = measured["input_i"]
+	row[3] = measured["input_tp"]
+	row[4] = measured["input_lra"]
+	row[9] = measured[measuredByKey]
+	row[10] = measured[standardKey]
+
+	var problems []string
+	inputI, _ := strconv.ParseFloat(measured["input_i"], 64)
+	targetI, _ := strconv.ParseFloat(target, 64)
+	if diff := inputI - targetI; diff > monitorTolerance || diff < -monitorTolerance {
+		problems = append(problems, fmt.Sprintf("loudness %.1f LU off target", diff))
+	}
+	if inputTp, err := strconv.ParseFloat(measured["input_tp"], 64); err == nil {
+		if maxTp, _ := strconv.ParseFloat(targetTp, 64); inputTp > maxTp {
+			problems = append(problems, fmt.Sprintf("true peak %.1f dBTP over %s", inputTp, targetTp))
+		}
+	}
+
+	if len(problems) == 0 {
+		row[7] = "yes"
+		n.logStatus(fmt.Sprintf("✓ In spec: %s (%s LUFS, %s dBTP)", name, row[2], row[3]))
+	} else {
+		row[7] = "no"
+		row[8] = strings.Join(problems, "; ")
+		n.logStatus(fmt.Sprintf("⚠ Out of spec: %s - %s", name, row[8]))
+		if n.watchAlertCheck.Checked {
+			fyne.CurrentApp().SendNotification(fyne.NewNotification("TNT: file out of spec", fmt.Sprintf("%s: %s", name, row[8])))
+		}
+	}
+
+	n.writeMonitorRow(row)
+}
+
+// writeMonitorRow appends one result to the monitor CSV, writing the header for a new file
+func (n *AudioNormalizer) writeMonitorRow(row []string) {
+	if err := appendCSVRow(n.monitorLogPath(), monitorHeader, row); err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to write monitor log: %v", err))
+		n.logToFile(n.logFile, fmt.Sprintf("Monitor log write failed: %v", err))
+	}
+}
+
+// appendCSVRow appends a row to a CSV file, creating it with header if it
+// doesn't exist. A file written with another header, by an older TNT, is
+// left alone and the row goes to the next versioned file, see csvVersionPath.
+func appendCSVRow(path string, header []string, row []string) error {
+	if err := os.MkdirAll(platform.LongPath(filepath.Dir(path)), 0755); err != nil {
+		return err
+	}
+
+	path, err := csvVersionPath(path, header)
+	if err != nil {
+		return err
+	}
+	info, statErr := os.Stat(platform.LongPath(path))
+	isNew := os.IsNotExist(statErr) || (statErr == nil && info.Size() == 0)
+
+	f, err := os.OpenFile(platform.LongPath(path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if isNew {
+		w.Write(header)
+	}
+	w.Write(row)
+	w.Flush()
+	return w.Error()
+}
+
+// csvVersionPath returns the first of path, path-v2, path-v3 and so on, e.g.
+// monitor-v2.csv, that doesn't exist yet or starts with header
+func csvVersionPath(path string, header []string) (string, error) {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for version := 1; ; version++ {
+		candidate := path
+		if version > 1 {
+			candidate = fmt.Sprintf("%s-v%d%s", stem, version, ext)
+		}
+
+		f, err := os.Open(platform.LongPath(candidate))
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		existing, err := csv.NewReader(f).Read()
+		f.Close()
+		if err == io.EOF || slices.Equal(existing, header) {
+			return candidate, nil
+		}
+	}
+}
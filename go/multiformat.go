@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/config"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/internal/license"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// extraTarget is an additional output format encoded from the same processed
+// audio as the main output, with fixed delivery settings
+type extraTarget struct {
+	Name string
+	Ext  string
+	Args []string
+}
+
+// extraTargets lists the formats that can be produced next to the main output
+var extraTargets = []extraTarget{
+	{Name: "FLAC archive", Ext: ".flac", Args: []string{"-c:a", "flac", "-compression_level", "8"}},
+	{Name: "PCM 48 kHz/24 bit", Ext: ".wav", Args: []string{"-ar", "48000", "-acodec", "pcm_s24le"}},
+	{Name: "AAC 256 kbps", Ext: ".m4a", Args: []string{"-ar", "48000", "-c:a", config.GetCodec("AAC"), "-b:a", "256000"}},
+	{Name: "MP3 320 kbps", Ext: ".mp3", Args: []string{"-ar", "48000", "-c:a", "libmp3lame", "-b:a", "320000"}},
+	{Name: "Opus 128 kbps", Ext: ".opus", Args: []string{"-ar", "48000", "-c:a", "libopus", "-b:a", "128000"}},
+	{Name: "MP2 384 kbps (playout)", Ext: ".mp2", Args: []string{"-ar", "48000", "-c:a", "mp2", "-b:a", "384k"}},
+}
+
+// extraTargetNames returns the target names in display order
+func extraTargetNames() []string {
+	names := make([]string, len(extraTargets))
+	for i, t := range extraTargets {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// findExtraTarget looks up a target by its display name
+func findExtraTarget(name string) (extraTarget, bool) {
+	for _, t := range extraTargets {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return extraTarget{}, false
+}
+
+// encodeExtraTargets encodes each selected extra format from the processed
+// working file, reusing the loudness filter and tags of the main output.
+// Returns false if any of them failed.
+func (n *AudioNormalizer) encodeExtraTargets(inputPath, workingPath, outputPath, filterChain string, tagArgs []string, cfg ProcessConfig, watchdog ffmpeg.Watchdog) bool {
+	if err := n.license.check(license.FeatureMultiFormat); err != nil {
+		n.logStatus(fmt.Sprintf("⚠ Extra formats of %s skipped: %v", filepath.Base(outputPath), err))
+		return true
+	}
+
+	ok := true
+	stem := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+
+	for _, name := range cfg.ExtraTargets {
+		target, found := findExtraTarget(name)
+		if !found {
+			continue
+		}
+
+		targetPath := stem + target.Ext
+		if targetPath == outputPath {
+			n.logToFile(n.logFile, fmt.Sprintf("Extra target %s skipped, same file as the main output", name))
+			continue
+		}
+
+		args := n.sourceArgs(inputPath, workingPath, targetPath, cfg)
+		args = append(args, target.Args...)
+		if filterChain != "" {
+			args = append(args, "-af", filterChain)
+		}
+		if target.Ext == ".m4a" && len(tagArgs) > 0 {
+			args = append(args, "-movflags", "use_metadata_tags")
+		}
+		args = append(args, tagArgs...)
+
+		writePath := partPathFor(targetPath)
+		args = append(args, "-f", muxerForOutput(targetPath), "-y", writePath)
+
+		n.logStatus(fmt.Sprintf("→ Encoding %s: %s", name, filepath.Base(inputPath)))
+		n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", args))
+
+		output, err := watchdog.Run(args...)
+		if err == nil {
+			err = os.Rename(platform.LongPath(writePath), platform.LongPath(targetPath))
+		}
+		if err != nil {
+			os.Remove(platform.LongPath(writePath))
+			n.logWatchdogFailure(inputPath, err, output)
+			n.logStatus(fmt.Sprintf("✗ Failed %s: %s - %v", name, filepath.Base(inputPath), err))
+			ok = false
+			continue
+		}
+
+		n.setOutputTime(inputPath, targetPath, cfg)
+		n.logStatus(fmt.Sprintf("✓ %s: %s", name, filepath.Base(targetPath)))
+	}
+
+	return ok
+}
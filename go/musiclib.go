@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const musicLibReportName = "music_library_review.csv"
+
+var musicLibReportHeader = []string{"time", "file", "integrated_lufs", "peak_db", "gain_db", "tagged_gain_db", "peak_after_gain_db", "ceiling_db", "issue", "measured_by", "standard"}
+
+// musicLibGain is the track gain worked out for one song
+type musicLibGain struct {
+	// Raw is the gain that would bring the track to target
+	Raw float64
+	// Gain is what gets tagged after the cap and clipping prevention
+	Gain      float64
+	PeakAfter float64
+	Capped    bool
+	// Clips is set when the raw gain would push the peak over the ceiling
+	Clips   bool
+	Reduced bool
+}
+
+// parseMaxTrackGain reads the gain cap; empty means no cap
+func parseMaxTrackGain(text string) (float64, bool, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.ToLower(text)), "db"))
+	if text == "" {
+		return 0, false, nil
+	}
+	limit, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+	if err != nil || math.IsNaN(limit) || limit < 0 {
+		return 0, false, fmt.Errorf("maximum gain %q must be a positive number of dB", text)
+	}
+	return limit, true, nil
+}
+
+// computeMusicLibGain caps the gain, then checks where the peak ends up once a
+// player applies it. With prevention on the gain is lowered so the peak stays
+// under the ceiling; otherwise the track is only flagged.
+func computeMusicLibGain(inputI, peak, target, ceiling float64, maxGain float64, capped bool, preventClip bool) musicLibGain {
+	g := musicLibGain{Raw: target - inputI}
+	g.Gain = g.Raw
+
+	if capped && g.Gain > maxGain {
+		g.Gain = maxGain
+		g.Capped = true
+	}
+
+	g.Clips = peak+g.Gain > ceiling
+	if g.Clips && preventClip {
+		g.Gain = ceiling - peak
+		g.Reduced = true
+	}
+	g.PeakAfter = peak + g.Gain
+	return g
+}
+
+// issue describes why a track needs the music director's attention, "" when it doesn't
+func (g musicLibGain) issue() string {
+	var issues []string
+	if g.Capped {
+		issues = append(issues, "gain capped")
+	}
+	switch {
+	case g.Reduced:
+		issues = append(issues, "gain lowered to prevent clipping")
+	case g.Clips:
+		issues = append(issues, "clips after gain")
+	}
+	return strings.Join(issues, ", ")
+}
+
+// musicLibReportPath returns the review list in the output folder of the batch
+func (n *AudioNormalizer) musicLibReportPath(cfg ProcessConfig) string {
+	if cfg.OutputDir != "" {
+		return filepath.Join(cfg.OutputDir, musicLibReportName)
+	}
+	return filepath.Join(n.outputDir, musicLibReportName)
+}
+
+// musicLibraryGain returns the track gain to tag for a song in music library
+// mode and lists the song for review when it was capped or would clip
+func (n *AudioNormalizer) musicLibraryGain(inputPath string, cfg ProcessConfig, measured map[string]string, target, targetTp string) float64 {
+	inputI, _ := strconv.ParseFloat(measured["input_i"], 64)
+	peak, _ := strconv.ParseFloat(measured["input_tp"], 64)
+	targetI, _ := strconv.ParseFloat(target, 64)
+	ceiling, _ := strconv.ParseFloat(targetTp, 64)
+	maxGain, capped, _ := parseMaxTrackGain(cfg.MaxTrackGain)
+
+	g := computeMusicLibGain(inputI, peak, targetI, ceiling, maxGain, capped, cfg.PreventClipping)
+	cfg.report.peakAfterGain(g.PeakAfter, g.Clips && !g.Reduced)
+
+	issue := g.issue()
+	if issue == "" {
+		return g.Gain
+	}
+
+	n.logStatus(fmt.Sprintf("⚠ %s: %s (%.2f dB tagged, peak after gain %.1f dB)", strings.ToUpper(issue[:1])+issue[1:], filepath.Base(inputPath), g.Gain, g.PeakAfter))
+	row := []string{
+		time.Now().Format(time.RFC3339), inputPath,
+		fmt.Sprintf("%.1f", inputI), fmt.Sprintf("%.1f", peak),
+		fmt.Sprintf("%.2f", g.Raw), fmt.Sprintf("%.2f", g.Gain),
+		fmt.Sprintf("%.1f", g.PeakAfter), fmt.Sprintf("%.1f", ceiling), issue,
+		measured[measuredByKey], measured[standardKey],
+	}
+	if err := appendCSVRow(n.musicLibReportPath(cfg), musicLibReportHeader, row); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Music library review write failed: %v", err))
+	}
+
+	n.musicLibFlagged.Add(1)
+	return g.Gain
+}
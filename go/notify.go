@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// Sounds played when a batch finishes or a watched file fails
+const (
+	notifySoundOff     = "Off"
+	notifySoundChime   = "Chime"
+	notifySoundTwoTone = "Two-tone"
+	notifySoundCustom  = "Custom WAV"
+)
+
+var notifySoundOptions = []string{notifySoundOff, notifySoundChime, notifySoundTwoTone, notifySoundCustom}
+
+// builtinSounds are the FFmpeg arguments that render each built-in sound
+var builtinSounds = map[string][]string{
+	notifySoundChime: {
+		"-f", "lavfi", "-i", "sine=frequency=880:duration=0.6",
+		"-af", "afade=t=in:d=0.01,afade=t=out:st=0.2:d=0.4,volume=-12dB",
+	},
+	notifySoundTwoTone: {
+		"-f", "lavfi", "-i", "sine=frequency=660:duration=0.2",
+		"-f", "lavfi", "-i", "sine=frequency=990:duration=0.4",
+		"-filter_complex", "[0:a][1:a]concat=n=2:v=0:a=1,afade=t=in:d=0.01,afade=t=out:st=0.3:d=0.3,volume=-12dB",
+	},
+}
+
+// soundRenderMutex keeps two notifications from rendering the same file at once
+var soundRenderMutex sync.Mutex
+
+// soundPath returns the WAV file for the selected sound, rendering a built-in
+// sound into the data folder the first time it is needed
+func (n *AudioNormalizer) soundPath() (string, error) {
+	choice := n.notifySoundDrop.Selected
+	if choice == notifySoundCustom {
+		if n.notifySoundFileEntry.Text == "" {
+			return "", fmt.Errorf("no WAV file chosen")
+		}
+		return n.notifySoundFileEntry.Text, nil
+	}
+
+	args, ok := builtinSounds[choice]
+	if !ok {
+		return "", nil
+	}
+
+	soundRenderMutex.Lock()
+	defer soundRenderMutex.Unlock()
+
+	path := filepath.Join(dataDir(), "sounds", choice+".wav")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	args = append(append([]string{}, args...), "-ar", "44100", "-acodec", "pcm_s16le", "-y", path)
+	if output, err := ffmpeg.Run(args...); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("%v: %s", err, output)
+	}
+	return path, nil
+}
+
+// playNotifySound plays the selected sound, doing nothing when it is off
+func (n *AudioNormalizer) playNotifySound() error {
+	path, err := n.soundPath()
+	if err != nil || path == "" {
+		return err
+	}
+	return platform.PlaySound(path)
+}
+
+// notifyOperator plays the chosen sound and, when TNT is in the background,
+// badges its Dock icon or flashes its taskbar button
+func (n *AudioNormalizer) notifyOperator(badge string) {
+	if n.notifySoundDrop == nil {
+		return
+	}
+
+	if err := n.playNotifySound(); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Notification sound failed: %v", err))
+	}
+
+	if n.notifyBadgeCheck.Checked && !n.inForeground.Load() {
+		if err := platform.SetBadge(badge); err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("Setting the badge failed: %v", err))
+		}
+	}
+}
+
+// enteredForeground clears the badge once the operator is back in TNT
+func (n *AudioNormalizer) enteredForeground() {
+	n.inForeground.Store(true)
+	platform.SetBadge("")
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fremen-fi/tnt/go/internal/config"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// partSuffix marks outputs that FFmpeg is still writing
+const partSuffix = ".part"
+
+// filenameTemplateHelp lists the placeholders a preset filename template can use
+const filenameTemplateHelp = "{name} input name, {preset} preset name, {date} processing date, {target} target LUFS, {scene} {take} {tape} {project} from BWF metadata"
+
+// expandFilenameTemplate fills in a preset filename template. The extension
+// is added by the caller. Path separators are replaced so a template can't
+// write outside the output folder. Fields fills further placeholders, those
+// a file has no value for are left empty.
+func expandFilenameTemplate(tmpl, baseName, preset, target string, now time.Time, fields map[string]string) string {
+	pairs := []string{
+		"{name}", baseName,
+		"{preset}", preset,
+		"{date}", now.Format("2006-01-02"),
+		"{target}", target,
+	}
+	for placeholder, value := range fields {
+		pairs = append(pairs, placeholder, value)
+	}
+	name := strings.NewReplacer(pairs...).Replace(tmpl)
+
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return baseName
+	}
+	return name
+}
+
+// partPathFor returns the in-progress path FFmpeg writes to before the final rename
+func partPathFor(outputPath string) string {
+	return outputPath + partSuffix
+}
+
+// isPartFile reports whether a path is an unfinished output
+func isPartFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), partSuffix)
+}
+
+// muxerForOutput returns the FFmpeg muxer for an output path, or "" when unknown.
+// The muxer has to be given explicitly because a .part file has no usable extension.
+func muxerForOutput(outputPath string) string {
+	return config.MuxerForExt(strings.ToLower(filepath.Ext(outputPath)))
+}
+
+// checkOutputWritable performs a touch test in the output folder, catching
+// permission changes and unmounted volumes before any job is started
+func checkOutputWritable(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("no output folder selected")
+	}
+
+	info, err := os.Stat(platform.LongPath(dir))
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", dir)
+	}
+
+	f, err := os.CreateTemp(platform.LongPath(dir), ".tnt-write-test-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// ensureWritableOutput checks the output folder before a job. When it is not
+// writable the batch is paused (other workers block on outputCheckMutex) while
+// the user is asked for a new folder; remaining jobs then resume there.
+// Returns false if the user declined, in which case remaining jobs are skipped.
+// A preset's own output folder is created if needed but never replaced.
+func (n *AudioNormalizer) ensureWritableOutput(cfg ProcessConfig) bool {
+	if cfg.OutputDir != "" {
+		os.MkdirAll(platform.LongPath(cfg.OutputDir), 0755)
+		if err := checkOutputWritable(cfg.OutputDir); err != nil {
+			n.logStatus(fmt.Sprintf("✗ Preset output folder not writable: %v", err))
+			n.logToFile(n.logFile, fmt.Sprintf("Preset %s output folder %s not writable: %v", cfg.PresetName, cfg.OutputDir, err))
+			return false
+		}
+		return true
+	}
+
+	n.outputCheckMutex.Lock()
+	defer n.outputCheckMutex.Unlock()
+
+	if n.outputAborted {
+		return false
+	}
+
+	for {
+		err := checkOutputWritable(n.outputDir)
+		if err == nil {
+			return true
+		}
+
+		n.logStatus(fmt.Sprintf("⏸ Output folder not writable, batch paused: %v", err))
+		n.logToFile(n.logFile, fmt.Sprintf("Output folder %s not writable: %v", n.outputDir, err))
+
+		newDir := n.showFolderPrompt(
+			"Output folder not writable",
+			fmt.Sprintf("TNT cannot write to the output folder:\n%s\n\n%v\n\nChoose a new output folder to resume the remaining files?", n.outputDir, err),
+		)
+		if newDir == "" {
+			n.outputAborted = true
+			n.logStatus("✗ No writable output folder, remaining files will be skipped")
+			return false
+		}
+
+		n.mutex.Lock()
+		n.outputDir = newDir
+		n.mutex.Unlock()
+		fyne.Do(func() {
+			n.outputLabel.SetText(filepath.Base(newDir))
+		})
+		n.logStatus(fmt.Sprintf("▶ Resuming with output folder: %s", newDir))
+	}
+}
+
+// codecFor returns the encoder for a format, the format itself when it names one
+func codecFor(format string) string {
+	if platformCodec := getPlatformCodecMap()[format]; platformCodec != "" {
+		return platformCodec
+	}
+	if codec := config.GetCodec(format); codec != "" {
+		return codec
+	}
+	return format
+}
+
+// outputExt returns the extension an encoder's output is written with
+func outputExt(codec, inputPath string) string {
+	if c, ok := config.LookupCodec(codec); ok {
+		return c.Ext
+	}
+	return filepath.Ext(inputPath)
+}
+
+// fileTargets returns the loudness targets for one file: the saved standard
+// or the config's own, with the target moved by the file's gain trim, since
+// normalization would otherwise undo the trim
+func (n *AudioNormalizer) fileTargets(inputPath string, cfg ProcessConfig) (string, string) {
+	target, targetTp := n.normalizationTargets()
+	if cfg.TargetI != "" {
+		target = cfg.TargetI
+	}
+	if cfg.TargetTp != "" {
+		targetTp = cfg.TargetTp
+	}
+
+	if trim := n.gainTrimFor(inputPath); trim != 0 && cfg.UseLoudnorm && !cfg.noTranscode {
+		if targetI, err := strconv.ParseFloat(target, 64); err == nil {
+			target = fmt.Sprintf("%.1f", targetI+trim)
+		}
+	}
+	return target, targetTp
+}
+
+// outputPathFor returns where a file's output goes, the folder it goes to and
+// the output root that folder mirrors the input under. A name planned for the
+// batch to avoid a collision wins over the usual one.
+func (n *AudioNormalizer) outputPathFor(inputPath, codec, target string, cfg ProcessConfig) (outputPath, outputDir, outputRoot string) {
+	// A preset with its own delivery folder overrides the global output folder
+	outputRoot = n.outputDir
+	if cfg.OutputDir != "" {
+		outputRoot = cfg.OutputDir
+	}
+
+	if src, fromZip := n.zipSourceFor(inputPath); fromZip {
+		outputDir = zipOutputDir(outputRoot, src, cfg.ZipKeepFolders)
+	} else if cfg.watchRoot != "" {
+		relPath, err := filepath.Rel(cfg.watchRoot, filepath.Dir(inputPath))
+		if err != nil {
+			relPath = ""
+		}
+		outputDir = filepath.Join(outputRoot, relPath)
+	} else if n.batchMode && n.inputDir != "" {
+		relPath, err := filepath.Rel(n.inputDir, filepath.Dir(inputPath))
+		if err != nil {
+			relPath = ""
+		}
+		outputDir = filepath.Join(outputRoot, relPath)
+	} else {
+		outputDir = outputRoot
+	}
+
+	if planned, ok := n.plannedOutput(inputPath); ok {
+		return planned, filepath.Dir(planned), outputRoot
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	ext := outputExt(codec, inputPath)
+
+	// A clip list names each of its outputs
+	if cfg.clip != nil {
+		return filepath.Join(outputDir, cfg.clip.Name+ext), outputDir, outputRoot
+	}
+	originalExt := filepath.Ext(inputPath)
+
+	switch {
+	case cfg.FilenameTemplate != "":
+		name := expandFilenameTemplate(cfg.FilenameTemplate, baseName, cfg.PresetName, target, time.Now(), bwfTemplateFields(cfg.bwf))
+		if cfg.noTranscode {
+			outputPath = filepath.Join(outputDir, name+originalExt)
+		} else {
+			outputPath = filepath.Join(outputDir, name+ext)
+		}
+	case cfg.UseLoudnorm:
+		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s.normalized%s", baseName, ext))
+	case cfg.writeTags && cfg.noTranscode:
+		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s.tagged%s", baseName, originalExt))
+	case cfg.writeTags:
+		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s.tagged%s", baseName, ext))
+	default:
+		outputPath = filepath.Join(outputDir, fmt.Sprintf("%s%s", baseName, ext))
+	}
+	return outputPath, outputDir, outputRoot
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var ErrOutputIsInput = errors.New("the output folder is the input folder, outputs would be processed again")
+
+// pathKey is a path in the form paths are compared in. Windows and macOS
+// volumes are case-insensitive by default.
+func pathKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// pathWithin reports whether path is dir or lies somewhere below it
+func pathWithin(path, dir string) bool {
+	if path == "" || dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(pathKey(dir), pathKey(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// outputRoots lists the folders outputs of the current settings can go to
+func (n *AudioNormalizer) outputRoots() []string {
+	n.mutex.Lock()
+	roots := []string{n.outputDir}
+	n.mutex.Unlock()
+
+	if dir := n.selectedPresetOutputDir(); dir != "" {
+		roots = append(roots, dir)
+	}
+	return append(roots, n.watchFolders.outputDirs()...)
+}
+
+// checkOutputOverlap refuses an input folder that is also an output folder.
+// It returns the output folders inside the input folder, which scans and the
+// watcher leave out.
+func (n *AudioNormalizer) checkOutputOverlap(inputDir string) ([]string, error) {
+	var nested []string
+	for _, root := range n.outputRoots() {
+		if root == "" || !pathWithin(root, inputDir) {
+			continue
+		}
+		if pathKey(root) == pathKey(inputDir) {
+			return nil, ErrOutputIsInput
+		}
+		nested = append(nested, root)
+	}
+	return nested, nil
+}
+
+// inOutputTree reports whether a file lies in one of the output folders
+func (n *AudioNormalizer) inOutputTree(path string) bool {
+	for _, root := range n.outputRoots() {
+		if pathWithin(path, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// warnNestedOutput tells the operator which output folders a scan of inputDir leaves out
+func (n *AudioNormalizer) warnNestedOutput(inputDir string, nested []string) {
+	for _, dir := range nested {
+		rel, _ := filepath.Rel(inputDir, dir)
+		n.logStatus(fmt.Sprintf("⚠ Output folder %s is inside %s and is left out", rel, filepath.Base(inputDir)))
+	}
+}
+
+// dropQueuedOutputs removes queued files that lie in an output folder, after
+// the output folder was moved into a folder already scanned
+func (n *AudioNormalizer) dropQueuedOutputs() {
+	n.mutex.Lock()
+	kept := n.files[:0]
+	dropped := 0
+	for _, file := range n.files {
+		if n.inOutputTreeLocked(file) {
+			dropped++
+			continue
+		}
+		kept = append(kept, file)
+	}
+	n.files = kept
+	n.mutex.Unlock()
+
+	if dropped > 0 {
+		n.logStatus(fmt.Sprintf("⊗ Removed %d queued files inside the output folder", dropped))
+		n.queueRefresh.Trigger()
+	}
+}
+
+// inOutputTreeLocked is inOutputTree for callers holding n.mutex
+func (n *AudioNormalizer) inOutputTreeLocked(path string) bool {
+	return pathWithin(path, n.outputDir) || pathWithin(path, n.selectedPresetOutputDir())
+}
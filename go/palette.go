@@ -0,0 +1,238 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// paletteShortcut opens the command palette, Ctrl+K or Cmd+K on macOS
+var paletteShortcut = &desktop.CustomShortcut{KeyName: fyne.KeyK, Modifier: fyne.KeyModifierShortcutDefault}
+
+// paletteSettingsPages are the Menu window tabs the palette can jump to,
+// with the pages of the Functions tab
+var paletteSettingsPages = []struct {
+	Tab, Page string
+}{
+	{"Normalization", ""},
+	{"Save Configuration", ""},
+	{"Presets", ""},
+	{"Metadata", ""},
+	{"Functions", "Mono compatibility check"},
+	{"Functions", "Watch mode"},
+	{"Functions", "Loudness badge"},
+	{"Functions", "Loudness report"},
+	{"Functions", "Channel mapping"},
+	{"Functions", "Quality score"},
+	{"Functions", "Bitrate ladder"},
+	{"Functions", "Audit trail"},
+	{"Functions", "ZIP archives"},
+	{"Functions", "Music library"},
+	{"Functions", "CD rips"},
+	{"Functions", "Folder scan"},
+	{"Functions", "Incremental runs"},
+	{"Functions", "Notifications"},
+	{"Functions", "BWF metadata"},
+	{"Functions", "Preview copies"},
+	{"Functions", "Web version"},
+	{"Functions", "QC comparison"},
+	{"Functions", "Long files"},
+	{"Functions", "Series drift"},
+	{"Functions", "Batch report"},
+	{"Functions", "Duplicate finder"},
+	{"Functions", "Resampling"},
+	{"Functions", "Shift report"},
+	{"Functions", "Clip lists"},
+	{"Watch mode", ""},
+	{"Watch folders", ""},
+	{"History", ""},
+	{"Version upgrade", ""},
+	{"License", ""},
+	{"Send error report", ""},
+}
+
+// paletteCommand is one thing the command palette can do
+type paletteCommand struct {
+	Title string
+	Run   func()
+}
+
+// paletteMatches returns the commands whose title contains every word of
+// the query, those starting with the query first
+func paletteMatches(commands []paletteCommand, query string) []paletteCommand {
+	query = strings.ToLower(strings.TrimSpace(query))
+	words := strings.Fields(query)
+
+	var first, rest []paletteCommand
+	for _, cmd := range commands {
+		title := strings.ToLower(cmd.Title)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(title, word) {
+				matched = false
+				break
+			}
+		}
+		switch {
+		case !matched:
+		case query != "" && strings.HasPrefix(title, query):
+			first = append(first, cmd)
+		default:
+			rest = append(rest, cmd)
+		}
+	}
+	return append(first, rest...)
+}
+
+// paletteCommands lists the actions set up with the main window, a preset
+// switch for every Fast preset and every settings page
+func (n *AudioNormalizer) paletteCommands() []paletteCommand {
+	commands := append([]paletteCommand(nil), n.paletteActions...)
+
+	for _, name := range n.simpleGroupButtons.Options {
+		commands = append(commands, paletteCommand{
+			Title: "Switch preset: " + name,
+			Run: func() {
+				n.modeTabs.SelectIndex(0)
+				n.simpleGroupButtons.SetSelected(name)
+			},
+		})
+	}
+
+	for _, page := range paletteSettingsPages {
+		title := "Settings: " + page.Tab
+		if page.Page != "" {
+			title = "Settings: " + page.Page + " (" + page.Tab + ")"
+		}
+		commands = append(commands, paletteCommand{
+			Title: title,
+			Run:   func() { n.openSettingsPage(page.Tab, page.Page) },
+		})
+	}
+	return commands
+}
+
+// openSettingsPage opens the Menu window on a tab, and on a page of it
+func (n *AudioNormalizer) openSettingsPage(tab, page string) {
+	n.openMenu()
+
+	n.menuMutex.Lock()
+	tabs, functions := n.menuTabs, n.functionsTabs
+	n.menuMutex.Unlock()
+
+	if tabs != nil {
+		selectTab(tabs, tab)
+	}
+	if page != "" && functions != nil {
+		selectTab(functions, page)
+	}
+}
+
+// selectTab selects the tab with the given title
+func selectTab(tabs *container.AppTabs, title string) {
+	for _, item := range tabs.Items {
+		if item.Text == title {
+			tabs.Select(item)
+			return
+		}
+	}
+}
+
+// paletteEntry is the palette's search field. It hands Escape and the arrow
+// keys to the palette instead of moving the cursor.
+type paletteEntry struct {
+	widget.Entry
+	onKey func(*fyne.KeyEvent) bool
+}
+
+func newPaletteEntry() *paletteEntry {
+	e := &paletteEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *paletteEntry) TypedKey(key *fyne.KeyEvent) {
+	if e.onKey != nil && e.onKey(key) {
+		return
+	}
+	e.Entry.TypedKey(key)
+}
+
+// showCommandPalette shows a search over actions, presets and settings.
+// Enter runs the highlighted match, Escape closes.
+func (n *AudioNormalizer) showCommandPalette() {
+	if n.palette != nil {
+		return
+	}
+
+	commands := n.paletteCommands()
+	matches := commands
+	highlighted := 0
+
+	list := widget.NewList(
+		func() int { return len(matches) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(i widget.ListItemID, obj fyne.CanvasObject) {
+			label := obj.(*widget.Label)
+			label.TextStyle.Bold = i == highlighted
+			label.SetText(matches[i].Title)
+		},
+	)
+
+	search := newPaletteEntry()
+	search.SetPlaceHolder("Type an action, preset or setting")
+
+	content := container.NewBorder(search, nil, nil, nil, list)
+	popup := widget.NewModalPopUp(content, n.window.Canvas())
+	n.palette = popup
+
+	closePalette := func() {
+		popup.Hide()
+		n.palette = nil
+	}
+	run := func(i int) {
+		if i < 0 || i >= len(matches) {
+			return
+		}
+		cmd := matches[i]
+		closePalette()
+		cmd.Run()
+	}
+	highlight := func(i int) {
+		if len(matches) == 0 {
+			return
+		}
+		highlighted = min(max(i, 0), len(matches)-1)
+		list.Refresh()
+		list.ScrollTo(highlighted)
+	}
+
+	search.OnChanged = func(query string) {
+		matches = paletteMatches(commands, query)
+		highlight(0)
+		list.Refresh()
+	}
+	search.OnSubmitted = func(string) { run(highlighted) }
+	search.onKey = func(key *fyne.KeyEvent) bool {
+		switch key.Name {
+		case fyne.KeyEscape:
+			closePalette()
+		case fyne.KeyDown:
+			highlight(highlighted + 1)
+		case fyne.KeyUp:
+			highlight(highlighted - 1)
+		default:
+			return false
+		}
+		return true
+	}
+	list.OnSelected = func(i widget.ListItemID) { run(i) }
+
+	popup.Resize(fyne.NewSize(480, 360))
+	popup.Show()
+	highlight(0)
+	n.window.Canvas().Focus(search)
+}
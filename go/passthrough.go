@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// Passthrough verification results in the batch report
+const (
+	passthroughVerified = "verified"
+	passthroughMismatch = "mismatch"
+	passthroughUnknown  = "not verified"
+)
+
+var errNoAudioStreams = errors.New("no audio streams")
+
+// audioStreamHash hashes the audio packets of a file as stored, without
+// decoding, so two files hash alike only when their audio is bit-identical
+func audioStreamHash(path string) (string, error) {
+	out, err := ffmpeg.Output("-hide_banner", "-nostdin", "-i", path,
+		"-map", "0:a", "-c", "copy", "-f", "streamhash", "-hash", "sha256", "-")
+	if err != nil {
+		return "", err
+	}
+	hash := strings.TrimSpace(string(out))
+	if hash == "" {
+		return "", errNoAudioStreams
+	}
+	return hash, nil
+}
+
+// verifyPassthrough checks that tagging without transcoding left the audio
+// untouched, so only the metadata of the output differs from the input.
+// Returns false when the audio differs.
+func (n *AudioNormalizer) verifyPassthrough(inputPath, outputPath string, cfg ProcessConfig) bool {
+	inputHash, err := audioStreamHash(inputPath)
+	if err == nil {
+		var outputHash string
+		if outputHash, err = audioStreamHash(outputPath); err == nil {
+			if inputHash != outputHash {
+				cfg.report.passthrough(passthroughMismatch)
+				n.logStatus(fmt.Sprintf("✗ Audio of the output differs from the source, passthrough not bit-exact: %s", filepath.Base(outputPath)))
+				n.logToFile(n.logFile, fmt.Sprintf("Passthrough mismatch for %s: input %s, output %s", inputPath, inputHash, outputHash))
+				return false
+			}
+			cfg.report.passthrough(passthroughVerified)
+			n.logStatus(fmt.Sprintf("✓ Audio bit-identical to the source: %s", filepath.Base(outputPath)))
+			return true
+		}
+	}
+
+	// A file that can't be hashed isn't shown to differ, it is only left unverified
+	cfg.report.passthrough(passthroughUnknown)
+	n.logStatus(fmt.Sprintf("⚠ Passthrough could not be verified: %s", filepath.Base(outputPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("Passthrough verification of %s failed: %v", outputPath, err))
+	return true
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// defaultWorkers is the number of files processed at once when the
+// preferences leave it open: one per core, leaving one for the rest of the
+// machine
+func defaultWorkers() int {
+	return max(1, runtime.NumCPU()-1)
+}
+
+// validateCount accepts an empty entry, for the default, or a positive whole number
+func validateCount(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(text))
+	if err == nil && count < 1 {
+		return fmt.Errorf("must be at least 1")
+	}
+	return err
+}
+
+// parseCount reads a count entry, 0 when it's empty or invalid
+func parseCount(text string) int {
+	count, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || count < 1 {
+		return 0
+	}
+	return count
+}
+
+// workerCount returns how many files a batch processes at once
+func (n *AudioNormalizer) workerCount() int {
+	if workers := parseCount(n.workersEntry.Text); workers > 0 {
+		return workers
+	}
+	return defaultWorkers()
+}
+
+// applyCPULimits passes the thread limit and priority in the preferences on
+// to every FFmpeg run started from now on
+func (n *AudioNormalizer) applyCPULimits() {
+	ffmpeg.SetThreads(parseCount(n.ffmpegThreadsEntry.Text))
+	ffmpeg.SetLowPriority(n.lowPriorityCheck.Checked)
+}
@@ -0,0 +1,28 @@
+//go:build darwin
+
+package platform
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+#include <stdlib.h>
+#import <AppKit/AppKit.h>
+
+static void tntSetBadge(const char *text) {
+	NSString *label = [NSString stringWithUTF8String:text];
+	dispatch_async(dispatch_get_main_queue(), ^{
+		[[NSApp dockTile] setBadgeLabel:([label length] > 0 ? label : nil)];
+	});
+}
+*/
+import "C"
+
+import "unsafe"
+
+// SetBadge shows text on TNT's Dock icon, an empty text removes the badge
+func SetBadge(text string) error {
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.tntSetBadge(ctext)
+	return nil
+}
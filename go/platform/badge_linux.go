@@ -0,0 +1,8 @@
+//go:build linux
+
+package platform
+
+// SetBadge does nothing on Linux, where desktops don't agree on a badge API
+func SetBadge(text string) error {
+	return nil
+}
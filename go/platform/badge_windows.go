@@ -0,0 +1,62 @@
+//go:build windows
+
+package platform
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	flashwStop      = 0
+	flashwTray      = 0x2
+	flashwTimerNoFG = 0xc
+)
+
+// flashWInfo is FLASHWINFO from winuser.h
+type flashWInfo struct {
+	size    uint32
+	hwnd    windows.HWND
+	flags   uint32
+	count   uint32
+	timeout uint32
+}
+
+var procFlashWindowEx = windows.NewLazySystemDLL("user32.dll").NewProc("FlashWindowEx")
+
+// SetBadge draws attention to TNT's taskbar button. Windows has no text
+// badges for plain windows, so the button flashes until TNT is brought to the
+// front instead; an empty text stops it.
+func SetBadge(text string) error {
+	hwnd := ownWindow()
+	if hwnd == 0 {
+		return nil
+	}
+
+	info := flashWInfo{hwnd: hwnd, flags: flashwTray | flashwTimerNoFG}
+	if text == "" {
+		info.flags = flashwStop
+	}
+	info.size = uint32(unsafe.Sizeof(info))
+	procFlashWindowEx.Call(uintptr(unsafe.Pointer(&info)))
+	return nil
+}
+
+// ownWindow finds the first visible top level window of this process
+func ownWindow() windows.HWND {
+	pid := windows.GetCurrentProcessId()
+	var found windows.HWND
+
+	callback := windows.NewCallback(func(hwnd windows.HWND, _ uintptr) uintptr {
+		var owner uint32
+		windows.GetWindowThreadProcessId(hwnd, &owner)
+		if owner == pid && windows.IsWindowVisible(hwnd) {
+			found = hwnd
+			return 0
+		}
+		return 1
+	})
+	windows.EnumWindows(callback, nil)
+	return found
+}
@@ -0,0 +1,95 @@
+package platform
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework Foundation
+#include <stdlib.h>
+#include <string.h>
+#import <Foundation/Foundation.h>
+
+static void *tntCreateBookmark(const char *path, int *length, char **errOut) {
+	@autoreleasepool {
+		NSURL *url = [NSURL fileURLWithPath:[NSString stringWithUTF8String:path] isDirectory:YES];
+		NSError *error = nil;
+		NSData *data = [url bookmarkDataWithOptions:NSURLBookmarkCreationWithSecurityScope
+			includingResourceValuesForKeys:nil
+			relativeToURL:nil
+			error:&error];
+		if (data == nil) {
+			*errOut = strdup([[error localizedDescription] UTF8String]);
+			return NULL;
+		}
+		*length = (int)[data length];
+		void *buf = malloc([data length]);
+		memcpy(buf, [data bytes], [data length]);
+		return buf;
+	}
+}
+
+static char *tntResolveBookmark(const void *bytes, int length, int *stale, char **errOut) {
+	@autoreleasepool {
+		NSData *data = [NSData dataWithBytes:bytes length:length];
+		BOOL isStale = NO;
+		NSError *error = nil;
+		NSURL *url = [NSURL URLByResolvingBookmarkData:data
+			options:NSURLBookmarkResolutionWithSecurityScope
+			relativeToURL:nil
+			bookmarkDataIsStale:&isStale
+			error:&error];
+		if (url == nil) {
+			*errOut = strdup([[error localizedDescription] UTF8String]);
+			return NULL;
+		}
+		[url startAccessingSecurityScopedResource];
+		*stale = isStale ? 1 : 0;
+		return strdup([[url path] UTF8String]);
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// BookmarksSupported reports whether folder access has to be kept with bookmarks
+const BookmarksSupported = true
+
+// CreateBookmark returns a security-scoped bookmark for a folder the user picked,
+// so access can be regained after a restart without asking again
+func CreateBookmark(path string) ([]byte, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var length C.int
+	var cErr *C.char
+	buf := C.tntCreateBookmark(cPath, &length, &cErr)
+	if buf == nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, errors.New(C.GoString(cErr))
+	}
+	defer C.free(buf)
+
+	return C.GoBytes(buf, length), nil
+}
+
+// ResolveBookmark regains access to a bookmarked folder for as long as TNT runs.
+// Returns the folder's current path, which differs from the saved one if it was
+// moved or renamed, and whether the bookmark should be recreated.
+func ResolveBookmark(data []byte) (string, bool, error) {
+	if len(data) == 0 {
+		return "", false, errors.New("empty bookmark")
+	}
+
+	var stale C.int
+	var cErr *C.char
+	cPath := C.tntResolveBookmark(unsafe.Pointer(&data[0]), C.int(len(data)), &stale, &cErr)
+	if cPath == nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return "", false, errors.New(C.GoString(cErr))
+	}
+	defer C.free(unsafe.Pointer(cPath))
+
+	return C.GoString(cPath), stale != 0, nil
+}
@@ -0,0 +1,21 @@
+//go:build !darwin
+
+package platform
+
+import "errors"
+
+// BookmarksSupported reports whether folder access has to be kept with bookmarks.
+// Only macOS restricts path access to folders the user picked.
+const BookmarksSupported = false
+
+var errNoBookmarks = errors.New("folder bookmarks are only used on macOS")
+
+// CreateBookmark is not needed outside macOS
+func CreateBookmark(path string) ([]byte, error) {
+	return nil, errNoBookmarks
+}
+
+// ResolveBookmark is not needed outside macOS
+func ResolveBookmark(data []byte) (string, bool, error) {
+	return "", false, errNoBookmarks
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package platform
+
+import "syscall"
+
+// FreeSpace returns the bytes available to the current user on the volume holding path
+func FreeSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,8 @@
+//go:build darwin && amd64
+
+package platform
+
+import _ "embed"
+
+//go:embed ffmpegSource/ffprobeMacX86
+var FFprobeBinary []byte
@@ -0,0 +1,8 @@
+//go:build darwin && arm64
+
+package platform
+
+import _ "embed"
+
+//go:embed ffmpegSource/ffprobeMacM
+var FFprobeBinary []byte
@@ -0,0 +1,8 @@
+//go:build linux && amd64
+
+package platform
+
+import _ "embed"
+
+//go:embed ffmpegSource/ffprobeLinuxAmd64
+var FFprobeBinary []byte
@@ -0,0 +1,8 @@
+//go:build windows
+
+package platform
+
+import _ "embed"
+
+//go:embed ffmpegSource/ffprobe.exe
+var FFprobeBinary []byte
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package platform
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsHidden reports whether a file is hidden from normal folder listings
+func IsHidden(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".")
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package platform
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsHidden reports whether a file is hidden from normal folder listings.
+// Dot files copied over from Macs count as hidden too.
+func IsHidden(path string) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	attrs, err := windows.GetFileAttributes(name)
+	if err != nil {
+		return false
+	}
+	return attrs&(windows.FILE_ATTRIBUTE_HIDDEN|windows.FILE_ATTRIBUTE_SYSTEM) != 0
+}
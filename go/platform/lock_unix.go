@@ -0,0 +1,24 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+// LockFile takes an advisory lock on f, exclusive for writers and shared for
+// readers, blocking until it is granted. Every process using the lock must
+// take it, it doesn't stop plain reads and writes.
+func LockFile(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	return syscall.Flock(int(f.Fd()), how)
+}
+
+// UnlockFile releases a lock taken with LockFile
+func UnlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package platform
+
+import (
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// LockFile takes a lock on f, exclusive for writers and shared for readers,
+// blocking until it is granted
+func LockFile(f *os.File, exclusive bool) error {
+	var flags uint32
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	var overlapped windows.Overlapped
+	return windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, math.MaxUint32, math.MaxUint32, &overlapped)
+}
+
+// UnlockFile releases a lock taken with LockFile
+func UnlockFile(f *os.File) error {
+	var overlapped windows.Overlapped
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, math.MaxUint32, math.MaxUint32, &overlapped)
+}
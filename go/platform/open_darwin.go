@@ -0,0 +1,8 @@
+package platform
+
+import "os/exec"
+
+// OpenFile opens a file in the application the system associates with it
+func OpenFile(path string) error {
+	return exec.Command("open", path).Start()
+}
@@ -0,0 +1,15 @@
+package platform
+
+import "golang.org/x/sys/windows"
+
+// OpenFile opens a file in the application the system associates with it.
+// ShellExecute takes the path as it is, through cmd.exe characters such as
+// & in a file name would run as commands.
+func OpenFile(path string) error {
+	file, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	verb, _ := windows.UTF16PtrFromString("open")
+	return windows.ShellExecute(0, verb, file, nil, nil, windows.SW_SHOWNORMAL)
+}
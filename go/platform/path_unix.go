@@ -0,0 +1,8 @@
+//go:build !windows
+
+package platform
+
+// LongPath is a no-op on non-Windows platforms
+func LongPath(path string) string {
+	return path
+}
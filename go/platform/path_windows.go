@@ -0,0 +1,30 @@
+//go:build windows
+
+package platform
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxDirPath is the Win32 limit for directory paths (MAX_PATH minus room for an 8.3 name)
+const maxDirPath = 248
+
+// LongPath returns the extended-length form of a path that would exceed the
+// Win32 MAX_PATH limit, so both os file calls and FFmpeg can open it
+func LongPath(path string) string {
+	if len(path) < maxDirPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	// UNC shares use their own prefix form
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// PrepareProcessGroup starts the command in its own process group, so it can
+// later be killed together with any children it spawns
+func PrepareProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// AttachProcessGroup is a no-op on Unix, the group exists from the start
+func AttachProcessGroup(cmd *exec.Cmd) error {
+	return nil
+}
+
+// KillProcessGroup kills the command's whole process group
+func KillProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// ReleaseProcessGroup is a no-op on Unix
+func ReleaseProcessGroup(cmd *exec.Cmd) {}
+
+// YieldProcessGroup pauses the command's process group so other work gets
+// the CPU, or resumes it. A process can lower its priority but, without
+// privileges, never raise it back, so the group is stopped instead of niced.
+func YieldProcessGroup(cmd *exec.Cmd, yield bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	sig := syscall.SIGCONT
+	if yield {
+		sig = syscall.SIGSTOP
+	}
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
+
+// lowPriorityNice is the niceness low priority runs get, as from nice -n 10
+const lowPriorityNice = 10
+
+// LowerProcessPriority renices the command's process group, every thread of
+// it included, so it only gets the CPU other work leaves
+func LowerProcessPriority(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Setpriority(syscall.PRIO_PGRP, cmd.Process.Pid, lowPriorityNice)
+}
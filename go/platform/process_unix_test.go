@@ -0,0 +1,72 @@
+//go:build !windows
+
+package platform
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// alive reports whether a process still runs. A killed process nobody has
+// reaped yet is a zombie, which counts as gone.
+func alive(pid int) bool {
+	if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		// No /proc outside Linux, the signal check above has to do
+		return true
+	}
+	// The state follows the command name in parentheses
+	_, after, _ := strings.Cut(string(stat), ") ")
+	return !strings.HasPrefix(after, "Z")
+}
+
+func TestKillProcessGroupKillsGrandchildren(t *testing.T) {
+	// The shell is the child, the sleep it starts in the background the grandchild
+	cmd := exec.Command("sh", "-c", "sleep 60 & echo $!; wait")
+	PrepareProcessGroup(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("reading the grandchild's pid: %v", err)
+	}
+	grandchild, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("grandchild pid %q: %v", line, err)
+	}
+	if !alive(grandchild) {
+		t.Fatal("grandchild isn't running before the kill")
+	}
+
+	if err := KillProcessGroup(cmd); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for alive(grandchild) {
+		if time.Now().After(deadline) {
+			syscall.Kill(grandchild, syscall.SIGKILL)
+			t.Fatalf("grandchild %d survived the process group kill", grandchild)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
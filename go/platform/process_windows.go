@@ -0,0 +1,152 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// jobs maps running commands to the Job Object that owns them
+var jobs sync.Map
+
+// PrepareProcessGroup has the command start suspended, so it can't spawn a
+// child before AttachProcessGroup has placed it in its Job Object
+func PrepareProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+}
+
+// AttachProcessGroup places a command started by PrepareProcessGroup in its
+// own Job Object, then lets it run. The job kills every process in it when
+// its last handle closes, so children die with TNT. The command runs even
+// when the job can't be set up, the error says it has no group.
+func AttachProcessGroup(cmd *exec.Cmd) (err error) {
+	defer func() {
+		err = errors.Join(err, resumeProcess(uint32(cmd.Process.Pid)))
+	}()
+
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(
+		job,
+		windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)),
+		uint32(unsafe.Sizeof(info)),
+	); err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+
+	proc, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+	defer windows.CloseHandle(proc)
+
+	if err := windows.AssignProcessToJobObject(job, proc); err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+
+	jobs.Store(cmd, job)
+	return nil
+}
+
+// resumeProcess resumes the threads of a process started suspended. Only its
+// main thread exists at that point, the handle to it isn't kept by os/exec.
+func resumeProcess(pid uint32) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != pid {
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return err
+		}
+		_, err = windows.ResumeThread(thread)
+		windows.CloseHandle(thread)
+		if err != nil {
+			return err
+		}
+	}
+	if errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return nil
+	}
+	return err
+}
+
+// KillProcessGroup terminates every process in the command's Job Object
+func KillProcessGroup(cmd *exec.Cmd) error {
+	if job, ok := jobs.Load(cmd); ok {
+		return windows.TerminateJobObject(job.(windows.Handle), 1)
+	}
+	if cmd.Process != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// ReleaseProcessGroup closes the Job Object handle once the command has exited
+func ReleaseProcessGroup(cmd *exec.Cmd) {
+	if job, ok := jobs.LoadAndDelete(cmd); ok {
+		windows.CloseHandle(job.(windows.Handle))
+	}
+}
+
+// YieldProcessGroup drops the command to idle priority so other work gets
+// the CPU, or restores normal priority
+func YieldProcessGroup(cmd *exec.Cmd, yield bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	proc, err := windows.OpenProcess(windows.PROCESS_SET_INFORMATION, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(proc)
+
+	class := uint32(windows.NORMAL_PRIORITY_CLASS)
+	if yield {
+		class = windows.IDLE_PRIORITY_CLASS
+	}
+	return windows.SetPriorityClass(proc, class)
+}
+
+// LowerProcessPriority drops the command to below normal priority, so it
+// only gets the CPU other work leaves
+func LowerProcessPriority(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	proc, err := windows.OpenProcess(windows.PROCESS_SET_INFORMATION, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(proc)
+
+	return windows.SetPriorityClass(proc, windows.BELOW_NORMAL_PRIORITY_CLASS)
+}
@@ -0,0 +1,87 @@
+//go:build windows
+
+package platform
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// helperEnv makes the test binary act as the child or grandchild of the test
+const helperEnv = "TNT_PROCESS_GROUP_HELPER"
+
+// TestHelperProcess isn't a test. As the child it starts the grandchild right
+// away, before anything could attach a job to it late, and prints its pid.
+func TestHelperProcess(t *testing.T) {
+	switch os.Getenv(helperEnv) {
+	case "child":
+		grandchild := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		grandchild.Env = append(os.Environ(), helperEnv+"=grandchild")
+		if err := grandchild.Start(); err != nil {
+			os.Exit(2)
+		}
+		fmt.Println(grandchild.Process.Pid)
+		grandchild.Wait()
+		os.Exit(0)
+	case "grandchild":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
+
+func TestKillProcessGroupKillsGrandchildren(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperEnv+"=child")
+	PrepareProcessGroup(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := AttachProcessGroup(cmd); err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("attaching the job: %v", err)
+	}
+	defer ReleaseProcessGroup(cmd)
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("reading the grandchild's pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("grandchild pid %q: %v", line, err)
+	}
+	grandchild, err := windows.OpenProcess(windows.SYNCHRONIZE|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		cmd.Process.Kill()
+		t.Fatalf("opening the grandchild: %v", err)
+	}
+	defer windows.CloseHandle(grandchild)
+
+	if err := KillProcessGroup(cmd); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Wait()
+
+	event, err := windows.WaitForSingleObject(grandchild, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event != windows.WAIT_OBJECT_0 {
+		windows.TerminateProcess(grandchild, 1)
+		t.Fatalf("grandchild %d survived the process group kill", pid)
+	}
+}
@@ -0,0 +1,17 @@
+package platform
+
+import "errors"
+
+// ErrServiceUnsupported is returned where TNT can't install itself as a background service
+var ErrServiceUnsupported = errors.New("background service is only available on Windows and macOS")
+
+// ServiceConfig describes the background service TNT installs for watch mode
+type ServiceConfig struct {
+	// Name identifies the service to the service manager, reverse-DNS style so it
+	// also works as a launchd label
+	Name        string
+	DisplayName string
+	Description string
+	Executable  string
+	Args        []string
+}
@@ -0,0 +1,77 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// launchAgentPath returns where the agent's property list is installed
+func launchAgentPath(name string) string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "LaunchAgents", name+".plist")
+}
+
+// InstallService installs TNT as a launchd agent of the current user and
+// loads it. launchd starts it at login and restarts it if it exits.
+func InstallService(c ServiceConfig) error {
+	path := launchAgentPath(c.Name)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("service %s is already installed", c.Name)
+	}
+
+	var args bytes.Buffer
+	for _, arg := range append([]string{c.Executable}, c.Args...) {
+		args.WriteString("\t\t<string>")
+		xml.EscapeText(&args, []byte(arg))
+		args.WriteString("</string>\n")
+	}
+
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>ProcessType</key>
+	<string>Background</string>
+</dict>
+</plist>
+`, c.Name, args.String())
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(plist), 0644); err != nil {
+		return err
+	}
+
+	if out, err := exec.Command("launchctl", "load", "-w", path).CombinedOutput(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("launchctl load failed: %v %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// RemoveService unloads the agent and deletes its property list
+func RemoveService(name string) error {
+	path := launchAgentPath(name)
+	exec.Command("launchctl", "unload", "-w", path).Run()
+	return os.Remove(path)
+}
+
+// ServiceInstalled reports whether the agent is installed
+func ServiceInstalled(name string) bool {
+	_, err := os.Stat(launchAgentPath(name))
+	return err == nil
+}
@@ -0,0 +1,17 @@
+package platform
+
+// InstallService is not available on Linux; run TNT with -service from a
+// systemd unit instead
+func InstallService(c ServiceConfig) error {
+	return ErrServiceUnsupported
+}
+
+// RemoveService is not available on Linux
+func RemoveService(name string) error {
+	return ErrServiceUnsupported
+}
+
+// ServiceInstalled always reports false on Linux
+func ServiceInstalled(name string) bool {
+	return false
+}
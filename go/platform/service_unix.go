@@ -0,0 +1,22 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RunService runs the engine until launchd (or whoever started it) sends SIGTERM
+func RunService(name string, run func(stop <-chan struct{})) error {
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	run(stop)
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build darwin
+
+package platform
+
+import "os/exec"
+
+// PlaySound plays a WAV file on the default output device without waiting for it
+func PlaySound(path string) error {
+	return startPlayer(exec.Command("afplay", path))
+}
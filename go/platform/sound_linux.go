@@ -0,0 +1,21 @@
+//go:build linux
+
+package platform
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// ErrNoPlayer is returned when neither PulseAudio nor ALSA tools are installed
+var ErrNoPlayer = errors.New("no paplay or aplay found to play sounds")
+
+// PlaySound plays a WAV file on the default output device without waiting for it
+func PlaySound(path string) error {
+	for _, player := range []string{"paplay", "aplay"} {
+		if bin, err := exec.LookPath(player); err == nil {
+			return startPlayer(exec.Command(bin, path))
+		}
+	}
+	return ErrNoPlayer
+}
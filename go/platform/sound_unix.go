@@ -0,0 +1,43 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os/exec"
+	"sync"
+)
+
+// player is the sound that is playing, so StopSound can end it
+var (
+	playerMutex sync.Mutex
+	player      *exec.Cmd
+)
+
+// startPlayer starts a player command in the background and keeps it for StopSound
+func startPlayer(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	playerMutex.Lock()
+	player = cmd
+	playerMutex.Unlock()
+	// Reap the player when it finishes on its own
+	go func() {
+		cmd.Wait()
+		playerMutex.Lock()
+		if player == cmd {
+			player = nil
+		}
+		playerMutex.Unlock()
+	}()
+	return nil
+}
+
+// StopSound stops the sound PlaySound started last, if it still plays
+func StopSound() {
+	playerMutex.Lock()
+	defer playerMutex.Unlock()
+	if player != nil && player.Process != nil {
+		player.Process.Kill()
+	}
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package platform
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	sndAsync     = 0x0001
+	sndNoDefault = 0x0002
+	sndFilename  = 0x00020000
+)
+
+var procPlaySound = windows.NewLazySystemDLL("winmm.dll").NewProc("PlaySoundW")
+
+// PlaySound plays a WAV file on the default output device without waiting for it
+func PlaySound(path string) error {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	if ok, _, err := procPlaySound.Call(uintptr(unsafe.Pointer(name)), 0, sndFilename|sndAsync|sndNoDefault); ok == 0 {
+		return err
+	}
+	return nil
+}
+
+// StopSound stops the sound PlaySound started, if it still plays
+func StopSound() {
+	procPlaySound.Call(0, 0, 0)
+}
@@ -0,0 +1,10 @@
+package platform
+
+// SystemLog forwards TNT's warnings and errors to the system's own log, where
+// infrastructure monitoring already looks: syslog on Linux and macOS, the
+// Application event log on Windows
+type SystemLog interface {
+	Warning(eventID uint32, message string) error
+	Error(eventID uint32, message string) error
+	Close() error
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package platform
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// unixSystemLog writes to the local syslog daemon. Syslog has no event IDs,
+// so the ID leads the message where filters can match it.
+type unixSystemLog struct {
+	w *syslog.Writer
+}
+
+// OpenSystemLog connects to syslog under source as the tag, in the daemon facility
+func OpenSystemLog(source string) (SystemLog, error) {
+	w, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_NOTICE, source)
+	if err != nil {
+		return nil, err
+	}
+	return unixSystemLog{w: w}, nil
+}
+
+func (l unixSystemLog) Warning(eventID uint32, message string) error {
+	return l.w.Warning(fmt.Sprintf("[%d] %s", eventID, message))
+}
+
+func (l unixSystemLog) Error(eventID uint32, message string) error {
+	return l.w.Err(fmt.Sprintf("[%d] %s", eventID, message))
+}
+
+func (l unixSystemLog) Close() error {
+	return l.w.Close()
+}
@@ -0,0 +1,44 @@
+package platform
+
+import (
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogTypes are the entry types TNT writes under its event source
+const eventLogTypes = eventlog.Error | eventlog.Warning | eventlog.Info
+
+// windowsSystemLog writes to the Application event log
+type windowsSystemLog struct {
+	l *eventlog.Log
+}
+
+// OpenSystemLog opens the Application event log under source. The source is
+// registered when the service is installed; without it Event Viewer still
+// shows the entries, with a note that the description is missing.
+func OpenSystemLog(source string) (SystemLog, error) {
+	l, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return windowsSystemLog{l: l}, nil
+}
+
+func (l windowsSystemLog) Warning(eventID uint32, message string) error {
+	return l.l.Warning(eventID, message)
+}
+
+func (l windowsSystemLog) Error(eventID uint32, message string) error {
+	return l.l.Error(eventID, message)
+}
+
+func (l windowsSystemLog) Close() error {
+	return l.l.Close()
+}
+
+// registerEventSource lets Event Viewer show TNT's messages in full. IDs have
+// to stay between 1 and 1000 for the EventCreate message file. A source left
+// over from an earlier install is replaced.
+func registerEventSource(source string) error {
+	eventlog.Remove(source)
+	return eventlog.InstallAsEventCreate(source, eventLogTypes)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const preferencesName = "preferences.json"
+
+func preferencesPath() string {
+	return filepath.Join(dataDir(), preferencesName)
+}
+
+// withPreferencesLock runs fn holding the preferences lock. The GUI and the
+// background service can share a profile, so the lock is a file both take.
+func withPreferencesLock(exclusive bool, fn func() error) error {
+	if err := os.MkdirAll(dataDir(), 0755); err != nil {
+		return err
+	}
+	lock, err := os.OpenFile(filepath.Join(dataDir(), preferencesName+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lock.Close()
+
+	if err := platform.LockFile(lock, exclusive); err != nil {
+		return err
+	}
+	defer platform.UnlockFile(lock)
+	return fn()
+}
+
+// readPreferences reads the stored preferences
+func readPreferences() (Preferences, error) {
+	var prefs Preferences
+	err := withPreferencesLock(false, func() error {
+		data, err := os.ReadFile(preferencesPath())
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, &prefs)
+	})
+	return prefs, err
+}
+
+// writePreferences stores the preferences. They are written to a temp file
+// that replaces the old one, so a power loss leaves either the old or the
+// new preferences and never half of each.
+func writePreferences(prefs Preferences) error {
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return withPreferencesLock(true, func() error {
+		return writeFileAtomic(preferencesPath(), data, 0644)
+	})
+}
+
+// writeFileAtomic writes data to a temp file next to path, flushes it to
+// disk and renames it over path
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err = errors.Join(err, tmp.Close()); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// subscribePreferences returns a channel receiving the preferences after
+// every save, for components that keep settings of their own, and a function
+// that ends the subscription. Only the latest preferences are kept for a slow
+// reader.
+func (n *AudioNormalizer) subscribePreferences() (<-chan Preferences, func()) {
+	ch := make(chan Preferences, 1)
+
+	n.prefsMutex.Lock()
+	n.prefsListeners = append(n.prefsListeners, ch)
+	n.prefsMutex.Unlock()
+
+	return ch, func() {
+		n.prefsMutex.Lock()
+		defer n.prefsMutex.Unlock()
+		for i, l := range n.prefsListeners {
+			if l == ch {
+				n.prefsListeners = append(n.prefsListeners[:i], n.prefsListeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notifyPreferences hands saved preferences to every subscriber without
+// waiting on any of them
+func (n *AudioNormalizer) notifyPreferences(prefs Preferences) {
+	n.prefsMutex.Lock()
+	defer n.prefsMutex.Unlock()
+
+	for _, ch := range n.prefsListeners {
+		// Drop a value the subscriber hasn't read yet, it is stale now
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- prefs
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// maxPinnedPresets is how many presets can be pinned to the Fast tab
+const maxPinnedPresets = 5
+
+// builtinFastPresets are the Fast tab choices that ship with TNT
+var builtinFastPresets = []string{
+	"Small file (AAC 256kbps)",
+	"Most compatible (MP3 320kbps)",
+	"Production (PCM 48kHz/24bit)",
+	digitizationPreset,
+}
+
+var (
+	ErrPresetName     = errors.New("preset needs a name")
+	ErrPresetReserved = errors.New("preset name is used by a built-in Fast preset")
+	ErrTooManyPinned  = errors.New("only five presets can be pinned")
+)
+
+// Preset is a named snapshot of the encoding and processing settings
+type Preset struct {
+	Name          string `json:"name"`
+	Format        string `json:"format"`
+	SampleRate    string `json:"sample_rate"`
+	BitDepth      string `json:"bit_depth"`
+	Bitrate       string `json:"bitrate"`
+	DataCompLevel int8   `json:"data_comp_level"`
+	Loudnorm      bool   `json:"loudnorm"`
+	WriteTags     bool   `json:"write_tags"`
+	TargetI       string `json:"target_i"`
+	TargetTp      string `json:"target_tp"`
+	EqPreset      string `json:"eq_preset"`
+	DynPreset     string `json:"dyn_preset"`
+	DynNorm       bool   `json:"dyn_norm"`
+	Restoration   bool   `json:"restoration"`
+	RumbleFilter  bool   `json:"rumble_filter,omitempty"`
+	ChannelAlign  bool   `json:"channel_align,omitempty"`
+	Speechnorm    string `json:"speechnorm"`
+	IsSpeech      bool   `json:"opus_speech"`
+	BypassProc    bool   `json:"bypass_processing"`
+	Pinned        bool   `json:"pinned"`
+
+	// OutputDir and FilenameTemplate route this preset's outputs, empty uses the global output folder and names
+	OutputDir        string `json:"output_dir,omitempty"`
+	FilenameTemplate string `json:"filename_template,omitempty"`
+
+	// Analyzers run on each file before processing, see analyzers.go
+	Analyzers []AnalyzerPlugin `json:"analyzers,omitempty"`
+
+	// LoudnessTags is the policy for loudness tags in the input, empty keeps the saved one
+	LoudnessTags string `json:"loudness_tags,omitempty"`
+}
+
+// legacySpeechnorm is the strength the Opus speech option applied along with
+// loudness normalization before speech normalization became its own stage
+const legacySpeechnorm = "Aggressive"
+
+// UnmarshalJSON reads a preset. One saved without a speech normalization
+// choice but with the speech option keeps the processing it used to get.
+func (p *Preset) UnmarshalJSON(data []byte) error {
+	type plainPreset Preset
+	if err := json.Unmarshal(data, (*plainPreset)(p)); err != nil {
+		return err
+	}
+	if p.Speechnorm == "" && p.IsSpeech {
+		p.Speechnorm = "Off"
+		if p.Loudnorm {
+			p.Speechnorm = legacySpeechnorm
+		}
+	}
+	return nil
+}
+
+// presetNone is the main window's preset choice that uses the Advanced tab as it is
+const presetNone = "No preset"
+
+// presetStore keeps the user's presets in presets.json next to the preferences
+type presetStore struct {
+	mutex   sync.Mutex
+	path    string
+	Presets []Preset `json:"presets"`
+}
+
+func loadPresetStore() *presetStore {
+	s := &presetStore{path: filepath.Join(dataDir(), "presets.json")}
+
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, s)
+	}
+
+	return s
+}
+
+// save writes the store to disk, the caller holds the mutex
+func (s *presetStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// list returns a copy of all presets
+func (s *presetStore) list() []Preset {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.Presets)
+}
+
+// find looks up a preset by name
+func (s *presetStore) find(name string) (Preset, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, p := range s.Presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// put adds a preset, replacing one with the same name but keeping its pin
+func (s *presetStore) put(p Preset) error {
+	if p.Name == "" {
+		return ErrPresetName
+	}
+	if slices.Contains(builtinFastPresets, p.Name) {
+		return ErrPresetReserved
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, existing := range s.Presets {
+		if existing.Name == p.Name {
+			p.Pinned = existing.Pinned
+			s.Presets[i] = p
+			return s.save()
+		}
+	}
+
+	s.Presets = append(s.Presets, p)
+	return s.save()
+}
+
+// importPresets adds presets from a configuration bundle. Replace drops the
+// existing presets first, otherwise presets with the same name are overwritten.
+// Pins beyond the Fast tab limit are dropped. Analyzers and output folders
+// outside the working folder are stripped, see withoutUntrusted, and
+// returned. A preset overwritten here keeps its own.
+func (s *presetStore) importPresets(presets []Preset, replace bool) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if replace {
+		s.Presets = nil
+	}
+
+	var stripped []string
+	for _, p := range presets {
+		if p.Name == "" || slices.Contains(builtinFastPresets, p.Name) {
+			continue
+		}
+		p, dropped := p.withoutUntrusted()
+		stripped = append(stripped, dropped...)
+		i := slices.IndexFunc(s.Presets, func(existing Preset) bool { return existing.Name == p.Name })
+		if i >= 0 {
+			// The analyzers and folder set up on this machine are trusted, they stay
+			p.Analyzers = s.Presets[i].Analyzers
+			if p.OutputDir == "" {
+				p.OutputDir = s.Presets[i].OutputDir
+			}
+			s.Presets[i] = p
+		} else {
+			s.Presets = append(s.Presets, p)
+		}
+	}
+
+	pinned := 0
+	for i := range s.Presets {
+		if s.Presets[i].Pinned {
+			pinned++
+			if pinned > maxPinnedPresets {
+				s.Presets[i].Pinned = false
+			}
+		}
+	}
+
+	return stripped, s.save()
+}
+
+// remove deletes a preset by name
+func (s *presetStore) remove(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Presets = slices.DeleteFunc(s.Presets, func(p Preset) bool { return p.Name == name })
+	return s.save()
+}
+
+// setPinned pins or unpins a preset, refusing more than maxPinnedPresets
+func (s *presetStore) setPinned(name string, pinned bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if pinned {
+		count := 0
+		for _, p := range s.Presets {
+			if p.Pinned && p.Name != name {
+				count++
+			}
+		}
+		if count >= maxPinnedPresets {
+			return ErrTooManyPinned
+		}
+	}
+
+	for i := range s.Presets {
+		if s.Presets[i].Name == name {
+			s.Presets[i].Pinned = pinned
+		}
+	}
+	return s.save()
+}
+
+// pinnedNames returns the names of the pinned presets in saved order
+func (s *presetStore) pinnedNames() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var names []string
+	for _, p := range s.Presets {
+		if p.Pinned {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// applyTo overrides the preset's settings on a process config
+func (p Preset) applyTo(cfg ProcessConfig) ProcessConfig {
+	cfg.Format = p.Format
+	cfg.SampleRate = p.SampleRate
+	cfg.BitDepth = p.BitDepth
+	cfg.Bitrate = p.Bitrate
+	cfg.dataCompLevel = p.DataCompLevel
+	cfg.UseLoudnorm = p.Loudnorm
+	cfg.writeTags = p.WriteTags
+	cfg.EqTarget = p.EqPreset
+	cfg.DynamicsPreset = p.DynPreset
+	cfg.DynNorm = p.DynNorm
+	cfg.Restoration = p.Restoration
+	cfg.RumbleFilter = p.RumbleFilter
+	cfg.ChannelAlign = p.ChannelAlign
+	cfg.Speechnorm = p.Speechnorm
+	cfg.IsSpeech = p.IsSpeech
+	cfg.bypassProc = p.BypassProc
+	return p.applyExtras(cfg)
+}
+
+// applyExtras overrides the settings the Advanced tab has no controls for:
+// the loudness target, routing, tag policy and analyzers
+func (p Preset) applyExtras(cfg ProcessConfig) ProcessConfig {
+	cfg.TargetI = p.TargetI
+	cfg.TargetTp = p.TargetTp
+	cfg.PresetName = p.Name
+	cfg.Analyzers = p.Analyzers
+	if p.OutputDir != "" {
+		cfg.OutputDir = p.OutputDir
+	}
+	if p.FilenameTemplate != "" {
+		cfg.FilenameTemplate = p.FilenameTemplate
+	}
+	if p.LoudnessTags != "" {
+		cfg.LoudnessTags = p.LoudnessTags
+	}
+	return cfg
+}
+
+// selectedPresetOutputDir returns the output folder of the preset selected
+// in the Fast tab, or of the active preset in the other tabs, "" when it has none
+func (n *AudioNormalizer) selectedPresetOutputDir() string {
+	name := n.simpleGroupButtons.Selected
+	if n.modeTabs != nil && n.modeTabs.Selected() != n.modeTabs.Items[0] {
+		name = n.activePreset
+	}
+	if preset, ok := n.presets.find(name); ok {
+		return preset.OutputDir
+	}
+	return ""
+}
+
+// loadPreset puts a preset's settings into the Advanced and Processing tabs
+// and makes it the active preset, whose target, routing and analyzers apply
+// on top of them. presetNone leaves the tabs as they are.
+func (n *AudioNormalizer) loadPreset(name string) {
+	p, ok := n.presets.find(name)
+	if !ok {
+		n.activePreset = ""
+		n.updateProcessButton()
+		return
+	}
+	n.activePreset = p.Name
+
+	// The format first, it shows and hides the other encoding controls
+	n.formatSelect.SetSelected(p.Format)
+	if p.SampleRate != "" {
+		n.sampleRate.SetSelected(p.SampleRate)
+	}
+	if p.BitDepth != "" {
+		n.bitDepth.SetSelected(p.BitDepth)
+	}
+	n.bitrateEntry.SetText(p.Bitrate)
+	n.dataCompLevel.SetValue(float64(p.DataCompLevel))
+	n.loudnormCheck.SetChecked(p.Loudnorm)
+	n.writeTags.SetChecked(p.WriteTags)
+	n.EqDrop.SetSelected(p.EqPreset)
+	n.dynamicsDrop.SetSelected(p.DynPreset)
+	n.dynNorm.SetChecked(p.DynNorm)
+	n.restorationCheck.SetChecked(p.Restoration)
+	n.rumbleCheck.SetChecked(p.RumbleFilter)
+	n.channelAlignCheck.SetChecked(p.ChannelAlign)
+	if p.Speechnorm != "" {
+		n.speechnormDrop.SetSelected(p.Speechnorm)
+	}
+	n.IsSpeechCheck.SetChecked(p.IsSpeech)
+	n.bypassProc.SetChecked(p.BypassProc)
+	if p.LoudnessTags != "" {
+		n.loudnessTagsDrop.SetSelected(p.LoudnessTags)
+	}
+
+	n.modeTabs.SelectIndex(1)
+	n.updateProcessButton()
+	n.logStatus(fmt.Sprintf("Preset %s loaded (%s, %s LUFS)", p.Name, p.Format, p.TargetI))
+}
+
+// capturePreset snapshots the Advanced and Processing tab settings under a name
+func (n *AudioNormalizer) capturePreset(name string) Preset {
+	target, targetTp := n.normalizationTargets()
+
+	return Preset{
+		Name:          name,
+		Format:        n.formatSelect.Selected,
+		SampleRate:    n.sampleRate.Selected,
+		BitDepth:      n.bitDepth.Selected,
+		Bitrate:       n.bitrateEntry.Text,
+		DataCompLevel: int8(n.dataCompLevel.Value),
+		Loudnorm:      n.loudnormCheck.Checked,
+		WriteTags:     n.writeTags.Checked,
+		TargetI:       target,
+		TargetTp:      targetTp,
+		EqPreset:      n.EqDrop.Selected,
+		DynPreset:     n.dynamicsDrop.Selected,
+		DynNorm:       n.dynNorm.Checked,
+		Restoration:   n.restorationCheck.Checked,
+		RumbleFilter:  n.rumbleCheck.Checked,
+		ChannelAlign:  n.channelAlignCheck.Checked,
+		Speechnorm:    n.speechnormDrop.Selected,
+		IsSpeech:      n.IsSpeechCheck.Checked,
+		BypassProc:    n.bypassProc.Checked,
+		LoudnessTags:  n.loudnessTagsDrop.Selected,
+	}
+}
+
+// refreshFastPresets rebuilds the Fast tab choices from the built-ins and
+// the pinned presets, and the main window's preset dropdown from all
+// presets, keeping the current selections when they still exist
+func (n *AudioNormalizer) refreshFastPresets() {
+	n.refreshPresetDrop()
+
+	pinned := n.presets.pinnedNames()
+
+	var options []string
+	if !n.fastReplaceBuiltins || len(pinned) == 0 {
+		options = append(options, builtinFastPresets...)
+	}
+	options = append(options, pinned...)
+
+	selected := n.simpleGroupButtons.Selected
+	n.simpleGroupButtons.Options = options
+	if !slices.Contains(options, selected) {
+		selected = options[0]
+	}
+	n.simpleGroupButtons.SetSelected(selected)
+	n.simpleGroupButtons.Refresh()
+}
+
+// refreshPresetDrop lists every preset in the main window's dropdown. A
+// removed active preset stops applying.
+func (n *AudioNormalizer) refreshPresetDrop() {
+	if n.presetDrop == nil {
+		return
+	}
+	options := []string{presetNone}
+	for _, p := range n.presets.list() {
+		options = append(options, p.Name)
+	}
+	n.presetDrop.Options = options
+	if !slices.Contains(options, n.activePreset) {
+		n.activePreset = ""
+		n.presetDrop.Selected = presetNone
+	}
+	n.presetDrop.Refresh()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+var ErrNoPresets = errors.New("no presets in the file")
+
+// presetFile is presets exported for colleagues to import. A configuration
+// bundle has its presets under the same key, so one can be read as a preset file.
+type presetFile struct {
+	TNTVersion string    `json:"tnt_version"`
+	Exported   time.Time `json:"exported"`
+	Presets    []Preset  `json:"presets"`
+}
+
+// exportPresets writes presets to w. Pins are left out, they are each
+// workstation's own choice.
+func exportPresets(w io.Writer, presets []Preset) error {
+	file := presetFile{TNTVersion: currentVersion, Exported: time.Now()}
+	for _, p := range presets {
+		p.Pinned = false
+		file.Presets = append(file.Presets, p)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+// importPresetFile adds the presets read from r, overwriting presets of the
+// same name, and returns their names and what was stripped from them as
+// untrusted, see withoutUntrusted
+func (n *AudioNormalizer) importPresetFile(r io.Reader) ([]string, []string, error) {
+	var file presetFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("not a TNT preset file: %w", err)
+	}
+	if len(file.Presets) == 0 {
+		return nil, nil, ErrNoPresets
+	}
+	for i := range file.Presets {
+		file.Presets[i].Pinned = false
+	}
+
+	stripped, err := n.presets.importPresets(file.Presets, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(stripped) > 0 {
+		n.logToFile(n.logFile, fmt.Sprintf("Not imported from the preset file: %s", strings.Join(stripped, "; ")))
+	}
+
+	var names []string
+	for _, p := range file.Presets {
+		names = append(names, p.Name)
+	}
+	n.logToFile(n.logFile, fmt.Sprintf("Imported presets from TNT %s: %s", file.TNTVersion, strings.Join(names, ", ")))
+	return names, stripped, nil
+}
+
+// strippedNotice tells the user what an import left out, "" when nothing was
+func strippedNotice(stripped []string) string {
+	if len(stripped) == 0 {
+		return ""
+	}
+	return "\n\nNot imported, add these again in the Presets tab if you trust them:\n" + strings.Join(stripped, "\n")
+}
+
+// showExportPreset asks where to save one preset
+func (n *AudioNormalizer) showExportPreset(preset Preset, parent fyne.Window) {
+	save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if err := exportPresets(writer, []Preset{preset}); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		dialog.ShowInformation("Preset exported", fmt.Sprintf("Saved %s to %s", preset.Name, writer.URI().Path()), parent)
+	}, parent)
+	save.SetFileName(strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(preset.Name) + ".tntpreset.json")
+	save.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	save.Show()
+}
+
+// showImportPresets asks for a preset file and adds its presets. onDone runs
+// after a successful import.
+func (n *AudioNormalizer) showImportPresets(parent fyne.Window, onDone func()) {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		defer reader.Close()
+
+		names, stripped, err := n.importPresetFile(reader)
+		if err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		onDone()
+		dialog.ShowInformation("Presets imported", strings.Join(names, "\n")+strippedNotice(stripped), parent)
+	}, parent)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
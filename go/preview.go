@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// Marks a preview copy can carry
+const (
+	previewMarkWatermark = "Watermark tone"
+	previewMarkIdent     = "Ident"
+)
+
+var previewMarkOptions = []string{previewMarkWatermark, previewMarkIdent}
+
+// previewWatermarkHz is the watermark tone, low enough to survive any codec
+const previewWatermarkHz = 1000
+
+// previewSuffix marks preview copies next to the clean output
+const previewSuffix = ".preview"
+
+// previewEncodeArgs encode previews as MP3, which every outside party can play
+var previewEncodeArgs = []string{"-ar", "48000", "-c:a", "libmp3lame", "-b:a", "192000"}
+
+// previewMark is the parsed preview settings
+type previewMark struct {
+	Kind    string
+	Offset  float64
+	LevelDB float64
+	Ident   string
+}
+
+// parsePreviewMark reads the preview settings as typed in the preferences
+func parsePreviewMark(kind, offsetText, levelText, ident string) (previewMark, error) {
+	m := previewMark{Kind: kind, Ident: ident, LevelDB: -60}
+
+	var err error
+	if strings.TrimSpace(offsetText) != "" {
+		if m.Offset, err = audio.ParseTimecode(offsetText); err != nil {
+			return m, err
+		}
+	}
+
+	switch kind {
+	case previewMarkWatermark:
+		if text := strings.TrimSpace(levelText); text != "" {
+			if m.LevelDB, err = strconv.ParseFloat(text, 64); err != nil {
+				return m, fmt.Errorf("watermark level %q is not a number", text)
+			}
+		}
+		if m.LevelDB >= 0 {
+			return m, fmt.Errorf("watermark level must be below 0 dB")
+		}
+	case previewMarkIdent:
+		if ident == "" {
+			return m, fmt.Errorf("no ident file chosen")
+		}
+	default:
+		return m, fmt.Errorf("unknown preview mark %q", kind)
+	}
+	return m, nil
+}
+
+// filterGraph mixes the mark into the program after its loudness filter. The
+// program is input 0, an ident input 1. The mix keeps the program's length
+// and level.
+func (m previewMark) filterGraph(programFilter string) string {
+	if programFilter == "" {
+		programFilter = "anull"
+	}
+	delay := fmt.Sprintf("adelay=%d:all=1", int(m.Offset*1000))
+
+	var mark string
+	if m.Kind == previewMarkIdent {
+		mark = "[1:a]" + delay + "[mark]"
+	} else {
+		mark = fmt.Sprintf("sine=frequency=%d:sample_rate=48000,volume=%.1fdB,%s[mark]", previewWatermarkHz, m.LevelDB, delay)
+	}
+
+	return fmt.Sprintf("[0:a]%s[program];%s;[program][mark]amix=inputs=2:duration=first:normalize=0", programFilter, mark)
+}
+
+// encodePreviewCopy encodes a marked copy for outside use next to the clean
+// output, from the same processed audio and with the same tags
+func (n *AudioNormalizer) encodePreviewCopy(inputPath, workingPath, outputPath, filterChain string, tagArgs []string, cfg ProcessConfig, watchdog ffmpeg.Watchdog) bool {
+	mark, err := parsePreviewMark(cfg.PreviewMark, cfg.PreviewOffset, cfg.PreviewLevel, cfg.PreviewIdent)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Preview copy not made: %s - %v", filepath.Base(inputPath), err))
+		return false
+	}
+
+	previewPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + previewSuffix + ".mp3"
+
+	args := []string{"-i", workingPath}
+	if mark.Kind == previewMarkIdent {
+		args = append(args, "-i", mark.Ident)
+	}
+	args = append(args, "-filter_complex", mark.filterGraph(filterChain), "-vn")
+	args = append(args, previewEncodeArgs...)
+	args = append(args, tagArgs...)
+	args = append(args, "-metadata", "comment=Preview copy, not for broadcast")
+
+	writePath := partPathFor(previewPath)
+	args = append(args, "-f", muxerForOutput(previewPath), "-y", writePath)
+
+	n.logStatus(fmt.Sprintf("→ Encoding preview copy (%s): %s", strings.ToLower(mark.Kind), filepath.Base(inputPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", args))
+
+	output, err := watchdog.Run(args...)
+	if err == nil {
+		err = os.Rename(platform.LongPath(writePath), platform.LongPath(previewPath))
+	}
+	if err != nil {
+		os.Remove(platform.LongPath(writePath))
+		n.logWatchdogFailure(inputPath, err, output)
+		n.logStatus(fmt.Sprintf("✗ Failed preview copy: %s - %v", filepath.Base(inputPath), err))
+		return false
+	}
+
+	n.setOutputTime(inputPath, previewPath, cfg)
+	n.logStatus(fmt.Sprintf("✓ Preview copy: %s", filepath.Base(previewPath)))
+	return true
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// defaultProfileName is shown for the settings kept directly in the TNT folder
+const defaultProfileName = "Default"
+
+var (
+	ErrProfileName   = errors.New("profile names may only use letters, digits, spaces, dashes and underscores")
+	ErrProfileExists = errors.New("a profile with that name already exists")
+)
+
+// activeProfile is the profile chosen at startup, "" for the default one.
+// It is set once before any settings are read.
+var activeProfile string
+
+// configDirOverride replaces the system config folder when set with -config-dir,
+// so a service running under another account uses the installing user's settings
+var configDirOverride string
+
+// configRoot returns the folder the TNT settings folder lives in
+func configRoot() string {
+	if configDirOverride != "" {
+		return configDirOverride
+	}
+	configDir, _ := os.UserConfigDir()
+	return configDir
+}
+
+// dataDir returns the folder holding the preferences, presets, history and
+// log of the active profile. The default profile uses the TNT folder itself,
+// so existing single-user setups keep working unchanged.
+func dataDir() string {
+	if activeProfile == "" {
+		return filepath.Join(configRoot(), "TNT")
+	}
+	return filepath.Join(configRoot(), "TNT", "profiles", activeProfile)
+}
+
+// profilesDir returns the folder the named profiles live in
+func profilesDir() string {
+	return filepath.Join(configRoot(), "TNT", "profiles")
+}
+
+// validateProfileName keeps profile names usable as folder names on every platform
+func validateProfileName(name string) error {
+	if name == "" || name == defaultProfileName || strings.TrimSpace(name) != name {
+		return ErrProfileName
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == ' ', r == '-', r == '_':
+		default:
+			return ErrProfileName
+		}
+	}
+	return nil
+}
+
+// listProfiles returns the named profiles, sorted
+func listProfiles() []string {
+	entries, err := os.ReadDir(profilesDir())
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && validateProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createProfile makes a new profile, starting from the active profile's
+// preferences and presets so a new operator doesn't begin from scratch
+func createProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(profilesDir(), name)
+	if _, err := os.Stat(dir); err == nil {
+		return ErrProfileExists
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, file := range []string{preferencesName, "presets.json"} {
+		if err := copyFile(filepath.Join(dataDir(), file), filepath.Join(dir, file)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies a small settings file
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// launchArgs are the command line options TNT understands
+type launchArgs struct {
+	Profile string
+	// ProfileGiven is set when -profile was passed, in which case no profile picker is shown
+	ProfileGiven bool
+	Operator     string
+	// Service runs the headless watch engine instead of the window
+	Service bool
+	// JSONRPC takes jobs as line-delimited JSON on stdin, see jsonrpc.go
+	JSONRPC   bool
+	ConfigDir string
+}
+
+// parseLaunchArgs reads the -profile, -operator, -service, -json-rpc and -config-dir flags
+func parseLaunchArgs() launchArgs {
+	// Launchers may add arguments of their own, so unknown flags are ignored
+	flags := flag.NewFlagSet("tnt", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	profile := flags.String("profile", "", "settings profile to use, \"Default\" for the shared one")
+	operator := flags.String("operator", "", "operator name recorded in the processing history")
+	service := flags.Bool("service", false, "run the watch engine in the background without a window")
+	jsonRPC := flags.Bool("json-rpc", false, "take jobs as line-delimited JSON on stdin and report on stdout")
+	configDir := flags.String("config-dir", "", "folder holding the TNT settings folder")
+	flags.Parse(os.Args[1:])
+
+	args := launchArgs{Operator: strings.TrimSpace(*operator), Service: *service, JSONRPC: *jsonRPC, ConfigDir: *configDir}
+
+	name := strings.TrimSpace(*profile)
+	if name == "" {
+		return args
+	}
+	args.ProfileGiven = true
+	if name != defaultProfileName && validateProfileName(name) == nil {
+		args.Profile = name
+	}
+	return args
+}
+
+// profileLabel returns the display name of the active profile
+func profileLabel() string {
+	if activeProfile == "" {
+		return defaultProfileName
+	}
+	return activeProfile
+}
+
+// showProfilePicker asks which profile to use before the main window opens
+func showProfilePicker(a fyne.App, profiles []string, onChosen func(string)) {
+	w := a.NewWindow("TNT - Choose profile")
+
+	options := append([]string{defaultProfileName}, profiles...)
+	picker := widget.NewRadioGroup(options, nil)
+	picker.SetSelected(defaultProfileName)
+
+	openBtn := widget.NewButton("Open", func() {
+		name := picker.Selected
+		if name == defaultProfileName {
+			name = ""
+		}
+		onChosen(name)
+		w.Close()
+	})
+
+	w.SetContent(container.NewVBox(
+		widget.NewLabel("Each profile keeps its own preferences, presets and history."),
+		picker,
+		openBtn,
+	))
+	w.Resize(fyne.NewSize(360, 0))
+	w.CenterOnScreen()
+	w.Show()
+}
+
+// buildProfilesSection shows the active profile in the Save Configuration tab
+// and lets operators create their own
+func (n *AudioNormalizer) buildProfilesSection() fyne.CanvasObject {
+	text := widget.NewLabel(fmt.Sprintf(`
+Profiles
+You are using the %s profile. Each profile keeps its own preferences, presets, history and log, so operators sharing one computer account don't overwrite each other's setup. A new profile starts as a copy of this one. When profiles exist TNT asks which one to use at startup; start TNT with -profile <name> to skip the question.
+	`, profileLabel()))
+	text.Wrapping = fyne.TextWrapWord
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("New profile name")
+
+	createBtn := widget.NewButton("Create profile", func() {
+		name := strings.TrimSpace(nameEntry.Text)
+		if err := createProfile(name); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		nameEntry.SetText("")
+		dialog.ShowInformation("Profile created", fmt.Sprintf("Profile %s was created. Restart TNT to switch to it.", name), n.menuWindow)
+	})
+
+	return container.NewVBox(text, container.NewBorder(nil, nil, nil, createBtn, nameEntry))
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+var ErrQCEmpty = errors.New("CSV has no rows with a filename and integrated loudness")
+
+// qcDefaultTolerance is how far, in LU or dB, TNT may read from an external
+// analyzer before a file is flagged. Loudness range is allowed twice that,
+// since analyzers gate and window it differently.
+const qcDefaultTolerance = 0.5
+
+// qcReportHeader is the comparison report written next to the imported CSV
+var qcReportHeader = []string{
+	"file",
+	"external_lufs", "tnt_lufs", "diff_lu",
+	"external_tp", "tnt_tp", "diff_db",
+	"external_lra", "tnt_lra", "diff_lra",
+	"within_tolerance",
+	"tnt_measured_by",
+	"tnt_standard",
+}
+
+// qcUnitRe strips units and parenthesised notes from header names and values
+var qcUnitRe = regexp.MustCompile(`(?i)\(.*?\)|\[.*?\]|lufs|lkfs|dbtp|dbfs|\blu\b|\bdb\b`)
+
+// qcMeasurement is one file's loudness as an external QC tool measured it.
+// Values the export doesn't have are NaN.
+type qcMeasurement struct {
+	Integrated float64
+	TruePeak   float64
+	LRA        float64
+}
+
+// qcColumnName maps the header spellings of common analyzer exports to
+// filename, integrated, true_peak and lra, or "" for other columns
+func qcColumnName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(qcUnitRe.ReplaceAllString(name, "")))
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '_' || r == '-' }), " ")
+
+	switch {
+	case name == "file" || name == "filename" || name == "file name" || name == "name" || name == "clip" || name == "source":
+		return "filename"
+	case strings.Contains(name, "range") || name == "lra":
+		return "lra"
+	case strings.Contains(name, "true peak") || strings.Contains(name, "truepeak") || name == "tp" || name == "max tp":
+		return "true_peak"
+	case strings.Contains(name, "integrated") || strings.Contains(name, "program loudness") || name == "loudness" || name == "i" || name == "":
+		return "integrated"
+	}
+	return ""
+}
+
+// parseQCValue reads a number the way analyzers export it, with or without
+// a unit and with either decimal separator
+func parseQCValue(text string) (float64, error) {
+	text = strings.TrimSpace(qcUnitRe.ReplaceAllString(text, ""))
+	return strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+}
+
+// loadQCMeasurements reads a loudness export with a header row naming the
+// file and at least its integrated loudness. Rows are keyed like the tag CSV,
+// by name without folder or extension. Unreadable values are skipped and
+// reported as warnings.
+func loadQCMeasurements(path string) (map[string]qcMeasurement, []string, error) {
+	f, err := os.Open(platform.LongPath(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.LazyQuotes = true
+
+	// Exports from European locales separate columns with semicolons
+	if data, err := os.ReadFile(platform.LongPath(path)); err == nil {
+		firstLine, _, _ := strings.Cut(string(data), "\n")
+		if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+			r.Comma = ';'
+		}
+	}
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, ErrQCEmpty
+	}
+	rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		// The first matching column wins, exports often add short-term maxima after it
+		if column := qcColumnName(name); column != "" && name != "" {
+			if _, seen := columns[column]; !seen {
+				columns[column] = i
+			}
+		}
+	}
+	if _, ok := columns["filename"]; !ok {
+		return nil, nil, errors.New("CSV has no filename column")
+	}
+	if _, ok := columns["integrated"]; !ok {
+		return nil, nil, errors.New("CSV has no integrated loudness column")
+	}
+
+	value := func(row []string, column string, line int, warnings *[]string) float64 {
+		i, ok := columns[column]
+		if !ok || i >= len(row) || strings.TrimSpace(row[i]) == "" {
+			return math.NaN()
+		}
+		v, err := parseQCValue(row[i])
+		if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("line %d: %s %q is not a number", line, strings.ReplaceAll(column, "_", " "), row[i]))
+			return math.NaN()
+		}
+		return v
+	}
+
+	measurements := make(map[string]qcMeasurement)
+	var warnings []string
+	for i, row := range rows[1:] {
+		line := i + 2
+		if columns["filename"] >= len(row) {
+			continue
+		}
+		key := tagMapKey(row[columns["filename"]])
+		if key == "" {
+			continue
+		}
+
+		m := qcMeasurement{
+			Integrated: value(row, "integrated", line, &warnings),
+			TruePeak:   value(row, "true_peak", line, &warnings),
+			LRA:        value(row, "lra", line, &warnings),
+		}
+		if math.IsNaN(m.Integrated) {
+			continue
+		}
+		measurements[key] = m
+	}
+
+	if len(measurements) == 0 {
+		return nil, warnings, ErrQCEmpty
+	}
+	return measurements, warnings, nil
+}
+
+// qcReportPath puts the report next to the imported export
+func qcReportPath(importPath string) string {
+	return strings.TrimSuffix(importPath, filepath.Ext(importPath)) + "_tnt_comparison.csv"
+}
+
+// qcTolerance returns the tolerance set in the preferences
+func (n *AudioNormalizer) qcTolerance() float64 {
+	if tolerance, err := strconv.ParseFloat(strings.TrimSpace(n.qcToleranceEntry.Text), 64); err == nil && tolerance > 0 {
+		return tolerance
+	}
+	return qcDefaultTolerance
+}
+
+// compareQC measures every queued file the export has a row for and reports
+// where TNT and the external tool disagree by more than the tolerance
+func (n *AudioNormalizer) compareQC(importPath string, external map[string]qcMeasurement) {
+	tolerance := n.qcTolerance()
+	options := n.loudnormOptions()
+	reportPath := qcReportPath(importPath)
+	os.Remove(platform.LongPath(reportPath))
+
+	n.mutex.Lock()
+	files := append([]string(nil), n.files...)
+	n.mutex.Unlock()
+
+	compared, deviating, unmatched := 0, 0, 0
+	for _, file := range files {
+		ext, ok := external[tagMapKey(file)]
+		if !ok {
+			unmatched++
+			continue
+		}
+
+		name := filepath.Base(file)
+		measured := n.measureLoudness(file, measureForReport, "", "", options)
+		if measured == nil || measured["input_i"] == "" {
+			n.logStatus(fmt.Sprintf("✗ Failed to measure: %s", name))
+			continue
+		}
+		compared++
+
+		row := []string{file}
+		var problems []string
+		compare := func(label string, theirs float64, key string, limit float64) {
+			ours, err := strconv.ParseFloat(measured[key], 64)
+			if math.IsNaN(theirs) || err != nil {
+				row = append(row, "", measured[key], "")
+				return
+			}
+			diff := ours - theirs
+			row = append(row, fmt.Sprintf("%.1f", theirs), measured[key], fmt.Sprintf("%+.1f", diff))
+			if math.Abs(diff) > limit {
+				problems = append(problems, fmt.Sprintf("%s %+.1f", label, diff))
+			}
+		}
+		compare("integrated", ext.Integrated, "input_i", tolerance)
+		compare("true peak", ext.TruePeak, "input_tp", tolerance)
+		compare("LRA", ext.LRA, "input_lra", 2*tolerance)
+
+		if len(problems) == 0 {
+			row = append(row, "yes")
+			n.logStatus(fmt.Sprintf("✓ Matches QC export: %s", name))
+		} else {
+			row = append(row, "no")
+			deviating++
+			n.logStatus(fmt.Sprintf("⚠ Differs from QC export: %s - %s", name, strings.Join(problems, ", ")))
+		}
+
+		row = append(row, measured[measuredByKey], measured[standardKey])
+
+		if err := appendCSVRow(reportPath, qcReportHeader, row); err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("QC comparison report write failed: %v", err))
+		}
+	}
+
+	if unmatched > 0 {
+		n.logStatus(fmt.Sprintf("⊗ %d queued files have no row in the QC export", unmatched))
+	}
+	n.logStatus(fmt.Sprintf("QC comparison: %d of %d files within ±%.1f, report in %s", compared-deviating, compared, tolerance, reportPath))
+}
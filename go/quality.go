@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/config"
+)
+
+// isLossyCodec reports whether an encoder discards audio information
+func isLossyCodec(encoder string) bool {
+	codec, ok := config.LookupCodec(encoder)
+	return ok && codec.Lossy
+}
+
+// logQualityScore compares a lossy output with the processed audio it was
+// encoded from and logs the result
+func (n *AudioNormalizer) logQualityScore(referencePath, filter, outputPath string) {
+	n.logStatus(fmt.Sprintf("→ Scoring quality: %s", filepath.Base(outputPath)))
+
+	score, err := audio.CompareQuality(referencePath, filter, outputPath)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Quality score failed: %s - %v", filepath.Base(outputPath), err))
+		return
+	}
+
+	n.logStatus(fmt.Sprintf("Quality: spectral distance %.2f dB, SNR %.1f dB: %s", score.SpectralDistance, score.SNR, filepath.Base(outputPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("Quality score for %s: LSD=%.3f dB SNR=%.2f dB offset=%d samples", outputPath, score.SpectralDistance, score.SNR, score.Offset))
+}
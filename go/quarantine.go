@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	// watchQuarantineDir is the subfolder of the watched folder failing files go to.
+	// Recursive watches skip it, see watchtree.go, so nothing in it is picked up again.
+	watchQuarantineDir = "quarantine"
+	// watchRetryDelay gives a delivery that was still arriving time to complete
+	watchRetryDelay = 2 * time.Minute
+	// watchRetriesDefault is how many times a failed file is tried again unless set otherwise
+	watchRetriesDefault = "2"
+
+	QuarantineOff  = "Off"
+	QuarantineMove = "Move"
+	QuarantineCopy = "Copy"
+)
+
+var (
+	// watchRetryOptions are the numbers of retries offered after a first failure
+	watchRetryOptions = []string{"0", "1", "2", "3", "5"}
+	// watchQuarantineOptions are what is done with a file out of retries
+	watchQuarantineOptions = []string{QuarantineOff, QuarantineMove, QuarantineCopy}
+)
+
+// watchFailed reports whether a watch outcome is a fault in the delivered file
+// worth retrying. Files turned down by analyzers or left for a full disk are not.
+func watchFailed(result string) bool {
+	return result == "failed" || result == "refused"
+}
+
+// failureCapture collects the failure lines logged about the watched file
+// being handled, for its error report
+type failureCapture struct {
+	mutex sync.Mutex
+	name  string
+	lines []string
+}
+
+// begin starts collecting lines that name the file
+func (c *failureCapture) begin(path string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.name = filepath.Base(path)
+	c.lines = nil
+}
+
+// add keeps a failure or refusal line about the file. Batches in the window
+// log at the same time, so lines about other files are left out.
+func (c *failureCapture) add(message string) {
+	if !strings.HasPrefix(message, "✗") && !strings.HasPrefix(message, "⊗") {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.name != "" && strings.Contains(message, c.name) {
+		c.lines = append(c.lines, message)
+	}
+}
+
+// end stops collecting and returns the lines
+func (c *failureCapture) end() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	lines := c.lines
+	c.name, c.lines = "", nil
+	return lines
+}
+
+// watchRetries returns how many times a failed watched file is tried again
+func (n *AudioNormalizer) watchRetries() int {
+	retries, err := strconv.Atoi(n.watchRetryDrop.Selected)
+	if err != nil || retries < 0 {
+		retries, _ = strconv.Atoi(watchRetriesDefault)
+	}
+	return retries
+}
+
+// retryOrQuarantine tries a failed watched file again after a delay, and once
+// it is out of retries puts it in quarantine with an error report
+func (n *AudioNormalizer) retryOrQuarantine(path, result string, attempts int, lines []string) {
+	name := filepath.Base(path)
+	if retries := n.watchRetries(); attempts <= retries {
+		n.logStatus(fmt.Sprintf("→ Retrying in %s (%d of %d): %s", watchRetryDelay, attempts, retries, name))
+		stop := n.watcherStop
+		go func() {
+			select {
+			case <-time.After(watchRetryDelay):
+				n.enqueueWatched(path)
+			case <-stop:
+			}
+		}()
+		return
+	}
+
+	mode := n.watchQuarantineDrop.Selected
+	if mode == "" || mode == QuarantineOff {
+		return
+	}
+
+	dest, err := quarantineFile(path, mode == QuarantineMove)
+	if err == nil {
+		err = writeQuarantineReport(dest, path, result, attempts, lines)
+	}
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Quarantine failed: %s - %v", name, err))
+		n.logToFile(n.logFile, fmt.Sprintf("Quarantine of %s failed: %v", path, err))
+		return
+	}
+
+	n.logStatus(fmt.Sprintf("⊗ Quarantined after %d attempts: %s", attempts, name))
+	verb := "copied"
+	if mode == QuarantineMove {
+		verb = "moved"
+	}
+	n.logToFile(n.logFile, fmt.Sprintf("%s %s to %s after %d attempts", path, verb, dest, attempts))
+	n.reportEvent(eventFileQuarantined, true, fmt.Sprintf("%s failed %d times and was quarantined in %s", path, attempts, filepath.Dir(dest)))
+}
+
+// quarantineFile moves or copies a file into the quarantine folder next to
+// it, numbering the name when an earlier delivery of the same name is there
+func quarantineFile(path string, move bool) (string, error) {
+	dir := filepath.Join(filepath.Dir(path), watchQuarantineDir)
+	if err := os.MkdirAll(platform.LongPath(dir), 0755); err != nil {
+		return "", err
+	}
+
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(filepath.Base(path), ext)
+	dest := filepath.Join(dir, stem+ext)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(platform.LongPath(dest)); os.IsNotExist(err) {
+			break
+		}
+		dest = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+	}
+
+	if move {
+		return dest, os.Rename(platform.LongPath(path), platform.LongPath(dest))
+	}
+	return dest, copyFile(platform.LongPath(path), platform.LongPath(dest))
+}
+
+// writeQuarantineReport writes the error report next to a quarantined file,
+// for whoever delivered it
+func writeQuarantineReport(dest, path, result string, attempts int, lines []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "File: %s\n", path)
+	fmt.Fprintf(&b, "Quarantined: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Result: %s after %d attempts\n", result, attempts)
+	fmt.Fprintf(&b, "TNT: %s (%s)\n", currentVersion, profileLabel())
+	if len(lines) > 0 {
+		b.WriteString("\nErrors of the last attempt:\n")
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+	return os.WriteFile(platform.LongPath(dest+".error.txt"), []byte(b.String()), 0644)
+}
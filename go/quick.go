@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// quickMaxDuration is the longest single file, in seconds, that Process
+// sends down the quick path on its own
+const quickMaxDuration = 180.0
+
+// quickConfig strips a config down to a two-pass loudnorm and the final
+// encode, for the Quick button, which asks for exactly that. With every
+// processing stage off, processFile writes no 192 kHz intermediates.
+func quickConfig(cfg ProcessConfig) ProcessConfig {
+	if !cfg.writeTags {
+		cfg.UseLoudnorm = true
+	}
+	cfg.bypassProc = true
+	cfg.EqTarget = "Off"
+	cfg.DynamicsPreset = "Off"
+	cfg.DynNorm = false
+	cfg.Speechnorm = "Off"
+	cfg.PhaseCheck = false
+	cfg.ChannelCheck = false
+	cfg.channelFilter = ""
+	cfg.ExtraTargets = nil
+	cfg.PreviewCopy = false
+	cfg.WebVersion = false
+	cfg.LoudnessBadge = false
+	cfg.LoudnessReport = loudnessReportOff
+	cfg.QualityScore = false
+	return cfg
+}
+
+// wantsQuickPath reports whether a batch is already as lean as the quick
+// path: one short file, no processing stage and no check or extra output
+// besides the loudness. Process then sends it down the quick path with its
+// config as it is, only the Quick button strips one down.
+func wantsQuickPath(files []string, cfg ProcessConfig) bool {
+	if len(files) != 1 {
+		return false
+	}
+	if !cfg.bypassProc {
+		if cfg.EqTarget != "" && cfg.EqTarget != "Off" || cfg.DynamicsPreset != "" && cfg.DynamicsPreset != "Off" {
+			return false
+		}
+		if cfg.DynNorm || cfg.Speechnorm != "" && cfg.Speechnorm != "Off" {
+			return false
+		}
+		if cfg.Restoration || cfg.RumbleFilter || cfg.ChannelAlign {
+			return false
+		}
+	}
+	if cfg.PhaseCheck || cfg.ChannelCheck || cfg.QualityScore || len(cfg.Analyzers) > 0 {
+		return false
+	}
+	if len(cfg.ExtraTargets) > 0 || cfg.PreviewCopy || cfg.WebVersion || cfg.LoudnessBadge {
+		return false
+	}
+	if cfg.LoudnessReport != "" && cfg.LoudnessReport != loudnessReportOff {
+		return false
+	}
+
+	duration, err := audio.ProbeDuration(files[0])
+	return err == nil && duration <= quickMaxDuration
+}
+
+// quickProcess normalizes the queued files one by one on the quick path,
+// for clips that have to be ready before air. While a batch runs it asks for
+// a file instead and normalizes it ahead of the batch.
+func (n *AudioNormalizer) quickProcess() {
+	if n.blockedByUpdate() {
+		return
+	}
+	if n.batchRunning.Load() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			path := reader.URI().Path()
+			reader.Close()
+			go n.quickNow(path)
+		}, n.window)
+		return
+	}
+
+	n.processBtn.Disable()
+	n.quickBtn.Disable()
+	n.progressBar.Show()
+	n.progressBar.SetValue(0)
+	n.statusLines.Reset()
+	n.statusLog.SetText("")
+
+	cfg := quickConfig(n.getProcessConfig())
+	files := append([]string(nil), n.files...)
+
+	n.outputCheckMutex.Lock()
+	n.outputAborted = false
+	n.outputCheckMutex.Unlock()
+
+	n.logStatus(fmt.Sprintf("Quick normalize: %d files", len(files)))
+
+	go func() {
+		n.planOutputs(files, cfg)
+		defer n.clearPlannedOutputs()
+
+		successful := 0
+		for i, file := range files {
+			if n.quickFile(file, cfg) {
+				successful++
+			}
+
+			progress := float64(i+1) / float64(len(files))
+			fyne.Do(func() {
+				n.progressBar.SetValue(progress)
+			})
+		}
+
+		n.logStatus(fmt.Sprintf("\nComplete: %d/%d files processed successfully", successful, len(files)))
+		fyne.Do(func() {
+			n.processBtn.Enable()
+			n.quickBtn.Enable()
+		})
+	}()
+}
+
+// quickBatch runs the single file of a batch wantsQuickPath accepted on the
+// quick path, without the album gain, ETA probe and report of a batch. The
+// user's settings stay as they are: with the processing stages off already,
+// no 192 kHz intermediates are written, and incremental runs find the file
+// under the same settings as in a batch.
+func (n *AudioNormalizer) quickBatch(file string, cfg ProcessConfig) {
+	if cfg.Incremental && n.alreadyProcessed(file, cfg) {
+		n.logStatus(fmt.Sprintf("⊗ Unchanged since the last run: %s", filepath.Base(file)))
+		return
+	}
+
+	n.planOutputs([]string{file}, cfg)
+	defer n.clearPlannedOutputs()
+
+	successful := 0
+	if n.quickFile(file, cfg) {
+		successful = 1
+	}
+	n.batchHistory.flush()
+	fyne.Do(func() {
+		n.progressBar.SetValue(1)
+	})
+	n.logStatus(fmt.Sprintf("\nComplete: %d/1 files processed successfully", successful))
+	n.notifyOperator(fmt.Sprintf("%d/1", successful))
+}
+
+// quickNow normalizes one file on the quick path while a batch runs. The
+// batch's encodes pause, or on Windows drop to idle priority, and its workers
+// start no new files until this one is done.
+func (n *AudioNormalizer) quickNow(path string) {
+	if err := audio.ValidateInput(path); err != nil {
+		n.refuseFile(path, err)
+		return
+	}
+
+	end := ffmpeg.BeginInteractive()
+	defer end()
+
+	name := filepath.Base(path)
+	n.logStatus(fmt.Sprintf("→ Batch yields to quick normalize: %s", name))
+
+	n.quickFile(path, quickConfig(n.getProcessConfig()))
+	n.logStatus("→ Batch resumes")
+}
+
+// quickFile normalizes one file on the quick path. The preset's analyzers
+// still run first, so a gating one holds a file back as it would in a batch.
+func (n *AudioNormalizer) quickFile(path string, cfg ProcessConfig) bool {
+	name := filepath.Base(path)
+	if len(cfg.Analyzers) > 0 {
+		var allowed bool
+		if cfg.analysis, allowed = n.runAnalyzers(path, cfg); !allowed {
+			return false
+		}
+	}
+	if !n.ensureWritableOutput(cfg) {
+		n.logStatus(fmt.Sprintf("⊗ Skipped: %s", name))
+		return false
+	}
+
+	started := time.Now()
+	var ok bool
+	n.runJob(path, func() { ok = n.processFile(path, cfg) })
+	if ok {
+		n.logStatus(fmt.Sprintf("✓ Quick normalize took %s: %s", time.Since(started).Round(100*time.Millisecond), name))
+	}
+	return ok
+}
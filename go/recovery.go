@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+)
+
+// workerRestartDelay keeps a worker that panics on every start from spinning
+const workerRestartDelay = time.Second
+
+// runJob runs one job of a worker and turns a panic in it into a failed
+// job, so one bad file neither takes the app down nor stops its worker
+// draining the queue. Returns false when the job panicked.
+func (n *AudioNormalizer) runJob(file string, job func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+			n.logStatus(fmt.Sprintf("✗ Internal error, job failed: %s - %v", filepath.Base(file), r))
+			n.logToFile(n.logFile, fmt.Sprintf("Worker panicked on %s: %v\n%s", file, r, debug.Stack()))
+		}
+	}()
+	job()
+	return true
+}
+
+// superviseWorker runs a long-lived worker and starts it again when it
+// panics outside a job. It gives up once stop is closed, and returns when
+// the worker does.
+func (n *AudioNormalizer) superviseWorker(name string, stop chan bool, worker func()) {
+	for !n.runWorker(name, worker) {
+		select {
+		case <-stop:
+			return
+		case <-time.After(workerRestartDelay):
+		}
+		n.logStatus(fmt.Sprintf("→ %s restarted", name))
+	}
+}
+
+// runWorker runs a worker until it returns or panics. Returns false when it panicked.
+func (n *AudioNormalizer) runWorker(name string, worker func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+			n.logStatus(fmt.Sprintf("✗ %s stopped by an internal error: %v", name, r))
+			n.logToFile(n.logFile, fmt.Sprintf("%s panicked: %v\n%s", name, r, debug.Stack()))
+		}
+	}()
+	worker()
+	return true
+}
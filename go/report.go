@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const batchReportPrefix = "tnt_report_"
+
+// Results of a file in the batch report
+const (
+	reportSuccess   = "success"
+	reportFailed    = "failed"
+	reportSkipped   = "skipped"
+	reportUnchanged = "unchanged"
+	reportCancelled = "cancelled"
+)
+
+var batchReportHeader = []string{
+	"input", "output", "codec", "result", "input_lufs", "input_tp", "input_lra",
+	"applied_gain_db", "track_gain_db", "peak_after_gain", "relies_on_limiter", "dynamics_score", "measured_by",
+	"standard", "passthrough",
+}
+
+// fileReport is one input file of the batch report. Values that weren't
+// measured for the file's settings are left out.
+type fileReport struct {
+	Input    string   `json:"input"`
+	Output   string   `json:"output,omitempty"`
+	Codec    string   `json:"codec,omitempty"`
+	Result   string   `json:"result"`
+	InputI   *float64 `json:"input_lufs,omitempty"`
+	InputTP  *float64 `json:"input_tp,omitempty"`
+	InputLRA *float64 `json:"input_lra,omitempty"`
+	// AppliedGain is the loudness normalization gain, TrackGain the ReplayGain tag
+	AppliedGain *float64 `json:"applied_gain_db,omitempty"`
+	TrackGain   *float64 `json:"track_gain_db,omitempty"`
+	// PeakAfterGain is where a tagged file's peak lands once players apply
+	// the gain, see clipguard.go
+	PeakAfterGain   *float64 `json:"peak_after_gain,omitempty"`
+	ReliesOnLimiter bool     `json:"relies_on_limiter,omitempty"`
+	DynamicsScore   *float64 `json:"dynamics_score,omitempty"`
+	MeasuredBy      string   `json:"measured_by,omitempty"`
+	Standard        string   `json:"standard,omitempty"`
+	// Passthrough is the verification of untranscoded audio, see passthrough.go
+	Passthrough string `json:"passthrough,omitempty"`
+}
+
+// reportValue parses a measured value, nil when it's missing or not a number
+func reportValue(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// measured records the loudness measurement of the file. The methods of
+// fileReport do nothing on a nil report, so files processed outside a
+// batch don't need one.
+func (r *fileReport) measured(measured map[string]string) {
+	if r == nil || measured == nil {
+		return
+	}
+	r.InputI = reportValue(measured["input_i"])
+	r.InputTP = reportValue(measured["input_tp"])
+	r.InputLRA = reportValue(measured["input_lra"])
+	r.MeasuredBy = measured[measuredByKey]
+	r.Standard = measured[standardKey]
+}
+
+// gain records the gain normalization applies to reach target
+func (r *fileReport) gain(target string) {
+	if r == nil || r.InputI == nil {
+		return
+	}
+	if t, err := strconv.ParseFloat(target, 64); err == nil {
+		gain := t - *r.InputI
+		r.AppliedGain = &gain
+	}
+}
+
+// trackGain records the ReplayGain track gain written into the tags
+func (r *fileReport) trackGain(gain float64) {
+	if r == nil {
+		return
+	}
+	r.TrackGain = &gain
+}
+
+// peakAfterGain records the peak of a tagged file after its track gain
+func (r *fileReport) peakAfterGain(peak float64, clips bool) {
+	if r == nil {
+		return
+	}
+	r.PeakAfterGain = &peak
+	r.ReliesOnLimiter = clips
+}
+
+// dynamicsScore records the Dynamics Score the presets were scaled with
+func (r *fileReport) dynamicsScore(ds *audio.DynamicsScoreAnalysis) {
+	if r == nil || ds == nil {
+		return
+	}
+	score := ds.DynamicsScore
+	r.DynamicsScore = &score
+}
+
+// passthrough records the verification of untranscoded audio
+func (r *fileReport) passthrough(status string) {
+	if r == nil {
+		return
+	}
+	r.Passthrough = status
+}
+
+// finish records the outcome of processing the file
+func (r *fileReport) finish(ok bool, outputPath, codec string) {
+	if r == nil {
+		return
+	}
+	r.Codec = codec
+	if ok {
+		r.Result = reportSuccess
+		r.Output = outputPath
+	} else {
+		r.Result = reportFailed
+	}
+}
+
+func (r *fileReport) row() []string {
+	value := func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'f', 2, 64)
+	}
+	return []string{
+		r.Input, r.Output, r.Codec, r.Result, value(r.InputI), value(r.InputTP), value(r.InputLRA),
+		value(r.AppliedGain), value(r.TrackGain), value(r.PeakAfterGain), strconv.FormatBool(r.ReliesOnLimiter),
+		value(r.DynamicsScore), r.MeasuredBy, r.Standard, r.Passthrough,
+	}
+}
+
+// batchReport holds a row per input file of a batch, in the order of the
+// file list. Each row is only written by the worker processing its file.
+type batchReport struct {
+	Started  time.Time     `json:"started"`
+	Finished time.Time     `json:"finished"`
+	Version  string        `json:"tnt_version"`
+	Files    []*fileReport `json:"files"`
+	byInput  map[string]*fileReport
+}
+
+func newBatchReport(files []string) *batchReport {
+	b := &batchReport{
+		Started: time.Now(),
+		Version: currentVersion,
+		byInput: make(map[string]*fileReport, len(files)),
+	}
+	for _, file := range files {
+		// Files no worker got to count as skipped
+		r := &fileReport{Input: file, Result: reportSkipped}
+		b.Files = append(b.Files, r)
+		b.byInput[file] = r
+	}
+	return b
+}
+
+// file returns the row of an input file
+func (b *batchReport) file(path string) *fileReport {
+	if b == nil {
+		return nil
+	}
+	return b.byInput[path]
+}
+
+// write stores the report as JSON and CSV in dir and returns the JSON path
+func (b *batchReport) write(dir string) (string, error) {
+	b.Finished = time.Now()
+	base := filepath.Join(dir, batchReportPrefix+b.Started.Format("20060102-150405"))
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(platform.LongPath(base+".json"), data, 0644); err != nil {
+		return "", err
+	}
+
+	f, err := os.Create(platform.LongPath(base + ".csv"))
+	if err != nil {
+		return "", err
+	}
+	w := csv.NewWriter(f)
+	w.Write(batchReportHeader)
+	for _, r := range b.Files {
+		w.Write(r.row())
+	}
+	w.Flush()
+	if err := errors.Join(w.Error(), f.Close()); err != nil {
+		return "", err
+	}
+	return base + ".json", nil
+}
+
+// writeBatchReport writes the report of a finished batch into its output folder
+func (n *AudioNormalizer) writeBatchReport(report *batchReport, cfg ProcessConfig) {
+	dir := n.outputDir
+	if cfg.OutputDir != "" {
+		dir = cfg.OutputDir
+	}
+	path, err := report.write(dir)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to write batch report: %v", err))
+		return
+	}
+	n.logStatus(fmt.Sprintf("Batch report written to %s and .csv", path))
+}
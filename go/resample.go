@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/config"
+)
+
+// outputResampleRate is the rate every output without a sample rate
+// setting of its own is encoded at
+const outputResampleRate = 48000
+
+// outputResample returns the filter converting the processed audio to the
+// rate of an encoder that only takes certain rates, "" for the others.
+// FFmpeg would otherwise pick a rate and a resampler of its own, so the
+// conversion is made explicit at the chosen quality and logged. Sample
+// rates the source's metadata claims that the output can't carry are warned
+// about, the output doesn't keep them.
+func (n *AudioNormalizer) outputResample(inputPath string, codec config.Codec, cfg ProcessConfig) string {
+	if codec.Rates == nil || cfg.noTranscode {
+		return ""
+	}
+
+	quality := cfg.SRCQuality
+	if quality == "" {
+		quality = audio.SRCStandard
+	}
+	name := filepath.Base(inputPath)
+	sourceRate, err := audio.ProbeSampleRate(inputPath)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Sample rate of %s unknown: %v", inputPath, err))
+	} else if sourceRate != outputResampleRate {
+		n.logStatus(fmt.Sprintf("→ Resampling %s kHz to %s kHz for %s (%s quality): %s",
+			formatKHz(sourceRate), formatKHz(outputResampleRate), codec.Encoder, quality, name))
+	}
+
+	claims := []struct {
+		source string
+		rate   int
+	}{
+		{"The selected sample rate", atoiOrZero(cfg.SampleRate)},
+		{"The recording's original rate in its BWF metadata", cfg.bwf.OriginalSampleRate},
+	}
+	for _, claim := range claims {
+		if claim.rate > 0 && !codec.SupportsRate(claim.rate) {
+			n.logStatus(fmt.Sprintf("⚠ %s is %s kHz, which %s can't carry, the output is %s kHz: %s",
+				claim.source, formatKHz(claim.rate), codec.Encoder, formatKHz(outputResampleRate), name))
+		}
+	}
+
+	filter := audio.ResampleFilter(outputResampleRate, quality)
+	n.logToFile(n.logFile, fmt.Sprintf("Output of %s converted with %s", inputPath, filter))
+	return filter
+}
+
+// formatKHz writes a rate in kHz as operators say it, 44.1 or 48
+func formatKHz(rate int) string {
+	return strconv.FormatFloat(float64(rate)/1000, 'f', -1, 64)
+}
+
+func atoiOrZero(text string) int {
+	value, _ := strconv.Atoi(text)
+	return value
+}
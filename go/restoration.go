@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// restore measures the file and runs the restoration stages it needs. It
+// returns the restored temp file, or workingPath when nothing was needed.
+func (n *AudioNormalizer) restore(inputPath, workingPath string, watchdog ffmpeg.Watchdog) (string, bool) {
+	filter, ok := n.restorationFilter(inputPath, workingPath)
+	if !ok || filter == "" {
+		return workingPath, ok
+	}
+
+	restoredPath := filepath.Join(os.TempDir(), fmt.Sprintf("tnt_restore_%d.wav", time.Now().UnixNano()))
+
+	output, err := watchdog.Run(
+		"-i", workingPath,
+		"-af", filter,
+		"-acodec", "pcm_f64le",
+		"-y", restoredPath,
+	)
+	if err != nil {
+		os.Remove(restoredPath)
+		n.logStatus(fmt.Sprintf("✗ Restoration failed: %s", filepath.Base(inputPath)))
+		n.logToFile(n.logFile, fmt.Sprintf("Restoration failed: %v", err))
+		n.logWatchdogFailure(inputPath, err, output)
+		return "", false
+	}
+
+	n.logStatus(fmt.Sprintf("✓ Restoration applied: %s", filepath.Base(inputPath)))
+	return restoredPath, true
+}
+
+// restorationFilter measures the file and returns the filter of the
+// restoration stages it needs, or "" when nothing was needed
+func (n *AudioNormalizer) restorationFilter(inputPath, workingPath string) (string, bool) {
+	n.logStatus(fmt.Sprintf("→ Analyzing for restoration: %s", filepath.Base(inputPath)))
+
+	analysis, err := audio.AnalyzeRestoration(workingPath)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to analyze for restoration: %s", filepath.Base(inputPath)))
+		n.logToFile(n.logFile, fmt.Sprintf("Restoration analysis failed: %v", err))
+		return "", false
+	}
+
+	plan := analysis.Plan()
+	n.logToFile(n.logFile, fmt.Sprintf("Restoration analysis for %s: peak %.2f dB, flat factor %.2f, noise floor %.1f dB, click crest %.1f dB",
+		inputPath, analysis.PeakDB, analysis.FlatFactor, analysis.NoiseFloorDB, analysis.ClickCrestDB))
+
+	if plan.Empty() {
+		n.logStatus(fmt.Sprintf("✓ No restoration needed: %s", filepath.Base(inputPath)))
+		return "", true
+	}
+
+	n.logStatus(fmt.Sprintf("→ Restoring (%s): %s", plan, filepath.Base(inputPath)))
+	return plan.Filter(), true
+}
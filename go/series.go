@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	seriesHistoryName = "series_history.json"
+	seriesReportName  = "series_report.csv"
+
+	// seriesMinEpisodes is how many earlier episodes a series needs before
+	// a new one is compared against their average
+	seriesMinEpisodes = 3
+	// seriesMaxEpisodes is how many recent episodes the average is taken over
+	seriesMaxEpisodes = 20
+
+	// seriesLoudnessTolerance is how far in LU an episode's integrated loudness
+	// or loudness range may stray from the series average
+	seriesLoudnessTolerance = 1.0
+	// seriesBandTolerance is how far in dB a band's share of the spectrum may stray
+	seriesBandTolerance = 3.0
+)
+
+// seriesBands are the bands the spectral balance is measured in, the same
+// split as the multiband compressor
+var seriesBands = []string{"sub", "bass", "low_mid", "mid", "high"}
+
+var seriesReportHeader = []string{"time", "series", "file", "integrated_lufs", "lra_lu", "true_peak_dbtp", "sub_db", "bass_db", "low_mid_db", "mid_db", "high_db", "episodes_before", "deviates", "notes"}
+
+var ErrSeriesLine = errors.New("series lines are name = filename pattern")
+
+// seriesRule names a series and the filename pattern its episodes match
+type seriesRule struct {
+	Name    string
+	Pattern string
+}
+
+// parseSeriesLines reads "name = pattern" lines, patterns use * and ? like a file search
+func parseSeriesLines(text string) ([]seriesRule, error) {
+	var rules []seriesRule
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, pattern, ok := strings.Cut(line, "=")
+		rule := seriesRule{Name: strings.TrimSpace(name), Pattern: strings.TrimSpace(pattern)}
+		if !ok || rule.Name == "" || rule.Pattern == "" {
+			return nil, fmt.Errorf("%w: %s", ErrSeriesLine, line)
+		}
+		if _, err := filepath.Match(rule.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrSeriesLine, line)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// seriesFor returns the first series whose pattern matches a file name, ignoring case
+func seriesFor(rules []seriesRule, path string) (seriesRule, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	for _, rule := range rules {
+		if ok, _ := filepath.Match(strings.ToLower(rule.Pattern), name); ok {
+			return rule, true
+		}
+	}
+	return seriesRule{}, false
+}
+
+// seriesEpisode is one processed episode's output measurements. Bands hold
+// each band's level relative to the average of the bands, so the balance
+// compares across episodes of different loudness.
+type seriesEpisode struct {
+	File       string             `json:"file"`
+	Time       time.Time          `json:"time"`
+	Integrated float64            `json:"integrated_lufs"`
+	LRA        float64            `json:"lra_lu"`
+	TruePeak   float64            `json:"true_peak_dbtp"`
+	Bands      map[string]float64 `json:"bands_db"`
+}
+
+// seriesHistory keeps the recent episodes of every series in the settings folder
+type seriesHistory struct {
+	mutex  sync.Mutex
+	path   string
+	Series map[string][]seriesEpisode `json:"series"`
+}
+
+func loadSeriesHistory() *seriesHistory {
+	h := &seriesHistory{path: filepath.Join(dataDir(), seriesHistoryName), Series: make(map[string][]seriesEpisode)}
+	if data, err := os.ReadFile(h.path); err == nil {
+		json.Unmarshal(data, h)
+	}
+	if h.Series == nil {
+		h.Series = make(map[string][]seriesEpisode)
+	}
+	return h
+}
+
+// add stores an episode and returns the episodes before it, oldest first
+func (h *seriesHistory) add(series string, ep seriesEpisode) ([]seriesEpisode, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// A reprocessed episode replaces its earlier measurement
+	before := slices.DeleteFunc(slices.Clone(h.Series[series]), func(e seriesEpisode) bool { return e.File == ep.File })
+
+	episodes := append(slices.Clone(before), ep)
+	if len(episodes) > seriesMaxEpisodes {
+		episodes = episodes[len(episodes)-seriesMaxEpisodes:]
+	}
+	h.Series[series] = episodes
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return before, err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return before, err
+	}
+	return before, os.WriteFile(h.path, data, 0644)
+}
+
+// seriesDeviations compares an episode with the average of the earlier ones
+func seriesDeviations(ep seriesEpisode, before []seriesEpisode) []string {
+	var problems []string
+	avg := func(value func(seriesEpisode) float64) float64 {
+		var sum float64
+		for _, e := range before {
+			sum += value(e)
+		}
+		return sum / float64(len(before))
+	}
+
+	if d := ep.Integrated - avg(func(e seriesEpisode) float64 { return e.Integrated }); math.Abs(d) > seriesLoudnessTolerance {
+		problems = append(problems, fmt.Sprintf("loudness %+.1f LU", d))
+	}
+	if d := ep.LRA - avg(func(e seriesEpisode) float64 { return e.LRA }); math.Abs(d) > seriesLoudnessTolerance {
+		problems = append(problems, fmt.Sprintf("loudness range %+.1f LU", d))
+	}
+	for _, band := range seriesBands {
+		level, ok := ep.Bands[band]
+		if !ok {
+			continue
+		}
+		// Only earlier episodes that measured the band count toward its average
+		var sum float64
+		var count int
+		for _, e := range before {
+			if l, ok := e.Bands[band]; ok {
+				sum += l
+				count++
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		if d := level - sum/float64(count); math.Abs(d) > seriesBandTolerance {
+			problems = append(problems, fmt.Sprintf("%s %+.1f dB", strings.ReplaceAll(band, "_", " "), d))
+		}
+	}
+	return problems
+}
+
+// trackSeries measures a finished output of a series episode, reports it and
+// alerts when it strays from the series' earlier episodes, which usually
+// means a producer changed something in their chain
+func (n *AudioNormalizer) trackSeries(inputPath, outputPath string, rules []seriesRule, options audio.LoudnormOptions) {
+	rule, ok := seriesFor(rules, inputPath)
+	if !ok {
+		return
+	}
+	name := filepath.Base(inputPath)
+
+	measured := n.measureLoudness(outputPath, measureForReport, "", "", options)
+	if measured == nil {
+		n.logStatus(fmt.Sprintf("⚠ Series %s not tracked, output not measured: %s", rule.Name, name))
+		return
+	}
+	ep := seriesEpisode{File: name, Time: time.Now(), Bands: make(map[string]float64)}
+	ep.Integrated, _ = strconv.ParseFloat(measured["input_i"], 64)
+	ep.LRA, _ = strconv.ParseFloat(measured["input_lra"], 64)
+	ep.TruePeak, _ = strconv.ParseFloat(measured["input_tp"], 64)
+
+	// Band levels relative to their average, the balance rather than the level
+	var sum float64
+	for band, analysis := range n.analyzeFrequencyBands(outputPath) {
+		if analysis.Confidence.Reliable() {
+			ep.Bands[band] = analysis.RMSLevel
+			sum += analysis.RMSLevel
+		}
+	}
+	if len(ep.Bands) < len(seriesBands) {
+		n.logStatus(fmt.Sprintf("⚠ Series %s spectral balance incomplete: %s", rule.Name, name))
+	}
+	for band := range ep.Bands {
+		ep.Bands[band] -= sum / float64(len(ep.Bands))
+	}
+
+	before, err := n.seriesHistory.add(rule.Name, ep)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Series history write failed: %v", err))
+	}
+
+	var problems []string
+	if len(before) >= seriesMinEpisodes {
+		problems = seriesDeviations(ep, before)
+	}
+
+	row := []string{
+		ep.Time.Format(time.RFC3339), rule.Name, outputPath,
+		fmt.Sprintf("%.1f", ep.Integrated), fmt.Sprintf("%.1f", ep.LRA), fmt.Sprintf("%.1f", ep.TruePeak),
+	}
+	for _, band := range seriesBands {
+		if level, ok := ep.Bands[band]; ok {
+			row = append(row, fmt.Sprintf("%.1f", level))
+		} else {
+			row = append(row, "")
+		}
+	}
+	row = append(row, strconv.Itoa(len(before)), strconv.FormatBool(len(problems) > 0), strings.Join(problems, "; "))
+	if err := appendCSVRow(filepath.Join(dataDir(), seriesReportName), seriesReportHeader, row); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Series report write failed: %v", err))
+	}
+
+	if len(problems) == 0 {
+		n.logToFile(n.logFile, fmt.Sprintf("Series %s episode %s in line with %d earlier episodes", rule.Name, name, len(before)))
+		return
+	}
+
+	message := fmt.Sprintf("%s differs from earlier %s episodes: %s", name, rule.Name, strings.Join(problems, ", "))
+	n.logStatus("⚠ " + message)
+	n.notifyOperator("!")
+	if n.seriesAlertCheck.Checked {
+		fyne.CurrentApp().SendNotification(fyne.NewNotification("TNT: series drift", message))
+	}
+}
+
+// openSeriesReport opens the series report in the default CSV application
+func (n *AudioNormalizer) openSeriesReport() {
+	path := filepath.Join(dataDir(), seriesReportName)
+	if _, err := os.Stat(path); err != nil {
+		dialog.ShowInformation("Series report", "No series episodes have been processed yet.", n.menuWindow)
+		return
+	}
+	if err := platform.OpenFile(path); err != nil {
+		dialog.ShowError(err, n.menuWindow)
+	}
+}
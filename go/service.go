@@ -0,0 +1,503 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/internal/license"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	serviceName        = "com.collinsgroup.tnt.watch"
+	serviceDisplayName = "TNT watch folder"
+	serviceStateName   = "service.json"
+
+	serviceClientTimeout = 5 * time.Second
+)
+
+var (
+	ErrServiceNotRunning   = errors.New("the TNT background service is not running")
+	ErrServiceNotInstalled = errors.New("the TNT background service is not installed")
+	ErrServiceWatching     = errors.New("the background service is already watching a folder")
+)
+
+// serviceState is kept in the profile's data folder by the background service.
+// It tells the GUI where the local API listens and lets the service resume
+// watching after a restart.
+type serviceState struct {
+	Addr      string `json:"addr"`
+	Token     string `json:"token"`
+	PID       int    `json:"pid"`
+	Watching  bool   `json:"watching"`
+	WatchDir  string `json:"watch_dir"`
+	OutputDir string `json:"output_dir"`
+}
+
+// serviceStatus is what the local API reports to the GUI
+type serviceStatus struct {
+	Version   string    `json:"version"`
+	Profile   string    `json:"profile"`
+	Started   time.Time `json:"started"`
+	Watching  bool      `json:"watching"`
+	WatchDir  string    `json:"watch_dir"`
+	OutputDir string    `json:"output_dir"`
+	Queued    int       `json:"queued"`
+	Log       string    `json:"log"`
+}
+
+// watchRequest asks the service to watch a folder
+type watchRequest struct {
+	WatchDir  string `json:"watch_dir"`
+	OutputDir string `json:"output_dir"`
+}
+
+func serviceStatePath() string {
+	return filepath.Join(dataDir(), serviceStateName)
+}
+
+func loadServiceState() (serviceState, error) {
+	var state serviceState
+	data, err := os.ReadFile(serviceStatePath())
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// saveServiceState writes the state readable only by the owner, as it holds the API token
+func saveServiceState(state serviceState) error {
+	os.MkdirAll(dataDir(), 0755)
+	data, _ := json.MarshalIndent(state, "", "  ")
+	return os.WriteFile(serviceStatePath(), data, 0600)
+}
+
+// serviceConfig describes how the service is launched for the active profile
+func serviceConfig() (platform.ServiceConfig, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return platform.ServiceConfig{}, err
+	}
+
+	args := []string{"-service", "-config-dir", configRoot()}
+	if activeProfile != "" {
+		args = append(args, "-profile", activeProfile)
+	}
+
+	return platform.ServiceConfig{
+		Name:        serviceName,
+		DisplayName: serviceDisplayName,
+		Description: "Processes files arriving in the TNT watch folder without anyone logged in.",
+		Executable:  exe,
+		Args:        args,
+	}, nil
+}
+
+// runService runs the watch engine without a window until the service
+// manager stops it. The widgets still exist, on the headless app from
+// headless.go, so the engine reads its settings exactly as the GUI would.
+func runService(args launchArgs) {
+	activeProfile = args.Profile
+
+	n := newHeadlessNormalizer(args.Operator)
+	n.serviceStarted = time.Now()
+	n.openSystemLog()
+
+	defer func() {
+		n.stopWatching()
+		ffmpeg.KillAll()
+		n.releaseAllZips()
+		n.closeSystemLog()
+		if n.logFile != nil {
+			n.logFile.Close()
+		}
+	}()
+
+	server, err := n.startServiceAPI()
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Service API failed to start: %v", err))
+		n.reportEvent(eventServiceFailed, true, fmt.Sprintf("Service API failed to start: %v", err))
+		return
+	}
+	defer server.Close()
+
+	n.serviceMutex.Lock()
+	resume := n.serviceState
+	n.serviceMutex.Unlock()
+
+	n.logToFile(n.logFile, fmt.Sprintf("Service started, API on %s", resume.Addr))
+	if resume.Watching {
+		if err := n.serviceWatch(watchRequest{WatchDir: resume.WatchDir, OutputDir: resume.OutputDir}); err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("Service could not resume watching %s: %v", resume.WatchDir, err))
+			n.reportEvent(eventWatchFailed, true, fmt.Sprintf("Could not resume watching %s: %v", resume.WatchDir, err))
+		}
+	}
+
+	if err := platform.RunService(serviceName, func(stop <-chan struct{}) { <-stop }); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Service stopped with error: %v", err))
+		n.reportEvent(eventServiceFailed, true, fmt.Sprintf("Service stopped with error: %v", err))
+	}
+
+	// Keep the watch folder so it's resumed, but tell the GUI nobody is listening
+	n.serviceMutex.Lock()
+	n.serviceState.Addr = ""
+	n.serviceState.Token = ""
+	state := n.serviceState
+	n.serviceMutex.Unlock()
+	saveServiceState(state)
+
+	n.logToFile(n.logFile, "Service stopped")
+}
+
+// startServiceAPI listens on a random loopback port and records it, with a
+// fresh token, in the service state
+func (n *AudioNormalizer) startServiceAPI() (*http.Server, error) {
+	if err := n.license.check(license.FeatureAPI); err != nil {
+		return nil, err
+	}
+	state, _ := loadServiceState()
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	state.Addr = listener.Addr().String()
+	state.Token = hex.EncodeToString(token)
+	state.PID = os.Getpid()
+	if err := saveServiceState(state); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	n.serviceState = state
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, n.currentServiceStatus())
+	})
+	mux.HandleFunc("POST /api/watch/start", func(w http.ResponseWriter, r *http.Request) {
+		var req watchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := n.serviceWatch(req); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, n.currentServiceStatus())
+	})
+	mux.HandleFunc("POST /api/watch/stop", func(w http.ResponseWriter, r *http.Request) {
+		n.serviceStopWatch()
+		writeJSON(w, n.currentServiceStatus())
+	})
+
+	server := &http.Server{Handler: requireToken(state.Token, mux), ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	return server, nil
+}
+
+// requireToken rejects requests that don't carry the token from the service state,
+// so other local users can't drive the service. The comparison takes the same
+// time however much of the token matches.
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// serviceWatch reloads the preferences and watch folders the GUI saved and starts watching
+func (n *AudioNormalizer) serviceWatch(req watchRequest) error {
+	n.watcherMutex.Lock()
+	watching := n.watching
+	n.watcherMutex.Unlock()
+	if watching {
+		return ErrServiceWatching
+	}
+
+	if info, err := os.Stat(platform.LongPath(req.WatchDir)); err != nil || !info.IsDir() {
+		return fmt.Errorf("watch folder %s is not available", req.WatchDir)
+	}
+
+	// Loaded before the preferences, their bookmarks cover the watch folders too
+	n.watchFolders = loadWatchFolders()
+	n.loadPreferences()
+	n.inputDir = req.WatchDir
+	n.outputDir = req.OutputDir
+	if err := n.startWatching(); err != nil {
+		return err
+	}
+
+	n.serviceMutex.Lock()
+	n.serviceState.Watching = true
+	n.serviceState.WatchDir = req.WatchDir
+	n.serviceState.OutputDir = req.OutputDir
+	state := n.serviceState
+	n.serviceMutex.Unlock()
+
+	return saveServiceState(state)
+}
+
+// serviceStopWatch stops watching and remembers not to resume after a restart
+func (n *AudioNormalizer) serviceStopWatch() {
+	n.stopWatching()
+
+	n.serviceMutex.Lock()
+	n.serviceState.Watching = false
+	state := n.serviceState
+	n.serviceMutex.Unlock()
+
+	saveServiceState(state)
+}
+
+func (n *AudioNormalizer) currentServiceStatus() serviceStatus {
+	n.watcherMutex.Lock()
+	watching := n.watching
+	queued := len(n.jobQueue)
+	n.watcherMutex.Unlock()
+
+	n.serviceMutex.Lock()
+	defer n.serviceMutex.Unlock()
+
+	return serviceStatus{
+		Version:   currentVersion,
+		Profile:   profileLabel(),
+		Started:   n.serviceStarted,
+		Watching:  watching,
+		WatchDir:  n.serviceState.WatchDir,
+		OutputDir: n.serviceState.OutputDir,
+		Queued:    queued,
+		Log:       n.statusLines.String(),
+	}
+}
+
+// logServiceStatus keeps status lines for the GUI to fetch, as the service has no window
+func (n *AudioNormalizer) logServiceStatus(message string) {
+	n.serviceMutex.Lock()
+	n.statusLines.Append(time.Now().Format("15:04:05 ") + message)
+	n.serviceMutex.Unlock()
+
+	if n.rpc != nil {
+		n.rpc.send(rpcEvent{Event: "status", Message: message})
+	}
+	n.forwardStatus(message)
+}
+
+// serviceClient talks to the background service of the active profile
+type serviceClient struct {
+	state  serviceState
+	client http.Client
+}
+
+func connectService() (*serviceClient, error) {
+	state, err := loadServiceState()
+	if err != nil || state.Addr == "" {
+		return nil, ErrServiceNotRunning
+	}
+	return &serviceClient{state: state, client: http.Client{Timeout: serviceClientTimeout}}, nil
+}
+
+func (c *serviceClient) call(method, path string, body any) (serviceStatus, error) {
+	var status serviceStatus
+
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serviceClientTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+c.state.Addr+path, &payload)
+	if err != nil {
+		return status, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.state.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return status, ErrServiceNotRunning
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var msg bytes.Buffer
+		msg.ReadFrom(resp.Body)
+		return status, errors.New(string(bytes.TrimSpace(msg.Bytes())))
+	}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	return status, err
+}
+
+func (c *serviceClient) status() (serviceStatus, error) {
+	return c.call(http.MethodGet, "/api/status", nil)
+}
+
+func (c *serviceClient) watch(watchDir, outputDir string) (serviceStatus, error) {
+	return c.call(http.MethodPost, "/api/watch/start", watchRequest{WatchDir: watchDir, OutputDir: outputDir})
+}
+
+func (c *serviceClient) stopWatch() (serviceStatus, error) {
+	return c.call(http.MethodPost, "/api/watch/stop", nil)
+}
+
+// describeServiceStatus formats a status for the Watch mode tab
+func describeServiceStatus(status serviceStatus) string {
+	text := fmt.Sprintf("Service running (TNT %s, profile %s) since %s.", status.Version, status.Profile, status.Started.Format("2006-01-02 15:04"))
+	if status.Watching {
+		text += fmt.Sprintf("\nWatching %s, output to %s, %d files queued.", status.WatchDir, status.OutputDir, status.Queued)
+	} else {
+		text += "\nNot watching."
+	}
+	return text
+}
+
+// buildServiceSection lets the Watch mode tab install the background service
+// and hand the watch folder over to it
+func (n *AudioNormalizer) buildServiceSection() fyne.CanvasObject {
+	text := widget.NewLabel(`
+Background service
+Runs watch mode as a Windows service or macOS login agent, so watched folders keep being processed after you log out or close TNT. The service uses this profile's saved preferences; click Save in Preferences before handing the folder over. It can forward failed files, warnings and its own problems to syslog on Linux and macOS or to the Windows Application event log, under the source com.collinsgroup.tnt.watch, with event IDs: 100 service failure, 110 watch folder failure, 200 file failed, 201 encoder killed, 300 warning. Restart the service after changing this. Installing the Windows service needs administrator rights, and for network folders set the service to log on as an account that can reach them.
+	`)
+	text.Wrapping = fyne.TextWrapWord
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Wrapping = fyne.TextWrapWord
+	serviceLog := widget.NewMultiLineEntry()
+	serviceLog.Wrapping = fyne.TextWrapWord
+	serviceLog.SetMinRowsVisible(6)
+
+	show := func(status serviceStatus, err error) {
+		fyne.Do(func() {
+			if err != nil {
+				statusLabel.SetText(err.Error())
+				return
+			}
+			statusLabel.SetText(describeServiceStatus(status))
+			serviceLog.SetText(status.Log)
+		})
+	}
+
+	refresh := func() {
+		go func() {
+			// On Linux the service is started by hand or by systemd, so ask it first
+			if client, err := connectService(); err == nil {
+				if status, err := client.status(); err == nil {
+					show(status, nil)
+					return
+				}
+			}
+			if !platform.ServiceInstalled(serviceName) {
+				show(serviceStatus{}, ErrServiceNotInstalled)
+				return
+			}
+			show(serviceStatus{}, ErrServiceNotRunning)
+		}()
+	}
+
+	installBtn := widget.NewButton("Install service", func() {
+		cfg, err := serviceConfig()
+		if err == nil {
+			err = platform.InstallService(cfg)
+		}
+		if err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		n.logToFile(n.logFile, "Installed background service")
+		time.AfterFunc(2*time.Second, refresh)
+	})
+
+	removeBtn := widget.NewButton("Remove service", func() {
+		if err := platform.RemoveService(serviceName); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		os.Remove(serviceStatePath())
+		n.logToFile(n.logFile, "Removed background service")
+		refresh()
+	})
+
+	handOverBtn := widget.NewButton("Watch in background", func() {
+		if n.inputDir == "" || n.outputDir == "" {
+			dialog.ShowInformation("Background service", "Select the folder to watch and the output folder first.", n.menuWindow)
+			return
+		}
+		// Two watchers on one folder would process every file twice
+		if n.watchMode.Checked {
+			n.watchMode.SetChecked(false)
+		}
+		n.savePreferences()
+
+		watchDir, outputDir := n.inputDir, n.outputDir
+		go func() {
+			client, err := connectService()
+			if err != nil {
+				show(serviceStatus{}, err)
+				return
+			}
+			client.stopWatch()
+			status, err := client.watch(watchDir, outputDir)
+			if err == nil {
+				n.logToFile(n.logFile, fmt.Sprintf("Handed %s over to the background service", watchDir))
+			}
+			show(status, err)
+		}()
+	})
+
+	stopBtn := widget.NewButton("Stop background watch", func() {
+		go func() {
+			client, err := connectService()
+			if err != nil {
+				show(serviceStatus{}, err)
+				return
+			}
+			show(client.stopWatch())
+		}()
+	})
+
+	refreshBtn := widget.NewButton("Refresh", refresh)
+	refresh()
+
+	return container.NewVBox(
+		text,
+		n.systemLogCheck,
+		container.NewHBox(installBtn, removeBtn),
+		container.NewHBox(handOverBtn, stopBtn, refreshBtn),
+		statusLabel,
+		serviceLog,
+	)
+}
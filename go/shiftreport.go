@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	shiftReportName = "shift_report.json"
+	// shiftReportRetry is how long a report that couldn't be sent waits before the next try
+	shiftReportRetry = 15 * time.Minute
+	// shiftReportListed is how many failed and out-of-spec files a report names
+	shiftReportListed = 25
+)
+
+var (
+	ErrShiftReportTime        = errors.New("the report time must be HH:MM")
+	ErrShiftReportDestination = errors.New("give an email address with an SMTP server, or a webhook URL")
+	ErrShiftReportRejected    = errors.New("the webhook did not accept the report")
+)
+
+// shiftReportSettings say when and where the shift report goes. They are
+// kept in their own file, readable only by the account, since they hold the
+// SMTP password.
+type shiftReportSettings struct {
+	Enabled bool   `json:"enabled"`
+	Time    string `json:"time"`
+	// EmailTo is a comma-separated list of recipients
+	EmailTo      string `json:"email_to,omitempty"`
+	EmailFrom    string `json:"email_from,omitempty"`
+	SMTPServer   string `json:"smtp_server,omitempty"`
+	SMTPUser     string `json:"smtp_user,omitempty"`
+	SMTPPassword string `json:"smtp_password,omitempty"`
+	Webhook      string `json:"webhook,omitempty"`
+	// LastSent ends the period of the previous report
+	LastSent time.Time `json:"last_sent"`
+}
+
+// shiftReportStore keeps the settings in shift_report.json next to the preferences
+type shiftReportStore struct {
+	mutex    sync.Mutex
+	path     string
+	settings shiftReportSettings
+	// attempted holds back retries of a report that failed to send
+	attempted time.Time
+}
+
+func loadShiftReport() *shiftReportStore {
+	s := &shiftReportStore{path: filepath.Join(dataDir(), shiftReportName)}
+	s.reload()
+	return s
+}
+
+// reload reads the settings from disk, another TNT instance may have sent the report meanwhile
+func (s *shiftReportStore) reload() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, &s.settings)
+	}
+}
+
+func (s *shiftReportStore) get() shiftReportSettings {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.settings
+}
+
+// set saves new settings, keeping when the last report went out
+func (s *shiftReportStore) set(settings shiftReportSettings) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	settings.LastSent = s.settings.LastSent
+	s.settings = settings
+	return s.save()
+}
+
+// markSent records the end of the period just reported
+func (s *shiftReportStore) markSent(at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings.LastSent = at
+	return s.save()
+}
+
+// save writes the settings, the caller holds the mutex
+func (s *shiftReportStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// validate checks the settings before they are saved
+func (r shiftReportSettings) validate() error {
+	if _, err := time.Parse("15:04", r.Time); err != nil {
+		return ErrShiftReportTime
+	}
+	if r.Enabled && r.Webhook == "" && (r.EmailTo == "" || r.SMTPServer == "") {
+		return ErrShiftReportDestination
+	}
+	return nil
+}
+
+// due returns the start of the period to report on when the day's report
+// is due at now
+func (r shiftReportSettings) due(now time.Time) (time.Time, bool) {
+	at, err := time.ParseInLocation("15:04", r.Time, now.Location())
+	if !r.Enabled || err != nil {
+		return time.Time{}, false
+	}
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if now.Before(scheduled) || !r.LastSent.Before(scheduled) {
+		return time.Time{}, false
+	}
+
+	// A machine that was off for days reports on the last day only
+	from := scheduled.AddDate(0, 0, -1)
+	if r.LastSent.After(from) {
+		from = r.LastSent
+	}
+	return from, true
+}
+
+// shiftSummary is what happened between two times, from the processing
+// history and the loudness monitor log
+type shiftSummary struct {
+	Host       string         `json:"host"`
+	Profile    string         `json:"profile"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Processed  int            `json:"processed"`
+	Failed     int            `json:"failed"`
+	Measured   int            `json:"measured"`
+	OutOfSpec  int            `json:"out_of_spec"`
+	Operators  map[string]int `json:"operators,omitempty"`
+	Failures   []string       `json:"failures,omitempty"`
+	OutOfSpecs []string       `json:"out_of_spec_files,omitempty"`
+}
+
+// summarizeShift aggregates the history of the period
+func (n *AudioNormalizer) summarizeShift(from, to time.Time) (shiftSummary, error) {
+	host, _ := os.Hostname()
+	s := shiftSummary{Host: host, Profile: profileLabel(), From: from, To: to, Operators: make(map[string]int)}
+
+	records, err := readProcessingHistory()
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	for _, r := range records {
+		if r.Time.Before(from) || r.Time.After(to) {
+			continue
+		}
+		if r.Result == "failed" {
+			s.Failed++
+			if len(s.Failures) < shiftReportListed {
+				s.Failures = append(s.Failures, fmt.Sprintf("%s  %s", r.Time.Format("15:04"), r.Input))
+			}
+			continue
+		}
+		s.Processed++
+		operator := r.Operator
+		if operator == "" {
+			operator = r.OSUser
+		}
+		s.Operators[operator]++
+	}
+
+	rows, err := readMonitorLog(n.monitorLogPath())
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	for _, row := range rows {
+		t, err := time.Parse(time.RFC3339, row[0])
+		if err != nil || t.Before(from) || t.After(to) {
+			continue
+		}
+		s.Measured++
+		if row[7] == "no" {
+			s.OutOfSpec++
+			if len(s.OutOfSpecs) < shiftReportListed {
+				s.OutOfSpecs = append(s.OutOfSpecs, fmt.Sprintf("%s  %s: %s", t.Format("15:04"), row[1], row[8]))
+			}
+		}
+	}
+	return s, nil
+}
+
+// readMonitorLog reads the rows of the loudness monitor log, header left out
+func readMonitorLog(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var out [][]string
+	for _, row := range rows {
+		if len(row) > 8 && row[0] != monitorHeader[0] {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+// text formats the summary for the email body
+func (s shiftSummary) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TNT shift report for %s (%s)\n", s.Host, s.Profile)
+	fmt.Fprintf(&b, "%s to %s\n\n", s.From.Format("2006-01-02 15:04"), s.To.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "Files processed: %d\n", s.Processed)
+	fmt.Fprintf(&b, "Failures: %d\n", s.Failed)
+	fmt.Fprintf(&b, "Files measured: %d, out of spec: %d\n", s.Measured, s.OutOfSpec)
+
+	if len(s.Operators) > 0 {
+		b.WriteString("\nProcessed by:\n")
+		for operator, count := range s.Operators {
+			fmt.Fprintf(&b, "  %s: %d\n", operator, count)
+		}
+	}
+	list := func(title string, total int, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+		if total > len(lines) {
+			fmt.Fprintf(&b, "  and %d more\n", total-len(lines))
+		}
+	}
+	list("Failed files", s.Failed, s.Failures)
+	list("Out of spec", s.OutOfSpec, s.OutOfSpecs)
+	return b.String()
+}
+
+// sendShiftReport delivers the summary by email and webhook, whichever are set
+func sendShiftReport(settings shiftReportSettings, s shiftSummary) error {
+	var errs []error
+	if settings.EmailTo != "" && settings.SMTPServer != "" {
+		if err := emailShiftReport(settings, s); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	if settings.Webhook != "" {
+		if err := postShiftReport(settings.Webhook, s); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func emailShiftReport(settings shiftReportSettings, s shiftSummary) error {
+	var to []string
+	for _, addr := range strings.Split(settings.EmailTo, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	from := settings.EmailFrom
+	if from == "" {
+		from = to[0]
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: TNT shift report %s, %s\r\n", s.To.Format(time.DateOnly), s.Host)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(s.text(), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if settings.SMTPUser != "" {
+		host, _, err := net.SplitHostPort(settings.SMTPServer)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", settings.SMTPUser, settings.SMTPPassword, host)
+	}
+	return smtp.SendMail(settings.SMTPServer, auth, from, to, msg.Bytes())
+}
+
+func postShiftReport(url string, s shiftSummary) error {
+	body, err := json.Marshal(struct {
+		shiftSummary
+		Text string `json:"text"`
+	}{s, s.text()})
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: feedbackTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s", ErrShiftReportRejected, resp.Status)
+	}
+	return nil
+}
+
+// runShiftReports sends the day's report once it is due, for as long as TNT runs
+func (n *AudioNormalizer) runShiftReports() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if _, due := n.shiftReport.get().due(now); !due || now.Sub(n.shiftReport.attempted) < shiftReportRetry {
+			continue
+		}
+		n.shiftReport.reload()
+		settings := n.shiftReport.get()
+		from, due := settings.due(now)
+		if !due {
+			continue
+		}
+		n.shiftReport.attempted = now
+		if err := n.deliverShiftReport(settings, from, now); err != nil {
+			n.logStatus(fmt.Sprintf("✗ Shift report not sent, trying again in %s: %v", shiftReportRetry, err))
+		}
+	}
+}
+
+// deliverShiftReport summarizes and sends one period and records it as sent
+func (n *AudioNormalizer) deliverShiftReport(settings shiftReportSettings, from, to time.Time) error {
+	summary, err := n.summarizeShift(from, to)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Shift report history read failed: %v", err))
+		return err
+	}
+	if err := sendShiftReport(settings, summary); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Shift report send failed: %v", err))
+		return err
+	}
+	n.logStatus(fmt.Sprintf("✓ Shift report sent: %d processed, %d failed, %d out of spec", summary.Processed, summary.Failed, summary.OutOfSpec))
+	n.logToFile(n.logFile, "Shift report sent:\n"+summary.text())
+	return n.shiftReport.markSent(to)
+}
+
+// buildShiftReportTab edits the shift report settings and sends a report on demand
+func (n *AudioNormalizer) buildShiftReportTab() fyne.CanvasObject {
+	text := widget.NewLabel(`
+Shift report
+Once a day at the time set below, TNT sums up the processing history since the last report: files processed and by whom, failures, and files the loudness monitor found out of spec. The summary is emailed, posted as JSON to a webhook, or both, so supervisors don't have to go through the logs. TNT has to be running at that time, as an app or a service. A report missed while TNT was closed is sent when it next runs. The SMTP password is kept in shift_report.json, readable only by your account.
+	`)
+	text.Wrapping = fyne.TextWrapWord
+
+	settings := n.shiftReport.get()
+	if settings.Time == "" {
+		settings.Time = "17:00"
+	}
+
+	enabled := widget.NewCheck("Send a shift report every day", nil)
+	enabled.SetChecked(settings.Enabled)
+	timeEntry := widget.NewEntry()
+	timeEntry.SetText(settings.Time)
+	emailTo := widget.NewEntry()
+	emailTo.SetText(settings.EmailTo)
+	emailTo.SetPlaceHolder("supervisor@station.fi, onair@station.fi")
+	emailFrom := widget.NewEntry()
+	emailFrom.SetText(settings.EmailFrom)
+	smtpServer := widget.NewEntry()
+	smtpServer.SetText(settings.SMTPServer)
+	smtpServer.SetPlaceHolder("mail.station.fi:587")
+	smtpUser := widget.NewEntry()
+	smtpUser.SetText(settings.SMTPUser)
+	smtpPassword := widget.NewPasswordEntry()
+	smtpPassword.SetText(settings.SMTPPassword)
+	webhook := widget.NewEntry()
+	webhook.SetText(settings.Webhook)
+	webhook.SetPlaceHolder("https://")
+
+	current := func() shiftReportSettings {
+		return shiftReportSettings{
+			Enabled:      enabled.Checked,
+			Time:         strings.TrimSpace(timeEntry.Text),
+			EmailTo:      strings.TrimSpace(emailTo.Text),
+			EmailFrom:    strings.TrimSpace(emailFrom.Text),
+			SMTPServer:   strings.TrimSpace(smtpServer.Text),
+			SMTPUser:     strings.TrimSpace(smtpUser.Text),
+			SMTPPassword: smtpPassword.Text,
+			Webhook:      strings.TrimSpace(webhook.Text),
+		}
+	}
+
+	saveBtn := widget.NewButton("Save", func() {
+		s := current()
+		if err := s.validate(); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		if err := n.shiftReport.set(s); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+		}
+	})
+
+	var sendBtn *widget.Button
+	sendBtn = widget.NewButton("Send the last 24 hours now", func() {
+		s := current()
+		s.Enabled = true
+		if err := s.validate(); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		sendBtn.Disable()
+		go func() {
+			defer fyne.Do(sendBtn.Enable)
+			now := time.Now()
+			summary, err := n.summarizeShift(now.AddDate(0, 0, -1), now)
+			if err == nil {
+				err = sendShiftReport(s, summary)
+			}
+			fyne.Do(func() {
+				if err != nil {
+					dialog.ShowError(err, n.menuWindow)
+					return
+				}
+				dialog.ShowInformation("Shift report", "The report was sent.", n.menuWindow)
+			})
+		}()
+	})
+
+	return container.NewVBox(
+		text,
+		enabled,
+		widget.NewForm(
+			widget.NewFormItem("Send at (HH:MM)", timeEntry),
+			widget.NewFormItem("Email to", emailTo),
+			widget.NewFormItem("Email from", emailFrom),
+			widget.NewFormItem("SMTP server", smtpServer),
+			widget.NewFormItem("SMTP user", smtpUser),
+			widget.NewFormItem("SMTP password", smtpPassword),
+			widget.NewFormItem("Webhook URL", webhook),
+		),
+		container.NewHBox(saveBtn, sendBtn),
+	)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+// simulationSeconds is the length of each generated test signal
+const simulationSeconds = 12
+
+// The simulation normalizes to fixed targets, so its results don't depend on
+// the settings of the machine it runs on
+const (
+	simulationTarget   = -23.0
+	simulationTargetTp = -1.0
+	// simulationTolerance is how far in LU an output may land from the target
+	simulationTolerance = 1.0
+	// simulationTpMargin allows for loudnorm and ebur128 estimating true peak differently
+	simulationTpMargin = 0.5
+)
+
+var ErrSimulationOutput = errors.New("no output written")
+
+// testSignal is a synthetic input the simulation runs through the pipeline
+type testSignal struct {
+	Name string
+	// Source is the lavfi graph generating the stereo signal
+	Source string
+	// Processing runs the signal through dynamics and EQ as well
+	Processing bool
+	// Inverted signals must be caught by the phase check
+	Inverted bool
+}
+
+// testSignals cover what support most often has to rule out on a customer
+// machine: plain normalization, noise-like material, the full processing
+// chain, limiting of clipped input and the phase check
+var testSignals = []testSignal{
+	{Name: "1 kHz tone", Source: "sine=frequency=1000:sample_rate=48000,pan=stereo|c0=c0|c1=c0"},
+	{Name: "Pink noise", Source: "anoisesrc=color=pink:amplitude=0.3:sample_rate=48000,pan=stereo|c0=c0|c1=c0"},
+	{Name: "Speech-shaped noise", Source: "anoisesrc=color=pink:amplitude=0.5:sample_rate=48000,highpass=f=100,lowpass=f=4000,tremolo=f=4:d=0.9,pan=stereo|c0=c0|c1=c0", Processing: true},
+	// 20 dB over a sine at -18 dBFS clips hard when written as 16 bit
+	{Name: "Clipped material", Source: "sine=frequency=440:sample_rate=48000,volume=20dB,pan=stereo|c0=c0|c1=c0"},
+	{Name: "Phase-inverted stereo", Source: "anoisesrc=color=pink:amplitude=0.3:sample_rate=48000,pan=stereo|c0=c0|c1=-1*c0", Inverted: true},
+}
+
+// generateTestSignal writes a test signal as a 16 bit WAV
+func generateTestSignal(path string, signal testSignal) error {
+	_, err := ffmpeg.Run("-hide_banner", "-nostdin", "-y",
+		"-f", "lavfi", "-i", signal.Source,
+		"-t", strconv.Itoa(simulationSeconds), "-c:a", "pcm_s16le", path)
+	return err
+}
+
+// simulationConfig is the fixed job the test signals are processed with
+func simulationConfig(outputDir string, signal testSignal) ProcessConfig {
+	cfg := ProcessConfig{
+		Format:           "PCM",
+		SampleRate:       "48000",
+		BitDepth:         "24",
+		UseLoudnorm:      true,
+		TargetI:          strconv.FormatFloat(simulationTarget, 'f', 1, 64),
+		TargetTp:         strconv.FormatFloat(simulationTargetTp, 'f', 1, 64),
+		DynamicsPreset:   "Off",
+		EqTarget:         "Off",
+		Speechnorm:       "Off",
+		LoudnormFallback: audio.LoudnormFallbacks[0],
+		OutputDir:        outputDir,
+		simulation:       true,
+	}
+	if signal.Processing {
+		cfg.DynamicsPreset = "Broadcast"
+		cfg.EqTarget = "Speech"
+	}
+	return cfg
+}
+
+// runSimulation generates the test signals, runs each through the pipeline
+// and checks the outputs, one result per signal
+func (n *AudioNormalizer) runSimulation() []diagnosticResult {
+	dir, err := os.MkdirTemp("", "tnt-simulation-*")
+	if err != nil {
+		return []diagnosticResult{{Name: "Simulation", Detail: err.Error()}}
+	}
+	defer os.RemoveAll(dir)
+
+	var results []diagnosticResult
+	for i, signal := range testSignals {
+		results = append(results, n.simulateSignal(filepath.Join(dir, strconv.Itoa(i)), signal))
+	}
+	return results
+}
+
+// simulateSignal runs one test signal in its own folder
+func (n *AudioNormalizer) simulateSignal(dir string, signal testSignal) diagnosticResult {
+	r := diagnosticResult{Name: signal.Name}
+
+	outputDir := filepath.Join(dir, "output")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		r.Detail = err.Error()
+		return r
+	}
+	inputPath := filepath.Join(dir, "signal.wav")
+	if err := generateTestSignal(inputPath, signal); err != nil {
+		r.Detail = fmt.Sprintf("generating the signal failed: %v", err)
+		return r
+	}
+
+	var failures, passed []string
+	if signal.Inverted {
+		inverted, _, err := audio.PhaseCheck(inputPath, n.logFile)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("phase check failed: %v", err))
+		case !inverted:
+			failures = append(failures, "phase check missed the inverted channel")
+		default:
+			passed = append(passed, "inversion detected")
+		}
+	}
+
+	cfg := simulationConfig(outputDir, signal)
+	if !n.processFile(inputPath, cfg) {
+		r.Detail = strings.Join(append(failures, "processing failed, see the log"), "; ")
+		return r
+	}
+	outputPath, err := singleOutput(outputDir)
+	if err != nil {
+		r.Detail = strings.Join(append(failures, err.Error()), "; ")
+		return r
+	}
+
+	measured := n.ebur128Pass(outputPath, nil, cfg.Loudnorm)
+	integrated, errI := strconv.ParseFloat(measured["input_i"], 64)
+	truePeak, errTp := strconv.ParseFloat(measured["input_tp"], 64)
+	if errI != nil || errTp != nil {
+		r.Detail = strings.Join(append(failures, "output could not be measured"), "; ")
+		return r
+	}
+
+	if math.Abs(integrated-simulationTarget) > simulationTolerance {
+		failures = append(failures, fmt.Sprintf("loudness %.1f LUFS, expected %.1f ±%.1f", integrated, simulationTarget, simulationTolerance))
+	} else {
+		passed = append(passed, fmt.Sprintf("%.1f LUFS", integrated))
+	}
+	if truePeak > simulationTargetTp+simulationTpMargin {
+		failures = append(failures, fmt.Sprintf("true peak %.1f dBTP over the %.1f limit", truePeak, simulationTargetTp))
+	} else {
+		passed = append(passed, fmt.Sprintf("%.1f dBTP", truePeak))
+	}
+	if duration, err := audio.ProbeDuration(outputPath); err != nil || math.Abs(duration-simulationSeconds) > 0.1 {
+		failures = append(failures, fmt.Sprintf("output is %.2f s long, expected %d s", duration, simulationSeconds))
+	}
+
+	if len(failures) > 0 {
+		r.Detail = strings.Join(failures, "; ")
+		return r
+	}
+	r.OK = true
+	r.Detail = strings.Join(passed, ", ")
+	return r
+}
+
+// singleOutput returns the one file processing wrote into dir
+func singleOutput(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && !isPartFile(e.Name()) {
+			return filepath.Join(dir, e.Name()), nil
+		}
+	}
+	return "", ErrSimulationOutput
+}
+
+// showSimulation runs the simulation in the background and shows a copyable report
+func (n *AudioNormalizer) showSimulation() {
+	n.showChecks("TNT simulation", "simulation", n.runSimulation)
+}
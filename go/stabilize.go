@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// watchQuietOptions are the quiet periods offered in seconds
+var watchQuietOptions = []string{"Off", "2", "5", "10", "30", "60"}
+
+// watchQuietDefault is the quiet period when none has been saved
+const watchQuietDefault = "5"
+
+// watchReadableAttempts is how many quiet periods a file that can't be read
+// to its end gets before it is queued anyway, to fail and be retried there
+const watchReadableAttempts = 5
+
+// watchSettling holds the watched files waiting to settle, so a file that
+// raises several events is only waited on once
+type watchSettling struct {
+	mutex sync.Mutex
+	paths map[string]bool
+}
+
+// begin marks a path as settling, false when it already is
+func (s *watchSettling) begin(path string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.paths == nil {
+		s.paths = make(map[string]bool)
+	}
+	if s.paths[path] {
+		return false
+	}
+	s.paths[path] = true
+	return true
+}
+
+func (s *watchSettling) end(path string) {
+	s.mutex.Lock()
+	delete(s.paths, path)
+	s.mutex.Unlock()
+}
+
+// watchQuietPeriod returns how long a watched file must stay unchanged
+// before it is queued, 0 when files are queued on arrival
+func (n *AudioNormalizer) watchQuietPeriod() time.Duration {
+	seconds, err := strconv.Atoi(n.watchQuietDrop.Selected)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// enqueueWhenStable queues a watched file once it has finished arriving.
+// Copies from network shares raise the create event as soon as they start,
+// so the file is only queued once its size and modification time have stayed
+// the same for the quiet period and, when enabled, it can be read to its end.
+func (n *AudioNormalizer) enqueueWhenStable(path string) {
+	quiet := n.watchQuietPeriod()
+	if quiet == 0 {
+		n.enqueueWatched(path)
+		return
+	}
+	if !n.watchSettling.begin(path) {
+		return
+	}
+
+	go func() {
+		defer n.watchSettling.end(path)
+
+		for attempt := 1; ; attempt++ {
+			if !n.waitUnchanged(path, quiet) {
+				return
+			}
+			if !n.watchProbeCheck.Checked || !isAudioFile(path) {
+				break
+			}
+			err := readableToEnd(path)
+			if err == nil {
+				break
+			}
+			if attempt == watchReadableAttempts {
+				n.logToFile(n.logFile, fmt.Sprintf("%s still unreadable to its end, queued anyway: %v", path, err))
+				break
+			}
+			n.logToFile(n.logFile, fmt.Sprintf("%s not readable to its end yet: %v", path, err))
+		}
+		n.enqueueWatched(path)
+	}()
+}
+
+// waitUnchanged polls a file until its size and modification time have not
+// changed for quiet, false when it disappeared or watch mode stopped
+func (n *AudioNormalizer) waitUnchanged(path string, quiet time.Duration) bool {
+	poll := min(quiet/4, time.Second)
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	var size int64 = -1
+	var modTime time.Time
+	var since time.Time
+	for {
+		info, err := os.Stat(platform.LongPath(path))
+		if err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("%s went away before it settled: %v", path, err))
+			return false
+		}
+		if info.Size() != size || !info.ModTime().Equal(modTime) {
+			size, modTime, since = info.Size(), info.ModTime(), time.Now()
+		} else if time.Since(since) >= quiet {
+			// Writers on Windows hold the file open until the copy is done
+			if f, err := os.Open(platform.LongPath(path)); err == nil {
+				f.Close()
+				return true
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-n.watcherStop:
+			return false
+		}
+	}
+}
+
+// readableToEnd checks that the header has a duration and that the last
+// second of the file decodes, which fails while a copy is still short
+func readableToEnd(path string) error {
+	if _, err := audio.ProbeDuration(path); err != nil {
+		return err
+	}
+	if _, err := ffmpeg.Output("-hide_banner", "-nostdin", "-v", "error", "-xerror", "-sseof", "-1", "-i", path, "-f", "null", "-"); err != nil {
+		return fmt.Errorf("end of %s doesn't decode: %w", filepath.Base(path), err)
+	}
+	return nil
+}
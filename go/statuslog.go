@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// statusLogLines is how many lines the on-screen status log keeps. The full
+// history is still written to the log file.
+const statusLogLines = 500
+
+// statusLogBuffer is a fixed size ring of status lines, so long batches
+// don't grow the log widget without bound. Workers append to it directly,
+// the widget reads it on the UI thread.
+type statusLogBuffer struct {
+	mutex sync.Mutex
+	lines []string
+	start int
+	count int
+}
+
+func newStatusLogBuffer(size int) *statusLogBuffer {
+	return &statusLogBuffer{lines: make([]string, size)}
+}
+
+// Append adds a line, dropping the oldest one when the buffer is full
+func (b *statusLogBuffer) Append(line string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.count < len(b.lines) {
+		b.lines[(b.start+b.count)%len(b.lines)] = line
+		b.count++
+		return
+	}
+
+	b.lines[b.start] = line
+	b.start = (b.start + 1) % len(b.lines)
+}
+
+// Reset empties the buffer
+func (b *statusLogBuffer) Reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	clear(b.lines)
+	b.start = 0
+	b.count = 0
+}
+
+// String joins the buffered lines oldest first
+func (b *statusLogBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var sb strings.Builder
+	for i := 0; i < b.count; i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(b.lines[(b.start+i)%len(b.lines)])
+	}
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// Event IDs of the problems the background service forwards to the system
+// log. Monitoring rules key on them, so they must not be renumbered.
+const (
+	eventServiceFailed   = 100 // the service could not start or stopped with an error
+	eventWatchFailed     = 110 // the watch folder can't be watched
+	eventFileFailed      = 200 // a file failed to process
+	eventEncoderKilled   = 201 // the watchdog killed a hung or overlong FFmpeg run
+	eventFileQuarantined = 202 // a watched file kept failing and was quarantined
+	eventWarning         = 300 // a file was processed, or left alone, with a warning
+)
+
+// openSystemLog starts forwarding warnings and errors when the preferences ask for it
+func (n *AudioNormalizer) openSystemLog() {
+	if !n.systemLogCheck.Checked {
+		return
+	}
+	l, err := platform.OpenSystemLog(serviceName)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("System log not opened: %v", err))
+		return
+	}
+	n.systemLog = l
+	n.logToFile(n.logFile, "Forwarding warnings and errors to the system log")
+}
+
+// closeSystemLog stops forwarding
+func (n *AudioNormalizer) closeSystemLog() {
+	if n.systemLog != nil {
+		n.systemLog.Close()
+		n.systemLog = nil
+	}
+}
+
+// reportEvent forwards one problem to the system log, if forwarding is on
+func (n *AudioNormalizer) reportEvent(eventID uint32, isError bool, message string) {
+	if n.systemLog == nil {
+		return
+	}
+
+	var err error
+	if isError {
+		err = n.systemLog.Error(eventID, message)
+	} else {
+		err = n.systemLog.Warning(eventID, message)
+	}
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("System log write failed: %v", err))
+	}
+}
+
+// forwardStatus forwards a status line that reports a failure or a warning,
+// telling them apart by the mark the line starts with
+func (n *AudioNormalizer) forwardStatus(message string) {
+	if n.systemLog == nil {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(message, "✗ Encoder killed"):
+		n.reportEvent(eventEncoderKilled, true, strings.TrimSpace(strings.TrimPrefix(message, "✗")))
+	case strings.HasPrefix(message, "✗ Watch mode"):
+		n.reportEvent(eventWatchFailed, true, strings.TrimSpace(strings.TrimPrefix(message, "✗")))
+	case strings.HasPrefix(message, "✗"):
+		n.reportEvent(eventFileFailed, true, strings.TrimSpace(strings.TrimPrefix(message, "✗")))
+	case strings.HasPrefix(message, "⚠"):
+		n.reportEvent(eventWarning, false, strings.TrimSpace(strings.TrimPrefix(message, "⚠")))
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+var ErrTagMapEmpty = errors.New("CSV has no rows with a filename")
+
+// tagMapColumns are the CSV columns in the order used when the file has no header
+var tagMapColumns = []string{"filename", "title", "artist", "programme_id", "air_date"}
+
+// tagMapping holds the tags imported for one delivered file
+type tagMapping struct {
+	Title       string
+	Artist      string
+	ProgrammeID string
+	AirDate     time.Time
+}
+
+// args returns the FFmpeg -metadata arguments for the mapped fields. The air
+// date is left to airDateArgs so it's written the same way as a typed one.
+func (m tagMapping) args() []string {
+	var args []string
+	if m.Title != "" {
+		args = append(args, "-metadata", "title="+m.Title)
+	}
+	if m.Artist != "" {
+		args = append(args, "-metadata", "artist="+m.Artist)
+	}
+	if m.ProgrammeID != "" {
+		args = append(args, "-metadata", "PROGRAMME_ID="+m.ProgrammeID)
+	}
+	return args
+}
+
+// tagMapKey matches files by name regardless of folder, case and extension,
+// since deliveries are often renamed to another format before processing
+func tagMapKey(name string) string {
+	base := strings.ToLower(filepath.Base(strings.TrimSpace(name)))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// normalizeColumnName maps header spellings like "Programme ID" or "airdate" to tagMapColumns
+func normalizeColumnName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	switch name {
+	case "file", "file_name":
+		return "filename"
+	case "program_id", "programmeid", "programid":
+		return "programme_id"
+	case "airdate", "air_time", "date":
+		return "air_date"
+	}
+	return name
+}
+
+// loadTagMapping reads a CSV of filename, title, artist, programme ID and air
+// date. A header row, when present, may list the columns in any order.
+// Rows with an unreadable air date are kept without it and reported as warnings.
+func loadTagMapping(path string) (map[string]tagMapping, []string, error) {
+	f, err := os.Open(platform.LongPath(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, ErrTagMapEmpty
+	}
+
+	// Spreadsheet exports often start with a byte order mark
+	rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+
+	// firstLine is the CSV line number of rows[0], for warnings
+	firstLine := 1
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[normalizeColumnName(name)] = i
+	}
+	if _, ok := columns["filename"]; ok {
+		rows = rows[1:]
+		firstLine = 2
+	} else {
+		columns = make(map[string]int)
+		for i, name := range tagMapColumns {
+			columns[name] = i
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	mapping := make(map[string]tagMapping)
+	var warnings []string
+	for i, row := range rows {
+		key := tagMapKey(field(row, "filename"))
+		if key == "" {
+			continue
+		}
+
+		m := tagMapping{
+			Title:       field(row, "title"),
+			Artist:      field(row, "artist"),
+			ProgrammeID: field(row, "programme_id"),
+		}
+		if text := field(row, "air_date"); text != "" {
+			if airDate, err := parseAirDate(text); err == nil {
+				m.AirDate = airDate
+			} else {
+				warnings = append(warnings, fmt.Sprintf("line %d: %v", firstLine+i, err))
+			}
+		}
+		mapping[key] = m
+	}
+
+	if len(mapping) == 0 {
+		return nil, warnings, ErrTagMapEmpty
+	}
+	return mapping, warnings, nil
+}
+
+// tagMappingFor returns the imported tags for an input file
+func (n *AudioNormalizer) tagMappingFor(inputPath string) (tagMapping, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	m, ok := n.tagMap[tagMapKey(inputPath)]
+	return m, ok
+}
+
+// tagMapSummary describes the imported mapping and how much of the queue it covers
+func (n *AudioNormalizer) tagMapSummary() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if len(n.tagMap) == 0 {
+		return "No tag CSV imported"
+	}
+
+	matched := 0
+	for _, file := range n.files {
+		if _, ok := n.tagMap[tagMapKey(file)]; ok {
+			matched++
+		}
+	}
+	return fmt.Sprintf("%d rows imported, %d of %d queued files matched", len(n.tagMap), matched, len(n.files))
+}
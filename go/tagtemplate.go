@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTagTemplateLine     = errors.New("tag template lines are tag = text")
+	ErrTagTemplateReserved = errors.New("loudness tags are written by TNT and can't be templated")
+)
+
+// tagTemplateHelp lists the placeholders a tag template can use
+const tagTemplateHelp = "{filename} input name without extension, {date} processing date, {time} processing time, {target} target LUFS, {preset} preset name"
+
+// tagTemplate is one tag written into every output, its text filled in per file
+type tagTemplate struct {
+	Key  string
+	Text string
+}
+
+// parseTagTemplates reads "title = {filename}" lines. Blank lines and lines
+// starting with # are skipped.
+func parseTagTemplates(text string) ([]tagTemplate, error) {
+	var templates []tagTemplate
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		t := tagTemplate{Key: strings.TrimSpace(key), Text: strings.TrimSpace(value)}
+		if !ok || t.Key == "" || strings.ContainsAny(t.Key, " \t") {
+			return nil, fmt.Errorf("%w: %s", ErrTagTemplateLine, line)
+		}
+		if slices.ContainsFunc(loudnessTagKeys, func(k string) bool { return strings.EqualFold(k, t.Key) }) {
+			return nil, fmt.Errorf("%w: %s", ErrTagTemplateReserved, t.Key)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// tagTemplateArgs fills in the templates for one file and returns them as
+// FFmpeg -metadata arguments
+func tagTemplateArgs(templates []tagTemplate, inputPath, preset, target string, now time.Time) []string {
+	base := filepath.Base(inputPath)
+	r := strings.NewReplacer(
+		"{filename}", strings.TrimSuffix(base, filepath.Ext(base)),
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("15:04"),
+		"{target}", target,
+		"{preset}", preset,
+	)
+
+	var args []string
+	for _, t := range templates {
+		args = append(args, "-metadata", t.Key+"="+r.Replace(t.Text))
+	}
+	return args
+}
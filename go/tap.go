@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// tapSeconds is how much of the watched file the listening tap plays
+const tapSeconds = 20
+
+// tapFilter skips leading silence, so the tap and the browser's audition
+// start on programme rather than on a quiet lead-in, and fades out the end
+// of the excerpt
+var tapFilter = fmt.Sprintf("silenceremove=start_periods=1:start_threshold=-50dB,atrim=duration=%d,afade=t=out:st=%d:d=1", tapSeconds, tapSeconds-1)
+
+// setWatchCurrent records the watched file being processed, "" when the
+// watch queue is idle, and lets the listening tap play it
+func (n *AudioNormalizer) setWatchCurrent(path string) {
+	n.mutex.Lock()
+	n.watchCurrent = path
+	n.mutex.Unlock()
+
+	if n.tapBtn == nil {
+		return
+	}
+	fyne.Do(func() {
+		if path == "" {
+			n.tapBtn.Disable()
+			return
+		}
+		n.tapBtn.Enable()
+	})
+}
+
+// showTap shows the listening tap while a folder is watched
+func (n *AudioNormalizer) showTap(watching bool) {
+	if n.tapBtn == nil {
+		return
+	}
+	if watching {
+		n.tapBtn.Show()
+		n.tapStopBtn.Show()
+		return
+	}
+	n.stopTap()
+	n.tapBtn.Hide()
+	n.tapStopBtn.Hide()
+}
+
+// stopTap stops the listening tap
+func (n *AudioNormalizer) stopTap() {
+	platform.StopSound()
+}
+
+// listenToWatched plays the start of the watched file being processed, the
+// source as it arrived rather than an intermediate, so the control room can
+// check the right material is going through the hot folder
+func (n *AudioNormalizer) listenToWatched() {
+	n.mutex.Lock()
+	source := n.watchCurrent
+	n.mutex.Unlock()
+	if source == "" {
+		n.logStatus("Nothing is being processed in the watch folder")
+		return
+	}
+
+	go n.playExcerpt(source, "Listening tap")
+}
+
+// playExcerpt renders the start of a file to a temporary WAV and plays it
+// inside TNT, so auditioning never hands a file to another application.
+// what names the player in the messages.
+func (n *AudioNormalizer) playExcerpt(source, what string) {
+	// A tap still playing holds the file on some systems
+	platform.StopSound()
+
+	tapPath := filepath.Join(os.TempDir(), "tnt_listen.wav")
+	output, err := ffmpeg.Run(
+		"-i", source,
+		"-vn",
+		"-af", tapFilter,
+		"-ac", "2",
+		"-ar", "44100",
+		"-acodec", "pcm_s16le",
+		"-y", tapPath,
+	)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ %s failed: %s", what, filepath.Base(source)))
+		n.logToFile(n.logFile, fmt.Sprintf("%s of %s failed: %v: %s", what, source, err, ffmpeg.Tail(output, 3)))
+		return
+	}
+	if err := platform.PlaySound(tapPath); err != nil {
+		n.logStatus(fmt.Sprintf("✗ %s can't play: %v", what, err))
+		return
+	}
+	n.logStatus(fmt.Sprintf("→ Listening to %ds of %s", tapSeconds, filepath.Base(source)))
+}
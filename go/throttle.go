@@ -0,0 +1,49 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// uiRefreshInterval coalesces widget updates to about ten a second, which is
+// as fast as anyone reads them. Large batches otherwise flood the UI thread.
+const uiRefreshInterval = 100 * time.Millisecond
+
+// uiThrottle runs an update on the UI thread at most once per interval. A
+// trigger is never lost: one arriving while an update is pending is covered
+// by it, since updates read the current state rather than the trigger's.
+type uiThrottle struct {
+	mutex   sync.Mutex
+	pending bool
+	last    time.Time
+	update  func()
+}
+
+func newUIThrottle(update func()) *uiThrottle {
+	return &uiThrottle{update: update}
+}
+
+// Trigger schedules the update, safe to call from any goroutine
+func (t *uiThrottle) Trigger() {
+	t.mutex.Lock()
+	if t.pending {
+		t.mutex.Unlock()
+		return
+	}
+	t.pending = true
+	wait := uiRefreshInterval - time.Since(t.last)
+	t.mutex.Unlock()
+
+	time.AfterFunc(max(0, wait), func() {
+		fyne.Do(func() {
+			t.mutex.Lock()
+			t.pending = false
+			t.last = time.Now()
+			t.mutex.Unlock()
+
+			t.update()
+		})
+	})
+}
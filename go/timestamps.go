@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// Modification times given to output files
+const (
+	TimestampProcessing = "Processing time"
+	TimestampSource     = "Source file time"
+	TimestampAirDate    = "Air date"
+)
+
+// TimestampModes lists the output timestamp choices in display order
+var TimestampModes = []string{TimestampProcessing, TimestampSource, TimestampAirDate}
+
+// airDateLayout is how air dates are entered, in local time
+const airDateLayout = "2006-01-02 15:04"
+
+// parseAirDate reads an air date entered as "2006-01-02 15:04" or just "2006-01-02"
+func parseAirDate(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if t, err := time.ParseInLocation(airDateLayout, text, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", text, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("air date %q is not in the form YYYY-MM-DD HH:MM", text)
+}
+
+// airDateArgs embeds the air date as a release date and a custom AIR_DATE tag
+func airDateArgs(airDate time.Time) []string {
+	return []string{
+		"-metadata", "date=" + airDate.Format("2006-01-02"),
+		"-metadata", "AIR_DATE=" + airDate.Format("2006-01-02T15:04:05-07:00"),
+	}
+}
+
+// setOutputTime sets an output's modification time as configured. Playout
+// ingest folders that sort by mtime then air files in the intended order.
+func (n *AudioNormalizer) setOutputTime(inputPath, outputPath string, cfg ProcessConfig) {
+	var stamp time.Time
+
+	switch cfg.TimestampMode {
+	case TimestampSource:
+		info, err := os.Stat(platform.LongPath(inputPath))
+		if err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("No source time for %s: %v", inputPath, err))
+			return
+		}
+		stamp = info.ModTime()
+	case TimestampAirDate:
+		if cfg.AirDate.IsZero() {
+			return
+		}
+		stamp = cfg.AirDate
+	default:
+		// FFmpeg just wrote the file, its mtime already is the processing time
+		return
+	}
+
+	if err := os.Chtimes(platform.LongPath(outputPath), stamp, stamp); err != nil {
+		n.logStatus(fmt.Sprintf("⚠ Could not set file time: %s - %v", filepath.Base(outputPath), err))
+		n.logToFile(n.logFile, fmt.Sprintf("Chtimes %s failed: %v", outputPath, err))
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+)
+
+// trimWaveformPoints is how many peaks the trim editor draws
+const trimWaveformPoints = 1200
+
+// trimRange is the part of a queued file that is kept, in seconds from the
+// start. An Out of 0 keeps the file to its end.
+type trimRange struct {
+	In  float64
+	Out float64
+}
+
+// inputArgs seeks the input to the kept part
+func (t trimRange) inputArgs() []string {
+	args := []string{"-ss", strconv.FormatFloat(t.In, 'f', 3, 64)}
+	if t.Out > 0 {
+		args = append(args, "-to", strconv.FormatFloat(t.Out, 'f', 3, 64))
+	}
+	return args
+}
+
+// filter cuts the kept part in a filter chain, for long files whose stages
+// are piped into the final encode
+func (t trimRange) filter() string {
+	f := "atrim=start=" + strconv.FormatFloat(t.In, 'f', 3, 64)
+	if t.Out > 0 {
+		f += ":end=" + strconv.FormatFloat(t.Out, 'f', 3, 64)
+	}
+	return f + ",asetpts=PTS-STARTPTS"
+}
+
+func (t trimRange) String() string {
+	if t.Out > 0 {
+		return audio.FormatTimecode(t.In) + "-" + audio.FormatTimecode(t.Out)
+	}
+	return audio.FormatTimecode(t.In) + "-end"
+}
+
+// setTrim stores the kept part of a queued file, removing it when the whole file is kept
+func (n *AudioNormalizer) setTrim(path string, t trimRange) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if t.In <= 0 && t.Out <= 0 {
+		delete(n.trims, path)
+		return
+	}
+	if n.trims == nil {
+		n.trims = make(map[string]trimRange)
+	}
+	n.trims[path] = t
+}
+
+// trimFor returns the kept part of a file, false when the whole file is kept
+func (n *AudioNormalizer) trimFor(path string) (trimRange, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	t, ok := n.trims[path]
+	return t, ok
+}
+
+// showTrimEditor decodes a queued file's waveform and lets the in and out
+// points be dragged to cut false starts and tails
+func (n *AudioNormalizer) showTrimEditor(path string) {
+	loading := dialog.NewCustomWithoutButtons("Reading "+filepath.Base(path), widget.NewProgressBarInfinite(), n.window)
+	loading.Show()
+
+	go func() {
+		peaks, duration, err := audio.Waveform(path, trimWaveformPoints)
+		fyne.Do(func() {
+			loading.Hide()
+			if err != nil {
+				dialog.ShowError(err, n.window)
+				return
+			}
+			n.showTrimDialog(path, peaks, duration)
+		})
+	}()
+}
+
+func (n *AudioNormalizer) showTrimDialog(path string, peaks []float64, duration float64) {
+	t, _ := n.trimFor(path)
+	view := newWaveformView(peaks, duration)
+	view.in = min(t.In, duration)
+	view.out = duration
+	if t.Out > 0 {
+		view.out = min(t.Out, duration)
+	}
+
+	rangeLabel := widget.NewLabel("")
+	showRange := func(in, out float64) {
+		rangeLabel.SetText(fmt.Sprintf("In %s   Out %s   Length %s",
+			audio.FormatTimecode(in), audio.FormatTimecode(out), audio.FormatTimecode(out-in)))
+	}
+	view.onChanged = showRange
+	showRange(view.in, view.out)
+
+	resetBtn := widget.NewButton("Keep whole file", func() {
+		view.setRange(0, duration)
+	})
+
+	content := container.NewBorder(
+		widget.NewLabel("Drag the markers to set the in and out points."),
+		container.NewBorder(nil, nil, nil, resetBtn, rangeLabel),
+		nil, nil,
+		view,
+	)
+
+	d := dialog.NewCustomConfirm("Trim "+filepath.Base(path), "Apply", "Cancel", content, func(apply bool) {
+		if !apply {
+			return
+		}
+		kept := trimRange{In: view.in, Out: view.out}
+		// Markers within a frame of the ends keep the file whole at that end
+		if kept.In < 0.01 {
+			kept.In = 0
+		}
+		if duration-kept.Out < 0.01 {
+			kept.Out = 0
+		}
+		n.setTrim(path, kept)
+		if kept.In > 0 || kept.Out > 0 {
+			n.logStatus(fmt.Sprintf("Trim %s: %s", kept, filepath.Base(path)))
+		}
+		n.queueRefresh.Trigger()
+	}, n.window)
+	d.Resize(fyne.NewSize(680, 300))
+	d.Show()
+}
+
+// waveformView draws a file's peaks with draggable in and out markers. A
+// tap or drag moves whichever marker is nearer.
+type waveformView struct {
+	widget.BaseWidget
+	peaks     []float64
+	duration  float64
+	in, out   float64
+	dragging  *float64
+	raster    *canvas.Raster
+	onChanged func(in, out float64)
+}
+
+func newWaveformView(peaks []float64, duration float64) *waveformView {
+	v := &waveformView{peaks: peaks, duration: duration, out: duration}
+	v.raster = canvas.NewRaster(v.draw)
+	v.raster.SetMinSize(fyne.NewSize(640, 160))
+	v.ExtendBaseWidget(v)
+	return v
+}
+
+func (v *waveformView) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(v.raster)
+}
+
+// setRange moves both markers
+func (v *waveformView) setRange(in, out float64) {
+	v.in, v.out = in, out
+	v.changed()
+}
+
+func (v *waveformView) changed() {
+	v.raster.Refresh()
+	if v.onChanged != nil {
+		v.onChanged(v.in, v.out)
+	}
+}
+
+// secondsAt converts a position in the view to a time in the file
+func (v *waveformView) secondsAt(x float32) float64 {
+	width := v.Size().Width
+	if width <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(v.duration, float64(x/width)*v.duration))
+}
+
+// nearer returns the marker closer to a time
+func (v *waveformView) nearer(t float64) *float64 {
+	if math.Abs(t-v.in) <= math.Abs(t-v.out) {
+		return &v.in
+	}
+	return &v.out
+}
+
+// moveMarker sets a marker, keeping in before out
+func (v *waveformView) moveMarker(marker *float64, t float64) {
+	if marker == &v.in {
+		v.in = math.Min(t, v.out)
+	} else {
+		v.out = math.Max(t, v.in)
+	}
+	v.changed()
+}
+
+func (v *waveformView) Tapped(e *fyne.PointEvent) {
+	t := v.secondsAt(e.Position.X)
+	v.moveMarker(v.nearer(t), t)
+}
+
+func (v *waveformView) Dragged(e *fyne.DragEvent) {
+	t := v.secondsAt(e.Position.X)
+	if v.dragging == nil {
+		v.dragging = v.nearer(t)
+	}
+	v.moveMarker(v.dragging, t)
+}
+
+func (v *waveformView) DragEnd() {
+	v.dragging = nil
+}
+
+// draw renders the peaks, dimming what is cut away, with the markers on top
+func (v *waveformView) draw(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 || len(v.peaks) == 0 || v.duration <= 0 {
+		return img
+	}
+
+	kept := theme.Color(theme.ColorNameForeground)
+	cut := theme.Color(theme.ColorNameDisabled)
+	marker := theme.Color(theme.ColorNamePrimary)
+
+	inX := int(v.in / v.duration * float64(w))
+	outX := int(v.out / v.duration * float64(w))
+	mid := h / 2
+
+	for x := range w {
+		c := cut
+		if x >= inX && x <= outX {
+			c = kept
+		}
+		reach := int(v.peaks[x*len(v.peaks)/w] * float64(mid))
+		for y := mid - reach; y <= mid+reach; y++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	for _, mx := range []int{inX, outX} {
+		for x := max(0, mx-1); x <= min(w-1, mx+1); x++ {
+			for y := range h {
+				img.Set(x, y, marker)
+			}
+		}
+	}
+	return img
+}
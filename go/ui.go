@@ -5,11 +5,16 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/config"
 	"fmt"
+	"image/color"
 	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 func (n *AudioNormalizer) setupUI(a fyne.App) {
@@ -27,25 +32,236 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 	n.fileList = widget.NewList(
 		func() int { return len(n.files) },
 		func() fyne.CanvasObject {
-			return container.NewBorder(nil, nil, nil,
-				widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+			trimEntry := widget.NewEntry()
+			trimEntry.SetPlaceHolder("±dB")
+			trimEntry.Validator = func(text string) error {
+				_, err := parseGainTrim(text)
+				return err
+			}
+			anchorEntry := widget.NewEntry()
+			anchorEntry.SetPlaceHolder("Anchor in-out")
+			anchorEntry.Validator = func(text string) error {
+				if strings.TrimSpace(text) == "" {
+					return nil
+				}
+				_, err := audio.ParseAnchor(text)
+				return err
+			}
+			// Loudness compliance from the pre-flight scan, see compliance.go
+			marker := canvas.NewRectangle(color.Transparent)
+			marker.SetMinSize(fyne.NewSize(12, 12))
+			marker.CornerRadius = 6
+			return container.NewBorder(nil, nil, container.NewCenter(marker),
+				container.NewHBox(
+					container.NewGridWrap(fyne.NewSize(130, anchorEntry.MinSize().Height), anchorEntry),
+					container.NewGridWrap(fyne.NewSize(80, trimEntry.MinSize().Height), trimEntry),
+					widget.NewButtonWithIcon("", theme.InfoIcon(), nil),
+					widget.NewButtonWithIcon("", theme.ContentCutIcon(), nil),
+					widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				),
 				widget.NewLabel("template"),
 			)
 		},
 		func(i widget.ListItemID, o fyne.CanvasObject) {
 			border := o.(*fyne.Container)
 			label := border.Objects[0].(*widget.Label)
-			btn := border.Objects[1].(*widget.Button)
+			marker := border.Objects[1].(*fyne.Container).Objects[0].(*canvas.Rectangle)
+			controls := border.Objects[2].(*fyne.Container)
+			anchorEntry := controls.Objects[0].(*fyne.Container).Objects[0].(*widget.Entry)
+			trimEntry := controls.Objects[1].(*fyne.Container).Objects[0].(*widget.Entry)
+			detailsBtn := controls.Objects[2].(*widget.Button)
+			trimBtn := controls.Objects[3].(*widget.Button)
+			btn := controls.Objects[4].(*widget.Button)
+
+			path := n.files[i]
+			label.SetText(n.fileRowText(path))
+
+			marker.FillColor = color.Transparent
+			if m, ok := n.complianceFor(path); ok {
+				marker.FillColor = complianceColor(m.level(n.complianceTargets()))
+			}
+			marker.Refresh()
+
+			// Rows are recycled, so detach the handler before showing this file's trim
+			trimEntry.OnChanged = nil
+			trimEntry.SetText(n.gainTrimText(path))
+			trimEntry.OnChanged = func(text string) {
+				if trim, err := parseGainTrim(text); err == nil {
+					n.setGainTrim(path, trim)
+				}
+			}
+
+			anchorEntry.OnChanged = nil
+			anchorEntry.SetText(n.anchorText(path))
+			anchorEntry.OnChanged = func(text string) {
+				if strings.TrimSpace(text) == "" {
+					n.clearAnchor(path)
+				} else if region, err := audio.ParseAnchor(text); err == nil {
+					n.setAnchor(path, region)
+				}
+			}
+
+			detailsBtn.OnTapped = func() {
+				n.showFileDetails(path)
+			}
+
+			// A trimmed file's button stands out so the cut isn't forgotten
+			trimBtn.Importance = widget.MediumImportance
+			if _, ok := n.trimFor(path); ok {
+				trimBtn.Importance = widget.HighImportance
+			}
+			trimBtn.Refresh()
+			trimBtn.OnTapped = func() {
+				n.showTrimEditor(path)
+			}
 
-			label.SetText(filepath.Base(n.files[i]))
 			btn.OnTapped = func() {
 				n.removeFile(i)
 			}
 		},
 	)
+	n.fileListRefresh = newUIThrottle(n.fileList.Refresh)
+
+	// Folder scans and archives add thousands of files, the list is redrawn once per interval
+	n.queueRefresh = newUIThrottle(func() {
+		n.fileList.Refresh()
+		n.updateProcessButton()
+		n.checkPCM()
+	})
 
 	n.checkPhaseBtn = widget.NewCheck("Phase check", nil)
 
+	n.loudnessBadgeCheck = widget.NewCheck("Generate loudness badge", nil)
+
+	n.loudnessReportDrop = widget.NewSelect(loudnessReportFormats, nil)
+	n.loudnessReportDrop.SetSelected(loudnessReportOff)
+
+	n.qualityScoreCheck = widget.NewCheck("Score lossy outputs against the source", nil)
+
+	n.askOperatorCheck = widget.NewCheck("Ask for the operator name at startup", nil)
+	n.auditSidecarCheck = widget.NewCheck("Write an audit sidecar next to every output", nil)
+
+	n.zipKeepFoldersCheck = widget.NewCheck("Keep the archive's folder structure in the output", nil)
+
+	n.anchorMarkersCheck = widget.NewCheck("Normalize to an \"anchor\" marker in WAV files", nil)
+
+	n.keepEmphasisCheck = widget.NewCheck("Leave pre-emphasized rips as they are", nil)
+
+	n.folderMinEntry = widget.NewEntry()
+	n.folderMinEntry.SetPlaceHolder("No minimum")
+	n.folderMaxEntry = widget.NewEntry()
+	n.folderMaxEntry.SetPlaceHolder("No maximum")
+	n.folderSinceEntry = widget.NewEntry()
+	n.folderSinceEntry.SetPlaceHolder("YYYY-MM-DD")
+	validateFolderFilter := func(string) error {
+		_, err := parseFolderFilter(n.folderMinEntry.Text, n.folderMaxEntry.Text, n.folderSinceEntry.Text, false)
+		return err
+	}
+	n.folderMinEntry.Validator = validateFolderFilter
+	n.folderMaxEntry.Validator = validateFolderFilter
+	n.folderSinceEntry.Validator = validateFolderFilter
+	n.folderSkipHiddenCheck = widget.NewCheck("Skip hidden files and macOS ._ resource files", nil)
+
+	n.incrementalCheck = widget.NewCheck("Skip files already processed with the same settings", nil)
+
+	n.notifySoundFileEntry = widget.NewEntry()
+	n.notifySoundFileEntry.SetPlaceHolder("WAV file")
+	n.notifySoundDrop = widget.NewSelect(notifySoundOptions, func(choice string) {
+		if choice == notifySoundCustom {
+			n.notifySoundFileEntry.Enable()
+		} else {
+			n.notifySoundFileEntry.Disable()
+		}
+	})
+	n.notifySoundDrop.SetSelected(notifySoundOff)
+	n.bwfHintsCheck = widget.NewCheck("Use speech and scene/take hints from BWF and iXML metadata", nil)
+	n.bwfTemplateEntry = widget.NewEntry()
+	n.bwfTemplateEntry.SetPlaceHolder("{scene}_T{take}_{name}")
+
+	n.previewCopyCheck = widget.NewCheck("Also make a marked preview copy of every output", nil)
+	n.previewOffsetEntry = widget.NewEntry()
+	n.previewOffsetEntry.SetPlaceHolder("0:00")
+	n.previewLevelEntry = widget.NewEntry()
+	n.previewLevelEntry.SetPlaceHolder("-60")
+	n.previewIdentEntry = widget.NewEntry()
+	n.previewIdentEntry.SetPlaceHolder("Ident audio file")
+	n.previewMarkDrop = widget.NewSelect(previewMarkOptions, func(kind string) {
+		if kind == previewMarkIdent {
+			n.previewLevelEntry.Disable()
+			n.previewIdentEntry.Enable()
+		} else {
+			n.previewLevelEntry.Enable()
+			n.previewIdentEntry.Disable()
+		}
+	})
+	n.previewMarkDrop.SetSelected(previewMarkWatermark)
+	validatePreviewMark := func(string) error {
+		_, err := parsePreviewMark(n.previewMarkDrop.Selected, n.previewOffsetEntry.Text, n.previewLevelEntry.Text, "-")
+		return err
+	}
+	n.previewOffsetEntry.Validator = validatePreviewMark
+	n.previewLevelEntry.Validator = validatePreviewMark
+
+	n.notifyBadgeCheck = widget.NewCheck("Badge the Dock icon or flash the taskbar button while TNT is in the background", nil)
+
+	n.musicLibCheck = widget.NewCheck("Music library mode", nil)
+	n.maxTrackGainEntry = widget.NewEntry()
+	n.maxTrackGainEntry.SetPlaceHolder("No cap")
+	n.maxTrackGainEntry.Validator = func(text string) error {
+		_, _, err := parseMaxTrackGain(text)
+		return err
+	}
+	n.preventClipCheck = widget.NewCheck("Lower the gain of tracks that would clip", nil)
+
+	n.metaPublisher = widget.NewEntry()
+	n.metaCopyright = widget.NewEntry()
+	n.metaEncodedBy = widget.NewEntry()
+	n.tagTemplatesEntry = widget.NewMultiLineEntry()
+	n.tagTemplatesEntry.SetPlaceHolder("title = {filename}\ncomment = Delivered {date}")
+	n.tagTemplatesEntry.SetMinRowsVisible(3)
+	n.tagTemplatesEntry.Validator = func(text string) error {
+		_, err := parseTagTemplates(text)
+		return err
+	}
+
+	n.loudnessTagsDrop = widget.NewSelect(LoudnessTagPolicies, nil)
+	n.loudnessTagsDrop.SetSelected(LoudnessTagsStrip)
+
+	n.timestampDrop = widget.NewSelect(TimestampModes, nil)
+	n.timestampDrop.SetSelected(TimestampProcessing)
+	n.airDateEntry = widget.NewEntry()
+	n.airDateEntry.SetPlaceHolder("YYYY-MM-DD HH:MM")
+	n.airDateTagCheck = widget.NewCheck("Write air date into the tags", nil)
+
+	n.tagMapLabel = widget.NewLabel("No tag CSV imported")
+
+	n.channelCheck = widget.NewCheck("Detect dual-mono and one-sided recordings", nil)
+	n.channelFixDrop = widget.NewSelect(audio.ChannelFixes, nil)
+	n.channelFixDrop.SetSelected(audio.ChannelFixAsk)
+
+	n.loudnormFallback = widget.NewSelect(audio.LoudnormFallbacks, nil)
+	n.loudnormFallback.SetSelected(audio.FallbackDynamic)
+
+	n.loudnormDualMonoCheck = widget.NewCheck("Measure mono files as dual mono, for stereo playout", nil)
+	n.loudnormOffsetEntry = widget.NewEntry()
+	n.loudnormOffsetEntry.SetPlaceHolder("Offset gain in dB, e.g. -1.5")
+	n.loudnormOffsetEntry.Validator = validateOffset
+	n.loudnormOffsetDrop = widget.NewSelect(audio.LoudnormOffsets, func(choice string) {
+		if choice == audio.OffsetCustom {
+			n.loudnormOffsetEntry.Enable()
+		} else {
+			n.loudnormOffsetEntry.Disable()
+		}
+	})
+	n.loudnormOffsetDrop.SetSelected(audio.OffsetMeasured)
+
+	var standards []string
+	for _, s := range audio.Standards {
+		standards = append(standards, s.Label())
+	}
+	n.measurementStandardDrop = widget.NewSelect(standards, nil)
+	n.measurementStandardDrop.SetSelected(audio.BS1770_4.Label())
+
 	// Mode toggle
 	n.modeToggle = widget.NewCheck("Advanced Mode", func(checked bool) {
 		n.advancedMode = checked
@@ -56,7 +272,12 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 		"Small file (AAC 256kbps)",
 		"Most compatible (MP3 320kbps)",
 		"Production (PCM 48kHz/24bit)",
-	}, nil)
+		digitizationPreset,
+	}, func(string) {
+		if n.quickBtn != nil {
+			n.updateProcessButton()
+		}
+	})
 	n.simpleGroupButtons.SetSelected("Production (PCM 48kHz/24bit)")
 
 	// Advanced mode widgets
@@ -69,6 +290,20 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 	n.bitrateEntry = widget.NewEntry()
 	n.bitrateEntry.SetPlaceHolder("Bitrate (kbps)")
 	n.bitrateEntry.SetText("256")
+	n.bitrateEntry.Validator = func(text string) error {
+		if n.formatSelect == nil {
+			return nil
+		}
+		codec, ok := config.LookupCodec(codecFor(n.formatSelect.Selected))
+		if !ok || !codec.Bitrate {
+			return nil
+		}
+		kbps, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(text), "k"))
+		if err != nil || kbps < codec.MinKbps || kbps > codec.MaxKbps {
+			return fmt.Errorf("bitrate must be %d-%d kbps", codec.MinKbps, codec.MaxKbps)
+		}
+		return nil
+	}
 
 	n.normalizeTarget = widget.NewEntry()
 	n.normalizeTarget.SetPlaceHolder("LUFS target")
@@ -93,7 +328,18 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 	// Loudnorm checkbox
 	n.writeTagsLabel = widget.NewLabel("Write RG tags (EBU R128: -23 LUFS)")
 
+	n.albumModeCheck = widget.NewCheck("Treat selection as album", nil)
+	albumRow := container.NewHBox(n.albumModeCheck, n.hintButton("album-gain"))
+	albumRow.Hide()
+
+	n.stripMetadataCheck = widget.NewCheck("Strip metadata", nil)
+
 	n.writeTags = widget.NewCheck("", func(checked bool) {
+		if checked {
+			albumRow.Show()
+		} else {
+			albumRow.Hide()
+		}
 		if checked  && n.checkPCM(){
 			n.loudnormCheck.Disable()
 			n.noTranscode.Disable()
@@ -112,24 +358,91 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 		}
 	})
 
-	writeTagsRow := container.NewHBox(n.writeTags, n.writeTagsLabel)
+	writeTagsRow := container.NewHBox(n.writeTags, n.writeTagsLabel, n.hintButton("write-tags"))
 	n.writeTags.SetChecked(false)
 
 	n.writeTags.SetChecked(false)
 	n.writeTags.Disable()
 
+	n.tagClipCapCheck = widget.NewCheck("Lower the tagged gain of files that would clip", nil)
+	n.tagClipCapCheck.Hide()
+
 	n.noTranscode = widget.NewCheck("Do not transcode", func(b bool) {
 		if b {
 			n.bypassProc.SetChecked(true)
 			n.bypassProc.Disable()
+			n.tagClipCapCheck.Show()
 		} else {
 			n.bypassProc.Enable()
+			n.tagClipCapCheck.Hide()
 		}
 	})
 	n.noTranscode.SetChecked(false)
 	n.noTranscode.Disable()
 	n.noTranscode.Hide()
 
+	n.extraTargetsGroup = widget.NewCheckGroup(extraTargetNames(), nil)
+
+	n.webVersionCheck = widget.NewCheck("Make web version too", nil)
+	n.webTargetEntry = widget.NewEntry()
+	n.webTargetEntry.SetPlaceHolder(webDefaultTarget)
+	n.webTargetTpEntry = widget.NewEntry()
+	n.webTargetTpEntry.SetPlaceHolder(webDefaultTargetTp)
+	validateNumber := func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		_, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		return err
+	}
+	n.webTargetEntry.Validator = validateNumber
+	n.webTargetTpEntry.Validator = validateNumber
+	n.webFormatDrop = widget.NewSelect(extraTargetNames(), nil)
+
+	n.qcToleranceEntry = widget.NewEntry()
+	n.qcToleranceEntry.SetPlaceHolder(strconv.FormatFloat(qcDefaultTolerance, 'f', 1, 64))
+	n.qcToleranceEntry.Validator = validateNumber
+
+	n.longFileCheck = widget.NewCheck("Use long-file mode for long recordings", nil)
+	n.longFileHoursEntry = widget.NewEntry()
+	n.longFileHoursEntry.SetPlaceHolder(strconv.FormatFloat(longFileDefaultHours, 'f', -1, 64))
+	n.longFileHoursEntry.Validator = validateNumber
+	n.stageTempFilesCheck = widget.NewCheck("Render stages to temp files", nil)
+
+	n.workersEntry = widget.NewEntry()
+	n.workersEntry.SetPlaceHolder(strconv.Itoa(defaultWorkers()))
+	n.workersEntry.Validator = validateCount
+	n.ffmpegThreadsEntry = widget.NewEntry()
+	n.ffmpegThreadsEntry.SetPlaceHolder("All cores")
+	n.ffmpegThreadsEntry.Validator = validateCount
+	n.ffmpegThreadsEntry.OnChanged = func(string) { n.applyCPULimits() }
+	n.lowPriorityCheck = widget.NewCheck("Run FFmpeg at low priority", func(bool) { n.applyCPULimits() })
+
+	n.seriesEntry = widget.NewMultiLineEntry()
+	n.seriesEntry.SetPlaceHolder("Morning show = morning_*.wav")
+	n.seriesEntry.SetMinRowsVisible(3)
+	n.seriesEntry.Validator = func(text string) error {
+		_, err := parseSeriesLines(text)
+		return err
+	}
+	n.seriesAlertCheck = widget.NewCheck("Alert when an episode differs from its series", nil)
+
+	n.batchReportCheck = widget.NewCheck("Write a JSON and CSV report after each batch", nil)
+
+	n.srcQualityDrop = widget.NewSelect(audio.SRCQualities, nil)
+	n.srcQualityDrop.SetSelected(audio.SRCHigh)
+
+	n.systemLogCheck = widget.NewCheck("Forward warnings and errors to the system log", nil)
+	n.usageStatsCheck = widget.NewCheck("Send anonymous usage statistics", func(checked bool) {
+		// Counts collected while opted in don't outlive opting out
+		if !checked {
+			n.usageStats.reset()
+		}
+	})
+	n.logRetentionDrop = widget.NewSelect(logRetentionOptions, nil)
+	n.logRetentionDrop.SetSelected(logRetentionDefault)
+	n.webFormatDrop.SetSelected(webDefaultFormat)
+
 	n.dataCompLevel = widget.NewSlider(0, 10)
 	n.dataCompLevel.Step = 1
 
@@ -159,15 +472,36 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 
 	n.watchMode = widget.NewCheck("Watch", func(checked bool) {
 		if checked {
-			n.startWatching()
+			if err := n.startWatching(); err != nil {
+				n.watchMode.SetChecked(false)
+				dialog.ShowError(err, n.window)
+				return
+			}
 			n.watcherWarnLabel.SetText("WATCHING")
+			n.showTap(true)
 		} else {
 			n.stopWatching()
 			n.watcherWarnLabel.SetText("")
+			n.showTap(false)
 		}
 	})
 	n.watchMode.SetChecked(false)
 
+	n.watchMeasureOnly = widget.NewCheck("Measure only, don't modify files", nil)
+	n.watchAlertCheck = widget.NewCheck("Alert when a file is out of spec", nil)
+	n.watchRescanDrop = widget.NewSelect(watchRescanOptions, nil)
+	n.watchRescanDrop.SetSelected("5")
+	n.watchPaceDrop = widget.NewSelect(watchPaceOptions, nil)
+	n.watchPaceDrop.SetSelected("Off")
+	n.watchQuietDrop = widget.NewSelect(watchQuietOptions, nil)
+	n.watchQuietDrop.SetSelected(watchQuietDefault)
+	n.watchProbeCheck = widget.NewCheck("Also wait until the whole file can be read", nil)
+	n.watchRecursiveCheck = widget.NewCheck("Watch subfolders too", nil)
+	n.watchRetryDrop = widget.NewSelect(watchRetryOptions, nil)
+	n.watchRetryDrop.SetSelected(watchRetriesDefault)
+	n.watchQuarantineDrop = widget.NewSelect(watchQuarantineOptions, nil)
+	n.watchQuarantineDrop.SetSelected(QuarantineOff)
+
 	formatLabel := widget.NewLabel("Format:")
 	sampleRateLabel := widget.NewLabel("Sample Rate:")
 	bitDepthLabel := widget.NewLabel("Bit Depth:")
@@ -188,25 +522,19 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 		dataCompLevelLabelCurrent.SetText(fmt.Sprintf("Set: %d", int(f)))
 	}
 
-	n.IsSpeechCheck = widget.NewCheck("Optimize Opus for speech", func(checked bool){
-		if checked {
-				n.formatSelect.SetSelected("Opus")
-				n.formatSelect.Disable()
-		} else {
-			n.formatSelect.Enable()
-			n.formatSelect.SetSelected("AAC")
-		}
-	})
+	n.IsSpeechCheck = widget.NewCheck("Optimize Opus for speech", nil)
 	n.IsSpeechCheck.SetChecked(false)
 
 	// Create format select after container exists
 	n.formatSelect = widget.NewSelect(getPlatformFormats(), func(value string) {
 		n.updateAdvancedControls()
 
-		usesDataComp := value == "Opus" || value == "FLAC"
-		usesBitDepth := value == "PCM"
-		usesBitRate := value != "PCM" && value != "FLAC"
-		usesSampleRate := value == "PCM"
+		codec, _ := config.LookupCodec(codecFor(value))
+		usesDataComp := codec.CompressionLevels > 0
+		usesBitDepth := codec.BitDepth
+		usesBitRate := codec.Bitrate
+		usesSampleRate := codec.SampleRate
+		n.bitrateEntry.Validate()
 
 		if usesDataComp {
 			n.dataCompLevel.Show()
@@ -256,13 +584,13 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 			n.writeTags.Enable()
 		}
 	})
-	loudnormRow := container.NewHBox(n.loudnormCheck, n.loudnormLabel)
+	loudnormRow := container.NewHBox(n.loudnormCheck, n.loudnormLabel, n.hintButton("normalize"))
 	n.loudnormCheck.SetChecked(false)
 
 	n.modeWarning = widget.NewLabel("To use advanced features, trigger processing from Advanced or Processing view.")
 	n.modeWarning.Wrapping = fyne.TextWrapWord
 
-	n.simpleGroup = container.NewVBox(n.modeWarning, n.simpleGroupButtons, loudnormRow)
+	n.simpleGroup = container.NewVBox(n.modeWarning, container.NewBorder(nil, nil, nil, n.hintButton("fast-presets"), n.simpleGroupButtons), loudnormRow)
 
 	n.advancedContainer = container.NewVBox(
 		container.NewBorder(nil, nil, formatLabel, nil, widget.NewLabel("")),
@@ -273,19 +601,30 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 		container.NewBorder(nil, nil, n.normalizeTargetLabelTp, nil, n.normalizeTargetTp),
 		container.NewBorder(nil,nil, dataCompLevelLabel, dataCompLevelLabelCurrent, n.dataCompLevel),
 
-		n.loudnormCustomCheck,
+		container.NewHBox(n.loudnormCustomCheck, n.hintButton("loudness-targets")),
 		writeTagsRow,
+		albumRow,
+		widget.NewLabel("Also encode to:"),
+		n.extraTargetsGroup,
+		n.webVersionCheck,
 		n.noTranscode,
+		container.NewHBox(n.stripMetadataCheck, n.hintButton("strip-metadata")),
+		n.tagClipCapCheck,
 		loudnormRow,
 		n.IsSpeechCheck,
 	)
 
 	// Replace placeholder with actual format select
-	n.advancedContainer.Objects[0] = container.NewBorder(nil, nil, formatLabel, nil, n.formatSelect)
+	n.advancedContainer.Objects[0] = container.NewBorder(nil, nil, formatLabel, n.hintButton("format"), n.formatSelect)
 
 	n.normalizationStandard = "EBU R128 (-23 LUFS)"
 
 	n.watcherWarnLabel = widget.NewLabel("")
+	n.tapBtn = widget.NewButtonWithIcon("Listen", theme.MediaPlayIcon(), n.listenToWatched)
+	n.tapBtn.Disable()
+	n.tapBtn.Hide()
+	n.tapStopBtn = widget.NewButtonWithIcon("", theme.MediaStopIcon(), n.stopTap)
+	n.tapStopBtn.Hide()
 
 	// File selection
 	selectFilesBtn := widget.NewButton("Select Files", n.selectFiles)
@@ -297,10 +636,25 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 	n.processBtn = widget.NewButton("Process", n.process)
 	n.processBtn.Disable()
 
+	n.quickBtn = widget.NewButton("Quick", n.quickProcess)
+	n.quickBtn.Disable()
+
 	n.progressBar = widget.NewProgressBar()
 	n.progressBar.Hide()
 
+	n.pauseBtn = widget.NewButton("Pause", n.pauseBatch)
+	n.pauseBtn.Hide()
+	n.cancelBtn = widget.NewButton("Cancel", n.cancelBatch)
+	n.cancelBtn.Hide()
+
+	n.etaLabel = widget.NewLabel("")
+	n.etaLabel.Hide()
+
 	n.statusLog = widget.NewMultiLineEntry()
+	n.statusLines = newStatusLogBuffer(statusLogLines)
+	n.statusRefresh = newUIThrottle(func() {
+		n.statusLog.SetText(n.statusLines.String())
+	})
 	n.statusLog.Disable()
 	n.statusLog.SetPlaceHolder("Processing log will appear here...")
 
@@ -308,254 +662,69 @@ func (n *AudioNormalizer) setupUI(a fyne.App) {
 	n.dynamicsLabel = widget.NewLabel("Dynamics processing level")
 	n.dynamicsDrop = widget.NewSelect([]string{"Off", "Light", "Moderate", "Broadcast"}, nil)
 	n.dynamicsDrop.SetSelected("Off")
-	dynamicsRow := container.NewHBox(n.dynamicsDrop, n.dynamicsLabel)
+	dynamicsRow := container.NewHBox(n.dynamicsDrop, n.dynamicsLabel, n.hintButton("dynamics"))
 
 	n.EqLabel = widget.NewLabel("EQ target curve")
 	n.EqDrop = widget.NewSelect([]string{"Off", "Flat", "Speech", "Broadcast"}, nil)
 	n.EqDrop.SetSelected("Off")
-	eqRow := container.NewHBox(n.EqDrop, n.EqLabel)
+	eqRow := container.NewHBox(n.EqDrop, n.EqLabel, n.hintButton("eq"))
 
 	n.bypassProc = widget.NewCheck("Bypass all processing", func(checked bool) {
 		if checked {
 			n.dynamicsDrop.Disable()
 			n.EqDrop.Disable()
+			n.speechnormDrop.Disable()
+			n.restorationCheck.Disable()
+			n.rumbleCheck.Disable()
+			n.channelAlignCheck.Disable()
 		} else {
 			n.dynamicsDrop.Enable()
 			n.EqDrop.Enable()
+			n.speechnormDrop.Enable()
+			n.restorationCheck.Enable()
+			n.rumbleCheck.Enable()
+			n.channelAlignCheck.Enable()
 		}
+		n.refreshChainDiagram()
 	})
 
 	n.dynNorm = widget.NewCheck("", nil)
 	n.dynNormLabel = widget.NewLabel("Use dynamic normalization")
-	dynNormRow := container.NewHBox(n.dynNorm, n.dynNormLabel)
-
-	processTab := container.NewVBox(dynamicsRow, eqRow, dynNormRow, widget.NewSeparator(), n.bypassProc)
+	dynNormRow := container.NewHBox(n.dynNorm, n.dynNormLabel, n.hintButton("dynamic-normalization"))
+
+	n.speechnormDrop = widget.NewSelect(audio.SpeechnormPresets, nil)
+	n.speechnormDrop.SetSelected("Off")
+	speechnormRow := container.NewHBox(n.speechnormDrop, widget.NewLabel("Speech normalization"), n.hintButton("speechnorm"))
+
+	n.restorationCheck = widget.NewCheck("Restoration (declick, declip, denoise)", nil)
+	n.rumbleCheck = widget.NewCheck("Rumble filter (below 25 Hz)", nil)
+	n.channelAlignCheck = widget.NewCheck("Correct azimuth and channel balance", nil)
+
+	refreshChain := func(string) { n.refreshChainDiagram() }
+	n.dynamicsDrop.OnChanged = refreshChain
+	n.EqDrop.OnChanged = refreshChain
+	n.speechnormDrop.OnChanged = refreshChain
+	n.dynNorm.OnChanged = func(bool) { n.refreshChainDiagram() }
+	n.restorationCheck.OnChanged = func(bool) { n.refreshChainDiagram() }
+	n.rumbleCheck.OnChanged = func(bool) { n.refreshChainDiagram() }
+	n.channelAlignCheck.OnChanged = func(bool) { n.refreshChainDiagram() }
+
+	processTab := container.NewVBox(
+		container.NewHBox(n.restorationCheck, n.hintButton("restoration")),
+		container.NewHBox(n.rumbleCheck, n.channelAlignCheck, n.hintButton("digitization")), dynamicsRow, eqRow, dynNormRow, speechnormRow, widget.NewSeparator(),
+		container.NewHBox(n.bypassProc, n.hintButton("bypass")),
+		widget.NewSeparator(),
+		container.NewHBox(widget.NewLabel("Processing chain for the current settings:"), n.hintButton("chain")),
+		n.buildChainDiagram(),
+	)
 
 	checkUpdateButton := widget.NewButton("Check for updates", func() {
 		go checkForUpdates(currentVersion, n.window, n.logFile)
 	})
 
-	helpBtn := widget.NewButton("Help", func() {
-
-			menuGettingStarted := widget.NewLabel(
-`TNT is designed for broadcast professionals to streamline audio workflows. The application provides three core capabilities:
-
-• Transcode - Convert between audio formats
-• Normalize - Ensure consistent loudness levels
-• Tag - Write ReplayGain metadata for playback guidance
-
-FAST MODE
-Simple mode offers three preset configurations for common use cases. Processing requires just four clicks, and files are processed individually in the background with results appearing in your output folder as they complete.
-
-ADVANCED MODE
-Advanced mode provides granular control over encoding parameters including format selection, sample rates, bit depths, and bitrates. You can configure custom loudness normalization targets or write ReplayGain tags instead of normalizing.
-
-Note: Normalization alters the audio data, while tagging only writes metadata. These options are mutually exclusive.
-
-WORKFLOW
-1. Select Files - Choose individual files, or Select Folder for batch processing
-2. Output Folder - Specify destination for processed files
-3. Configure settings in Fast or Advanced mode
-4. Click Process
-
-For more information visit https://www.fremen.fi/software/tnt and scroll to the bottom of the page.`)
-			menuGettingStarted.Wrapping = fyne.TextWrapWord
-
-			menuSimpleTab := widget.NewLabel(`
-SIMPLE MODE
-
-Simple mode provides three preset configurations optimized for common broadcast scenarios:
-
-• Small file (AAC 256kbps) - Compressed format balancing quality and file size
-• Most compatible (MP3 320kbps) - Universal playback support across all devices
-• Production (PCM 48kHz/24bit) - Uncompressed broadcast-quality audio
-
-Each preset handles format conversion with minimal configuration required. Simply select your desired output format from the three options.
-
-NORMALIZATION
-The 'Normalize' checkbox applies EBU R128 loudness normalization to your audio files. When enabled, all processed files will meet the -23 LUFS standard with -1 dBTP limiting, ensuring consistent playback levels across your content.
-
-WORKFLOW
-Processing in Simple mode requires just four clicks:
-1. Select your files or folder
-2. Choose output destination
-3. Pick a preset format
-4. Click Process
-
-The application processes files individually in the background. Completed files appear in your output folder as they finish, allowing you to continue working while processing continues.`)
-			menuSimpleTab.Wrapping = fyne.TextWrapWord
-
-			menuAdvancedTab := widget.NewLabel(
-`ADVANCED MODE
-
-Advanced mode provides granular control over all encoding parameters.
-
-FORMAT SELECTION
-Choose from AAC, Opus, MP3, PCM (Wave), or FLAC.
-
-Sample Rate: Available only for PCM (44.1 - 192 kHz)
-Bit Depth: Available only for PCM (16, 24, 32-float, 64-float)
-Bitrate: Available for AAC, Opus, and MP3 (12 kbps minimum, encoder-specific maximum)
-Compression Level: Available for FLAC and Opus (slider from 0-10)
-• 0 = no compression
-• 10 = most compression
-
-LOUDNESS TARGETS
-Target in LUFS and TP limit in dB control loudness processing for both normalization and ReplayGain tagging.
-
-Custom Loudness: When enabled, you can configure custom LUFS I and TP targets. Values are automatically converted to negative. When disabled, the system uses EBU R128 defaults (-23 LUFS, -1 dBTP) if Normalize is selected.
-
-PROCESSING OPTIONS
-
-Normalize: Applies loudness correction using the BS.1770-5 algorithm
-• Uses custom values if Custom Loudness is enabled
-• Uses EBU R128 standard if Custom Loudness is disabled
-• Alters the audio data to match target loudness
+	helpBtn := widget.NewButton("Help", n.showHelp)
 
-Write RG tags: Writes ReplayGain metadata to audio files
-• Uses custom values if Custom Loudness is enabled, otherwise EBU R128
-• Does not alter audio data, only writes metadata
-• Cannot be used with Normalize (mutually exclusive)
-• Cannot be used with PCM source files
-
-Do not transcode: Preserves original audio encoding while writing tags
-• Only available when Write RG tags is enabled
-• Does not alter audio data, only writes metadata
-• Cannot be used with PCM source files
-• Useful for adding metadata without re-encoding
-• Checking this box disables processing
-
-Speech: Optimizes encoding for voice content
-• Automatically selects Opus codec
-• Applies VoIP-optimized compression settings
-• Uses speech-specific normalization when combined with Normalize
-• Do not use with music content`)
-			menuAdvancedTab.Wrapping = fyne.TextWrapWord
-
-			menuFormatsTab := widget.NewLabel(
-`AUDIO FORMATS
-
-AAC (Advanced Audio Coding)
-AAC is a data compression method that at high bitrates can sound similar to a non-compressed file. In simple mode, the bitrate is set to 256 kbit/s, which gives very good results. The maximum bitrate for this encoder is 512 kbit/s. At 320 kbit/s the encoder tends to lose almost all of its encoding artifacts. Thirty seconds of audio encoded with 256 kbit/s results in approximately 1 MB filesize.
-
-Two AAC encoders are available depending on platform:
-• Fraunhofer FDK-AAC (all platforms) - Industry-standard reference encoder
-• Apple AudioToolbox AAC (macOS only) - Native hardware-accelerated encoder optimized for Apple Silicon
-
-Opus
-Opus is a modern data compression method that can achieve very good results even with lower bitrates. Opus has a lower algorithmic delay, which makes it suitable for live applications. It's an open-source format. Its minimum bitrate is 6 kbit/s, though the UI limits the bitrate at 12 kbit/s at minimum. The maximum bitrate for this encoder is 510 kbit/s.
-
-MP3 (MPEG-I Layer 3)
-MP3 is an older, but one of the most compatible encoders available. It isn't as capable at lower bitrates as the two encoders above, but at high bitrates (>320 kbit/s) it's usable. Use this if you know the end-user can't decode AAC or Opus. Filesize for MP3 at 320 kbit/s for 30 second audio file is 1.2 MB.
-
-FLAC (Free Lossless Audio Codec)
-FLAC is a lossless compression format that reduces file size without any quality loss. Unlike AAC, Opus, or MP3, FLAC preserves the original audio data perfectly while still achieving significant compression. File sizes are typically 40-60% of uncompressed PCM, depending on the compression level selected. FLAC is widely supported and ideal for archival or when perfect audio fidelity is required with reasonable file sizes.
-
-PCM (WAV)
-PCM, or WAV in this tool is a pulse-code modulated, raw uncompressed audio stream. It's the highest quality, but it comes with a size-cost. This encoder doesn't have a bitrate setting, but has two other settings that result in a bitrate. First, sample rate (either 44.1, 48, 88.2, 96, 192 kHz) means "how often the original data is converted into audio in a second". With 48 kHz the audio is sampled forty-eight thousand times in a second. Second, the bit depth controls "how precisely we want to have each sample". The options are either 16, 24, 32 or 64, of which the last two are floating-point and used in specific scenarios. The file size for a thirty-second audio with 48 kHz, 24-bit audio is 8.64 MB.`)
-			menuFormatsTab.Wrapping = fyne.TextWrapWord
-
-			menuProcessingTab := widget.NewLabel(
-`
-Setting 'Do not transcode' in the Advanced tab bypasses all processing.
-
-Dynamics processing
-Dynamics processing controls how TNT manages the volume variations in your audio. The software analyzes peak levels, average energy, and dynamic range before applying any processing. While designed for spoken content, dynamic processing may deliver pleasing results when used on music content. The first two presets are usually relatively transparent, with the last "Broadcast" preset being an aggressive multi-band compressor.
-
-TNT uses a Dynamic Scoring system in determining the characteristics of each compression preset. The user shall choose the style of compression, while the program decides what exact values are used within each preset. The amount of compression will always increase when choosing a higher processing tier.
-
-Off No dynamics processing is applied. Use this when your audio is already properly compressed or when you need the original dynamics preserved.
-
-Light Gentle compression that reduces only the loudest peaks. The software identifies peak RMS levels and applies subtle compression with a 2.5:1 ratio. Attack and release times are set to preserve transients while smoothing out occasional loud moments. This preset maintains the natural character of your audio while preventing clipping.
-
-Light processing is appropriate for: well-recorded content that needs minimal adjustment, acoustic music where dynamics are intentional, and content where natural dynamics should be preserved.
-
-Using Light on a music track, whose Dynamic Score is 18.64, results in a new Dynamic Score of 17.33.
-
-Moderate Standard broadcast compression suitable for most content. TNT analyzes your audio's average RMS level and applies moderate compression with a 3.5:1 ratio. The software calculates makeup gain automatically based on how much compression is being applied, ensuring consistent output levels without manual adjustment.
-
-Moderate processing works for: podcasts, voice-overs, most music content, and general broadcast material that needs to sound consistent across different playback systems.
-
-For the same example track as in Light preset above, the new Dynamic Score is 14.58.
-
-Broadcast Aggressive multiband processing for maximum clarity and consistency. Instead of analyzing overall dynamics, the software splits your audio into five frequency bands (sub-bass, bass, low-mid, mid, and high) and analyzes each band independently. Each band receives compression tailored to its specific characteristics—bass frequencies get tighter control with longer attack times, while high frequencies receive faster compression to maintain clarity.
-
-The Broadcast preset uses adaptive ratios: bass content receives moderate compression (4.0:1 ratio) while high-frequency content gets more aggressive processing (up to 8.0:1 ratio). This ensures your audio maintains punch in the low end while achieving maximum intelligibility in the speech range. Attack and release times are frequency-dependent, ranging from 200ms in the bass to 100ms in the highs.
-
-Broadcast processing is designed for: radio content, streaming platforms with varied playback systems, content consumed on small speakers or mobile devices, and any situation where maximum loudness and clarity are required.
-
-Broadcasting preset will deliver varying results with music. For heavily compressed material (DS <9), distortion is likely to occur.
-
-For the same track as in two previous presets, the new Dynamic Score is 12.71
-
-EQ target curves
-EQ processing analyzes your audio's frequency response across ten octave-spaced bands from 50Hz to 12.8kHz+. The software measures RMS level, peak level, and crest factor for each band, then compares these measurements against professional target curves. All EQ adjustments use an attenuation-focused philosophy—corrections are calculated, then halved before application, with a maximum adjustment of ±10 dB. This conservative approach maintains audio quality while achieving broadcast standards. Equalization is designed to work with spoken content. It will delivery varying results when used with music.
-
-Off
-No equalization is applied.
-
-Flat
-Targets a pink noise curve, which naturally contains more energy in the bass frequencies (-3 dB per octave rise from 1kHz). The Flat preset attenuates frequencies that exceed this curve while leaving frequencies below the curve unchanged. This prevents excessive energy buildup in any frequency range while maintaining the natural tonal balance of your content.
-
-Flat EQ is appropriate for material that's already well-balanced, and situations where you want to prevent frequency buildup without imposing a specific tonal character.
-
-Speech
-Optimized for vocal clarity and intelligibility. The Speech preset boosts presence frequencies (1.6kHz-6.4kHz) where consonants and speech intelligibility live, while attenuating sub-bass rumble and reducing boxiness in the 200-400Hz range.
-
-Speech EQ is designed for: podcasts, voice-overs, audiobooks, conference recordings, interviews, and any content where vocal clarity is paramount.
-
-Broadcast
-Aggressive clarity enhancement for playback on small speakers, mobile devices, and varied listening environments. The Broadcast curve emphasizes midrange intelligibility even more strongly than Speech mode, with deeper cuts in the bass and more aggressive presence boost. This ensures your content cuts through on phone speakers, laptop audio, and car radios.
-
-Broadcast EQ is intended for: radio content, streaming platforms, mobile-first content, situations where playback systems are unknown, and any content that must remain intelligible on poor speakers.
-
-Bypass all processing
-When enabled, this checkbox disables both Dynamics and EQ processing regardless of their selected settings. Use this when you want loudness normalization only, without any dynamics control or tonal shaping. The Bypass option is useful for: testing how your audio sounds with normalization alone, A/B comparing processed versus unprocessed versions, or situations where you've already applied processing in your DAW and only need format conversion and loudness compliance.
-
-Processing order
-When multiple processing stages are enabled, TNT applies them in this order:
-
-EQ adjustments (if enabled)
-De-esser (automatically applied when EQ is active)
-Dynamic normalization
-Dynamics processing (if enabled)
-Loudness normalization (if enabled)
-This signal chain ensures frequency balance is corrected before dynamics processing, preventing the compressor from reacting to frequency imbalances. The de-esser removes harsh sibilance after EQ boosts but before compression, ensuring the compressor doesn't overreact to "s" sounds. Loudness normalization happens last, after all processing is complete, guaranteeing your target LUFS level is achieved accurately.
-
-Notes
-All processing happens at 192kHz sample rate internally to ensure intersample peak accuracy. For 16-bit PCM output, the software applies triangular dithering after all processing to minimize quantization artifacts. Multiband processing uses linear-phase crossover filters to prevent phase distortion between frequency bands.
-
-The adaptive nature of TNT's processing means two identical preset selections may produce different filter parameters depending on the input audio's characteristics. This is intentional — the software adjusts its processing based on what it measures, ensuring optimal results for each file rather than applying static presets that may not suit the content.
-`)
-		menuProcessingTab.Wrapping = fyne.TextWrapWord
-
-		menuWatchHelpTab := widget.NewLabel(
-`
-Watch mode automates repetitive processing tasks by monitoring a folder and automatically processing new files as they appear. For example, a newsdesk can configure TNT to watch their raw audio folder - whenever a reporter records new audio, TNT detects it within seconds and outputs the processed file to the specified destination. TNT must remain running (the window can be minimized or hidden).
-
-Watch mode uses your current UI settings. To change processing parameters, simply adjust the settings in the interface - all subsequent files will use the new configuration. Save your preferences to automatically restore your settings on startup.
-
-Watch mode only processes new files added after activation - it ignores existing files. To process a folder's current contents, select it via "Select Folder" first. Once complete, enable Watch mode to handle any newly added files.
-`)
-		menuWatchHelpTab.Wrapping = fyne.TextWrapWord
-
-			tabs := container.NewAppTabs(
-				container.NewTabItem("Getting started", container.NewScroll(menuGettingStarted)),
-				container.NewTabItem("Simple", container.NewScroll(menuSimpleTab)),
-				container.NewTabItem("Advanced", container.NewScroll(menuAdvancedTab)),
-				container.NewTabItem("Processing", container.NewScroll(menuProcessingTab)),
-				container.NewTabItem("Watcher", container.NewScroll(menuWatchHelpTab)),
-				container.NewTabItem("Audio formats", container.NewScroll(menuFormatsTab)),			)
-
-			tabs.SetTabLocation(container.TabLocationTop)
-
-		helpWindow := fyne.CurrentApp().NewWindow("Help")
-		helpWindow.SetContent(tabs)
-		helpWindow.Resize(fyne.NewSize(600, 400))
-		helpWindow.Show()
-	})
-
-	menuBtn := widget.NewButton("Menu", func() {
+	n.openMenu = func() {
 		n.menuMutex.Lock()
 		if n.menuWindow != nil {
 			n.menuMutex.Unlock()
@@ -642,6 +811,19 @@ Watch mode only processes new files added after activation - it ignores existing
 			lufsEntry,
 			widget.NewLabel("Custom TP target:"),
 			tpRow,
+			widget.NewSeparator(),
+			widget.NewLabel("When the gain can't fit under the TP target in linear mode:"),
+			n.loudnormFallback,
+			widget.NewSeparator(),
+			n.loudnormDualMonoCheck,
+			widget.NewLabel("Offset gain when loudnorm runs in dynamic mode:"),
+			n.loudnormOffsetDrop,
+			n.loudnormOffsetEntry,
+			widget.NewSeparator(),
+			widget.NewLabel("Measure loudness to:"),
+			n.measurementStandardDrop,
+			widget.NewSeparator(),
+			n.anchorMarkersCheck,
 		)
 
 		// Create save button content
@@ -687,12 +869,23 @@ Preferences aren't saved automatically.
 		)
 		})
 
+		bundleText := widget.NewLabel("Export all preferences, including watch mode and metadata settings, together with the presets into one file. Import it on another edit suite to set it up identically.")
+		bundleText.Wrapping = fyne.TextWrapWord
+
 		saveContent := container.NewVBox(
 			saveContentText,
 			widget.NewSeparator(),
 			saveBtn,
 			widget.NewSeparator(),
 			userFactoryResetBtn,
+			widget.NewSeparator(),
+			bundleText,
+			container.NewHBox(
+				widget.NewButton("Export configuration", func() { n.showExportBundle(n.menuWindow) }),
+				widget.NewButton("Import configuration", func() { n.showImportBundle(n.menuWindow) }),
+			),
+			widget.NewSeparator(),
+			n.buildProfilesSection(),
 		)
 
 		versionUpdate := container.NewVBox(
@@ -711,10 +904,75 @@ Watch mode status is indicated by a text in the top left corner. If empty, watch
 
 		settingsWatchModeText.Wrapping = fyne.TextWrapWord
 
+		settingsWatchMonitorText := widget.NewLabel(`
+Measure only
+Watched files are only measured, never processed. Loudness, true peak and LRA are appended to tnt_loudness_monitor.csv in the output folder, or in the TNT settings folder when no output folder is selected. Files further than 1 LU from the target, or over the TP target, are marked out of spec.
+			`)
+
+		settingsWatchMonitorText.Wrapping = fyne.TextWrapWord
+
+		settingsWatchQuietText := widget.NewLabel(`
+Arriving files
+A file copied in from a network share appears in the watched folder as soon as the copy starts. TNT waits until its size has stayed the same for this many seconds before queueing it. Slow shares that pause mid-copy need a longer wait. With the check below, the file is also only queued once its duration can be read and its last second decodes. Off queues files as soon as they appear.
+			`)
+
+		settingsWatchQuietText.Wrapping = fyne.TextWrapWord
+
+		settingsWatchRecursiveText := widget.NewLabel(`
+Subfolders
+Files dropped into subfolders of a watched folder are picked up as well, also in subfolders created while watching. The output goes into the same subfolders under the output folder, like a batch run of a folder. Output folders inside the watched folder, quarantine folders and hidden folders are left out. Changes apply the next time watch mode starts.
+			`)
+
+		settingsWatchRecursiveText.Wrapping = fyne.TextWrapWord
+
+		settingsWatchRescanText := widget.NewLabel(`
+Re-check folder
+File system events can get lost when many files arrive at once. The watched folder is also re-checked on this interval and any file that was missed is queued. Files are only picked up after they have been left unchanged for a minute.
+			`)
+
+		settingsWatchRescanText.Wrapping = fyne.TextWrapWord
+
+		settingsWatchPaceText := widget.NewLabel(`
+Speed limit
+Watched files are processed no faster than this many times realtime, so a machine in the studio stays quiet instead of spinning up its fans. At 4, an hour-long show takes at least 15 minutes. The measurement passes before processing still run at full speed. Off processes as fast as the machine allows.
+			`)
+
+		settingsWatchPaceText.Wrapping = fyne.TextWrapWord
+
+		settingsWatchQuarantineText := widget.NewLabel(`
+Failed files
+A watched file that fails to process is tried again two minutes later, in case it was still arriving. Once it is out of retries it can be moved or copied into a quarantine folder inside the watched folder, with an .error.txt report beside it, so whoever delivered it can see what went wrong.
+			`)
+
+		settingsWatchQuarantineText.Wrapping = fyne.TextWrapWord
+
 		settingsWatchMode := container.NewVBox(
 			settingsWatchModeText,
 			widget.NewSeparator(),
 			n.watchMode,
+			widget.NewSeparator(),
+			settingsWatchMonitorText,
+			n.watchMeasureOnly,
+			n.watchAlertCheck,
+			widget.NewSeparator(),
+			settingsWatchQuietText,
+			container.NewHBox(widget.NewLabel("Wait for (seconds)"), n.watchQuietDrop),
+			n.watchProbeCheck,
+			widget.NewSeparator(),
+			settingsWatchRecursiveText,
+			n.watchRecursiveCheck,
+			widget.NewSeparator(),
+			settingsWatchRescanText,
+			container.NewHBox(widget.NewLabel("Re-check every (minutes)"), n.watchRescanDrop),
+			widget.NewSeparator(),
+			settingsWatchPaceText,
+			container.NewHBox(widget.NewLabel("Limit to (× realtime)"), n.watchPaceDrop),
+			widget.NewSeparator(),
+			settingsWatchQuarantineText,
+			container.NewHBox(widget.NewLabel("Retries"), n.watchRetryDrop),
+			container.NewHBox(widget.NewLabel("Then"), n.watchQuarantineDrop, widget.NewLabel("the file to quarantine")),
+			widget.NewSeparator(),
+			n.buildServiceSection(),
 		)
 
 		settingsFunctionsTabText := widget.NewLabel(`
@@ -735,14 +993,445 @@ Check this if you wish to automatically check for the mono compatibility of the
 			n.checkPhaseBtn,
 		)
 
+		functionsBadgeText := widget.NewLabel(`
+Loudness badge
+Check this to render a small PNG badge next to every output file. The badge shows the target loudness, the measured output loudness, the true peak and the processing date, ready for use in web CMS listings.
+		`)
+
+		functionsBadgeText.Wrapping = fyne.TextWrapWord
+
+		badgeTab := container.NewVBox(
+			functionsBadgeText,
+			n.loudnessBadgeCheck,
+		)
+
+		functionsLoudnessReportText := widget.NewLabel(`
+Loudness report
+Choose a format to write a compliance report next to every output file, as proof of EBU R128 delivery. The finished output is measured again and the report lists its integrated loudness, loudness range, maximum true peak, maximum momentary and short-term loudness, whether it is within 1 LU of the target and under the true peak limit, a chart of its loudness over time, and the TNT and FFmpeg versions and settings it was made with. The HTML report opens in any browser, the PDF is a single A4 page for archiving.
+		`)
+
+		functionsLoudnessReportText.Wrapping = fyne.TextWrapWord
+
+		loudnessReportTab := container.NewVBox(
+			functionsLoudnessReportText,
+			widget.NewForm(widget.NewFormItem("Loudness report:", n.loudnessReportDrop)),
+		)
+
+		functionsChannelText := widget.NewLabel(`
+Channel mapping
+Check this to inspect stereo files before processing. Dual-mono files (both channels identical) are folded to true mono. One-sided files (one channel silent, typical of field recorders) get the populated channel folded to mono or copied to both sides. Choose Ask to confirm each fix.
+		`)
+
+		functionsChannelText.Wrapping = fyne.TextWrapWord
+
+		channelTab := container.NewVBox(
+			functionsChannelText,
+			n.channelCheck,
+			n.channelFixDrop,
+		)
+
+		functionsQualityText := widget.NewLabel(`
+Quality score
+Check this to compare every lossy output (AAC, Opus, MP3) with the processed audio it was encoded from. The log shows the spectral distance in dB (lower is closer to the source) and the signal-to-noise ratio. Use it to compare codecs and bitrates on your own material. Only the first two minutes of each file are compared.
+		`)
+
+		functionsQualityText.Wrapping = fyne.TextWrapWord
+
+		qualityTab := container.NewVBox(
+			functionsQualityText,
+			n.qualityScoreCheck,
+		)
+
+		functionsAuditText := widget.NewLabel(`
+Audit trail
+Every processed file is recorded in processing_history.csv in the TNT settings folder, with the computer account, the operator name, the profile, the settings used and the result. The operator name can be asked at startup or given with -operator <name>. Sidecars put the same record in a .audit.json file next to each output. The History tab of the menu lists the records with a QC state and notes per file.
+		`)
+
+		functionsAuditText.Wrapping = fyne.TextWrapWord
+
+		auditTab := container.NewVBox(
+			functionsAuditText,
+			n.askOperatorCheck,
+			n.auditSidecarCheck,
+			widget.NewButton("Change operator", n.askOperator),
+		)
+
+		functionsZipText := widget.NewLabel(`
+ZIP archives
+ZIP files added to the queue, found in a selected folder or dropped into a watched folder are unpacked to a temporary folder and their audio files are processed. Outputs go to the output folder, or with the option below to a folder named after the archive that keeps its internal folders.
+		`)
+
+		functionsZipText.Wrapping = fyne.TextWrapWord
+
+		zipTab := container.NewVBox(
+			functionsZipText,
+			n.zipKeepFoldersCheck,
+		)
+
 		watchModeTab := container.NewVBox(
 			settingsWatchModeText,
 			n.watchMode,
 		)
 
+		functionsMusicLibText := widget.NewLabel(`
+Music library
+Levels songs with ReplayGain track gain tags instead of changing the audio, so players can crossfade between tracks at matched loudness. The gain to the loudness target can be capped, so quiet tracks aren't pushed up too far. TNT also checks where each track's peak lands once the gain is applied: tracks that would go over the TP target are either lowered until they fit, or only flagged. Capped, lowered and clipping tracks are listed in music_library_review.csv in the output folder for the music director to review.
+		`)
+
+		functionsMusicLibText.Wrapping = fyne.TextWrapWord
+
+		musicLibTab := container.NewVBox(
+			functionsMusicLibText,
+			n.musicLibCheck,
+			widget.NewForm(widget.NewFormItem("Maximum gain (dB)", n.maxTrackGainEntry)),
+			n.preventClipCheck,
+		)
+
+		functionsCDText := widget.NewLabel(`
+CD rips
+Some CDs were mastered with pre-emphasis, a treble boost the player is meant to undo. TNT looks for the pre-emphasis flag in the file's tags, in a cue sheet embedded in a FLAC file and in a .cue file next to the rip, and applies de-emphasis before any other processing. The ISRC and catalog number found in the same places are written into the outputs.
+		`)
+
+		functionsCDText.Wrapping = fyne.TextWrapWord
+
+		cdTab := container.NewVBox(
+			functionsCDText,
+			n.keepEmphasisCheck,
+		)
+
+		functionsFolderScanText := widget.NewLabel(`
+Folder scan
+Filters applied when adding a folder with Select Folder, to leave out jingles, stray temp files or material that was already processed. Durations are written as seconds, mm:ss or hh:mm:ss. Skipped files are listed in the log file.
+		`)
+
+		functionsFolderScanText.Wrapping = fyne.TextWrapWord
+
+		folderScanTab := container.NewVBox(
+			functionsFolderScanText,
+			widget.NewForm(
+				widget.NewFormItem("Skip files shorter than", n.folderMinEntry),
+				widget.NewFormItem("Skip files longer than", n.folderMaxEntry),
+				widget.NewFormItem("Skip files modified before", n.folderSinceEntry),
+			),
+			n.folderSkipHiddenCheck,
+		)
+
+		functionsIncrementalText := widget.NewLabel(`
+Incremental runs
+For recurring runs over a whole library. TNT remembers the content of every file it processed successfully together with the settings used, and skips files whose content and settings both match an earlier run whose output still exists. New, edited, moved and renamed files are processed as usual, as are files whose output was deleted. Touching a file without changing its content doesn't make it count as changed.
+		`)
+
+		functionsIncrementalText.Wrapping = fyne.TextWrapWord
+
+		forgetRunsBtn := widget.NewButton("Forget earlier runs", func() {
+			if err := n.batchHistory.clear(); err != nil {
+				dialog.ShowError(err, n.menuWindow)
+				return
+			}
+			dialog.ShowInformation("Incremental runs", "The next run processes every file again.", n.menuWindow)
+		})
+
+		incrementalTab := container.NewVBox(
+			functionsIncrementalText,
+			n.incrementalCheck,
+			forgetRunsBtn,
+		)
+
+		functionsNotifyText := widget.NewLabel(`
+Notifications
+Plays a sound on the default output device when a batch finishes, and when a watched file fails, is refused or is skipped. The built-in sounds are generated on first use. A custom sound must be a WAV file. On Linux the sound is played with paplay or aplay when either is installed. The badge shows the batch result on the Dock icon on macOS; Windows flashes the taskbar button instead. It clears when TNT is brought to the front.
+		`)
+
+		functionsNotifyText.Wrapping = fyne.TextWrapWord
+
+		browseSoundBtn := widget.NewButton("Choose WAV", func() {
+			open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil || reader == nil {
+					return
+				}
+				n.notifySoundFileEntry.SetText(reader.URI().Path())
+				reader.Close()
+			}, n.menuWindow)
+			open.SetFilter(storage.NewExtensionFileFilter([]string{".wav"}))
+			open.Show()
+		})
+
+		testSoundBtn := widget.NewButton("Test", func() {
+			go func() {
+				if err := n.playNotifySound(); err != nil {
+					fyne.Do(func() {
+						dialog.ShowError(err, n.menuWindow)
+					})
+				}
+			}()
+		})
+
+		notifyTab := container.NewVBox(
+			functionsNotifyText,
+			widget.NewForm(
+				widget.NewFormItem("Sound", n.notifySoundDrop),
+				widget.NewFormItem("Custom sound", container.NewBorder(nil, nil, nil, browseSoundBtn, n.notifySoundFileEntry)),
+			),
+			testSoundBtn,
+			n.notifyBadgeCheck,
+		)
+
+		functionsBWFText := widget.NewLabel(`
+BWF metadata
+Broadcast WAVs from field recorders and editors carry a bext chunk with a description and coding history, and often an iXML chunk with project, scene and take. The info button next to a queued file shows them. With hints on, a file whose description, note, track names or coding history mark it as speech (SPEECH, dialogue, voice-over) gets the speech chain: Opus speech mode, Medium speech normalization unless one is set, and the Speech EQ curve when EQ is on. Files with a scene or take are named with the template below, unless a preset sets its own.
+		`)
+
+		functionsBWFText.Wrapping = fyne.TextWrapWord
+
+		bwfTemplateHelp := widget.NewLabel(filenameTemplateHelp)
+		bwfTemplateHelp.Wrapping = fyne.TextWrapWord
+
+		bwfTab := container.NewVBox(
+			functionsBWFText,
+			n.bwfHintsCheck,
+			widget.NewForm(
+				widget.NewFormItem("Filename template", n.bwfTemplateEntry),
+			),
+			bwfTemplateHelp,
+		)
+
+		functionsPreviewText := widget.NewLabel(`
+Preview copies
+For copies sent outside the station for approval or promotion. Next to every output TNT encodes a preview copy as MP3, named with .preview, from the same processed audio. The copy carries either a watermark tone at a low level or an ident mixed in at its own level, starting at the offset below. The clean output for air is produced as usual.
+		`)
+
+		functionsPreviewText.Wrapping = fyne.TextWrapWord
+
+		chooseIdentBtn := widget.NewButton("Choose", func() {
+			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil || reader == nil {
+					return
+				}
+				n.previewIdentEntry.SetText(reader.URI().Path())
+				reader.Close()
+			}, n.menuWindow)
+		})
+
+		previewTab := container.NewVBox(
+			functionsPreviewText,
+			n.previewCopyCheck,
+			widget.NewForm(
+				widget.NewFormItem("Mark", n.previewMarkDrop),
+				widget.NewFormItem("Starts at", n.previewOffsetEntry),
+				widget.NewFormItem("Watermark level (dBFS)", n.previewLevelEntry),
+				widget.NewFormItem("Ident", container.NewBorder(nil, nil, nil, chooseIdentBtn, n.previewIdentEntry)),
+			),
+		)
+
+		functionsWebText := widget.NewLabel(`
+Web version
+With Make web version too checked in the Advanced view, every normalized output gets a second file named with .web, normalized to the target below for streaming and podcast platforms. Both come from the same processed audio and the same loudness measurement, so the web version costs one extra encode. A gain trim or dialogue anchor moves both targets alike.
+		`)
+
+		functionsWebText.Wrapping = fyne.TextWrapWord
+
+		webTab := container.NewVBox(
+			functionsWebText,
+			widget.NewForm(
+				widget.NewFormItem("Target in LUFS", n.webTargetEntry),
+				widget.NewFormItem("TP limit in dB", n.webTargetTpEntry),
+				widget.NewFormItem("Format", n.webFormatDrop),
+			),
+		)
+
+		functionsQCText := widget.NewLabel(`
+QC comparison
+To check TNT's measurements against the analyzer already in use, export its measurements of the queued files as CSV, for example from a Dolby or Nugen batch analysis. The export needs a header row with the file name and integrated loudness; true peak and loudness range are compared when present. Files are matched by name, ignoring folder and extension. TNT measures each matched file and flags those that differ by more than the tolerance, loudness range by more than twice it. The comparison is written next to the export as a CSV.
+		`)
+
+		functionsQCText.Wrapping = fyne.TextWrapWord
+
+		compareQCBtn := widget.NewButton("Compare with QC export", func() {
+			open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil || reader == nil {
+					return
+				}
+				path := reader.URI().Path()
+				reader.Close()
+
+				external, warnings, err := loadQCMeasurements(path)
+				if err != nil {
+					dialog.ShowError(err, n.menuWindow)
+					return
+				}
+
+				n.logStatus(fmt.Sprintf("Imported QC measurements for %d files from %s", len(external), filepath.Base(path)))
+				for _, w := range warnings {
+					n.logStatus(fmt.Sprintf("⚠ QC export %s", w))
+				}
+				go n.compareQC(path, external)
+			}, n.menuWindow)
+			open.SetFilter(storage.NewExtensionFileFilter([]string{".csv", ".txt"}))
+			open.Show()
+		})
+
+		qcTab := container.NewVBox(
+			functionsQCText,
+			widget.NewForm(
+				widget.NewFormItem("Tolerance (LU/dB)", n.qcToleranceEntry),
+			),
+			compareQCBtn,
+		)
+
+		functionsLongFileText := widget.NewLabel(`
+Long files
+Processing stages are chained into the final encode instead of each being rendered to a 192 kHz intermediate in the temp folder, about 11 GB per stage and hour of stereo. Every analysis reads the file through the stages before it. A file with a dialogue anchor still gets intermediates, so the anchor is measured on the processed audio, and "Render stages to temp files" brings them back for every file.
+Logger recordings and other files many hours long don't fit in memory when measured the usual way. In long-file mode, files at least as long as set below have their loudness measured in one streaming pass whose memory use doesn't grow with the file. A dialogue anchor can't be used with processing stages in this mode.
+		`)
+
+		functionsLongFileText.Wrapping = fyne.TextWrapWord
+
+		longFileTab := container.NewVBox(
+			functionsLongFileText,
+			n.longFileCheck,
+			widget.NewForm(
+				widget.NewFormItem("From length (hours)", n.longFileHoursEntry),
+			),
+			n.stageTempFilesCheck,
+		)
+
+		functionsPerformanceText := widget.NewLabel(`
+Performance
+A batch processes several files at once, by default one per processor core but one, and every FFmpeg run uses all the cores it can. On a workstation that is used during large batches, process fewer files at once and limit the threads of each run. Low priority lowers every FFmpeg run below other programs, so the machine stays responsive and the batch takes the CPU time that is left. Changes apply to the runs started afterwards, the number of files to the next batch.
+		`)
+
+		functionsPerformanceText.Wrapping = fyne.TextWrapWord
+
+		performanceTab := container.NewVBox(
+			functionsPerformanceText,
+			widget.NewForm(
+				widget.NewFormItem("Files at once", n.workersEntry),
+				widget.NewFormItem("FFmpeg threads per run", n.ffmpegThreadsEntry),
+			),
+			n.lowPriorityCheck,
+		)
+
+		functionsSeriesText := widget.NewLabel(`
+Series drift
+Name the series you process and the filename pattern of their episodes, one per line as name = pattern, where * matches any text. Each processed episode's output is measured for loudness, loudness range and the balance between five frequency bands. Once a series has three earlier episodes, an episode that is more than 1 LU off their average loudness or range, or has a band more than 3 dB off, is flagged. That usually means a producer changed their microphone, plugins or mix. Every episode goes into series_report.csv in the TNT settings folder.
+		`)
+
+		functionsSeriesText.Wrapping = fyne.TextWrapWord
+
+		seriesTab := container.NewVBox(
+			functionsSeriesText,
+			n.seriesEntry,
+			n.seriesAlertCheck,
+			widget.NewButton("Open series report", n.openSeriesReport),
+		)
+
+		functionsBatchReportText := widget.NewLabel(`
+Batch report
+When a batch finishes, TNT writes tnt_report_<date>-<time>.json and .csv into the output folder for traffic and scheduling systems to read. Every input file gets a row with its measured loudness, true peak and loudness range, the gain normalization applied or the ReplayGain tagged, its Dynamics Score, the output path and codec, and whether it succeeded, failed, was skipped or was unchanged since the last run. Values a file's settings didn't measure are left empty. Loudness is measured after processing, just before normalization.
+		`)
+
+		functionsBatchReportText.Wrapping = fyne.TextWrapWord
+
+		batchReportTab := container.NewVBox(
+			functionsBatchReportText,
+			n.batchReportCheck,
+		)
+
+		functionsResampleText := widget.NewLabel(`
+Resampling
+Opus only encodes at 48, 24, 16, 12 or 8 kHz and TNT writes it at 48 kHz. A 44.1 kHz source, or one processed at 192 kHz, is converted before encoding at the quality chosen below, and the conversion is logged. Standard is FFmpeg's own resampler, High and Very high use the SoX resampler, Very high at its highest precision for masters that go on to further processing. When the chosen sample rate or a recording's original rate in its BWF metadata is one Opus can't carry, a warning says so.
+		`)
+
+		functionsResampleText.Wrapping = fyne.TextWrapWord
+
+		resampleTab := container.NewVBox(
+			functionsResampleText,
+			widget.NewForm(
+				widget.NewFormItem("Conversion quality", n.srcQualityDrop),
+			),
+		)
+
+		functionsDedupeText := widget.NewLabel(`
+Duplicate finder
+Finds the same programme stored more than once in an output library, for example delivered twice under different names over years of watch mode. Every audio file is fingerprinted from its first two minutes, so copies in other formats, bitrates or levels, or cut a little differently, are still found. Groups are listed in duplicate_audio.csv in the scanned folder with the largest copy suggested for keeping. Nothing is deleted. Fingerprints are kept between scans, so rescanning the same library is quick.
+		`)
+
+		functionsDedupeText.Wrapping = fyne.TextWrapWord
+
+		var dedupeBtn *widget.Button
+		dedupeBtn = widget.NewButton("Choose library folder and scan", func() {
+			dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+				if err != nil || uri == nil {
+					return
+				}
+				dedupeBtn.Disable()
+				go func() {
+					defer fyne.Do(dedupeBtn.Enable)
+					n.findDuplicates(uri.Path())
+				}()
+			}, n.menuWindow)
+		})
+
+		dedupeTab := container.NewVBox(
+			functionsDedupeText,
+			dedupeBtn,
+		)
+
+		functionsClipListText := widget.NewLabel(`
+Clip lists
+Cuts ranges out of long recordings and processes each as its own output with the current settings, for example the clips of a weekly show taken from the raw recording. The list is a CSV or simple EDL with the columns file, in, out and output name, one clip per line. Files are found relative to the list, in and out points are seconds, mm:ss or hh:mm:ss. Outputs are named as listed and go to the output folder. A clip without a name is named after its recording. Lines starting with # are skipped.
+		`)
+
+		functionsClipListText.Wrapping = fyne.TextWrapWord
+
+		var clipListBtn *widget.Button
+		clipListBtn = widget.NewButton("Choose clip list and process", func() {
+			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil || reader == nil {
+					return
+				}
+				path := reader.URI().Path()
+				reader.Close()
+				clipListBtn.Disable()
+				go func() {
+					defer fyne.Do(clipListBtn.Enable)
+					n.processClipList(path)
+				}()
+			}, n.menuWindow)
+		})
+
+		clipListTab := container.NewVBox(
+			functionsClipListText,
+			clipListBtn,
+		)
+
 		settingsFunctionsTabs := container.NewAppTabs(
 			container.NewTabItem("Mono compatibility check", phaseCheckTab),
 			container.NewTabItem("Watch mode", watchModeTab),
+			container.NewTabItem("Loudness badge", badgeTab),
+			container.NewTabItem("Loudness report", loudnessReportTab),
+			container.NewTabItem("Channel mapping", channelTab),
+			container.NewTabItem("Quality score", qualityTab),
+			container.NewTabItem("Bitrate ladder", n.buildLadderTab()),
+			container.NewTabItem("Audit trail", auditTab),
+			container.NewTabItem("ZIP archives", zipTab),
+			container.NewTabItem("Music library", musicLibTab),
+			container.NewTabItem("CD rips", cdTab),
+			container.NewTabItem("Folder scan", folderScanTab),
+			container.NewTabItem("Incremental runs", incrementalTab),
+			container.NewTabItem("Notifications", notifyTab),
+			container.NewTabItem("BWF metadata", bwfTab),
+			container.NewTabItem("Preview copies", previewTab),
+			container.NewTabItem("Web version", webTab),
+			container.NewTabItem("QC comparison", qcTab),
+			container.NewTabItem("Long files", longFileTab),
+			container.NewTabItem("Series drift", seriesTab),
+			container.NewTabItem("Batch report", batchReportTab),
+			container.NewTabItem("Duplicate finder", dedupeTab),
+			container.NewTabItem("Resampling", resampleTab),
+			container.NewTabItem("Shift report", n.buildShiftReportTab()),
+			container.NewTabItem("Clip lists", clipListTab),
+			container.NewTabItem("Performance", performanceTab),
 		)
 
 		/*
@@ -760,28 +1449,162 @@ Check this if you wish to automatically check for the mono compatibility of the
 		*/
 
 		settingsSendErrorReportText := widget.NewLabel(`
-Send an error report.
+Send an error report or a feature request. The message and, if you like, the end of the log go to TNT's support over HTTPS. Without a connection, TNT offers to open your email client with the log attached instead.
 			`)
 
 			settingsSendErrorReportText.Wrapping = fyne.TextWrapWord
 
 		sendLogReportBtn := widget.NewButton("Send report", func() {
-			n.sendLogReport()
+			n.showFeedbackForm()
 		})
 
+		diagnosticsText := widget.NewLabel(`
+Diagnostics
+Checks the FFmpeg binary, its encoders and filters, the temp and output folders, the connection to the update server and the folder watcher. Copy the report into a support ticket.
+			`)
+		diagnosticsText.Wrapping = fyne.TextWrapWord
+
+		diagnosticsBtn := widget.NewButton("Run diagnostics", func() {
+			n.showDiagnostics()
+		})
+
+		simulationText := widget.NewLabel(`
+Simulation
+Generates test signals, a tone, pink noise, speech-shaped noise, clipped material and phase-inverted stereo, and runs each through the full pipeline with fixed settings. Every output is checked for loudness, true peak and length, and the inverted signal must be caught by the phase check. A pass means this installation processes correctly, whatever the settings. Nothing is written outside the temp folder.
+			`)
+		simulationText.Wrapping = fyne.TextWrapWord
+
+		simulationBtn := widget.NewButton("Run simulation", n.showSimulation)
+
+		usageStatsText := widget.NewLabel(`
+Usage statistics
+Off unless you turn it on. TNT then counts which presets, codecs and processing chains your batches use and what kind of step failed, and sends the counts once a week, so the most used chains and codecs get optimized first. File names, folders, metadata, operator names and audio are never counted or sent. Preview shows exactly what would be sent now.
+			`)
+		usageStatsText.Wrapping = fyne.TextWrapWord
+
+		usagePreviewBtn := widget.NewButton("Preview", func() {
+			n.showUsagePreview(n.menuWindow)
+		})
+
+		logFilesText := widget.NewLabel(`
+Log files
+The log moves to a dated file at the start of each day and whenever it passes 10 MB. Only the newest rotated logs are kept, choose how many below.
+			`)
+		logFilesText.Wrapping = fyne.TextWrapWord
+
+		openLogFolderBtn := widget.NewButton("Open log folder", n.openLogFolder)
+
 		settingsSendErrorReport := container.NewVBox(
 			settingsSendErrorReportText,
 			widget.NewSeparator(),
 			sendLogReportBtn,
+			widget.NewSeparator(),
+			diagnosticsText,
+			diagnosticsBtn,
+			widget.NewSeparator(),
+			simulationText,
+			simulationBtn,
+			widget.NewSeparator(),
+			usageStatsText,
+			n.usageStatsCheck,
+			usagePreviewBtn,
+			widget.NewSeparator(),
+			logFilesText,
+			container.NewHBox(widget.NewLabel("Rotated logs to keep"), n.logRetentionDrop),
+			openLogFolderBtn,
 
 		)
 
+		presetsContent := n.buildPresetsTab()
+
+		metadataText := widget.NewLabel(`
+Station metadata
+These tags are written into every output file. Leave a field empty to skip it. Save the configuration to keep them for the next session.
+			`)
+		metadataText.Wrapping = fyne.TextWrapWord
+
+		tagTemplatesText := widget.NewLabel("Tag templates are written into every output as well, one tag per line, for example the title, artist, programme ID or comment a delivery needs. Placeholders are filled in for each file: " + tagTemplateHelp + ". Templates come after the station tags and before tags imported from a CSV, so a CSV row wins for its file. ReplayGain and other loudness tags can't be templated.")
+		tagTemplatesText.Wrapping = fyne.TextWrapWord
+
+		loudnessTagsHelp := widget.NewLabel("Strip removes them, Preserve copies them as they are, Update writes values that match the normalized output.")
+		loudnessTagsHelp.Wrapping = fyne.TextWrapWord
+
+		airDateText := widget.NewLabel("Some playout ingest folders order content by file modification time. Outputs can keep the processing time, take the source file's time or use the air date below. The air date is not saved with the configuration.")
+		airDateText.Wrapping = fyne.TextWrapWord
+
+		tagMapText := widget.NewLabel("Import a CSV with the columns filename, title, artist, programme ID and air date to tag a delivery in one pass. Rows are matched to queued files by name, ignoring folder and extension. An air date from the CSV replaces the one typed above for that file.")
+		tagMapText.Wrapping = fyne.TextWrapWord
+
+		importTagMapBtn := widget.NewButton("Import tag CSV", func() {
+			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil || reader == nil {
+					return
+				}
+				path := reader.URI().Path()
+				reader.Close()
+
+				mapping, warnings, err := loadTagMapping(path)
+				if err != nil {
+					dialog.ShowError(err, n.menuWindow)
+					return
+				}
+
+				n.mutex.Lock()
+				n.tagMap = mapping
+				n.mutex.Unlock()
+
+				n.tagMapLabel.SetText(n.tagMapSummary())
+				n.logStatus(fmt.Sprintf("Imported tags for %d files from %s", len(mapping), filepath.Base(path)))
+				for _, w := range warnings {
+					n.logStatus(fmt.Sprintf("⚠ Tag CSV %s", w))
+				}
+			}, n.menuWindow)
+		})
+
+		clearTagMapBtn := widget.NewButton("Clear", func() {
+			n.mutex.Lock()
+			n.tagMap = nil
+			n.mutex.Unlock()
+			n.tagMapLabel.SetText(n.tagMapSummary())
+		})
+
+		metadataContent := container.NewVBox(
+			metadataText,
+			widget.NewForm(
+				widget.NewFormItem("Publisher", n.metaPublisher),
+				widget.NewFormItem("Copyright", n.metaCopyright),
+				widget.NewFormItem("Encoded by", n.metaEncodedBy),
+			),
+			tagTemplatesText,
+			n.tagTemplatesEntry,
+			widget.NewSeparator(),
+			widget.NewLabel("Loudness tags already in the input (ReplayGain, R128):"),
+			n.loudnessTagsDrop,
+			loudnessTagsHelp,
+			widget.NewSeparator(),
+			airDateText,
+			widget.NewForm(
+				widget.NewFormItem("File time", n.timestampDrop),
+				widget.NewFormItem("Air date", n.airDateEntry),
+			),
+			n.airDateTagCheck,
+			widget.NewSeparator(),
+			tagMapText,
+			container.NewHBox(importTagMapBtn, clearTagMapBtn),
+			n.tagMapLabel,
+		)
+
 		tabs := container.NewAppTabs(
 			container.NewTabItem("Normalization", normContent),
 			container.NewTabItem("Save Configuration", saveContent),
+			container.NewTabItem("Presets", presetsContent),
+			container.NewTabItem("Metadata", metadataContent),
 			container.NewTabItem("Functions", settingsFunctionsTabs),
 			container.NewTabItem("Watch mode", settingsWatchMode),
+			container.NewTabItem("Watch folders", n.buildWatchFoldersTab()),
+			container.NewTabItem("History", n.buildHistoryTab()),
 			container.NewTabItem("Version upgrade", versionUpdate),
+			container.NewTabItem("License", n.buildLicenseTab()),
 			container.NewTabItem("Send error report", settingsSendErrorReport),
 		)
 
@@ -790,31 +1613,57 @@ Send an error report.
 		prefsWindow.Resize(fyne.NewSize(500, 400))
 
 		n.menuWindow = prefsWindow
+		n.menuMutex.Lock()
+		n.menuTabs, n.functionsTabs = tabs, settingsFunctionsTabs
+		n.menuMutex.Unlock()
 		prefsWindow.SetOnClosed(func() {
 			n.menuMutex.Lock()
 			n.menuWindow = nil
+			n.menuTabs, n.functionsTabs = nil, nil
 			n.menuMutex.Unlock()
 		})
 
 		prefsWindow.Show()
-	})
+	}
+
+	menuBtn := widget.NewButton("Menu", n.openMenu)
 
 	clearAllBtn := widget.NewButton("Clear all", func() {
 		n.mutex.Lock()
 		n.files = make([]string, 0)
+		n.gainTrims = nil
+		n.trims = nil
+		n.anchors = nil
 		n.mutex.Unlock()
-		n.fileList.Refresh()
-		n.updateProcessButton()
+		n.compliance.Clear()
+		n.queueRefresh.Trigger()
 		n.logStatus("Cleared all files from queue")
 	})
 
+	var scanLoudnessBtn *widget.Button
+	scanLoudnessBtn = widget.NewButton("Scan loudness", func() {
+		scanLoudnessBtn.Disable()
+		go func() {
+			defer fyne.Do(scanLoudnessBtn.Enable)
+			n.scanCompliance()
+		}()
+	})
+
 	previewSizeBtn := widget.NewButton("Preview Size", func() {
 		n.previewSize()
 	})
 
-	topButtons := container.NewHBox(selectFilesBtn, selectFolderBtn)
+	browseBtn := widget.NewButton("Browse", n.showBrowser)
+
+	topButtons := container.NewHBox(selectFilesBtn, selectFolderBtn, browseBtn)
 	outputSection := container.NewBorder(nil, nil, widget.NewLabel("Output:"), selectOutputBtn, n.outputLabel)
 
+	n.presetDrop = widget.NewSelect([]string{presetNone}, nil)
+	n.presetDrop.SetSelected(presetNone)
+	n.presetDrop.OnChanged = n.loadPreset
+	n.refreshPresetDrop()
+	presetSection := container.NewBorder(nil, nil, widget.NewLabel("Preset:"), nil, n.presetDrop)
+
 	topBar := container.NewHBox(helpBtn, menuBtn)
 
 	modeTabs := container.NewAppTabs(
@@ -824,16 +1673,18 @@ Send an error report.
 	)
 
 	n.modeTabs = modeTabs
+	modeTabs.OnSelected = func(*container.TabItem) { n.refreshChainDiagram() }
 
 	// Layout
 	settingsContainer := container.NewVBox(
-		n.watcherWarnLabel,
+		container.NewHBox(n.watcherWarnLabel, n.tapBtn, n.tapStopBtn),
 		logoImg,
 		topBar,
 		//n.modeToggle,
 		widget.NewSeparator(),
 		topButtons,
 		outputSection,
+		presetSection,
 		widget.NewSeparator(),
 		modeTabs,
 		//n.simpleGroup,
@@ -847,12 +1698,13 @@ Send an error report.
 		),
 		container.NewVBox(
 			n.progressBar,
-			container.NewPadded(container.NewHBox(n.processBtn, clearAllBtn, previewSizeBtn)),
+			n.etaLabel,
+			container.NewPadded(container.NewHBox(n.processBtn, n.quickBtn, n.pauseBtn, n.cancelBtn, clearAllBtn, previewSizeBtn)),
 		),
 		nil,
 		nil,
 		container.NewBorder(
-			widget.NewLabel("Files to process:"),
+			container.NewBorder(nil, nil, widget.NewLabel("Files to process:"), container.NewHBox(scanLoudnessBtn, n.hintButton("loudness-scan"))),
 			nil,
 			nil,
 			nil,
@@ -864,9 +1716,48 @@ Send an error report.
 	split.SetOffset(0.6)
 
 	n.window.SetContent(split)
+
+	n.paletteActions = []paletteCommand{
+		{"Add files", n.selectFiles},
+		{"Pause or resume the batch", n.pauseBatch},
+		{"Cancel the batch", n.cancelBatch},
+		{"Add folder", n.selectFolder},
+		{"Browse folders", n.showBrowser},
+		{"Choose output folder", n.selectOutputFolder},
+		{"Process queue", func() {
+			if !n.processBtn.Disabled() {
+				n.process()
+			}
+		}},
+		{"Quick process", func() {
+			if !n.quickBtn.Disabled() {
+				n.quickProcess()
+			}
+		}},
+		{"Clear queue", clearAllBtn.OnTapped},
+		{"Scan loudness of the queue", scanLoudnessBtn.OnTapped},
+		{"Preview output size", previewSizeBtn.OnTapped},
+		{"Start or stop watch mode", func() { n.watchMode.SetChecked(!n.watchMode.Checked) }},
+		{"Listen to the watched file", n.listenToWatched},
+		{"Open processing history", n.openProcessingHistory},
+		{"Show Fast tab", func() { modeTabs.SelectIndex(0) }},
+		{"Show Advanced tab", func() { modeTabs.SelectIndex(1) }},
+		{"Show Processing tab", func() { modeTabs.SelectIndex(2) }},
+		{"Open menu", n.openMenu},
+		{"Help", helpBtn.OnTapped},
+		{"Run diagnostics", n.showDiagnostics},
+		{"Run simulation", n.showSimulation},
+		{"Send feedback or error report", n.showFeedbackForm},
+		{"Check for updates", checkUpdateButton.OnTapped},
+	}
+	n.window.Canvas().AddShortcut(paletteShortcut, func(fyne.Shortcut) { n.showCommandPalette() })
 }
 
 func (n *AudioNormalizer) showConfirmDialog(title, message string) bool {
+	if n.headless {
+		n.logToFile(n.logFile, fmt.Sprintf("%s: answered no, nobody to ask", title))
+		return false
+	}
 	result := make(chan bool, 1)
 
 	fyne.Do(func() {
@@ -877,3 +1768,181 @@ func (n *AudioNormalizer) showConfirmDialog(title, message string) bool {
 
 	return <-result
 }
+
+// showFolderPrompt asks a yes/no question and, on yes, lets the user pick a folder.
+// Blocks the calling worker and returns the chosen path, or "" when declined.
+func (n *AudioNormalizer) showFolderPrompt(title, message string) string {
+	if n.headless {
+		return ""
+	}
+	result := make(chan string, 1)
+
+	fyne.Do(func() {
+		dialog.ShowConfirm(title, message, func(response bool) {
+			if !response {
+				result <- ""
+				return
+			}
+			dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+				if err != nil || uri == nil {
+					result <- ""
+					return
+				}
+				result <- uri.Path()
+			}, n.window)
+		}, n.window)
+	})
+
+	return <-result
+}
+
+// buildPresetsTab creates the Menu tab for saving presets and pinning them to the Fast tab
+func (n *AudioNormalizer) buildPresetsTab() fyne.CanvasObject {
+	presetsText := widget.NewLabel(`
+Presets
+Save the current Advanced and Processing settings, including the loudness target, under a name. Pin up to five presets to show them in the Fast tab. Every preset can be chosen from the Preset dropdown in the main window, which loads it into the Advanced and Processing tabs.
+Export a preset with its save button to give colleagues the same settings, they add it with Import presets. A configuration bundle can be imported here too, only its presets are taken.
+A preset can also carry its own output folder and filename template. Choosing it in the Fast tab then sends files straight to that folder instead of the main output folder.
+Analyzers are programs of your own run on each file before processing, one per line as name = program. TNT runs the program with the file path and reads a JSON object of metrics from its output, which goes into the audit trail. Start a line with gate to hold back files for which the program reports "pass": false, or that it fails on.
+	`)
+	presetsText.Wrapping = fyne.TextWrapWord
+
+	presets := n.presets.list()
+
+	var presetList *widget.List
+	reload := func() {
+		presets = n.presets.list()
+		presetList.Refresh()
+		n.refreshFastPresets()
+	}
+
+	presetList = widget.NewList(
+		func() int { return len(presets) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				container.NewHBox(
+					widget.NewCheck("Pin", nil),
+					widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), nil),
+					widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				),
+				widget.NewLabel("template"),
+			)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			preset := presets[i]
+			border := o.(*fyne.Container)
+			label := border.Objects[0].(*widget.Label)
+			buttons := border.Objects[1].(*fyne.Container)
+			pinCheck := buttons.Objects[0].(*widget.Check)
+			exportBtn := buttons.Objects[1].(*widget.Button)
+			deleteBtn := buttons.Objects[2].(*widget.Button)
+
+			text := fmt.Sprintf("%s (%s, %s LUFS)", preset.Name, preset.Format, preset.TargetI)
+			if preset.OutputDir != "" {
+				text += " → " + preset.OutputDir
+			}
+			if len(preset.Analyzers) > 0 {
+				text += fmt.Sprintf(", %d analyzers", len(preset.Analyzers))
+			}
+			label.SetText(text)
+
+			pinCheck.OnChanged = nil
+			pinCheck.SetChecked(preset.Pinned)
+			pinCheck.OnChanged = func(checked bool) {
+				if err := n.presets.setPinned(preset.Name, checked); err != nil {
+					dialog.ShowError(err, n.menuWindow)
+				}
+				reload()
+			}
+
+			exportBtn.OnTapped = func() {
+				n.showExportPreset(preset, n.menuWindow)
+			}
+
+			deleteBtn.OnTapped = func() {
+				dialog.ShowConfirm("Delete preset", fmt.Sprintf("Delete preset %s?", preset.Name), func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := n.presets.remove(preset.Name); err != nil {
+						dialog.ShowError(err, n.menuWindow)
+					}
+					reload()
+				}, n.menuWindow)
+			}
+		},
+	)
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Preset name, e.g. Radio news")
+
+	outputEntry := widget.NewEntry()
+	outputEntry.SetPlaceHolder("Output folder (empty uses the main output folder)")
+
+	outputBtn := widget.NewButton("Choose", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			outputEntry.SetText(uri.Path())
+		}, n.menuWindow)
+	})
+
+	templateEntry := widget.NewEntry()
+	templateEntry.SetPlaceHolder("Filename template, e.g. {name}_{preset}")
+
+	analyzersEntry := widget.NewMultiLineEntry()
+	analyzersEntry.SetPlaceHolder("Analyzers, e.g. gate profanity = /opt/station/profanity-check")
+	analyzersEntry.SetMinRowsVisible(2)
+
+	saveBtn := widget.NewButton("Save current settings as preset", func() {
+		preset := n.capturePreset(nameEntry.Text)
+		preset.OutputDir = strings.TrimSpace(outputEntry.Text)
+		preset.FilenameTemplate = strings.TrimSpace(templateEntry.Text)
+		analyzers, err := parseAnalyzerLines(analyzersEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		preset.Analyzers = analyzers
+		if err := n.presets.put(preset); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		nameEntry.SetText("")
+		outputEntry.SetText("")
+		templateEntry.SetText("")
+		analyzersEntry.SetText("")
+		reload()
+		n.updateProcessButton()
+	})
+
+	templateHelp := widget.NewLabel(filenameTemplateHelp)
+	templateHelp.Wrapping = fyne.TextWrapWord
+
+	replaceCheck := widget.NewCheck("Pinned presets replace the built-in Fast choices", func(checked bool) {
+		n.fastReplaceBuiltins = checked
+		n.refreshFastPresets()
+	})
+	replaceCheck.SetChecked(n.fastReplaceBuiltins)
+
+	importBtn := widget.NewButton("Import presets", func() {
+		n.showImportPresets(n.menuWindow, reload)
+	})
+
+	return container.NewBorder(
+		container.NewVBox(
+			presetsText,
+			container.NewBorder(nil, nil, nil, saveBtn, nameEntry),
+			container.NewBorder(nil, nil, nil, outputBtn, outputEntry),
+			templateEntry,
+			templateHelp,
+			analyzersEntry,
+			replaceCheck,
+			importBtn,
+			widget.NewSeparator(),
+		),
+		nil, nil, nil,
+		presetList,
+	)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+const updateBlockName = "update_block.json"
+
+// updateBlock is why this version may no longer process, from the version
+// feed. It is kept on disk so a machine that goes offline stays blocked
+// until it is updated.
+type updateBlock struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+var (
+	updateBlockMutex sync.Mutex
+	// blockedReason is "" while processing is allowed
+	blockedReason string
+)
+
+func updateBlockPath() string {
+	return filepath.Join(configRoot(), "TNT", updateBlockName)
+}
+
+// loadUpdateBlock restores a block the feed set for the running version.
+// A block left from an older version is dropped once TNT was updated.
+func loadUpdateBlock(currentVersion string) {
+	data, err := os.ReadFile(updateBlockPath())
+	if err != nil {
+		return
+	}
+	var block updateBlock
+	if json.Unmarshal(data, &block) != nil || block.Version != currentVersion {
+		os.Remove(updateBlockPath())
+		return
+	}
+	setUpdateBlock(currentVersion, block.Reason)
+}
+
+// updatePolicy returns why the feed retires the running version, or "".
+// Versions below the minimum are unsupported, and a critical release
+// retires every version before it.
+func updatePolicy(info VersionInfo, currentVersion string) string {
+	if info.MinimumVersion != "" && compareVersions(currentVersion, info.MinimumVersion) < 0 {
+		return fmt.Sprintf("TNT %s is no longer supported. Version %s or later is required to process files.", currentVersion, info.MinimumVersion)
+	}
+	if info.Critical && compareVersions(info.Version, currentVersion) > 0 {
+		return fmt.Sprintf("Version %s is a critical update. Processing is blocked until TNT is updated.", info.Version)
+	}
+	return ""
+}
+
+// setUpdateBlock blocks or, with an empty reason, unblocks processing and
+// keeps the state for the next start
+func setUpdateBlock(currentVersion, reason string) {
+	updateBlockMutex.Lock()
+	defer updateBlockMutex.Unlock()
+
+	blockedReason = reason
+	if reason == "" {
+		os.Remove(updateBlockPath())
+		return
+	}
+	data, err := json.Marshal(updateBlock{Version: currentVersion, Reason: reason})
+	if err != nil {
+		return
+	}
+	os.MkdirAll(filepath.Dir(updateBlockPath()), 0755)
+	os.WriteFile(updateBlockPath(), data, 0644)
+}
+
+// updateBlockReason returns why processing is blocked, "" when it isn't
+func updateBlockReason() string {
+	updateBlockMutex.Lock()
+	defer updateBlockMutex.Unlock()
+	return blockedReason
+}
+
+// blockedByUpdate tells the operator processing is blocked until TNT is
+// updated and reports whether it is
+func (n *AudioNormalizer) blockedByUpdate() bool {
+	reason := updateBlockReason()
+	if reason == "" {
+		return false
+	}
+	n.logStatus("⊗ " + reason)
+	fyne.Do(func() {
+		dialog.ShowInformation("Update Required", reason+"\n\nUse Check for updates to install the new version.", n.window)
+	})
+	return true
+}
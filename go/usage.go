@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	usageURL = "https://software.collinsgroup.fi/tnt-usage"
+	// usageInterval is how long counts are collected before they are sent
+	usageInterval = 7 * 24 * time.Hour
+)
+
+// failureCategories sort failure lines into the categories the statistics
+// count, first match wins. Only the category is kept, never the line.
+var failureCategories = []struct {
+	Match, Category string
+}{
+	{"analyzer", "analyzer"},
+	{"encoder killed", "encoder killed"},
+	{"measure", "measurement"},
+	{"phase check", "preflight"},
+	{"channel check", "preflight"},
+	{"restoration", "restoration"},
+	{"apply eq", "eq"},
+	{"frequency", "eq"},
+	{"dynaudnorm", "dynamics"},
+	{"dynamics", "dynamics"},
+	{"compression", "dynamics"},
+	{"speech normalization", "speech normalization"},
+	{"normalization", "loudness normalization"},
+	{"limiting", "loudness normalization"},
+	{"output", "output"},
+	{"watch mode", "watch mode"},
+}
+
+// usageReport is everything the statistics send. It has no file names,
+// folders, metadata, operator names or audio, and no installation ID.
+type usageReport struct {
+	Version  string         `json:"version"`
+	OS       string         `json:"os"`
+	Arch     string         `json:"arch"`
+	Since    string         `json:"since"`
+	Batches  int            `json:"batches"`
+	Files    int            `json:"files"`
+	Presets  map[string]int `json:"presets"`
+	Codecs   map[string]int `json:"codecs"`
+	Chains   map[string]int `json:"chains"`
+	Failures map[string]int `json:"failures"`
+}
+
+// usageStats counts usage between reports. Counting only happens while the
+// operator has opted in, and turning the setting off drops the counts.
+type usageStats struct {
+	mutex  sync.Mutex
+	path   string
+	Since  time.Time   `json:"since"`
+	Report usageReport `json:"pending"`
+}
+
+// loadUsageStats reads the counts not sent yet
+func loadUsageStats() *usageStats {
+	u := &usageStats{path: filepath.Join(dataDir(), "usage.json")}
+	if data, err := os.ReadFile(u.path); err == nil {
+		json.Unmarshal(data, u)
+	}
+	return u
+}
+
+// save writes the counts to disk, the caller holds the mutex
+func (u *usageStats) save() error {
+	if err := os.MkdirAll(filepath.Dir(u.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(u.path, data, 0644)
+}
+
+// count adds one to a key of a count map, starting the period on the first count
+func (u *usageStats) count(counts *map[string]int, key string, n int) {
+	if u.Since.IsZero() {
+		u.Since = time.Now()
+	}
+	if *counts == nil {
+		*counts = make(map[string]int)
+	}
+	(*counts)[key] += n
+}
+
+// reset drops the counts
+func (u *usageStats) reset() error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.Since = time.Time{}
+	u.Report = usageReport{}
+	return u.save()
+}
+
+// pending returns the report as it would be sent now
+func (u *usageStats) pending() usageReport {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	r := u.Report
+	r.Version = currentVersion
+	r.OS = runtime.GOOS
+	r.Arch = runtime.GOARCH
+	if !u.Since.IsZero() {
+		r.Since = u.Since.Format("2006-01-02")
+	}
+	return r
+}
+
+// usagePresetName is the preset a batch used. Only the built-in presets are
+// named, the operator's own show up as custom.
+func (n *AudioNormalizer) usagePresetName() string {
+	switch {
+	case n.advancedMode:
+		return "Advanced"
+	case slices.Contains(builtinFastPresets, n.simpleGroupButtons.Selected):
+		return n.simpleGroupButtons.Selected
+	default:
+		return "Custom preset"
+	}
+}
+
+// countBatch records the preset, codec and chain of a batch
+func (n *AudioNormalizer) countBatch(cfg ProcessConfig, files int) {
+	if !n.usageStatsCheck.Checked {
+		return
+	}
+
+	codec := cfg.Format
+	if cfg.noTranscode {
+		codec = "No transcode"
+	}
+	var stages []string
+	for _, stage := range n.pipelinePlan(cfg) {
+		stages = append(stages, stage.Name)
+	}
+	preset := n.usagePresetName()
+
+	u := n.usageStats
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.Report.Batches++
+	u.Report.Files += files
+	u.count(&u.Report.Presets, preset, files)
+	u.count(&u.Report.Codecs, codec, files)
+	u.count(&u.Report.Chains, strings.Join(stages, " > "), files)
+	if err := u.save(); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Usage statistics not saved: %v", err))
+	}
+}
+
+// countFailure records the category of a failure line
+func (n *AudioNormalizer) countFailure(message string) {
+	if n.usageStatsCheck == nil || !n.usageStatsCheck.Checked || !strings.HasPrefix(message, "✗") {
+		return
+	}
+
+	category := "other"
+	lower := strings.ToLower(message)
+	for _, c := range failureCategories {
+		if strings.Contains(lower, c.Match) {
+			category = c.Category
+			break
+		}
+	}
+
+	u := n.usageStats
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.count(&u.Report.Failures, category, 1)
+	if err := u.save(); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Usage statistics not saved: %v", err))
+	}
+}
+
+// sendUsageStats sends the counts once a period is over and starts a new one
+func (n *AudioNormalizer) sendUsageStats() {
+	if !n.usageStatsCheck.Checked {
+		return
+	}
+
+	u := n.usageStats
+	u.mutex.Lock()
+	due := !u.Since.IsZero() && time.Since(u.Since) >= usageInterval
+	u.mutex.Unlock()
+	if !due {
+		return
+	}
+
+	body, err := json.Marshal(u.pending())
+	if err != nil {
+		return
+	}
+	client := http.Client{Timeout: feedbackTimeout}
+	resp, err := client.Post(usageURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Usage statistics not sent: %v", err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		n.logToFile(n.logFile, fmt.Sprintf("Usage statistics not accepted: %s", resp.Status))
+		return
+	}
+
+	n.logToFile(n.logFile, "Usage statistics sent")
+	if err := u.reset(); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Usage statistics not reset: %v", err))
+	}
+}
+
+// showUsagePreview shows the exact report the statistics would send now
+func (n *AudioNormalizer) showUsagePreview(parent fyne.Window) {
+	data, err := json.MarshalIndent(n.usageStats.pending(), "", "  ")
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	preview := widget.NewMultiLineEntry()
+	preview.SetText(string(data))
+	preview.Disable()
+	preview.SetMinRowsVisible(16)
+
+	d := dialog.NewCustom("Usage statistics", "Close", preview, parent)
+	d.Resize(fyne.NewSize(460, 420))
+	d.Show()
+}
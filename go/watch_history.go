@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// watchRescanSettle is how long a file must be left unmodified before a
+// rescan picks it up, so files still being copied in are left to fsnotify
+const watchRescanSettle = time.Minute
+
+// watchRescanOptions are the rescan intervals offered in minutes
+var watchRescanOptions = []string{"Off", "1", "5", "15", "30", "60"}
+
+// watchPaceOptions are the processing speed limits offered, in multiples of realtime
+var watchPaceOptions = []string{"Off", "1", "2", "4", "8", "16"}
+
+// watchHistoryEntry identifies the version of a file that was handled
+type watchHistoryEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Handled time.Time `json:"handled"`
+	Result  string    `json:"result"`
+	// Attempts counts failures of this version of the file in a row
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// watchHistory records which files in the watched folder have been handled,
+// so a periodic rescan can enqueue what fsnotify missed without redoing work
+type watchHistory struct {
+	mutex   sync.Mutex
+	path    string
+	queued  map[string]bool
+	Entries map[string]watchHistoryEntry `json:"entries"`
+}
+
+// loadWatchHistory reads the stored history, starting empty when there is none
+func loadWatchHistory() *watchHistory {
+	h := &watchHistory{
+		path:    filepath.Join(dataDir(), "watch_history.json"),
+		queued:  make(map[string]bool),
+		Entries: make(map[string]watchHistoryEntry),
+	}
+
+	if data, err := os.ReadFile(h.path); err == nil {
+		json.Unmarshal(data, h)
+	}
+	if h.Entries == nil {
+		h.Entries = make(map[string]watchHistoryEntry)
+	}
+
+	return h
+}
+
+// save writes the history to disk, the caller holds the mutex
+func (h *watchHistory) save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0644)
+}
+
+// handled reports whether this version of the file was already handled or is waiting in the queue
+func (h *watchHistory) handled(path string, info os.FileInfo) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.queued[path] {
+		return true
+	}
+	e, ok := h.Entries[path]
+	return ok && e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// markQueued notes that a file is waiting in the job queue. Returns false if it already was.
+func (h *watchHistory) markQueued(path string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.queued[path] {
+		return false
+	}
+	h.queued[path] = true
+	return true
+}
+
+// record stores the outcome for a file and takes it off the queue. Returns
+// how many times in a row this version of the file has failed.
+func (h *watchHistory) record(path, result string) (int, error) {
+	info, err := os.Stat(platform.LongPath(path))
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.queued, path)
+	// A file blocked until TNT is updated is picked up again afterwards
+	if err != nil || result == "blocked" {
+		delete(h.Entries, path)
+		return 0, h.save()
+	}
+
+	attempts := 0
+	if watchFailed(result) {
+		attempts = 1
+		if old, ok := h.Entries[path]; ok && old.Size == info.Size() && old.ModTime.Equal(info.ModTime()) {
+			attempts = old.Attempts + 1
+		}
+	}
+	h.Entries[path] = watchHistoryEntry{
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Handled:  time.Now(),
+		Result:   result,
+		Attempts: attempts,
+	}
+	return attempts, h.save()
+}
+
+// reset clears the queue, used when watching stops and the queue is drained
+func (h *watchHistory) reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.queued = make(map[string]bool)
+}
+
+// baseline records the files of a watched folder, see watchedFiles, as
+// handled. Watch mode doesn't process files that existed before it started,
+// and neither may a rescan.
+func (h *watchHistory) baseline(files []watchedFile) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, f := range files {
+		path, info := f.path, f.info
+		if old, ok := h.Entries[path]; ok && old.Size == info.Size() && old.ModTime.Equal(info.ModTime()) {
+			continue
+		}
+		h.Entries[path] = watchHistoryEntry{
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			Handled: time.Now(),
+			Result:  "present at start",
+		}
+	}
+	return h.save()
+}
+
+// prune forgets files in dir that no longer exist, keeping the history small
+func (h *watchHistory) prune(dir string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	changed := false
+	for path := range h.Entries {
+		if !pathWithin(path, dir) {
+			continue
+		}
+		if _, err := os.Stat(platform.LongPath(path)); os.IsNotExist(err) {
+			delete(h.Entries, path)
+			changed = true
+		}
+	}
+	if changed {
+		h.save()
+	}
+}
+
+// enqueueWatched puts a file on the watch queue unless it's already waiting there.
+// Returns false if watching stopped while waiting for room in the queue.
+func (n *AudioNormalizer) enqueueWatched(path string) bool {
+	// Never feed TNT's own outputs back in, wherever the output folder is
+	if n.inOutputTree(path) {
+		return true
+	}
+	if !n.watchHistory.markQueued(path) {
+		return true
+	}
+	select {
+	case n.jobQueue <- path:
+		return true
+	case <-n.watcherStop:
+		return false
+	}
+}
+
+// recordWatched stores the outcome of a watched file in the history and
+// retries or quarantines it when it failed
+func (n *AudioNormalizer) recordWatched(path, result string) {
+	lines := n.watchFailures.end()
+	attempts, err := n.watchHistory.record(path, result)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Watch history write failed: %v", err))
+	}
+	if result != "processed" && result != "measured" {
+		n.notifyOperator("!")
+	}
+	if attempts > 0 {
+		n.retryOrQuarantine(path, result, attempts, lines)
+	}
+}
+
+// watchRescanInterval returns the configured rescan interval, 0 when rescans are off
+func (n *AudioNormalizer) watchRescanInterval() time.Duration {
+	return rescanInterval(n.watchRescanDrop.Selected)
+}
+
+// watchReadRate returns how many times realtime watch jobs may run, 0 when unlimited
+func (n *AudioNormalizer) watchReadRate() float64 {
+	rate, err := strconv.ParseFloat(n.watchPaceDrop.Selected, 64)
+	if err != nil || rate <= 0 {
+		return 0
+	}
+	return rate
+}
+
+// rescanInterval converts a rescan option to its interval
+func rescanInterval(option string) time.Duration {
+	minutes, err := strconv.Atoi(option)
+	if err != nil || minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// reconcileWatchFolder periodically compares the watched folder with the
+// history and enqueues files whose events were dropped. A saved rescan
+// interval applies without restarting watch mode, 0 pauses the rescans.
+func (n *AudioNormalizer) reconcileWatchFolder(dir string, interval time.Duration) {
+	ticker := time.NewTicker(max(interval, time.Minute))
+	defer ticker.Stop()
+	if interval <= 0 {
+		ticker.Stop()
+	}
+
+	prefs, unsubscribe := n.subscribePreferences()
+	defer unsubscribe()
+
+	for {
+		select {
+		case p := <-prefs:
+			if next := rescanInterval(p.WatchRescan); next != interval {
+				interval = next
+				if interval > 0 {
+					ticker.Reset(interval)
+					n.logToFile(n.logFile, fmt.Sprintf("rescanning watched folder every %s", interval))
+				} else {
+					ticker.Stop()
+					n.logToFile(n.logFile, "watched folder rescans off")
+				}
+			}
+		case <-ticker.C:
+			missed, err := n.findMissedFiles(dir)
+			if err != nil {
+				n.logToFile(n.logFile, fmt.Sprintf("Watch rescan of %s failed: %v", dir, err))
+				continue
+			}
+			if len(missed) > 0 {
+				n.logStatus(fmt.Sprintf("↻ Rescan found %d missed file(s)", len(missed)))
+				n.logToFile(n.logFile, fmt.Sprintf("Watch rescan enqueued %q", missed))
+			}
+			for _, path := range missed {
+				if !n.enqueueWatched(path) {
+					return
+				}
+			}
+			n.watchHistory.prune(dir)
+		case <-n.watcherStop:
+			return
+		}
+	}
+}
+
+// findMissedFiles lists settled audio files in dir that are neither handled nor queued
+func (n *AudioNormalizer) findMissedFiles(dir string) ([]string, error) {
+	files, err := n.watchedFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var missed []string
+	for _, f := range files {
+		if time.Since(f.info.ModTime()) < watchRescanSettle {
+			continue
+		}
+		if !n.watchHistory.handled(f.path, f.info) {
+			missed = append(missed, f.path)
+		}
+	}
+	return missed, nil
+}
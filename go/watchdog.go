@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/internal/audio"
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+)
+
+const (
+	// jobTimeoutFactor is how many times the input duration a single FFmpeg run may take
+	jobTimeoutFactor = 20
+	// minJobTimeout keeps very short files from getting unrealistically tight limits
+	minJobTimeout = 2 * time.Minute
+	// jobStallTimeout is how long FFmpeg may go without advancing before it is considered hung
+	jobStallTimeout = 60 * time.Second
+)
+
+// jobWatchdog returns the time limits for processing one input file
+func (n *AudioNormalizer) jobWatchdog(inputPath string) ffmpeg.Watchdog {
+	wd := ffmpeg.Watchdog{StallTimeout: jobStallTimeout}
+
+	duration, err := audio.ProbeDuration(inputPath)
+	if err != nil {
+		// Unknown length: rely on stall detection only
+		n.logToFile(n.logFile, fmt.Sprintf("No duration for %s, job timeout disabled", filepath.Base(inputPath)))
+		return wd
+	}
+
+	wd.Timeout = max(time.Duration(duration*jobTimeoutFactor)*time.Second, minJobTimeout)
+	wd.Length = time.Duration(duration * float64(time.Second))
+	n.logToFile(n.logFile, fmt.Sprintf("Job limits for %s: timeout %s, stall %s", filepath.Base(inputPath), wd.Timeout, wd.StallTimeout))
+
+	return wd
+}
+
+// logWatchdogFailure adds a diagnostic when a job was killed by its watchdog
+func (n *AudioNormalizer) logWatchdogFailure(inputPath string, err error, output []byte) {
+	if !errors.Is(err, ffmpeg.ErrTimeout) && !errors.Is(err, ffmpeg.ErrStalled) {
+		return
+	}
+
+	n.logStatus(fmt.Sprintf("✗ Encoder killed: %s - %v", filepath.Base(inputPath), err))
+	n.logToFile(n.logFile, fmt.Sprintf("Watchdog killed FFmpeg for %s: %v. Last output:\n%s", inputPath, err, ffmpeg.Tail(output, 20)))
+}
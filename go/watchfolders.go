@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var (
+	ErrWatchFolderExists = errors.New("the folder is already watched")
+	ErrWatchFolderPreset = errors.New("the preset of the watch folder no longer exists")
+	ErrNothingToWatch    = errors.New("select an input folder or add a watch folder first")
+)
+
+// watchFolder is a folder watched next to the main window's input folder,
+// processed with its own preset into its own output folder
+type watchFolder struct {
+	Dir    string `json:"dir"`
+	Preset string `json:"preset"`
+	// OutputDir overrides the preset's and the main window's output folder
+	OutputDir string `json:"output_dir,omitempty"`
+}
+
+// watchFolderStore keeps the watch folders in watch_folders.json next to the preferences
+type watchFolderStore struct {
+	mutex   sync.Mutex
+	path    string
+	Folders []watchFolder `json:"folders"`
+}
+
+func loadWatchFolders() *watchFolderStore {
+	s := &watchFolderStore{path: filepath.Join(dataDir(), "watch_folders.json")}
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, s)
+	}
+	return s
+}
+
+// save writes the store to disk, the caller holds the mutex
+func (s *watchFolderStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// list returns a copy of all watch folders
+func (s *watchFolderStore) list() []watchFolder {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.Folders)
+}
+
+// add registers a folder, refusing one that is already watched
+func (s *watchFolderStore) add(f watchFolder) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, existing := range s.Folders {
+		if pathKey(existing.Dir) == pathKey(f.Dir) {
+			return ErrWatchFolderExists
+		}
+	}
+	s.Folders = append(s.Folders, f)
+	return s.save()
+}
+
+// remove drops the watch folder for dir
+func (s *watchFolderStore) remove(dir string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.Folders = slices.DeleteFunc(s.Folders, func(f watchFolder) bool {
+		return pathKey(f.Dir) == pathKey(dir)
+	})
+	return s.save()
+}
+
+// relocate points watch folders and output folders at dir to moved instead,
+// for a folder that was moved or renamed while TNT was closed
+func (s *watchFolderStore) relocate(dir, moved string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	changed := false
+	for i := range s.Folders {
+		if pathKey(s.Folders[i].Dir) == pathKey(dir) {
+			s.Folders[i].Dir = moved
+			changed = true
+		}
+		if s.Folders[i].OutputDir != "" && pathKey(s.Folders[i].OutputDir) == pathKey(dir) {
+			s.Folders[i].OutputDir = moved
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// folderFor returns the watch folder a watched file arrived in, the deepest
+// one holding it when subfolders are watched too
+func (s *watchFolderStore) folderFor(path string) (watchFolder, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var found watchFolder
+	for _, f := range s.Folders {
+		if pathWithin(path, f.Dir) && len(f.Dir) > len(found.Dir) {
+			found = f
+		}
+	}
+	return found, found.Dir != ""
+}
+
+// outputDirs lists the output folders of the watch folders
+func (s *watchFolderStore) outputDirs() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var dirs []string
+	for _, f := range s.Folders {
+		if f.OutputDir != "" {
+			dirs = append(dirs, f.OutputDir)
+		}
+	}
+	return dirs
+}
+
+// watchedDirs lists every folder watch mode watches: the input folder of the
+// main window and the watch folders. A watch folder that is its own output
+// folder is left out.
+func (n *AudioNormalizer) watchedDirs() []string {
+	var dirs []string
+	if n.inputDir != "" {
+		dirs = append(dirs, n.inputDir)
+	}
+	for _, f := range n.watchFolders.list() {
+		if f.OutputDir != "" && pathKey(f.OutputDir) == pathKey(f.Dir) {
+			n.logStatus(fmt.Sprintf("✗ Not watching %s: %v", f.Dir, ErrOutputIsInput))
+			continue
+		}
+		if slices.ContainsFunc(dirs, func(d string) bool { return pathKey(d) == pathKey(f.Dir) }) {
+			continue
+		}
+		dirs = append(dirs, f.Dir)
+	}
+	return dirs
+}
+
+// watchConfig returns the settings a watched file is processed with: those
+// of its watch folder, or the main window's for the input folder
+func (n *AudioNormalizer) watchConfig(file string) (ProcessConfig, error) {
+	cfg := n.getProcessConfig()
+	folder, ok := n.watchFolders.folderFor(file)
+	if !ok {
+		return cfg, nil
+	}
+
+	preset, ok := n.presets.find(folder.Preset)
+	if !ok {
+		return cfg, fmt.Errorf("%w: %s", ErrWatchFolderPreset, folder.Preset)
+	}
+	cfg = preset.applyTo(cfg)
+	if folder.OutputDir != "" {
+		cfg.OutputDir = folder.OutputDir
+	}
+	return cfg, nil
+}
+
+// buildWatchFoldersTab lists the watch folders and adds new ones
+func (n *AudioNormalizer) buildWatchFoldersTab() fyne.CanvasObject {
+	text := widget.NewLabel(`
+Watch folders
+Watch more folders than the input folder, each processed with its own saved preset into its own output folder. For example, news reports dropped into /ingest/news can get the speech EQ of a News preset while /ingest/music gets a preset without processing. Without an output folder of its own, a watch folder writes to its preset's output folder, or to the main window's. The input folder of the main window is still watched with the current settings. Changes apply the next time watch mode starts.
+	`)
+	text.Wrapping = fyne.TextWrapWord
+
+	folders := n.watchFolders.list()
+
+	var list *widget.List
+	reload := func() {
+		folders = n.watchFolders.list()
+		list.Refresh()
+	}
+
+	list = widget.NewList(
+		func() int { return len(folders) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				widget.NewLabel("template"),
+			)
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			folder := folders[i]
+			border := o.(*fyne.Container)
+			label := border.Objects[0].(*widget.Label)
+			deleteBtn := border.Objects[1].(*widget.Button)
+
+			text := fmt.Sprintf("%s, preset %s", folder.Dir, folder.Preset)
+			if folder.OutputDir != "" {
+				text += " → " + folder.OutputDir
+			}
+			label.SetText(text)
+
+			deleteBtn.OnTapped = func() {
+				if err := n.watchFolders.remove(folder.Dir); err != nil {
+					dialog.ShowError(err, n.menuWindow)
+				}
+				n.saveFolderBookmarks()
+				reload()
+			}
+		},
+	)
+
+	var presetNames []string
+	for _, p := range n.presets.list() {
+		presetNames = append(presetNames, p.Name)
+	}
+	presetSelect := widget.NewSelect(presetNames, nil)
+	presetSelect.PlaceHolder = "Preset"
+
+	var dir, outputDir string
+	dirLabel := widget.NewLabel("No folder chosen")
+	outputLabel := widget.NewLabel("Preset's output folder")
+
+	chooseDir := widget.NewButton("Watch folder", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dir = uri.Path()
+			dirLabel.SetText(dir)
+		}, n.menuWindow)
+	})
+	chooseOutput := widget.NewButton("Output folder", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			outputDir = uri.Path()
+			outputLabel.SetText(outputDir)
+		}, n.menuWindow)
+	})
+
+	addBtn := widget.NewButton("Add watch folder", func() {
+		if dir == "" || presetSelect.Selected == "" {
+			dialog.ShowInformation("Watch folders", "Choose a folder and a preset first.", n.menuWindow)
+			return
+		}
+		if outputDir != "" && pathKey(outputDir) == pathKey(dir) {
+			dialog.ShowError(ErrOutputIsInput, n.menuWindow)
+			return
+		}
+		if err := n.watchFolders.add(watchFolder{Dir: dir, Preset: presetSelect.Selected, OutputDir: outputDir}); err != nil {
+			dialog.ShowError(err, n.menuWindow)
+			return
+		}
+		// Bookmarked now, the sandbox only grants the chosen folders until TNT quits
+		n.saveFolderBookmarks()
+		dir, outputDir = "", ""
+		dirLabel.SetText("No folder chosen")
+		outputLabel.SetText("Preset's output folder")
+		presetSelect.ClearSelected()
+		reload()
+	})
+
+	form := container.NewVBox(
+		container.NewBorder(nil, nil, chooseDir, nil, dirLabel),
+		presetSelect,
+		container.NewBorder(nil, nil, chooseOutput, nil, outputLabel),
+		addBtn,
+	)
+
+	return container.NewBorder(container.NewVBox(text, form, widget.NewSeparator()), nil, nil, nil, list)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// watchedFile is an audio or ZIP file found in a watched folder
+type watchedFile struct {
+	path string
+	info fs.FileInfo
+}
+
+// skipWatchSubfolder reports whether a subfolder of a watched folder is left
+// out of recursive watching: output folders inside the tree, the quarantine
+// folders and hidden folders
+func (n *AudioNormalizer) skipWatchSubfolder(dir string) bool {
+	name := filepath.Base(dir)
+	return name == watchQuarantineDir || strings.HasPrefix(name, ".") || n.inOutputTree(dir)
+}
+
+// watchedFiles lists the audio and ZIP files in a watched folder, and in its
+// subfolders when watching recursively
+func (n *AudioNormalizer) watchedFiles(dir string) ([]watchedFile, error) {
+	var files []watchedFile
+	add := func(path string, e fs.DirEntry) {
+		if !(isAudioFile(path) || isZipFile(path)) || isPartFile(path) {
+			return
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, watchedFile{path: path, info: info})
+		}
+	}
+
+	if !n.watchRecursiveCheck.Checked {
+		entries, err := os.ReadDir(platform.LongPath(dir))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				add(filepath.Join(dir, e.Name()), e)
+			}
+		}
+		return files, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			// An unreadable subfolder doesn't stop the rest of the tree
+			if path != dir {
+				n.logToFile(n.logFile, fmt.Sprintf("Watch scan skipped %s: %v", path, err))
+				return fs.SkipDir
+			}
+			return err
+		}
+		if e.IsDir() {
+			if path != dir && n.skipWatchSubfolder(path) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		add(path, e)
+		return nil
+	})
+	return files, err
+}
+
+// addWatchTree watches dir, and every subfolder of it when watching recursively
+func (n *AudioNormalizer) addWatchTree(watcher *fsnotify.Watcher, dir string) error {
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+	if !n.watchRecursiveCheck.Checked {
+		return nil
+	}
+
+	return filepath.WalkDir(dir, func(path string, e fs.DirEntry, err error) error {
+		if path == dir || err != nil || !e.IsDir() {
+			return nil
+		}
+		if n.skipWatchSubfolder(path) {
+			return fs.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			n.logToFile(n.logFile, fmt.Sprintf("Failed to watch subfolder %s: %v", path, err))
+			return fs.SkipDir
+		}
+		return nil
+	})
+}
+
+// watchNewSubfolder starts watching a folder created inside the watched
+// tree. Files moved or copied in together with the folder raise no events
+// of their own, so those already in it are queued.
+func (n *AudioNormalizer) watchNewSubfolder(watcher *fsnotify.Watcher, dir string) {
+	if n.skipWatchSubfolder(dir) {
+		return
+	}
+	if err := n.addWatchTree(watcher, dir); err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Failed to watch subfolder %s: %v", dir, err))
+		return
+	}
+	files, err := n.watchedFiles(dir)
+	if err != nil {
+		n.logToFile(n.logFile, fmt.Sprintf("Failed to scan new subfolder %s: %v", dir, err))
+		return
+	}
+	for _, f := range files {
+		n.enqueueWhenStable(f.path)
+	}
+}
+
+// watchRootFor returns the watched folder a file arrived under, the deepest
+// one when watched folders are nested
+func (n *AudioNormalizer) watchRootFor(path string) string {
+	root := ""
+	consider := func(dir string) {
+		if pathWithin(path, dir) && len(dir) > len(root) {
+			root = dir
+		}
+	}
+	if n.inputDir != "" {
+		consider(n.inputDir)
+	}
+	for _, f := range n.watchFolders.list() {
+		consider(f.Dir)
+	}
+	return root
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fremen-fi/tnt/go/internal/ffmpeg"
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+// webSuffix marks web versions next to the broadcast output
+const webSuffix = ".web"
+
+// Defaults for the web version, the usual streaming platform targets
+const (
+	webDefaultTarget   = "-16"
+	webDefaultTargetTp = "-1"
+	webDefaultFormat   = "AAC 256 kbps"
+)
+
+// webTargets returns the web version's loudness targets, moved by the same
+// gain trim or anchor offset as the broadcast target
+func (n *AudioNormalizer) webTargets(cfg ProcessConfig, broadcastTarget string) (string, string, error) {
+	webI, webTp := cfg.WebTarget, cfg.WebTargetTp
+	if strings.TrimSpace(webI) == "" {
+		webI = webDefaultTarget
+	}
+	if strings.TrimSpace(webTp) == "" {
+		webTp = webDefaultTargetTp
+	}
+	// Typed the way the broadcast target is, the minus sign is optional
+	if !strings.Contains(webI, "-") {
+		webI = "-" + strings.TrimSpace(webI)
+	}
+
+	targetI, err := strconv.ParseFloat(webI, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("web target %q is not a number", webI)
+	}
+	if _, err := strconv.ParseFloat(webTp, 64); err != nil {
+		return "", "", fmt.Errorf("web true peak %q is not a number", webTp)
+	}
+
+	nominal, _ := n.normalizationTargets()
+	if cfg.TargetI != "" {
+		nominal = cfg.TargetI
+	}
+	nominalI, _ := strconv.ParseFloat(nominal, 64)
+	if actual, err := strconv.ParseFloat(broadcastTarget, 64); err == nil {
+		targetI += actual - nominalI
+	}
+	return fmt.Sprintf("%.1f", targetI), webTp, nil
+}
+
+// encodeWebVersion encodes a second output normalized to the web target from
+// the same processed audio, reusing the broadcast output's measurements
+func (n *AudioNormalizer) encodeWebVersion(inputPath, workingPath, outputPath, broadcastTarget string, measured map[string]string, tagArgs []string, cfg ProcessConfig, watchdog ffmpeg.Watchdog) bool {
+	format := cfg.WebFormat
+	if format == "" {
+		format = webDefaultFormat
+	}
+	target, found := findExtraTarget(format)
+	if !found {
+		n.logStatus(fmt.Sprintf("✗ Web version not made: %s - unknown format %s", filepath.Base(inputPath), format))
+		return false
+	}
+
+	webI, webTp, err := n.webTargets(cfg, broadcastTarget)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Web version not made: %s - %v", filepath.Base(inputPath), err))
+		return false
+	}
+
+	filter, ok := n.loudnormFilter(inputPath, cfg, webI, webTp, measured)
+	if !ok {
+		return false
+	}
+
+	webPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + webSuffix + target.Ext
+
+	args := n.sourceArgs(inputPath, workingPath, webPath, cfg)
+	args = append(args, target.Args...)
+	args = append(args, "-af", withPiped(cfg.piped, filter))
+	if target.Ext == ".m4a" && len(tagArgs) > 0 {
+		args = append(args, "-movflags", "use_metadata_tags")
+	}
+	args = append(args, tagArgs...)
+	// Written after the broadcast tags, so these describe the web target
+	if cfg.LoudnessTags == LoudnessTagsUpdate {
+		args = append(args, updatedLoudnessTagArgs(measured, webI, webTp, targetCodec(target))...)
+	}
+
+	writePath := partPathFor(webPath)
+	args = append(args, "-f", muxerForOutput(webPath), "-y", writePath)
+
+	n.logStatus(fmt.Sprintf("→ Encoding web version (%s LUFS, %s): %s", webI, format, filepath.Base(inputPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("FFmpeg args: %q", args))
+
+	output, err := watchdog.Run(args...)
+	if err == nil {
+		err = os.Rename(platform.LongPath(writePath), platform.LongPath(webPath))
+	}
+	if err != nil {
+		os.Remove(platform.LongPath(writePath))
+		n.logWatchdogFailure(inputPath, err, output)
+		n.logStatus(fmt.Sprintf("✗ Failed web version: %s - %v", filepath.Base(inputPath), err))
+		return false
+	}
+
+	n.setOutputTime(inputPath, webPath, cfg)
+	n.logStatus(fmt.Sprintf("✓ Web version: %s", filepath.Base(webPath)))
+	return true
+}
+
+// targetCodec returns the encoder an extra target uses
+func targetCodec(target extraTarget) string {
+	if i := slices.Index(target.Args, "-c:a"); i >= 0 && i+1 < len(target.Args) {
+		return target.Args[i+1]
+	}
+	return ""
+}
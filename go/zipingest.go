@@ -0,0 +1,257 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fremen-fi/tnt/go/platform"
+)
+
+const (
+	// maxZipExtractSize caps how much audio one archive may unpack to
+	maxZipExtractSize = 16 << 30
+
+	// zipSettleInterval is how often a watched archive's size is checked
+	// while it's still being copied in
+	zipSettleInterval = 2 * time.Second
+	zipSettleTimeout  = 10 * time.Minute
+)
+
+var (
+	ErrZipNoAudio = errors.New("archive contains no audio files")
+	ErrZipTooBig  = errors.New("archive unpacks to more than 16 GB")
+)
+
+// zipSource records where an extracted file came from
+type zipSource struct {
+	Archive string
+	// Entry is the file's slash-separated path inside the archive
+	Entry string
+	// TempDir is the extraction folder the file lives in
+	TempDir string
+}
+
+// isZipFile reports whether a path looks like a ZIP archive
+func isZipFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip")
+}
+
+// zipOutputDir returns where outputs of an extracted file go: straight into
+// the output folder, or under a folder named after the archive that mirrors
+// the archive's internal folders
+func zipOutputDir(outputRoot string, src zipSource, keepFolders bool) string {
+	if !keepFolders {
+		return outputRoot
+	}
+	stem := strings.TrimSuffix(filepath.Base(src.Archive), filepath.Ext(src.Archive))
+	return filepath.Join(outputRoot, stem, filepath.FromSlash(path.Dir(src.Entry)))
+}
+
+// extractZipAudio unpacks the audio files of an archive into a new temp
+// folder. Entries that would land outside it and macOS resource forks are skipped.
+func extractZipAudio(zipPath string) (string, []zipSource, error) {
+	r, err := zip.OpenReader(platform.LongPath(zipPath))
+	if err != nil {
+		return "", nil, err
+	}
+	defer r.Close()
+
+	dir, err := os.MkdirTemp("", "tnt-zip-*")
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sources []zipSource
+	var total uint64
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		if name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			continue
+		}
+		if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), "._") {
+			continue
+		}
+		if !isAudioFile(name) {
+			continue
+		}
+
+		total += f.UncompressedSize64
+		if total > maxZipExtractSize {
+			os.RemoveAll(dir)
+			return "", nil, ErrZipTooBig
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := extractZipEntry(f, target); err != nil {
+			os.RemoveAll(dir)
+			return "", nil, fmt.Errorf("extracting %s: %w", name, err)
+		}
+		sources = append(sources, zipSource{Archive: zipPath, Entry: name, TempDir: dir})
+	}
+
+	if len(sources) == 0 {
+		os.RemoveAll(dir)
+		return "", nil, ErrZipNoAudio
+	}
+	return dir, sources, nil
+}
+
+// extractZipEntry writes one archive entry to target
+func extractZipEntry(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(platform.LongPath(target))
+	if err != nil {
+		return err
+	}
+	// The header size can lie, so never copy more than it announced
+	if _, err := io.Copy(out, io.LimitReader(in, int64(f.UncompressedSize64))); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// unpackZip extracts an archive and remembers where each file came from.
+// Returns the extracted paths in archive order.
+func (n *AudioNormalizer) unpackZip(zipPath string) ([]string, error) {
+	n.logStatus(fmt.Sprintf("→ Extracting: %s", filepath.Base(zipPath)))
+
+	dir, sources, err := extractZipAudio(zipPath)
+	if err != nil {
+		n.logStatus(fmt.Sprintf("✗ Failed to extract: %s - %v", filepath.Base(zipPath), err))
+		n.logToFile(n.logFile, fmt.Sprintf("Extracting %s failed: %v", zipPath, err))
+		return nil, err
+	}
+
+	files := make([]string, len(sources))
+	n.mutex.Lock()
+	if n.zipSources == nil {
+		n.zipSources = make(map[string]zipSource)
+	}
+	for i, src := range sources {
+		files[i] = filepath.Join(dir, filepath.FromSlash(src.Entry))
+		n.zipSources[files[i]] = src
+	}
+	n.zipTempDirs = append(n.zipTempDirs, dir)
+	n.mutex.Unlock()
+
+	n.logStatus(fmt.Sprintf("Extracted %d audio files from %s", len(files), filepath.Base(zipPath)))
+	n.logToFile(n.logFile, fmt.Sprintf("Extracted %s to %s", zipPath, dir))
+	return files, nil
+}
+
+// zipSourceFor returns the archive an input was extracted from
+func (n *AudioNormalizer) zipSourceFor(inputPath string) (zipSource, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	src, ok := n.zipSources[inputPath]
+	return src, ok
+}
+
+// addZip extracts an archive and queues its audio files
+func (n *AudioNormalizer) addZip(zipPath string) {
+	files, err := n.unpackZip(zipPath)
+	if err != nil {
+		return
+	}
+	for _, file := range n.acceptFiles(files) {
+		n.addFile(file)
+	}
+}
+
+// handleWatchedZip waits for an archive to finish copying, then handles each
+// of its audio files like a watched file and removes the extracted copies
+func (n *AudioNormalizer) handleWatchedZip(zipPath string) string {
+	if err := waitForStableSize(zipPath, zipSettleInterval, zipSettleTimeout); err != nil {
+		n.logStatus(fmt.Sprintf("✗ Archive never finished copying: %s", filepath.Base(zipPath)))
+		return "incomplete"
+	}
+
+	files, err := n.unpackZip(zipPath)
+	if err != nil {
+		return "refused"
+	}
+	defer n.releaseZip(files)
+
+	failed := 0
+	for _, file := range files {
+		if result := n.handleWatchedFile(file); result != "processed" && result != "measured" {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Sprintf("%d of %d failed", failed, len(files))
+	}
+	return "processed"
+}
+
+// releaseZip forgets extracted files and deletes their temp folder
+func (n *AudioNormalizer) releaseZip(files []string) {
+	n.mutex.Lock()
+	src := n.zipSources[files[0]]
+	for _, file := range files {
+		delete(n.zipSources, file)
+	}
+	n.zipTempDirs = slices.DeleteFunc(n.zipTempDirs, func(d string) bool { return d == src.TempDir })
+	n.mutex.Unlock()
+
+	if src.TempDir != "" {
+		os.RemoveAll(src.TempDir)
+	}
+}
+
+// releaseAllZips deletes every extraction folder, used when TNT quits
+func (n *AudioNormalizer) releaseAllZips() {
+	n.mutex.Lock()
+	dirs := n.zipTempDirs
+	n.zipTempDirs = nil
+	n.zipSources = nil
+	n.mutex.Unlock()
+
+	for _, dir := range dirs {
+		os.RemoveAll(dir)
+	}
+}
+
+// waitForStableSize waits until a file stops growing, for archives that
+// appear in the watch folder before they're fully copied
+func waitForStableSize(path string, interval, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	last := int64(-1)
+
+	for time.Now().Before(deadline) {
+		info, err := os.Stat(platform.LongPath(path))
+		if err != nil {
+			return err
+		}
+		if info.Size() == last && info.Size() > 0 {
+			return nil
+		}
+		last = info.Size()
+		time.Sleep(interval)
+	}
+	return fmt.Errorf("%s still growing after %s", path, timeout)
+}